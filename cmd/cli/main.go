@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	// Time zone database is embedded, so -time-zone works without tzdata in image
+	_ "time/tzdata"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+	"rest-refs/internal/app/repository/database"
+)
+
+const usage = `Использование:
+  cli generate-codes -referrer-id ID -count N (-expires-in DURATION | -expiration-date DATE) [-time-zone TZ] [-max-uses N] [-campaign-id ID] [-out FILE]`
+
+func main() {
+	// Initialize logger, only warnings are printed so CSV on stdout stays clean
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+	log.SetLevel(logrus.WarnLevel)
+
+	if len(os.Args) < 2 || os.Args[1] != "generate-codes" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Parse command flags
+	flags := flag.NewFlagSet("generate-codes", flag.ExitOnError)
+	referrerID := flags.Int("referrer-id", 0, "id пользователя-владельца кодов")
+	count := flags.Int("count", 0, "количество кодов")
+	expiresIn := flags.String("expires-in", "", "срок действия кодов, например 720h")
+	expirationDate := flags.String("expiration-date", "", "дата окончания действия кодов (RFC 3339 или 02.01.2006)")
+	timeZone := flags.String("time-zone", "", "часовой пояс для даты без времени, например Europe/Moscow")
+	maxUses := flags.Int("max-uses", 1, "лимит использований каждого кода")
+	campaignID := flags.Int("campaign-id", 0, "id кампании, к которой относятся коды")
+	out := flags.String("out", "", "файл для CSV, по умолчанию stdout")
+	flags.Parse(os.Args[2:])
+
+	// Create config
+	cfg, err := config.New()
+	if err != nil {
+		log.Errorf("Ошибка при чтении конфига: %v", err)
+		os.Exit(1)
+	}
+
+	// Create a new connection pool to database
+	pool, err := database.NewPool(cfg.DbUrl)
+	if err != nil {
+		log.Errorf("Ошибка при создании соединения к базе данных: %v", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	// Create a new service with Database and logger
+	db := database.NewDatabase(pool)
+	repo := repository.New(*db, log)
+	refService := api.New(repo, cfg, log)
+
+	input := models.ReferralCodeBatchCreateRequest{
+		ReferrerID: *referrerID,
+		Count:      *count,
+		ReferralCodeCreateRequest: models.ReferralCodeCreateRequest{
+			ExpirationDate: *expirationDate,
+			ExpiresIn:      *expiresIn,
+			TimeZone:       *timeZone,
+			MaxUses:        maxUses,
+		},
+	}
+
+	if *campaignID != 0 {
+		input.CampaignID = campaignID
+	}
+
+	batch, err := refService.CreateReferralCodeBatch(input, nil)
+	if err != nil {
+		log.Errorf("Ошибка при создании пакета реферальных кодов: %v", err)
+		os.Exit(1)
+	}
+
+	// Write CSV to file or stdout
+	output := os.Stdout
+	if *out != "" {
+		output, err = os.Create(*out)
+		if err != nil {
+			log.Errorf("Ошибка при создании файла %s: %v", *out, err)
+			os.Exit(1)
+		}
+		defer output.Close()
+	}
+
+	if err = refService.WriteReferralCodeBatchCSV(batch.ID, output); err != nil {
+		log.Errorf("Ошибка при выгрузке пакета реферальных кодов: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "Создан пакет %d из %d реферальных кодов\n", batch.ID, batch.Size)
+}
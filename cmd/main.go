@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"os"
+	// Time zone database is embedded, so DEFAULT_TIME_ZONE and time_zone of requests work without tzdata in image
+	_ "time/tzdata"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	_ "rest-refs/docs"
 	"rest-refs/internal/app/api"
 	"rest-refs/internal/app/config"
+	grpcServer "rest-refs/internal/app/grpc"
 	httpHandler "rest-refs/internal/app/http"
 	"rest-refs/internal/app/repository"
 	"rest-refs/internal/app/repository/database"
@@ -48,16 +52,31 @@ func main() {
 	repo := repository.New(*db, log)
 
 	// Create a new service
-	refService := api.New(repo, log)
+	refService := api.New(repo, cfg, log)
 
 	// Create Http handler
-	handler := httpHandler.New(*refService, log)
+	handler := httpHandler.New(*refService, cfg, log)
 
 	// Init Router
 	r := mux.NewRouter()
 
 	handler.RegisterRoutes(r)
 
+	// Send webhook deliveries in background
+	go refService.RunWebhookDispatcher(context.Background())
+
+	// Publish domain events recorded in outbox in background
+	go refService.RunOutboxRelay(context.Background())
+
+	// Email queued notifications in background
+	go refService.RunNotificationWorker(context.Background())
+
+	// Push outbox messages to connected referrers in background
+	go refService.RunReferralFeedListener(context.Background())
+
+	// Serve gRPC API on its own port
+	go grpcServer.New(*refService, cfg, log).StartServer(cfg.GrpcPort)
+
 	// Start server
 	handler.StartServer(cfg.HttpPort)
 }
@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
+        "/admin/campaign": {
             "post": {
-                "description": "Authenticates a user with email and password",
+                "description": "Creates campaign grouping referral codes (admin only).\ndefault_expires_in is lifetime of campaign codes created without expiration,\nreward_rules is JSON object with campaign-wide reward settings",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,45 +25,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "admin"
                 ],
-                "summary": "Login a user",
+                "summary": "Create campaign",
                 "parameters": [
                     {
-                        "description": "User credentials",
-                        "name": "input",
+                        "description": "Campaign request",
+                        "name": "CampaignRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LoginRequest"
+                            "$ref": "#/definitions/models.CampaignRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Successfully authenticated",
+                    "201": {
+                        "description": "Campaign created",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Campaign"
                         }
                     },
                     "400": {
-                        "description": "Invalid data format",
+                        "description": "Invalid data format or campaign parameters",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "Registers a new user with email and password",
+        "/admin/campaign/{id}": {
+            "put": {
+                "description": "Replaces campaign settings (admin only). Existing codes keep their expiration",
                 "consumes": [
                     "application/json"
                 ],
@@ -71,162 +83,235 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "admin"
                 ],
-                "summary": "Register a new user",
+                "summary": "Update campaign",
                 "parameters": [
                     {
-                        "description": "User data",
-                        "name": "input",
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Campaign request",
+                        "name": "CampaignRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.RegisterRequest"
+                            "$ref": "#/definitions/models.CampaignRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "User successfully registered",
+                    "200": {
+                        "description": "Campaign updated",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Campaign"
                         }
                     },
                     "400": {
-                        "description": "Invalid data format",
+                        "description": "Invalid ID, data format or campaign parameters",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
-                    "409": {
-                        "description": "User already exists",
+                    "401": {
+                        "description": "Authentication error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Campaign not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes campaign (admin only). Its codes stay valid as regular codes of their referrers",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete campaign",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Campaign deleted"
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Campaign not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             }
         },
-        "/auth/register/referral": {
-            "post": {
-                "description": "Registers a new user with a referral code",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/payouts": {
+            "get": {
+                "description": "Returns payouts in order of requests (admin only), status=requested is approval queue",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Referral"
+                    "admin"
                 ],
-                "summary": "Register a user with a referral code",
+                "summary": "List payouts",
                 "parameters": [
                     {
-                        "description": "User data with referral code",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RegisterRequest"
-                        }
+                        "type": "string",
+                        "description": "Comma-separated statuses: requested, approved, rejected, paid, failed",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "User successfully registered",
+                    "200": {
+                        "description": "List of payouts",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Payout"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid referral code or data",
+                        "description": "Invalid status",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Referral code not found",
+                    "401": {
+                        "description": "Authentication error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
-                    "409": {
-                        "description": "User already exists",
+                    "403": {
+                        "description": "Not an administrator",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             }
         },
-        "/referral/id/{referrer_id}": {
+        "/admin/payouts/{id}": {
             "get": {
-                "description": "Retrieves a list of referrals based on the referrer's ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Returns payout with history of its status changes (admin only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "referral"
+                    "admin"
                 ],
-                "summary": "Get referrals by referrer ID",
+                "summary": "Get payout",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Referrer ID",
-                        "name": "referrer_id",
+                        "description": "Payout ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of referrals",
+                        "description": "Payout",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.ReferralInfoResponse"
-                            }
+                            "$ref": "#/definitions/models.Payout"
                         }
                     },
                     "400": {
                         "description": "Invalid ID format",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "404": {
-                        "description": "Referrals not found",
+                        "description": "Payout not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             }
         },
-        "/referral_code": {
+        "/admin/payouts/{id}/approve": {
             "post": {
-                "description": "Creates a referral code for the authenticated user",
+                "description": "Approves requested payout and sends it through payout provider (admin only).\nPayout becomes paid, or failed with its amount returned to user if provider declined it.\nIf provider did not answer whether payout was sent, payout is left approved and 502 is returned.\nPayout left approved is sent again with the same idempotency key.\nRequest body is optional",
                 "consumes": [
                     "application/json"
                 ],
@@ -234,207 +319,3932 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "referral_code"
+                    "admin"
                 ],
-                "summary": "Create a new referral code",
+                "summary": "Approve payout",
                 "parameters": [
                     {
-                        "description": "Referral code request",
-                        "name": "ReferralCodeCreateRequest",
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment",
+                        "name": "PayoutDecisionRequest",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ReferralCodeCreateRequest"
+                            "$ref": "#/definitions/models.PayoutDecisionRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Referral code created",
+                    "200": {
+                        "description": "Processed payout",
                         "schema": {
-                            "$ref": "#/definitions/models.ReferralCodeResponse"
+                            "$ref": "#/definitions/models.Payout"
                         }
                     },
                     "400": {
-                        "description": "Invalid data format or date",
+                        "description": "Invalid ID or data format",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "401": {
                         "description": "Authentication error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
-                    "409": {
-                        "description": "Referral code already exists",
+                    "403": {
+                        "description": "Not an administrator",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "404": {
+                        "description": "Payout not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Deletes the referral code of the authenticated user",
-                "tags": [
-                    "referral_code"
-                ],
-                "summary": "Delete an active referral code",
-                "responses": {
-                    "204": {
-                        "description": "Referral code deleted"
                     },
-                    "401": {
-                        "description": "Authentication error",
+                    "409": {
+                        "description": "Payout is not waiting for approval or sending",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Referral code not found",
+                    "500": {
+                        "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "502": {
+                        "description": "Provider did not confirm sending, approve again later",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             }
         },
-        "/referral_code/email/{email}": {
-            "get": {
-                "description": "Retrieves the referral code by the email of the referrer",
+        "/admin/payouts/{id}/reject": {
+            "post": {
+                "description": "Rejects requested payout and returns its amount to user (admin only). Request body is optional",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "referral_code"
+                    "admin"
                 ],
-                "summary": "Get referral code by referrer email",
+                "summary": "Reject payout",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Referrer email",
-                        "name": "email",
+                        "type": "integer",
+                        "description": "Payout ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Reason of rejection",
+                        "name": "PayoutDecisionRequest",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.PayoutDecisionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Referral code found",
+                        "description": "Rejected payout",
                         "schema": {
-                            "$ref": "#/definitions/models.ReferralCodeResponse"
+                            "$ref": "#/definitions/models.Payout"
                         }
                     },
                     "400": {
-                        "description": "Email cannot be empty",
+                        "description": "Invalid ID or data format",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "404": {
-                        "description": "Referral code not found",
+                        "description": "Payout not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Payout is not waiting for approval",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.LoginRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
-                }
-            }
         },
-        "models.Referral": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string"
-                },
+        "/admin/referral/export": {
+            "get": {
+                "description": "Downloads referrals of all referrers, or of one referrer if referrer_id is set, as CSV, XLSX\nor JSON Lines (admin only). Accepts the same filters and sort order as referral listing.\nRows are streamed from database with chunked transfer encoding",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Export referrals of program",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Referrer ID",
+                        "name": "referrer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Export format: csv (default), xlsx or jsonl",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated statuses: pending, qualified, rejected, reversed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Referral code ID",
+                        "name": "code_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of creation range, RFC 3339 timestamp or date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of creation range, RFC 3339 timestamp or date (inclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: -created_at (default) or created_at",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Export file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid referrer ID, format, status or filters",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/referral/stats": {
+            "get": {
+                "description": "Returns statistics of whole referral program, or of one referrer if referrer_id is set (admin only).\nBreakdown of whole program lists only codes with clicks or sign-ups in range",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get referral program statistics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Referrer ID",
+                        "name": "referrer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of range: RFC 3339 timestamp or date, e.g. 2024-10-01",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range: RFC 3339 timestamp or date, date is inclusive",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period of time series: day (default) or week",
+                        "name": "granularity",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Referral statistics",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid referrer ID, range or granularity",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/referral_code/batch": {
+            "post": {
+                "description": "Generates count unique referral codes owned by referrer in one batch (admin only).\nCodes are single-use unless max_uses is set, expiration fields are the same as for single code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Generate batch of referral codes",
+                "parameters": [
+                    {
+                        "description": "Batch request",
+                        "name": "ReferralCodeBatchCreateRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralCodeBatchCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Batch created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralCodeBatch"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format, count or date",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referrer or campaign not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Campaign is not active",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/referral_code/batch/{id}/csv": {
+            "get": {
+                "description": "Returns CSV with code, share_url, expires_at, max_uses and uses_count columns (admin only)",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Download batch of referral codes as CSV",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Batch not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/referral_reviews": {
+            "get": {
+                "description": "Returns referrals placed into manual review queue by fraud checks with their risk score and signals\n(admin only). Without status only pending reviews are returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List referral reviews",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated review statuses: pending, approved, rejected",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of referral reviews",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReferralReview"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/referral_reviews/{id}/approve": {
+            "post": {
+                "description": "Approves referral held for manual review and credits rewards withheld during review (admin only).\nRequest body is optional",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Approve referral review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Referral ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment",
+                        "name": "ReferralReviewDecisionRequest",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralReviewDecisionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reviewed referral",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralReview"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or data format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral review not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Referral is not waiting for review",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/referral_reviews/{id}/reject": {
+            "post": {
+                "description": "Rejects referral held for manual review and reverses its rewards (admin only).\nRequest body is optional",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reject referral review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Referral ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason of rejection",
+                        "name": "ReferralReviewDecisionRequest",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralReviewDecisionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reviewed referral",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralReview"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or data format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral review not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Referral is not waiting for review",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reward_rules": {
+            "get": {
+                "description": "Returns all reward rule sets from newest to oldest (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List reward rule sets",
+                "responses": {
+                    "200": {
+                        "description": "List of rule sets",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RewardRuleSet"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates set of reward rules written in JSON or YAML (admin only).\nRules set or multiply reward amounts by trigger, party, campaign, date range and referral number,\ncaps limit what party may earn per period. Active rule set replaces previously active one\nof the same campaign, or the global one if campaign_id is not set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create reward rule set",
+                "parameters": [
+                    {
+                        "description": "Rule set request",
+                        "name": "RewardRuleSetRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RewardRuleSetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Rule set created",
+                        "schema": {
+                            "$ref": "#/definitions/models.RewardRuleSet"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format or rules",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reward_rules/dry_run": {
+            "post": {
+                "description": "Evaluates rewards of referrer and referee for creation and qualification of referral\nat given moment without posting them (admin only). If rule_set is given, it is evaluated\ninstead of active rule sets",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Dry run of reward rules",
+                "parameters": [
+                    {
+                        "description": "Dry run request",
+                        "name": "RewardDryRunRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RewardDryRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Evaluated rewards",
+                        "schema": {
+                            "$ref": "#/definitions/models.RewardDryRunResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format or rules",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reward_rules/{id}": {
+            "get": {
+                "description": "Returns rule set with its source and normalized rules (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get reward rule set",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule set ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rule set",
+                        "schema": {
+                            "$ref": "#/definitions/models.RewardRuleSet"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rule set not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces rules of rule set (admin only). Rewards already posted are not recalculated",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Update reward rule set",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule set ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule set request",
+                        "name": "RewardRuleSetRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RewardRuleSetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rule set updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.RewardRuleSet"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, data format or rules",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rule set not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes rule set (admin only). Rewards already posted by its rules are kept",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete reward rule set",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule set ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Rule set deleted"
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rule set not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Authenticates a user with email and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Login a user",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully authenticated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid email or password",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Registers a new user with email and password.\nIf request has attribution cookie set by share link /r/{code}, sign-up is attributed to that code\nwithin attribution window. Optional X-Device-Fingerprint header identifies device for fraud checks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User successfully registered",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "User already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register/referral": {
+            "post": {
+                "description": "Registers a new user with a referral code. Referral is scored by fraud checks using client IP\nand optional X-Device-Fingerprint header, rewards for high-risk referrals wait for manual review",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Referral"
+                ],
+                "summary": "Register a user with a referral code",
+                "parameters": [
+                    {
+                        "description": "User data with referral code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User successfully registered",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or malformed referral code or data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral code not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "User already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/campaign": {
+            "get": {
+                "description": "Returns all campaigns with their codes and participants counts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "campaign"
+                ],
+                "summary": "List campaigns",
+                "responses": {
+                    "200": {
+                        "description": "List of campaigns",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Campaign"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/campaign/{id}": {
+            "get": {
+                "description": "Returns campaign with its codes and participants counts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "campaign"
+                ],
+                "summary": "Get campaign",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Campaign",
+                        "schema": {
+                            "$ref": "#/definitions/models.Campaign"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Campaign not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "description": "Executes query over the authenticated user (me), other users (user, admin only), their referral codes,\nreferrals, statistics and campaign statistics in single round trip. Nested fields are loaded\nin batches. Queries deeper than GRAPHQL_MAX_DEPTH or more complex than GRAPHQL_MAX_COMPLEXITY\nare rejected. Errors of query are returned in errors field of response with status 200",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "Execute GraphQL query",
+                "parameters": [
+                    {
+                        "description": "GraphQL query",
+                        "name": "GraphQLRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GraphQLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "GraphQL response with data and errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard": {
+            "get": {
+                "description": "Ranks referrers by metric over calendar window up to now, optionally within campaign,\nand returns page of ranking with rank of the authenticated user.\nUsers who did not opt in are listed without display name. Ranking is cached for a short time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboard"
+                ],
+                "summary": "Get leaderboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "referrals, qualified (default) or rewards",
+                        "name": "metric",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "day, week, month (default), year or total",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "campaign_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, from 1 to 100, default 20",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of ranked users to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Leaderboard",
+                        "schema": {
+                            "$ref": "#/definitions/models.LeaderboardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/leaderboard": {
+            "get": {
+                "description": "Returns whether the authenticated user is shown on leaderboards by display name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboard"
+                ],
+                "summary": "Get leaderboard settings",
+                "responses": {
+                    "200": {
+                        "description": "Leaderboard settings",
+                        "schema": {
+                            "$ref": "#/definitions/models.LeaderboardSettings"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Opts the authenticated user in or out of public display on leaderboards.\nDisplay name is required to opt in, email is never shown",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboard"
+                ],
+                "summary": "Update leaderboard settings",
+                "parameters": [
+                    {
+                        "description": "Leaderboard settings",
+                        "name": "LeaderboardSettings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LeaderboardSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Leaderboard settings",
+                        "schema": {
+                            "$ref": "#/definitions/models.LeaderboardSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format or display name",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/notifications": {
+            "get": {
+                "description": "Returns how the authenticated user is emailed about new referrals and language of emails",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get notification settings",
+                "responses": {
+                    "200": {
+                        "description": "Notification settings",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationSettings"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Sets whether the authenticated user is emailed about every new referral at once (immediate),\nonce a day in digest (digest) or not at all (off), and language of emails (ru or en).\nNotifications already queued are not sent after mode is set to off. Omitted fields are left unchanged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Update notification settings",
+                "parameters": [
+                    {
+                        "description": "Notification settings",
+                        "name": "NotificationSettings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notification settings",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format, mode or locale",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/payouts": {
+            "get": {
+                "description": "Returns payouts of the authenticated user from newest to oldest",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rewards"
+                ],
+                "summary": "Get my payouts",
+                "responses": {
+                    "200": {
+                        "description": "List of payouts",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Payout"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Reserves amount of available rewards of the authenticated user for payout to destination.\nAmount must not be less than minimum payout, rewards of referrals still inside chargeback window\nare not available",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rewards"
+                ],
+                "summary": "Request payout",
+                "parameters": [
+                    {
+                        "description": "Payout request",
+                        "name": "PayoutRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PayoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Payout requested",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payout"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format, amount or destination",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough available rewards",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/rewards": {
+            "get": {
+                "description": "Returns reward balance of the authenticated user and history of ledger entries from newest to oldest.\nRewards are credited when referral is created or qualified and reversed when it is rejected or reversed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rewards"
+                ],
+                "summary": "Get my rewards",
+                "responses": {
+                    "200": {
+                        "description": "Balance and history",
+                        "schema": {
+                            "$ref": "#/definitions/models.RewardsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/r/{code}": {
+            "get": {
+                "description": "Records click (time, hashed IP, user agent, referer and UTM parameters), sets attribution cookie\nfor active code and redirects to landing page with code in \"ref\" query parameter.\nUnknown codes are redirected to landing page without cookie",
+                "tags": [
+                    "referral_code"
+                ],
+                "summary": "Follow referral share link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Referral code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UTM source",
+                        "name": "utm_source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UTM medium",
+                        "name": "utm_medium",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UTM campaign",
+                        "name": "utm_campaign",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UTM term",
+                        "name": "utm_term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UTM content",
+                        "name": "utm_content",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirect to landing page"
+                    }
+                }
+            }
+        },
+        "/referral/events": {
+            "post": {
+                "description": "Receives event from external system (e.g. email_verified, first_purchase, refund) authenticated\nby X-Api-Key header. Events move referral from pending to qualified, rejected or reversed\naccording to configured rules. Repeated event_id is accepted once, later copies are reported as duplicate",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral"
+                ],
+                "summary": "Ingest referral event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Events API key",
+                        "name": "X-Api-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Referral event",
+                        "name": "ReferralEventRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Duplicate event, nothing changed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralEventResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Event processed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral/export": {
+            "get": {
+                "description": "Downloads referrals of the authenticated user as CSV, XLSX or JSON Lines.\nAccepts the same filters and sort order as referral listing, all matching referrals are exported.\nRows are streamed from database with chunked transfer encoding",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "referral"
+                ],
+                "summary": "Export referrals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export format: csv (default), xlsx or jsonl",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated statuses: pending, qualified, rejected, reversed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Referral code ID",
+                        "name": "code_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of creation range, RFC 3339 timestamp or date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of creation range, RFC 3339 timestamp or date (inclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: -created_at (default) or created_at",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Export file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format, status or filters",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral/id/{referrer_id}": {
+            "get": {
+                "description": "Retrieves a page of referrals based on the referrer's ID, newest first by default.\nNumber of referrals matching filters is returned in X-Total-Count header. Cursor of next page is\nreturned in X-Next-Cursor header and in Link header with rel=\"next\", both are absent on last page",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral"
+                ],
+                "summary": "Get referrals by referrer ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Referrer ID",
+                        "name": "referrer_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated statuses: pending, qualified, rejected, reversed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Referral code ID",
+                        "name": "code_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of creation range, RFC 3339 timestamp or date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of creation range, RFC 3339 timestamp or date (inclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: -created_at (default) or created_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of page from X-Next-Cursor header of previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, from 1 to 500, default 50",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of referrals",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReferralInfoResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of next page"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of referrals matching filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format, status or listing parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral/id/{referrer_id}/campaigns": {
+            "get": {
+                "description": "Returns codes and referrals counts of referrer grouped by campaign.\nCodes outside of any campaign are reported with null campaign_id.\nStats are returned only to referrer itself and to administrators",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral"
+                ],
+                "summary": "Get referral stats per campaign",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Referrer ID",
+                        "name": "referrer_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stats per campaign",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CampaignReferralStats"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Stats of another referrer",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral/stats": {
+            "get": {
+                "description": "Returns totals, time series of clicks, sign-ups and qualifications, conversion rates\nand breakdown by referral code for referrals of the authenticated user.\nRange defaults to last 30 days including today, dates are interpreted in default time zone",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral"
+                ],
+                "summary": "Get referral statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start of range: RFC 3339 timestamp or date, e.g. 2024-10-01",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range: RFC 3339 timestamp or date, date is inclusive",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period of time series: day (default) or week",
+                        "name": "granularity",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Referral statistics",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid range or granularity",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral/stream": {
+            "get": {
+                "description": "Pushes events of the authenticated user as Server-Sent Events: referral.created,\nreferral.status_changed and referral_code.created, revoked, rotated, paused and resumed.\nEvery event has id, type of event is its SSE event name and event JSON is its data.\nReconnecting client sends Last-Event-ID header (EventSource does it automatically) or last_event_id\nand gets events it missed. Event \"reset\" means that too many events were missed or Last-Event-ID\nis older than events still kept, so state must be reloaded.\nBrowser EventSource cannot set headers, so token may be passed as access_token",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "referral"
+                ],
+                "summary": "Stream referral feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of last event seen by client",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of last event seen by client, used when header is not set",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JWT, used when Authorization header is not set",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of events",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralFeedEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid last event ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral/stream/ws": {
+            "get": {
+                "description": "WebSocket alternative to /referral/stream. Every event is sent as JSON text message,\nconnection is kept alive by ping frames, messages from client are ignored.\nReconnecting client passes last_event_id and gets events it missed, \"reset\" is sent as in SSE feed.\nBrowser WebSocket cannot set headers, so token may be passed as access_token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral"
+                ],
+                "summary": "Stream referral feed over WebSocket",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of last event seen by client",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JWT, used when Authorization header is not set",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Stream of events",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralFeedEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid last event ID or WebSocket handshake",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral/tree": {
+            "get": {
+                "description": "Returns referrals of the authenticated user, referrals of referred users and so on\nup to depth levels, with number of referrals per level. Depth defaults to maximum allowed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral"
+                ],
+                "summary": "Get referral tree",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of levels, from 1 to maximum allowed",
+                        "name": "depth",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Referral tree",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralTreeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid depth",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral_code": {
+            "post": {
+                "description": "Creates a referral code for the authenticated user.\nExpiration is set either by expiration_date (RFC 3339 timestamp or date-only value meaning\nend of day in time_zone) or by relative expires_in duration (e.g. \"72h\").\nCode may be linked to active campaign by campaign_id, then expiration defaults to campaign's\ndefault lifetime and never exceeds end of campaign",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral_code"
+                ],
+                "summary": "Create a new referral code",
+                "parameters": [
+                    {
+                        "description": "Referral code request",
+                        "name": "ReferralCodeCreateRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralCodeCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Referral code created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format, date, time zone or usage limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Campaign not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Referral code already exists or campaign is not active",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the referral code of the authenticated user",
+                "tags": [
+                    "referral_code"
+                ],
+                "summary": "Delete an active referral code",
+                "responses": {
+                    "204": {
+                        "description": "Referral code deleted"
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral code not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral_code/email/{email}": {
+            "get": {
+                "description": "Retrieves the referral code by the email of the referrer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral_code"
+                ],
+                "summary": "Get referral code by referrer email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Referrer email",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Referral code found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Email cannot be empty",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral code not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral_code/history": {
+            "get": {
+                "description": "Returns referral links of the authenticated user, each with all its codes in order of rotation\nand referrals and clicks counts summed over these codes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral_code"
+                ],
+                "summary": "Get referral links with rotation history",
+                "responses": {
+                    "200": {
+                        "description": "Referral links",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReferralLink"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral_code/pause": {
+            "put": {
+                "description": "Pauses the referral code of the authenticated user, paused code can not be used for registration",
+                "tags": [
+                    "referral_code"
+                ],
+                "summary": "Pause an active referral code",
+                "responses": {
+                    "204": {
+                        "description": "Referral code paused"
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral code not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral_code/resume": {
+            "put": {
+                "description": "Resumes the referral code of the authenticated user",
+                "tags": [
+                    "referral_code"
+                ],
+                "summary": "Resume a paused referral code",
+                "responses": {
+                    "204": {
+                        "description": "Referral code resumed"
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral code not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral_code/rotate": {
+            "post": {
+                "description": "Issues new referral code for the authenticated user on the same referral link.\nOld code stays valid for grace_period (default from config), \"0s\" revokes it immediately.\nRequest body is optional",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral_code"
+                ],
+                "summary": "Rotate referral code",
+                "parameters": [
+                    {
+                        "description": "Rotation request",
+                        "name": "ReferralCodeRotateRequest",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralCodeRotateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "New referral code",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format or grace period",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral code not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral_code/{code}/status": {
+            "get": {
+                "description": "Public endpoint for sign-up form: reports whether the code exists and is active,\nexpired, paused or exhausted, and returns referrer's public display name.\nRequests are rate limited per IP to prevent code enumeration",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referral_code"
+                ],
+                "summary": "Check referral code status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Referral code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Referral code status",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferralCodeStatusResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/referral_code/{id}/qr": {
+            "get": {
+                "description": "Renders PNG or SVG QR code encoding share link /r/{code} of the authenticated user's referral code",
+                "produces": [
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "referral_code"
+                ],
+                "summary": "Get QR code of referral share link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Referral code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Image format: png (default) or svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Image width and height in pixels, 64-2048 (default 256)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Error correction level: L, M (default), Q or H",
+                        "name": "level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quiet zone width in modules, 0-16 (default 4)",
+                        "name": "margin",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "QR code image",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral code not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Returns webhook subscriptions of the authenticated user without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "List of subscriptions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookSubscription"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribes endpoint of the authenticated user to events of their referrals and referral codes.\nEmpty events subscribe to all events, all_users subscribes to events of all users (admin only).\nSigning secret is returned only in this response. Every delivery is POST with JSON body\n{id, type, created_at, data} and headers X-Webhook-Event, X-Webhook-Delivery, X-Webhook-Timestamp and\nX-Webhook-Signature \"t=\u003ctimestamp\u003e,v1=\u003chex HMAC-SHA256 of \"\u003ctimestamp\u003e.\u003cbody\u003e\" keyed by secret\u003e\".\nNon-2xx responses are retried with exponential delay, delivery is dead after last attempt",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Webhook subscription",
+                        "name": "WebhookSubscriptionRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Subscription with signing secret",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data format, URL or event type",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Subscription to all users requires administrator",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "delete": {
+                "description": "Deletes webhook subscription of the authenticated user with its delivery log",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Subscription deleted"
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Returns latest 100 deliveries of webhook subscription of the authenticated user, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated delivery statuses: pending, delivered, dead",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of deliveries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format or status",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{delivery_id}": {
+            "get": {
+                "description": "Returns delivery of webhook subscription of the authenticated user with event and all attempts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delivery with attempts",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription or delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{delivery_id}/redeliver": {
+            "post": {
+                "description": "Queues delivered or dead delivery of webhook subscription of the authenticated user to be sent again\nwith full number of attempts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Queued delivery",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription or delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Delivery is still pending",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.Campaign": {
+            "type": "object",
+            "properties": {
+                "codes_count": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "default_expires_in": {
+                    "type": "string",
+                    "example": "720h0m0s"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_participants": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "participants_count": {
+                    "type": "integer"
+                },
+                "reward_rules": {
+                    "type": "object"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CampaignReferralStats": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "campaign_name": {
+                    "type": "string"
+                },
+                "codes_count": {
+                    "type": "integer"
+                },
+                "referrals_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CampaignRequest": {
+            "type": "object",
+            "properties": {
+                "default_expires_in": {
+                    "type": "string",
+                    "example": "720h"
+                },
+                "ends_at": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00+03:00"
+                },
+                "max_participants": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Новогодняя акция"
+                },
+                "reward_rules": {
+                    "type": "object"
+                },
+                "starts_at": {
+                    "type": "string",
+                    "example": "2024-12-01T00:00:00+03:00"
+                }
+            }
+        },
+        "models.GraphQLRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "{ me { id email referral_codes { code referrals { email status } } } }"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "models.LeaderboardEntry": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string",
+                    "example": "Alice"
+                },
+                "is_me": {
+                    "type": "boolean"
+                },
+                "rank": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 15
+                }
+            }
+        },
+        "models.LeaderboardResponse": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LeaderboardEntry"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "me": {
+                    "$ref": "#/definitions/models.LeaderboardEntry"
+                },
+                "metric": {
+                    "type": "string",
+                    "example": "qualified"
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "since": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "window": {
+                    "type": "string",
+                    "example": "month"
+                }
+            }
+        },
+        "models.LeaderboardSettings": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string",
+                    "example": "Alice"
+                },
+                "opt_in": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NotificationSettings": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "type": "string",
+                    "example": "ru"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "immediate"
+                }
+            }
+        },
+        "models.NotificationSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "digest"
+                }
+            }
+        },
+        "models.Payout": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "destination": {
+                    "type": "string",
+                    "example": "card:4276********1234"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PayoutStatusChange"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "fake"
+                },
+                "provider_reference": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "requested"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PayoutDecisionRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Подозрение на мошенничество"
+                }
+            }
+        },
+        "models.PayoutRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "destination": {
+                    "type": "string",
+                    "example": "card:4276********1234"
+                }
+            }
+        },
+        "models.PayoutStatusChange": {
+            "type": "object",
+            "properties": {
+                "changed_by": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payout_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to_status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "user_already_exists"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "Такой пользователь уже существует"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProblemField"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/auth/register"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "3f2b8c1d9e4a7b6c5d0e1f2a3b4c5d6e"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 409
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Conflict"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:rest-refs:problem:user_already_exists"
+                }
+            }
+        },
+        "models.ProblemField": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "limit"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "limit должен быть от 1 до 100"
+                }
+            }
+        },
+        "models.Referral": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "referral_click_id": {
+                    "type": "integer"
+                },
+                "referral_code_id": {
+                    "type": "integer"
+                },
+                "referrer_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "status_changed_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReferralCode": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "integer"
+                },
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "link_id": {
+                    "type": "integer"
+                },
+                "max_uses": {
+                    "type": "integer"
+                },
+                "paused_at": {
+                    "type": "string"
+                },
+                "referrals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Referral"
+                    }
+                },
+                "referrer_id": {
+                    "type": "integer"
+                },
+                "rotated_at": {
+                    "type": "string"
+                },
+                "superseded_by": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uses_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReferralCodeBatch": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_uses": {
+                    "type": "integer"
+                },
+                "referrer_id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReferralCodeBatchCreateRequest": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "expiration_date": {
+                    "type": "string",
+                    "example": "2024-10-20T18:00:00+03:00"
+                },
+                "expires_in": {
+                    "type": "string",
+                    "example": "72h"
+                },
+                "max_uses": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "referrer_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "time_zone": {
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                }
+            }
+        },
+        "models.ReferralCodeCreateRequest": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "expiration_date": {
+                    "type": "string",
+                    "example": "2024-10-20T18:00:00+03:00"
+                },
+                "expires_in": {
+                    "type": "string",
+                    "example": "72h"
+                },
+                "max_uses": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "time_zone": {
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                }
+            }
+        },
+        "models.ReferralCodeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "expiration": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReferralCodeRotateRequest": {
+            "type": "object",
+            "properties": {
+                "grace_period": {
+                    "type": "string",
+                    "example": "24h"
+                }
+            }
+        },
+        "models.ReferralCodeStats": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "clicks": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "code": {
+                    "type": "string",
+                    "example": "ABCD-EFGH"
+                },
+                "conversion": {
+                    "$ref": "#/definitions/models.ReferralConversion"
+                },
+                "qualified": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "referral_code_id": {
+                    "type": "integer"
+                },
+                "sign_ups": {
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "models.ReferralCodeStatusResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "exists": {
+                    "type": "boolean"
+                },
+                "expiration": {
+                    "type": "string"
+                },
+                "referrer_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "models.ReferralConversion": {
+            "type": "object",
+            "properties": {
+                "click_to_qualified": {
+                    "type": "number",
+                    "example": 0.125
+                },
+                "click_to_sign_up": {
+                    "type": "number",
+                    "example": 0.25
+                },
+                "sign_up_to_qualified": {
+                    "type": "number",
+                    "example": 0.5
+                }
+            }
+        },
+        "models.ReferralEventRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "friend@example.com"
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "shop-order-10042"
+                },
+                "occurred_at": {
+                    "type": "string",
+                    "example": "2024-10-26T12:00:00Z"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "referral_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "type": {
+                    "type": "string",
+                    "example": "first_purchase"
+                }
+            }
+        },
+        "models.ReferralEventResponse": {
+            "type": "object",
+            "properties": {
+                "duplicate": {
+                    "type": "boolean"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "referral_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "qualified"
+                }
+            }
+        },
+        "models.ReferralFeedEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "type": {
+                    "type": "string",
+                    "example": "referral.created"
+                }
+            }
+        },
+        "models.ReferralInfoResponse": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "referral_id": {
+                    "type": "integer"
+                },
+                "referrer_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "qualified"
+                },
+                "status_changed_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReferralLink": {
+            "type": "object",
+            "properties": {
+                "clicks_count": {
+                    "type": "integer"
+                },
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReferralCode"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "referrals_count": {
+                    "type": "integer"
+                },
+                "referrer_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReferralReview": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "referral_code_id": {
+                    "type": "integer"
+                },
+                "referral_id": {
+                    "type": "integer"
+                },
+                "referrer_id": {
+                    "type": "integer"
+                },
+                "review_reason": {
+                    "type": "string"
+                },
+                "review_status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "integer"
+                },
+                "risk_score": {
+                    "type": "integer",
+                    "example": 70
+                },
+                "risk_signals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RiskSignal"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                }
+            }
+        },
+        "models.ReferralReviewDecisionRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Same household, confirmed by support"
+                }
+            }
+        },
+        "models.ReferralStatsPoint": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "qualified": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "sign_ups": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.ReferralStatsResponse": {
+            "type": "object",
+            "properties": {
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReferralCodeStats"
+                    }
+                },
+                "conversion": {
+                    "$ref": "#/definitions/models.ReferralConversion"
+                },
+                "from": {
+                    "type": "string"
                 },
-                "referral_code_id": {
-                    "type": "integer"
+                "granularity": {
+                    "type": "string",
+                    "example": "day"
                 },
                 "referrer_id": {
                     "type": "integer"
                 },
-                "updated_at": {
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReferralStatsPoint"
+                    }
+                },
+                "to": {
                     "type": "string"
+                },
+                "totals": {
+                    "$ref": "#/definitions/models.ReferralStatsTotals"
                 }
             }
         },
-        "models.ReferralCodeCreateRequest": {
+        "models.ReferralStatsTotals": {
             "type": "object",
-            "required": [
-                "expiration_date"
-            ],
             "properties": {
-                "expiration_date": {
-                    "type": "string"
+                "clicks": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "pending": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "qualified": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "rejected": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "reversed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "sign_ups": {
+                    "type": "integer",
+                    "example": 30
                 }
             }
         },
-        "models.ReferralCodeResponse": {
+        "models.ReferralTreeLevel": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "string"
+                "count": {
+                    "type": "integer",
+                    "example": 4
                 },
-                "expiration": {
-                    "type": "string"
+                "level": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "models.ReferralInfoResponse": {
+        "models.ReferralTreeNode": {
             "type": "object",
             "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReferralTreeNode"
+                    }
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "email": {
                     "type": "string"
                 },
+                "level": {
+                    "type": "integer",
+                    "example": 1
+                },
                 "referral_id": {
                     "type": "integer"
                 },
                 "referrer_id": {
                     "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "qualified"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReferralTreeResponse": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "levels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReferralTreeLevel"
+                    }
+                },
+                "referrals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReferralTreeNode"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -445,6 +4255,9 @@ const docTemplate = `{
                 "password"
             ],
             "properties": {
+                "display_name": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
@@ -456,18 +4269,223 @@ const docTemplate = `{
                 }
             }
         },
+        "models.RewardDryRunRequest": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string",
+                    "example": "2024-12-15T12:00:00Z"
+                },
+                "referral_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rule_set": {
+                    "$ref": "#/definitions/models.RewardRuleSetRequest"
+                }
+            }
+        },
+        "models.RewardDryRunResponse": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "referral_id": {
+                    "type": "integer"
+                },
+                "referral_number": {
+                    "type": "integer"
+                },
+                "rewards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RewardEstimate"
+                    }
+                },
+                "rule_set_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RewardEntry": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "referral_id": {
+                    "type": "integer"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RewardEstimate": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "applied_rules": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "base_amount": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "cap_remaining": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "party": {
+                    "type": "string",
+                    "example": "referrer"
+                },
+                "trigger": {
+                    "type": "string",
+                    "example": "qualified"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RewardRuleSet": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "yaml"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rules": {
+                    "type": "object"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RewardRuleSetRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "yaml"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Декабрь"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RewardsResponse": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "balance": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "held": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RewardEntry"
+                    }
+                }
+            }
+        },
+        "models.RiskSignal": {
+            "type": "object",
+            "properties": {
+                "check": {
+                    "type": "string",
+                    "example": "same_ip"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 40
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
+                "display_name": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "is_admin": {
+                    "type": "boolean"
+                },
+                "leaderboard_opt_in": {
+                    "type": "boolean"
+                },
                 "password": {
                     "type": "string"
                 },
@@ -481,6 +4499,154 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/models.WebhookEvent"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDeliveryAttempt"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_response_status": {
+                    "type": "integer",
+                    "example": 502
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookDeliveryAttempt": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "attempted_at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "error": {
+                    "type": "string"
+                },
+                "response_status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "models.WebhookEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "referral.created"
+                }
+            }
+        },
+        "models.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "all_users": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "referral.created",
+                        "referral_code.created"
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_5f2b..."
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://crm.example.com/hooks/referrals"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "all_users": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "referral.created",
+                        "referral_code.created"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://crm.example.com/hooks/referrals"
+                }
+            }
         }
     }
 }`
@@ -10,9 +10,16 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"rest-refs/internal/app/models"
 	"rest-refs/internal/app/repository"
+	"rest-refs/internal/app/repository/postgresql"
 )
 
 var ErrUserAlreadyExists = errors.New("пользователь уже существует")
+var ErrInvalidCredentials = errors.New("неверный email или пароль")
+var ErrInvalidToken = errors.New("неверный токен")
+
+// dummyPasswordHash is compared with password of unknown user, so that login takes the same time
+// whether user exists or not
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
 
 // AuthService provides authentication services using user repository
 type AuthService struct {
@@ -33,21 +40,17 @@ func (as *AuthService) GetUserByEmail(email string) (models.User, error) {
 	return as.repo.GetByEmail(email)
 }
 
+// GetUserByID retrieves user by id from repository
+func (as *AuthService) GetUserByID(id int) (models.User, error) {
+	as.logger.Debugf("GetUserByID[service]: Получение пользователя по id: %d", id)
+	return as.repo.GetByID(id)
+}
+
 func (as *AuthService) RegisterUser(user models.User) error {
 	as.logger.Debugf("RegisterUser[service]: Регистрация пользователя с email: %s", user.Email)
 
-	// Check if the user with the provided email already exists
-	_, err := as.GetUserByEmail(user.Email)
-	if err == nil {
-		as.logger.Errorf("RegisterUser[service]: Регистрация пользователя не удалось: " +
-			"Пользователь с таким email уже существует")
-		return ErrUserAlreadyExists
-	}
-
-	// Hash user's password before saving
-	user.Password, err = generatePasswordHash(user.Password)
+	user, err := as.prepareUser(user)
 	if err != nil {
-		as.logger.Errorf("RegisterUser[service]: Ошибка при хэшировании пароля: %s", err)
 		return err
 	}
 
@@ -62,14 +65,40 @@ func (as *AuthService) RegisterUser(user models.User) error {
 	return nil
 }
 
+// prepareUser checks that email of new user is not taken yet and hashes password of user before saving
+func (as *AuthService) prepareUser(user models.User) (models.User, error) {
+	// Check if the user with the provided email already exists
+	_, err := as.GetUserByEmail(user.Email)
+	if err == nil {
+		as.logger.Errorf("prepareUser[service]: Регистрация пользователя не удалась: " +
+			"Пользователь с таким email уже существует")
+		return models.User{}, ErrUserAlreadyExists
+	}
+
+	// Hash user's password before saving
+	user.Password, err = generatePasswordHash(user.Password)
+	if err != nil {
+		as.logger.Errorf("prepareUser[service]: Ошибка при хэшировании пароля: %s", err)
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
 // GenerateToken generates JWT for authenticated user
-// It retrieves user from repository and creates signed JWT token
+// It retrieves user from repository and creates signed JWT token. Unknown email and wrong password
+// are both reported as ErrInvalidCredentials, so login does not tell which emails are registered
 func (as *AuthService) GenerateToken(user models.User) (string, error) {
 	as.logger.Debugf("GenerateToken[service]: Создание токена для пользователя: %s", user.Email)
 
 	// Retrieve user from repository
 	dbUser, err := as.repo.GetByEmail(user.Email)
 	if err != nil {
+		if errors.Is(err, postgresql.ErrUserNotFound) {
+			as.logger.Warnf("GenerateToken[service]: Пользователь: %s не найден", user.Email)
+			bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(user.Password))
+			return "", ErrInvalidCredentials
+		}
 		as.logger.Errorf("GenerateToken[service]: Ошибка при получении пользователя: %s для генерации токена: %s", user.Email, err)
 		return "", err
 	}
@@ -77,8 +106,8 @@ func (as *AuthService) GenerateToken(user models.User) (string, error) {
 	// Compare provided password with hashed password stored in database
 	err = bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(user.Password))
 	if err != nil {
-		as.logger.Errorf("GenerateToken[service]: Ошибка при сравнении паролей пользователя: %s: ,%s", user.Email, err)
-		return "", errors.New("invalid password")
+		as.logger.Warnf("GenerateToken[service]: Неверный пароль пользователя: %s: %s", user.Email, err)
+		return "", ErrInvalidCredentials
 	}
 
 	// Generate JWT token
@@ -101,7 +130,7 @@ func (as *AuthService) IsTokenValid(tokenString string) (bool, jwt.MapClaims, er
 	validToken, claims, err := as.checkToken(tokenString)
 	if err != nil || !validToken {
 		as.logger.Errorf("IsTokenValid[service]: Неверный токен: %s", err)
-		return false, nil, errors.New("invalid token")
+		return false, nil, ErrInvalidToken
 	}
 
 	as.logger.Infof("Токен валиден")
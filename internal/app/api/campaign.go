@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+var ErrInvalidCampaign = errors.New("неправильные параметры кампании")
+var ErrCampaignNotActive = errors.New("кампания не активна")
+
+// CampaignService represents service for managing campaigns that group referral codes
+type CampaignService struct {
+	repo        repository.CampaignRepo
+	logger      *logrus.Logger
+	maxLifetime time.Duration
+}
+
+// NewCampaignService creates new instance of CampaignService with repository and config
+func NewCampaignService(repo repository.CampaignRepo, cfg *config.Config, logger *logrus.Logger) *CampaignService {
+	return &CampaignService{
+		repo:        repo,
+		maxLifetime: cfg.ReferralCodeMaxLifetime,
+		logger:      logger,
+	}
+}
+
+// CreateCampaign validates request and creates new campaign
+// createdBy is id of admin who created the campaign
+func (c *CampaignService) CreateCampaign(input models.CampaignRequest, createdBy *int) (models.Campaign, error) {
+	c.logger.Debugf("CreateCampaign[service]: Создание кампании: %s", input.Name)
+
+	campaign, err := c.campaignFromRequest(input)
+	if err != nil {
+		return models.Campaign{}, err
+	}
+	campaign.CreatedBy = createdBy
+
+	created, err := c.repo.Create(campaign)
+	if err != nil {
+		c.logger.Errorf("CreateCampaign[service]: Ошибка создания кампании: %s", err)
+		return models.Campaign{}, err
+	}
+
+	c.logger.Infof("CreateCampaign[service]: Кампания с id: %d создана", created.ID)
+	return c.repo.GetByID(created.ID)
+}
+
+// UpdateCampaign validates request and replaces campaign settings
+// Already created codes keep their expiration, new settings apply to codes created afterwards
+func (c *CampaignService) UpdateCampaign(id int, input models.CampaignRequest) (models.Campaign, error) {
+	c.logger.Debugf("UpdateCampaign[service]: Изменение кампании с id: %d", id)
+
+	campaign, err := c.campaignFromRequest(input)
+	if err != nil {
+		return models.Campaign{}, err
+	}
+	campaign.ID = id
+
+	if err = c.repo.Update(campaign); err != nil {
+		c.logger.Errorf("UpdateCampaign[service]: Ошибка изменения кампании с id: %d: %s", id, err)
+		return models.Campaign{}, err
+	}
+
+	c.logger.Infof("UpdateCampaign[service]: Кампания с id: %d изменена", id)
+	return c.repo.GetByID(id)
+}
+
+// DeleteCampaign removes campaign, its codes are kept as regular codes of their referrers
+func (c *CampaignService) DeleteCampaign(id int) error {
+	c.logger.Debugf("DeleteCampaign[service]: Удаление кампании с id: %d", id)
+	return c.repo.Delete(id)
+}
+
+// GetCampaignByID retrieves campaign with its codes and participants counts
+func (c *CampaignService) GetCampaignByID(id int) (models.Campaign, error) {
+	c.logger.Debugf("GetCampaignByID[service]: Получение кампании с id: %d", id)
+	return c.repo.GetByID(id)
+}
+
+// GetCampaigns retrieves all campaigns
+func (c *CampaignService) GetCampaigns() ([]models.Campaign, error) {
+	c.logger.Debugf("GetCampaigns[service]: Получение списка кампаний")
+
+	campaigns, err := c.repo.GetAll()
+	if err != nil {
+		c.logger.Errorf("GetCampaigns[service]: Ошибка получения списка кампаний: %s", err)
+		return nil, err
+	}
+
+	if campaigns == nil {
+		campaigns = []models.Campaign{}
+	}
+	return campaigns, nil
+}
+
+// GetActiveCampaign retrieves campaign that accepts new codes at given moment
+// Campaign that has not started yet, has already ended or reached its participants limit is ErrCampaignNotActive
+func (c *CampaignService) GetActiveCampaign(id int, now time.Time) (models.Campaign, error) {
+	campaign, err := c.repo.GetByID(id)
+	if err != nil {
+		return models.Campaign{}, err
+	}
+
+	if now.Before(campaign.StartsAt) || (campaign.EndsAt != nil && !now.Before(*campaign.EndsAt)) {
+		c.logger.Warnf("GetActiveCampaign[service]: Кампания с id: %d вне периода проведения", id)
+		return models.Campaign{}, ErrCampaignNotActive
+	}
+
+	if campaign.MaxParticipants != nil && campaign.ParticipantsCount >= *campaign.MaxParticipants {
+		c.logger.Warnf("GetActiveCampaign[service]: Кампания с id: %d набрала лимит участников", id)
+		return models.Campaign{}, ErrCampaignNotActive
+	}
+
+	return campaign, nil
+}
+
+// campaignFromRequest validates campaign request and converts it into campaign
+// Missing start means now, missing reward rules are stored as empty object
+func (c *CampaignService) campaignFromRequest(input models.CampaignRequest) (models.Campaign, error) {
+	campaign := models.Campaign{
+		Name:            strings.TrimSpace(input.Name),
+		StartsAt:        input.StartsAt,
+		EndsAt:          input.EndsAt,
+		RewardRules:     input.RewardRules,
+		MaxParticipants: input.MaxParticipants,
+	}
+
+	if campaign.Name == "" {
+		c.logger.Errorf("campaignFromRequest[service]: Не указано название кампании")
+		return models.Campaign{}, ErrInvalidCampaign
+	}
+
+	if campaign.StartsAt.IsZero() {
+		campaign.StartsAt = time.Now()
+	}
+
+	if campaign.EndsAt != nil && !campaign.EndsAt.After(campaign.StartsAt) {
+		c.logger.Errorf("campaignFromRequest[service]: Кампания заканчивается раньше, чем начинается")
+		return models.Campaign{}, ErrInvalidCampaign
+	}
+
+	if campaign.MaxParticipants != nil && *campaign.MaxParticipants <= 0 {
+		c.logger.Errorf("campaignFromRequest[service]: Неправильный лимит участников: %d", *campaign.MaxParticipants)
+		return models.Campaign{}, ErrInvalidCampaign
+	}
+
+	if expiresIn := strings.TrimSpace(input.DefaultExpiresIn); expiresIn != "" {
+		lifetime, err := time.ParseDuration(expiresIn)
+		if err != nil || lifetime < time.Second || lifetime > c.maxLifetime {
+			c.logger.Errorf("campaignFromRequest[service]: Неправильный срок действия кодов кампании: %s", expiresIn)
+			return models.Campaign{}, ErrInvalidCampaign
+		}
+		campaign.DefaultCodeLifetime = &lifetime
+	}
+
+	if len(campaign.RewardRules) == 0 {
+		campaign.RewardRules = json.RawMessage("{}")
+	}
+
+	// Reward rules are free-form for now, but must be JSON object
+	var rules map[string]interface{}
+	if err := json.Unmarshal(campaign.RewardRules, &rules); err != nil || rules == nil {
+		c.logger.Errorf("campaignFromRequest[service]: Правила вознаграждения должны быть JSON-объектом")
+		return models.Campaign{}, ErrInvalidCampaign
+	}
+
+	return campaign, nil
+}
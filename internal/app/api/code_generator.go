@@ -0,0 +1,152 @@
+package api
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+)
+
+var ErrReferralCodeMalformed = errors.New("неверный формат реферального кода")
+
+// crockfordAlphabet is Crockford's base32 alphabet without lookalike letters I, L, O and U
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// codeGroupSeparator separates groups of characters in formatted referral code
+const codeGroupSeparator = "-"
+
+// legacyCodeLength is length of codes issued before check characters, which are uppercased base64url
+const legacyCodeLength = 8
+
+// CodeGenerator defines methods for generating referral codes and normalizing user input
+type CodeGenerator interface {
+	// Generate returns new random referral code in canonical form
+	Generate() (string, error)
+	// Normalize converts user input into canonical form or returns ErrReferralCodeMalformed
+	Normalize(code string) (string, error)
+	// Format converts canonical code into form shown to users
+	Format(code string) string
+}
+
+// CrockfordCodeGenerator generates codes from unambiguous Crockford base32 alphabet
+// Last character of code is check character, so typos are detected without database lookup
+type CrockfordCodeGenerator struct {
+	length    int
+	groupSize int
+}
+
+// NewCrockfordCodeGenerator creates new CrockfordCodeGenerator
+// length is total number of characters including check character,
+// groupSize is number of characters between dashes (0 disables grouping)
+func NewCrockfordCodeGenerator(length, groupSize int) *CrockfordCodeGenerator {
+	return &CrockfordCodeGenerator{
+		length:    length,
+		groupSize: groupSize,
+	}
+}
+
+// Generate generates random payload and appends check character
+// Codes are stored in canonical form without dashes, so changing grouping does not affect stored codes
+func (g *CrockfordCodeGenerator) Generate() (string, error) {
+	randomBytes := make([]byte, g.length-1)
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	// Alphabet size is power of two, so masking keeps distribution uniform
+	payload := make([]byte, len(randomBytes))
+	for i, b := range randomBytes {
+		payload[i] = crockfordAlphabet[b&31]
+	}
+
+	return string(payload) + string(checkCharacter(string(payload))), nil
+}
+
+// Normalize uppercases input, drops dashes and spaces, maps lookalikes (O -> 0, I and L -> 1)
+// and verifies length and check character
+func (g *CrockfordCodeGenerator) Normalize(code string) (string, error) {
+	var builder strings.Builder
+	for _, char := range strings.ToUpper(code) {
+		switch char {
+		case '-', ' ', '\t':
+			continue
+		case 'O':
+			char = '0'
+		case 'I', 'L':
+			char = '1'
+		}
+
+		if !strings.ContainsRune(crockfordAlphabet, char) {
+			return "", ErrReferralCodeMalformed
+		}
+		builder.WriteRune(char)
+	}
+
+	raw := builder.String()
+	if len(raw) != g.length {
+		return "", ErrReferralCodeMalformed
+	}
+
+	payload := raw[:len(raw)-1]
+	if raw[len(raw)-1] != checkCharacter(payload) {
+		return "", ErrReferralCodeMalformed
+	}
+
+	return raw, nil
+}
+
+// Format splits canonical code into groups separated by dashes (e.g. ABCD-EFGH)
+// Codes of other length, such as codes issued before check characters, are returned unchanged
+func (g *CrockfordCodeGenerator) Format(raw string) string {
+	if g.groupSize <= 0 || len(raw) != g.length || strings.Trim(raw, crockfordAlphabet) != "" {
+		return raw
+	}
+
+	groups := make([]string, 0, len(raw)/g.groupSize+1)
+	for len(raw) > g.groupSize {
+		groups = append(groups, raw[:g.groupSize])
+		raw = raw[g.groupSize:]
+	}
+	groups = append(groups, raw)
+
+	return strings.Join(groups, codeGroupSeparator)
+}
+
+// checkCharacter calculates check character using Luhn mod N algorithm over Crockford alphabet
+// It detects any single character error and most transpositions of adjacent characters
+func checkCharacter(payload string) byte {
+	n := len(crockfordAlphabet)
+	factor := 2
+	sum := 0
+
+	for i := len(payload) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(crockfordAlphabet, payload[i])
+		addend = addend/n + addend%n
+		sum += addend
+
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+	}
+
+	return crockfordAlphabet[(n-sum%n)%n]
+}
+
+// legacyReferralCode returns code typed by user in form of codes issued before check characters
+// These codes are 8 uppercased base64url characters, dashes included, and can only be matched exactly
+func legacyReferralCode(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != legacyCodeLength {
+		return "", false
+	}
+
+	for _, char := range code {
+		if !(char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' || char == '-' || char == '_') {
+			return "", false
+		}
+	}
+	return code, true
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+// validCode returns canonical code of generator with check character appended to payload
+func validCode(payload string) string {
+	return payload + string(checkCharacter(payload))
+}
+
+func TestNormalize(t *testing.T) {
+	generator := NewCrockfordCodeGenerator(8, 4)
+	code := validCode("7K3M9QX")
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		err   error
+	}{
+		{name: "canonical", input: code, want: code},
+		{name: "grouped", input: code[:4] + "-" + code[4:], want: code},
+		{name: "lowercase with spaces", input: " 7k3m 9qx" + code[7:] + " ", want: code},
+		{name: "too short", input: code[:7], err: ErrReferralCodeMalformed},
+		{name: "too long", input: code + "0", err: ErrReferralCodeMalformed},
+		{name: "letter U is not in alphabet", input: "7K3M9QU" + code[7:], err: ErrReferralCodeMalformed},
+		{name: "legacy base64url code", input: "AB_CD-EF", err: ErrReferralCodeMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generator.Normalize(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.input, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeFoldsLookalikes(t *testing.T) {
+	generator := NewCrockfordCodeGenerator(8, 4)
+	code := validCode("10A1B0C")
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "O as zero", input: "1OA1BOC" + code[7:]},
+		{name: "I as one", input: "I0AIB0C" + code[7:]},
+		{name: "L as one", input: "L0ALB0C" + code[7:]},
+		{name: "lowercase lookalikes", input: "lOaiBoc" + code[7:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generator.Normalize(tt.input)
+			if err != nil {
+				t.Fatalf("Normalize(%q) error = %v", tt.input, err)
+			}
+			if got != code {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, code)
+			}
+		})
+	}
+}
+
+func TestNormalizeDetectsSubstitutions(t *testing.T) {
+	generator := NewCrockfordCodeGenerator(8, 4)
+
+	for _, payload := range []string{"0000000", "7K3M9QX", "ZZZZZZZ", "A1B2C3D", "HJKMNPQ"} {
+		code := validCode(payload)
+		for i := 0; i < len(code); i++ {
+			for _, char := range []byte(crockfordAlphabet) {
+				if char == code[i] {
+					continue
+				}
+
+				typo := code[:i] + string(char) + code[i+1:]
+				if _, err := generator.Normalize(typo); !errors.Is(err, ErrReferralCodeMalformed) {
+					t.Errorf("Normalize(%q) of %q with substitution at %d error = %v, want %v",
+						typo, code, i, err, ErrReferralCodeMalformed)
+				}
+			}
+		}
+	}
+}
+
+func TestNormalizeDetectsTranspositions(t *testing.T) {
+	generator := NewCrockfordCodeGenerator(8, 4)
+
+	for _, payload := range []string{"0123456", "7K3M9QX", "ZA0BYC1", "A1B2C3D", "HJKMNPQ", "RSTVWXY"} {
+		code := validCode(payload)
+		for i := 0; i+1 < len(code); i++ {
+			a, b := code[i], code[i+1]
+			// Luhn mod N misses only transposition of first and last characters of alphabet
+			if a == b || a == '0' && b == 'Z' || a == 'Z' && b == '0' {
+				continue
+			}
+
+			typo := code[:i] + string(b) + string(a) + code[i+2:]
+			if _, err := generator.Normalize(typo); !errors.Is(err, ErrReferralCodeMalformed) {
+				t.Errorf("Normalize(%q) of %q with transposition at %d error = %v, want %v",
+					typo, code, i, err, ErrReferralCodeMalformed)
+			}
+		}
+	}
+}
+
+func TestGenerateIsNormalized(t *testing.T) {
+	generator := NewCrockfordCodeGenerator(10, 4)
+
+	for i := 0; i < 100; i++ {
+		code, err := generator.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if len(code) != 10 {
+			t.Fatalf("Generate() = %q, want canonical code of length 10", code)
+		}
+
+		normalized, err := generator.Normalize(generator.Format(code))
+		if err != nil || normalized != code {
+			t.Fatalf("Normalize(Format(%q)) = %q, %v, want %q", code, normalized, err, code)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	code := validCode("7K3M9QX")
+
+	tests := []struct {
+		name      string
+		length    int
+		groupSize int
+		input     string
+		want      string
+	}{
+		{name: "grouped", length: 8, groupSize: 4, input: code, want: code[:4] + "-" + code[4:]},
+		{name: "uneven groups", length: 8, groupSize: 3, input: code, want: code[:3] + "-" + code[3:6] + "-" + code[6:]},
+		{name: "grouping disabled", length: 8, groupSize: 0, input: code, want: code},
+		{name: "legacy code", length: 8, groupSize: 4, input: "AB_CD-EF", want: "AB_CD-EF"},
+		{name: "other length", length: 10, groupSize: 4, input: code, want: code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewCrockfordCodeGenerator(tt.length, tt.groupSize).Format(tt.input)
+			if got != tt.want {
+				t.Errorf("Format(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindReferralCode(t *testing.T) {
+	generator := NewCrockfordCodeGenerator(8, 4)
+	code := validCode("7K3M9QX")
+	stored := map[string]int{code: 1, "AB_CD-EF": 2, "HELLO123": 3}
+
+	lookup := func(code string) (int, error) {
+		id, ok := stored[code]
+		if !ok {
+			return 0, postgresql.ErrReferralCodeNotFound
+		}
+		return id, nil
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  int
+		err   error
+	}{
+		{name: "canonical code", input: code[:4] + "-" + code[4:], want: 1},
+		{name: "legacy code", input: "ab_cd-ef", want: 2},
+		{name: "legacy code with lookalikes", input: "hello123", want: 3},
+		{name: "unknown code", input: validCode("0000000"), err: postgresql.ErrReferralCodeNotFound},
+		{name: "unknown legacy code", input: "ZZ_ZZ-ZZ", err: postgresql.ErrReferralCodeNotFound},
+		{name: "malformed code", input: "7K3M-9Q", err: ErrReferralCodeMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findReferralCode(generator, tt.input, lookup)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("findReferralCode(%q) error = %v, want %v", tt.input, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("findReferralCode(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
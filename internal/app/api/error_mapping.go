@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+// ErrorMapping is response of REST and gRPC APIs to sentinel error: HTTP status, gRPC code,
+// code of problem and detail. Empty detail means text of error, which explains what is wrong with input
+type ErrorMapping struct {
+	Err        error
+	HTTPStatus int
+	GRPCCode   codes.Code
+	Code       string
+	Detail     string
+}
+
+// errorMappings maps sentinel errors of api and postgresql packages to responses of both APIs
+// Codes are part of API and must not change, errors missing here are internal errors
+var errorMappings = []ErrorMapping{
+	// Users
+	{ErrUserAlreadyExists, http.StatusConflict, codes.AlreadyExists, "user_already_exists",
+		"Такой пользователь уже существует"},
+	{ErrInvalidCredentials, http.StatusUnauthorized, codes.Unauthenticated, "invalid_credentials",
+		"Неверный email или пароль"},
+	{ErrInvalidToken, http.StatusUnauthorized, codes.Unauthenticated, "invalid_token", "Пользователь не авторизован"},
+	{postgresql.ErrUserNotFound, http.StatusNotFound, codes.NotFound, "user_not_found", "Пользователь не найден"},
+
+	// Referral codes
+	{ErrReferralCodeMalformed, http.StatusBadRequest, codes.InvalidArgument, "referral_code_malformed",
+		"Неверный формат реферального кода"},
+	{postgresql.ErrReferralCodeNotFound, http.StatusNotFound, codes.NotFound, "referral_code_not_found",
+		"Реферальный код не найден"},
+	{postgresql.ErrReferralCodeNotActive, http.StatusBadRequest, codes.FailedPrecondition, "referral_code_not_active",
+		"Введенный реферальный код неактивен"},
+	{ErrReferralCodeAlreadyExists, http.StatusConflict, codes.AlreadyExists, "referral_code_already_exists",
+		"Активный реферальный код уже существует"},
+	{ErrInvalidExpiration, http.StatusBadRequest, codes.InvalidArgument, "invalid_expiration",
+		"Неправильный формат срока действия реферального кода"},
+	{ErrExpirationInPast, http.StatusBadRequest, codes.InvalidArgument, "expiration_in_past",
+		"Срок годности реферального кода не может быть в прошлом"},
+	{ErrExpirationTooFar, http.StatusBadRequest, codes.InvalidArgument, "expiration_too_far",
+		"Срок годности реферального кода превышает максимально допустимый"},
+	{ErrUnknownTimeZone, http.StatusBadRequest, codes.InvalidArgument, "unknown_time_zone", "Неизвестный часовой пояс"},
+	{ErrInvalidGracePeriod, http.StatusBadRequest, codes.InvalidArgument, "invalid_grace_period",
+		"Неправильный льготный период"},
+	{ErrInvalidQRCodeOptions, http.StatusBadRequest, codes.InvalidArgument, "invalid_qr_code_options",
+		"Неправильные параметры QR-кода"},
+	{ErrInvalidBatchSize, http.StatusBadRequest, codes.InvalidArgument, "invalid_batch_size",
+		"Неправильный размер пакета реферальных кодов"},
+	{postgresql.ErrReferralCodeBatchNotFound, http.StatusNotFound, codes.NotFound, "referral_code_batch_not_found",
+		"Пакет реферальных кодов не найден"},
+
+	// Campaigns
+	{ErrInvalidCampaign, http.StatusBadRequest, codes.InvalidArgument, "invalid_campaign",
+		"Неправильные параметры кампании"},
+	{ErrCampaignNotActive, http.StatusConflict, codes.FailedPrecondition, "campaign_not_active", "Кампания не активна"},
+	{postgresql.ErrCampaignNotFound, http.StatusNotFound, codes.NotFound, "campaign_not_found", "Кампания не найдена"},
+
+	// Referrals
+	{postgresql.ErrReferralNotFound, http.StatusNotFound, codes.NotFound, "referral_not_found", "Реферал не найден"},
+	{ErrInvalidReferralStatus, http.StatusBadRequest, codes.InvalidArgument, "invalid_referral_status",
+		"Неизвестный статус реферала"},
+	{ErrCampaignStatsForbidden, http.StatusForbidden, codes.PermissionDenied, "campaign_stats_forbidden",
+		"Статистика по кампаниям доступна только самому рефереру и администраторам"},
+	{ErrInvalidReferralList, http.StatusBadRequest, codes.InvalidArgument, "invalid_referral_list", ""},
+	{ErrInvalidReferralTreeDepth, http.StatusBadRequest, codes.InvalidArgument, "invalid_referral_tree_depth",
+		"Неправильная глубина дерева"},
+	{ErrInvalidReferralExportFormat, http.StatusBadRequest, codes.InvalidArgument, "invalid_export_format", ""},
+	{ErrInvalidReferralStats, http.StatusBadRequest, codes.InvalidArgument, "invalid_referral_stats", ""},
+	{ErrInvalidReferralEvent, http.StatusBadRequest, codes.InvalidArgument, "invalid_referral_event",
+		"Неправильный формат события"},
+
+	// Fraud review
+	{ErrInvalidReviewStatus, http.StatusBadRequest, codes.InvalidArgument, "invalid_review_status",
+		"Неизвестный статус проверки"},
+	{postgresql.ErrReferralReviewNotFound, http.StatusNotFound, codes.NotFound, "referral_review_not_found",
+		"Реферал на проверке не найден"},
+	{postgresql.ErrReferralReviewConflict, http.StatusConflict, codes.FailedPrecondition, "referral_review_conflict",
+		"Реферал не ожидает проверки"},
+
+	// Rewards and payouts
+	{ErrInvalidRewardRuleSet, http.StatusBadRequest, codes.InvalidArgument, "invalid_reward_rule_set", ""},
+	{ErrRewardAmountOutOfRange, http.StatusBadRequest, codes.InvalidArgument, "reward_amount_out_of_range", ""},
+	{postgresql.ErrRewardRuleSetNotFound, http.StatusNotFound, codes.NotFound, "reward_rule_set_not_found",
+		"Набор правил не найден"},
+	{ErrInvalidRewardDryRun, http.StatusBadRequest, codes.InvalidArgument, "invalid_reward_dry_run",
+		"Неправильный запрос расчета вознаграждений"},
+	{ErrInvalidPayout, http.StatusBadRequest, codes.InvalidArgument, "invalid_payout",
+		"Неправильные параметры выплаты"},
+	{ErrInvalidPayoutStatus, http.StatusBadRequest, codes.InvalidArgument, "invalid_payout_status",
+		"Неизвестный статус выплаты"},
+	{ErrPayoutNotSent, http.StatusBadGateway, codes.Unavailable, "payout_not_sent",
+		"Провайдер не подтвердил отправку выплаты, повторите одобрение позже"},
+	{postgresql.ErrPayoutNotFound, http.StatusNotFound, codes.NotFound, "payout_not_found", "Выплата не найдена"},
+	{postgresql.ErrPayoutStatusConflict, http.StatusConflict, codes.FailedPrecondition, "payout_status_conflict",
+		"Выплата не ожидает решения"},
+	{postgresql.ErrInsufficientRewardBalance, http.StatusConflict, codes.FailedPrecondition,
+		"insufficient_reward_balance", "Недостаточно доступных вознаграждений"},
+
+	// Leaderboard and notifications
+	{ErrInvalidLeaderboard, http.StatusBadRequest, codes.InvalidArgument, "invalid_leaderboard_query", ""},
+	{ErrInvalidLeaderboardSettings, http.StatusBadRequest, codes.InvalidArgument, "invalid_leaderboard_settings", ""},
+	{ErrInvalidNotificationSettings, http.StatusBadRequest, codes.InvalidArgument, "invalid_notification_settings", ""},
+
+	// Webhooks
+	{ErrInvalidWebhookSubscription, http.StatusBadRequest, codes.InvalidArgument, "invalid_webhook_subscription", ""},
+	{ErrWebhookForbidden, http.StatusForbidden, codes.PermissionDenied, "webhook_forbidden",
+		"Подписка на события всех пользователей доступна только администраторам"},
+	{ErrInvalidWebhookDeliveryStatus, http.StatusBadRequest, codes.InvalidArgument, "invalid_webhook_delivery_status",
+		"Неизвестный статус доставки"},
+	{postgresql.ErrWebhookSubscriptionNotFound, http.StatusNotFound, codes.NotFound, "webhook_subscription_not_found",
+		"Подписка на вебхуки не найдена"},
+	{postgresql.ErrWebhookDeliveryNotFound, http.StatusNotFound, codes.NotFound, "webhook_delivery_not_found",
+		"Доставка вебхука не найдена"},
+	{postgresql.ErrWebhookDeliveryPending, http.StatusConflict, codes.FailedPrecondition, "webhook_delivery_pending",
+		"Доставка вебхука еще не завершена"},
+}
+
+// LookupError returns mapping of sentinel error wrapped by err, ok is false for unknown errors
+func LookupError(err error) (ErrorMapping, bool) {
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.Err) {
+			return mapping, true
+		}
+	}
+	return ErrorMapping{}, false
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestLookupErrorFindsEveryMapping(t *testing.T) {
+	codes := make(map[string]bool)
+	for _, mapping := range errorMappings {
+		if codes[mapping.Code] {
+			t.Errorf("code %q is mapped twice", mapping.Code)
+		}
+		codes[mapping.Code] = true
+
+		// Earlier mapping of error wrapping this one would shadow it
+		got, ok := LookupError(fmt.Errorf("%w: подробности", mapping.Err))
+		if !ok || got.Code != mapping.Code {
+			t.Errorf("LookupError(%v) = %q, %v, want %q", mapping.Err, got.Code, ok, mapping.Code)
+		}
+	}
+
+	if _, ok := LookupError(errors.New("неизвестная ошибка")); ok {
+		t.Error("LookupError() of unknown error ok = true, want false")
+	}
+}
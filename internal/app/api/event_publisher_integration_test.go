@@ -0,0 +1,156 @@
+//go:build integration
+
+package api
+
+// Tests of NATSEventPublisher against local NATS server with JetStream enabled:
+//
+//	docker compose --profile brokers up -d nats
+//	NATS_TEST_URL=nats://localhost:4222 go test -tags integration ./internal/app/api/ -run NATS
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+)
+
+// testJetStream connects to server from NATS_TEST_URL, test is skipped if it is not set
+func testJetStream(t *testing.T) (string, jetstream.JetStream) {
+	t.Helper()
+
+	url := os.Getenv("NATS_TEST_URL")
+	if url == "" {
+		t.Skip("NATS_TEST_URL не задан")
+	}
+
+	conn, err := nats.Connect(url)
+	if err != nil {
+		t.Fatalf("nats.Connect(%q) error = %v", url, err)
+	}
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatalf("jetstream.New() error = %v", err)
+	}
+	return url, js
+}
+
+// testPublisher returns publisher with prefix and stream unique to test, stream is deleted after test
+func testPublisher(t *testing.T, url string, js jetstream.JetStream) (*NATSEventPublisher, string, string) {
+	t.Helper()
+
+	suffix := time.Now().UnixNano()
+	prefix := fmt.Sprintf("test%d", suffix)
+	stream := fmt.Sprintf("TEST%d", suffix)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	publisher := NewNATSEventPublisher(url, prefix, stream, 5*time.Second, logger)
+	t.Cleanup(func() {
+		publisher.Close()
+		js.DeleteStream(context.Background(), stream)
+	})
+	return publisher, prefix, stream
+}
+
+func testOutboxMessage(id int64) models.OutboxMessage {
+	return models.OutboxMessage{
+		ID:            id,
+		AggregateType: models.OutboxAggregateReferral,
+		AggregateID:   7,
+		EventType:     models.OutboxEventReferralCreated,
+		Payload:       json.RawMessage(`{"id":7}`),
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestNATSEventPublisherPublishesToStream(t *testing.T) {
+	url, js := testJetStream(t)
+	publisher, prefix, stream := testPublisher(t, url, js)
+	ctx := context.Background()
+
+	message := testOutboxMessage(1)
+	if err := publisher.Publish(ctx, message); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	s, err := js.Stream(ctx, stream)
+	if err != nil {
+		t.Fatalf("Stream(%q) error = %v, stream must be created by publisher", stream, err)
+	}
+	stored, err := s.GetMsg(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetMsg(1) error = %v", err)
+	}
+
+	if want := prefix + "." + models.OutboxEventReferralCreated; stored.Subject != want {
+		t.Errorf("subject = %q, want %q", stored.Subject, want)
+	}
+	if got := stored.Header.Get(jetstream.MsgIDHeader); got != "1" {
+		t.Errorf("Nats-Msg-Id = %q, want %q", got, "1")
+	}
+
+	var got models.OutboxMessage
+	if err = json.Unmarshal(stored.Data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.ID != message.ID || got.EventType != message.EventType || string(got.Payload) != string(message.Payload) {
+		t.Errorf("message = %+v, want %+v", got, message)
+	}
+}
+
+func TestNATSEventPublisherDropsDuplicates(t *testing.T) {
+	url, js := testJetStream(t)
+	publisher, _, stream := testPublisher(t, url, js)
+	ctx := context.Background()
+
+	// Relay publishes message again when it fails to mark it published
+	for i := 0; i < 2; i++ {
+		if err := publisher.Publish(ctx, testOutboxMessage(1)); err != nil {
+			t.Fatalf("Publish() #%d error = %v", i+1, err)
+		}
+	}
+	if err := publisher.Publish(ctx, testOutboxMessage(2)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	s, err := js.Stream(ctx, stream)
+	if err != nil {
+		t.Fatalf("Stream(%q) error = %v", stream, err)
+	}
+	info, err := s.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.State.Msgs != 2 {
+		t.Errorf("stream holds %d messages, want 2", info.State.Msgs)
+	}
+}
+
+func TestNATSEventPublisherFailsWithoutAck(t *testing.T) {
+	url, js := testJetStream(t)
+	publisher, _, stream := testPublisher(t, url, js)
+	ctx := context.Background()
+
+	// Existing stream is left as it is, subjects of publisher are not stored by any stream
+	_, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: stream, Subjects: []string{stream + ".other.>"}})
+	if err != nil {
+		t.Fatalf("CreateStream() error = %v", err)
+	}
+
+	err = publisher.Publish(ctx, testOutboxMessage(1))
+	if !errors.Is(err, jetstream.ErrNoStreamResponse) {
+		t.Fatalf("Publish() error = %v, want %v", err, jetstream.ErrNoStreamResponse)
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"rest-refs/internal/app/models"
+)
+
+var ErrInvalidExpiration = errors.New("неправильный формат срока действия реферального кода")
+var ErrExpirationInPast = errors.New("срок действия реферального кода не может быть в прошлом")
+var ErrExpirationTooFar = errors.New("срок действия реферального кода превышает максимально допустимый")
+var ErrUnknownTimeZone = errors.New("неизвестный часовой пояс")
+
+// dateOnlyLayouts lists accepted layouts for expiration dates without time of day
+var dateOnlyLayouts = []string{"02.01.2006", "2006-01-02"}
+
+// ResolveExpiration converts expiration from create request into exact point in time
+// Request must contain either expiration_date or expires_in:
+//   - expires_in is relative duration (e.g. "72h") counted from now
+//   - expiration_date is RFC 3339 timestamp or date-only value ("20.10.2024", "2024-10-20"),
+//     date-only value means end of that day in time_zone (or default time zone from config)
+//
+// If campaign_id is set, campaign must be active. Its default lifetime is used when request has no expiration,
+// and expiration is cut to the end of campaign, so campaign codes never outlive it
+// Resulting expiration must be in the future and not exceed configured maximum lifetime
+func (r *ReferralCodeService) ResolveExpiration(input models.ReferralCodeCreateRequest) (time.Time, error) {
+	r.logger.Debugf("ResolveExpiration[service]: Вычисление срока действия реферального кода")
+
+	now := time.Now()
+	expiresIn := strings.TrimSpace(input.ExpiresIn)
+	expirationDate := strings.TrimSpace(input.ExpirationDate)
+
+	var campaign *models.Campaign
+	if input.CampaignID != nil {
+		found, err := r.campaignService.GetActiveCampaign(*input.CampaignID, now)
+		if err != nil {
+			r.logger.Errorf("ResolveExpiration[service]: Ошибка получения кампании с id: %d: %s", *input.CampaignID, err)
+			return time.Time{}, err
+		}
+		campaign = &found
+	}
+
+	var expiration time.Time
+	switch {
+	case expiresIn != "" && expirationDate != "":
+		r.logger.Errorf("ResolveExpiration[service]: Указаны одновременно expiration_date и expires_in")
+		return time.Time{}, ErrInvalidExpiration
+
+	case expiresIn != "":
+		duration, err := time.ParseDuration(expiresIn)
+		if err != nil {
+			r.logger.Errorf("ResolveExpiration[service]: Неправильный формат expires_in: %s", expiresIn)
+			return time.Time{}, ErrInvalidExpiration
+		}
+		expiration = now.Add(duration)
+
+	case expirationDate != "":
+		location := r.defaultTimeZone
+		if input.TimeZone != "" {
+			loc, err := time.LoadLocation(input.TimeZone)
+			if err != nil {
+				r.logger.Errorf("ResolveExpiration[service]: Неизвестный часовой пояс: %s", input.TimeZone)
+				return time.Time{}, ErrUnknownTimeZone
+			}
+			location = loc
+		}
+
+		parsed, err := parseExpirationDate(expirationDate, location)
+		if err != nil {
+			r.logger.Errorf("ResolveExpiration[service]: Неправильный формат expiration_date: %s", expirationDate)
+			return time.Time{}, err
+		}
+		expiration = parsed
+
+	case campaign != nil && campaign.DefaultCodeLifetime != nil:
+		expiration = now.Add(*campaign.DefaultCodeLifetime)
+
+	default:
+		r.logger.Errorf("ResolveExpiration[service]: Срок действия реферального кода не указан")
+		return time.Time{}, ErrInvalidExpiration
+	}
+
+	if campaign != nil && campaign.EndsAt != nil && expiration.After(*campaign.EndsAt) {
+		expiration = *campaign.EndsAt
+	}
+
+	if !expiration.After(now) {
+		return time.Time{}, ErrExpirationInPast
+	}
+
+	if expiration.Sub(now) > r.maxLifetime {
+		return time.Time{}, ErrExpirationTooFar
+	}
+
+	return expiration, nil
+}
+
+// parseExpirationDate parses RFC 3339 timestamp as is
+// Date-only value is interpreted as the last moment of that day in given location
+func parseExpirationDate(value string, location *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range dateOnlyLayouts {
+		date, err := time.ParseInLocation(layout, value, location)
+		if err != nil {
+			continue
+		}
+
+		// Postgres stores timestamps with microsecond precision
+		return time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 999999000, location), nil
+	}
+
+	return time.Time{}, ErrInvalidExpiration
+}
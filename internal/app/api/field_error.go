@@ -0,0 +1,30 @@
+package api
+
+import "fmt"
+
+// FieldError is validation error of one field of input
+// It wraps sentinel error of validation, so errors.Is still recognizes it, and its text is "sentinel: message"
+type FieldError struct {
+	Err     error
+	Field   string
+	Message string
+}
+
+// newFieldError returns FieldError of field wrapping err, message is formatted from format and args
+func newFieldError(err error, field, format string, args ...interface{}) error {
+	return &FieldError{
+		Err:     err,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Error returns text of sentinel error followed by message
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Err, e.Message)
+}
+
+// Unwrap returns sentinel error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
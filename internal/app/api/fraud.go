@@ -0,0 +1,290 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+var ErrInvalidReviewStatus = errors.New("неизвестный статус проверки реферала")
+
+// Contributions of fraud checks to risk score of referral
+const (
+	selfReferralRiskScore    = 100
+	sameDeviceRiskScore      = 60
+	disposableEmailRiskScore = 50
+	sameIPRiskScore          = 40
+	velocityRiskScore        = 30
+	maxRiskScore             = 100
+)
+
+// fraudCheck inspects referral and returns signal if referral looks suspicious, nil otherwise
+type fraudCheck func(referral models.Referral, referrer models.User) (*models.RiskSignal, error)
+
+// FraudService represents service for scoring referrals by fraud checks and manual review of risky referrals
+type FraudService struct {
+	repo              repository.FraudRepo
+	userRepo          repository.UserRepo
+	rewardService     *RewardService
+	logger            *logrus.Logger
+	ipHashSalt        string
+	reviewThreshold   int
+	velocityLimit     int
+	velocityWindow    time.Duration
+	disposableDomains map[string]bool
+	checks            []fraudCheck
+}
+
+// NewFraudService creates new instance of FraudService with repositories, rewardService
+// and fraud limits from config
+func NewFraudService(repo repository.FraudRepo, userRepo repository.UserRepo, rewardService *RewardService,
+	cfg *config.Config, logger *logrus.Logger) *FraudService {
+	f := &FraudService{
+		repo:              repo,
+		userRepo:          userRepo,
+		rewardService:     rewardService,
+		ipHashSalt:        cfg.IPHashSalt,
+		reviewThreshold:   cfg.FraudReviewThreshold,
+		velocityLimit:     cfg.FraudVelocityLimit,
+		velocityWindow:    cfg.FraudVelocityWindow,
+		disposableDomains: make(map[string]bool),
+		logger:            logger,
+	}
+	for _, domain := range cfg.DisposableEmailDomains {
+		f.disposableDomains[strings.ToLower(domain)] = true
+	}
+	f.checks = []fraudCheck{f.checkSelfReferral, f.checkDevice, f.checkVelocity, f.checkDisposableEmail}
+
+	return f
+}
+
+// HashClient returns salted hashes of IP address and device fingerprint of client, empty values stay empty
+func (f *FraudService) HashClient(client models.ClientInfo) (string, string) {
+	var ipHash, deviceHash string
+	if client.IP != "" {
+		ipHash = hashWithSalt(f.ipHashSalt, client.IP)
+	}
+	if client.DeviceFingerprint != "" {
+		deviceHash = hashWithSalt(f.ipHashSalt, client.DeviceFingerprint)
+	}
+	return ipHash, deviceHash
+}
+
+// RecordUserDevice remembers IP address and device of user, so later referrals from them are recognized
+func (f *FraudService) RecordUserDevice(email string, client models.ClientInfo) error {
+	ipHash, deviceHash := f.HashClient(client)
+	if ipHash == "" && deviceHash == "" {
+		return nil
+	}
+
+	user, err := f.userRepo.GetByEmail(email)
+	if err != nil {
+		f.logger.Errorf("RecordUserDevice[service]: Ошибка при получении пользователя %s: %s", email, err)
+		return err
+	}
+
+	return f.repo.RecordDevice(user.ID, ipHash, deviceHash)
+}
+
+// Assess runs all fraud checks on referral and stores its risk score
+// Referral with risk score reaching review threshold is placed into manual review queue,
+// rewards for it are withheld until approval
+func (f *FraudService) Assess(referral models.Referral) (models.RiskAssessment, error) {
+	f.logger.Debugf("Assess[service]: Проверка реферала с id: %d", referral.ID)
+
+	referrer, err := f.userRepo.GetByID(referral.ReferrerID)
+	if err != nil {
+		f.logger.Errorf("Assess[service]: Ошибка при получении реферера с id: %d: %s", referral.ReferrerID, err)
+		return models.RiskAssessment{}, err
+	}
+
+	assessment := models.RiskAssessment{Signals: []models.RiskSignal{}}
+	for _, check := range f.checks {
+		signal, err := check(referral, referrer)
+		if err != nil {
+			return models.RiskAssessment{}, err
+		}
+		if signal != nil {
+			assessment.Signals = append(assessment.Signals, *signal)
+			assessment.Score += signal.Score
+		}
+	}
+	assessment.Score = min(assessment.Score, maxRiskScore)
+	assessment.Review = assessment.Score >= f.reviewThreshold
+
+	if err = f.repo.SaveAssessment(referral.ID, assessment); err != nil {
+		f.logger.Errorf("Assess[service]: Ошибка сохранения оценки риска реферала с id: %d: %s", referral.ID, err)
+		return models.RiskAssessment{}, err
+	}
+
+	if assessment.Review {
+		f.logger.Warnf("Assess[service]: Реферал с id: %d отправлен на проверку, оценка риска: %d",
+			referral.ID, assessment.Score)
+	}
+	return assessment, nil
+}
+
+// HoldForReview places referral that could not be assessed because of cause into manual review queue
+// with maximum risk score, so unscored referral is never treated as clean and its rewards are withheld
+func (f *FraudService) HoldForReview(referral models.Referral, cause error) (models.RiskAssessment, error) {
+	f.logger.Debugf("HoldForReview[service]: Отправка реферала с id: %d на проверку", referral.ID)
+
+	assessment := models.RiskAssessment{
+		Score:   maxRiskScore,
+		Signals: []models.RiskSignal{{Check: models.FraudCheckFailed, Score: maxRiskScore, Detail: cause.Error()}},
+		Review:  true,
+	}
+	if err := f.repo.SaveAssessment(referral.ID, assessment); err != nil {
+		f.logger.Errorf("HoldForReview[service]: Ошибка отправки реферала с id: %d на проверку: %s", referral.ID, err)
+		return models.RiskAssessment{}, err
+	}
+
+	f.logger.Warnf("HoldForReview[service]: Реферал с id: %d не проверен и отправлен на проверку: %s",
+		referral.ID, cause)
+	return assessment, nil
+}
+
+// GetReferralReviews retrieves referrals in manual review queue, non-empty statuses limit result to these
+// review statuses, otherwise only pending reviews are returned
+func (f *FraudService) GetReferralReviews(statuses []string) ([]models.ReferralReview, error) {
+	f.logger.Debugf("GetReferralReviews[service]: Получение рефералов на проверке")
+
+	for _, status := range statuses {
+		if status != models.ReviewStatusPending && status != models.ReviewStatusApproved &&
+			status != models.ReviewStatusRejected {
+			f.logger.Errorf("GetReferralReviews[service]: Неизвестный статус проверки: %s", status)
+			return nil, ErrInvalidReviewStatus
+		}
+	}
+	if len(statuses) == 0 {
+		statuses = []string{models.ReviewStatusPending}
+	}
+
+	reviews, err := f.repo.GetReviews(statuses)
+	if err != nil {
+		f.logger.Errorf("GetReferralReviews[service]: Ошибка при получении рефералов на проверке: %s", err)
+		return nil, err
+	}
+
+	if reviews == nil {
+		reviews = []models.ReferralReview{}
+	}
+	return reviews, nil
+}
+
+// ApproveReferralReview approves pending referral and credits rewards withheld during review
+func (f *FraudService) ApproveReferralReview(id, adminID int,
+	input models.ReferralReviewDecisionRequest) (models.ReferralReview, error) {
+	f.logger.Debugf("ApproveReferralReview[service]: Одобрение реферала с id: %d", id)
+
+	return f.decide(id, adminID, models.ReviewStatusApproved, input)
+}
+
+// RejectReferralReview rejects pending referral and reverses its rewards
+func (f *FraudService) RejectReferralReview(id, adminID int,
+	input models.ReferralReviewDecisionRequest) (models.ReferralReview, error) {
+	f.logger.Debugf("RejectReferralReview[service]: Отклонение реферала с id: %d", id)
+
+	return f.decide(id, adminID, models.ReviewStatusRejected, input)
+}
+
+// decide stores review decision and brings rewards of referral in line with its resulting status
+func (f *FraudService) decide(id, adminID int, decision string,
+	input models.ReferralReviewDecisionRequest) (models.ReferralReview, error) {
+	review, err := f.repo.Decide(id, adminID, decision, optionalReason(input.Reason))
+	if err != nil {
+		f.logger.Errorf("decide[service]: Ошибка проверки реферала с id: %d: %s", id, err)
+		return models.ReferralReview{}, err
+	}
+
+	// Decision is already stored, so failed reward is only logged, it is credited again with next referral event
+	if err = f.rewardService.OnReferralStatus(id, review.Status); err != nil {
+		f.logger.Errorf("decide[service]: Ошибка пересчета вознаграждений реферала с id: %d: %s", id, err)
+	}
+
+	f.logger.Infof("decide[service]: Реферал с id: %d проверен, решение: %s", id, decision)
+	return review, nil
+}
+
+// checkSelfReferral flags referral whose email is alias of referrer email
+func (f *FraudService) checkSelfReferral(referral models.Referral, referrer models.User) (*models.RiskSignal, error) {
+	if normalizeEmail(referral.Email) != normalizeEmail(referrer.Email) {
+		return nil, nil
+	}
+	return &models.RiskSignal{Check: models.FraudCheckSelfReferral, Score: selfReferralRiskScore,
+		Detail: "email совпадает с email реферера после нормализации"}, nil
+}
+
+// checkDevice flags referral made from IP address or device already seen for referrer
+func (f *FraudService) checkDevice(referral models.Referral, referrer models.User) (*models.RiskSignal, error) {
+	if referral.IPHash == "" && referral.DeviceHash == "" {
+		return nil, nil
+	}
+
+	sameIP, sameDevice, err := f.repo.MatchDevice(referrer.ID, referral.IPHash, referral.DeviceHash)
+	if err != nil {
+		f.logger.Errorf("checkDevice[service]: Ошибка при сравнении устройств реферала с id: %d: %s", referral.ID, err)
+		return nil, err
+	}
+
+	// Same device outweighs same IP, shared IP alone is common for households and offices
+	if sameDevice {
+		return &models.RiskSignal{Check: models.FraudCheckSameDevice, Score: sameDeviceRiskScore,
+			Detail: "устройство совпадает с устройством реферера"}, nil
+	}
+	if sameIP {
+		return &models.RiskSignal{Check: models.FraudCheckSameIP, Score: sameIPRiskScore,
+			Detail: "IP-адрес совпадает с IP-адресом реферера"}, nil
+	}
+	return nil, nil
+}
+
+// checkVelocity flags referral if its referral code got more referrals within velocity window than allowed
+func (f *FraudService) checkVelocity(referral models.Referral, _ models.User) (*models.RiskSignal, error) {
+	count, err := f.repo.CountReferralsByCodeSince(referral.ReferralCodeID, time.Now().Add(-f.velocityWindow))
+	if err != nil {
+		f.logger.Errorf("checkVelocity[service]: Ошибка при подсчете рефералов кода с id: %d: %s",
+			referral.ReferralCodeID, err)
+		return nil, err
+	}
+
+	if count <= f.velocityLimit {
+		return nil, nil
+	}
+	return &models.RiskSignal{Check: models.FraudCheckVelocity, Score: velocityRiskScore,
+		Detail: fmt.Sprintf("%d рефералов по коду за %s", count, f.velocityWindow)}, nil
+}
+
+// checkDisposableEmail flags referral registered with mailbox of disposable email service
+func (f *FraudService) checkDisposableEmail(referral models.Referral, _ models.User) (*models.RiskSignal, error) {
+	_, domain, found := strings.Cut(strings.ToLower(strings.TrimSpace(referral.Email)), "@")
+	if !found || !f.disposableDomains[domain] {
+		return nil, nil
+	}
+	return &models.RiskSignal{Check: models.FraudCheckDisposableEmail, Score: disposableEmailRiskScore,
+		Detail: domain}, nil
+}
+
+// normalizeEmail reduces email to canonical mailbox: lowercases it, drops plus-addressing tag
+// and for Gmail also dots in local part, since Gmail ignores them
+func normalizeEmail(email string) string {
+	local, domain, found := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	if !found {
+		return local
+	}
+
+	local, _, _ = strings.Cut(local, "+")
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
@@ -0,0 +1,31 @@
+package api
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "plain", input: "user@example.com", want: "user@example.com"},
+		{name: "case and spaces", input: "  User@Example.COM ", want: "user@example.com"},
+		{name: "plus tag", input: "user+promo@example.com", want: "user@example.com"},
+		{name: "several plus tags", input: "user+a+b@example.com", want: "user@example.com"},
+		{name: "dots kept outside Gmail", input: "first.last@example.com", want: "first.last@example.com"},
+		{name: "Gmail dots", input: "f.i.r.s.t.last@gmail.com", want: "firstlast@gmail.com"},
+		{name: "Gmail dots and tag", input: "First.Last+ref@Gmail.com", want: "firstlast@gmail.com"},
+		{name: "googlemail is Gmail", input: "first.last@googlemail.com", want: "firstlast@gmail.com"},
+		{name: "Gmail subdomain is not Gmail", input: "first.last@mail.gmail.com", want: "first.last@mail.gmail.com"},
+		{name: "no at sign", input: " First.Last+ref ", want: "first.last+ref"},
+		{name: "empty", input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeEmail(tt.input); got != tt.want {
+				t.Errorf("normalizeEmail(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
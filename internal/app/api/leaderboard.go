@@ -0,0 +1,228 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+var ErrInvalidLeaderboard = errors.New("неправильные параметры рейтинга")
+var ErrInvalidLeaderboardSettings = errors.New("неправильные настройки рейтинга")
+
+const (
+	defaultLeaderboardLimit = 20
+	maxLeaderboardLimit     = 100
+	maxDisplayNameLength    = 64
+)
+
+// leaderboardCacheSize limits number of computed leaderboards kept in memory
+const leaderboardCacheSize = 64
+
+// cachedLeaderboard is computed ranking with moment it was computed
+type cachedLeaderboard struct {
+	entries     []models.LeaderboardEntry
+	generatedAt time.Time
+}
+
+// LeaderboardService represents service for ranking referrers over time windows
+// Whole ranking is computed by single query and kept in memory for configured time,
+// so pages and ranks of users are served without touching database
+type LeaderboardService struct {
+	repo     repository.LeaderboardRepo
+	userRepo repository.UserRepo
+	logger   *logrus.Logger
+	location *time.Location
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedLeaderboard
+	// computeMu lets only one request compute leaderboard while others wait for cached result
+	computeMu sync.Mutex
+}
+
+// NewLeaderboardService creates new instance of LeaderboardService with repositories,
+// time zone of windows and cache lifetime from config
+func NewLeaderboardService(repo repository.LeaderboardRepo, userRepo repository.UserRepo, cfg *config.Config,
+	logger *logrus.Logger) *LeaderboardService {
+	return &LeaderboardService{
+		repo:     repo,
+		userRepo: userRepo,
+		location: cfg.DefaultTimeZone,
+		cacheTTL: cfg.LeaderboardCacheTTL,
+		cache:    make(map[string]cachedLeaderboard),
+		logger:   logger,
+	}
+}
+
+// GetLeaderboard returns page of leaderboard and rank of user with given id
+// By default users are ranked by qualified referrals in current month, 20 per page
+func (l *LeaderboardService) GetLeaderboard(userID int, opts models.LeaderboardOptions) (models.LeaderboardResponse, error) {
+	l.logger.Debugf("GetLeaderboard[service]: Получение рейтинга для пользователя с id: %d", userID)
+
+	opts, err := normalizeLeaderboardOptions(opts)
+	if err != nil {
+		l.logger.Errorf("GetLeaderboard[service]: %s", err)
+		return models.LeaderboardResponse{}, err
+	}
+
+	since := capPeriodStart(opts.Window, time.Now(), l.location)
+	leaderboard, err := l.leaderboard(opts.Metric, since, opts.CampaignID)
+	if err != nil {
+		return models.LeaderboardResponse{}, err
+	}
+
+	response := models.LeaderboardResponse{
+		Metric:      opts.Metric,
+		Window:      opts.Window,
+		CampaignID:  opts.CampaignID,
+		Total:       len(leaderboard.entries),
+		Limit:       opts.Limit,
+		Offset:      opts.Offset,
+		GeneratedAt: leaderboard.generatedAt,
+		Entries:     []models.LeaderboardEntry{},
+	}
+	if !since.IsZero() {
+		response.Since = &since
+	}
+
+	for i, entry := range leaderboard.entries {
+		entry.IsMe = entry.UserID == userID
+		if entry.IsMe {
+			me := entry
+			response.Me = &me
+		}
+		if i >= opts.Offset && i < opts.Offset+opts.Limit {
+			response.Entries = append(response.Entries, entry)
+		}
+	}
+
+	return response, nil
+}
+
+// GetLeaderboardSettings returns whether user is shown on leaderboards by display name
+func (l *LeaderboardService) GetLeaderboardSettings(userID int) (models.LeaderboardSettings, error) {
+	l.logger.Debugf("GetLeaderboardSettings[service]: Получение настроек рейтинга пользователя с id: %d", userID)
+
+	user, err := l.userRepo.GetByID(userID)
+	if err != nil {
+		return models.LeaderboardSettings{}, err
+	}
+
+	return models.LeaderboardSettings{OptIn: user.LeaderboardOptIn, DisplayName: user.DisplayName}, nil
+}
+
+// UpdateLeaderboardSettings opts user in or out of public display on leaderboards
+// Opted-in user must have display name, leaderboards show it once cached ranking expires
+func (l *LeaderboardService) UpdateLeaderboardSettings(userID int,
+	input models.LeaderboardSettings) (models.LeaderboardSettings, error) {
+	l.logger.Debugf("UpdateLeaderboardSettings[service]: Изменение настроек рейтинга пользователя с id: %d", userID)
+
+	settings := models.LeaderboardSettings{OptIn: input.OptIn, DisplayName: strings.TrimSpace(input.DisplayName)}
+	if len([]rune(settings.DisplayName)) > maxDisplayNameLength {
+		return models.LeaderboardSettings{}, newFieldError(ErrInvalidLeaderboardSettings, "display_name",
+			"display_name длиннее %d символов", maxDisplayNameLength)
+	}
+	if settings.OptIn && settings.DisplayName == "" {
+		return models.LeaderboardSettings{}, newFieldError(ErrInvalidLeaderboardSettings, "display_name",
+			"для участия в рейтинге нужен display_name")
+	}
+
+	if err := l.userRepo.UpdateLeaderboardSettings(userID, settings.OptIn, settings.DisplayName); err != nil {
+		l.logger.Errorf("UpdateLeaderboardSettings[service]: Ошибка изменения настроек рейтинга"+
+			" пользователя с id: %d: %s", userID, err)
+		return models.LeaderboardSettings{}, err
+	}
+
+	return settings, nil
+}
+
+// leaderboard returns cached ranking or computes it if it is missing or expired
+func (l *LeaderboardService) leaderboard(metric string, since time.Time, campaignID *int) (cachedLeaderboard, error) {
+	key := fmt.Sprintf("%s:%d", metric, since.Unix())
+	if campaignID != nil {
+		key = fmt.Sprintf("%s:%d", key, *campaignID)
+	}
+
+	if cached, ok := l.cached(key); ok {
+		return cached, nil
+	}
+
+	l.computeMu.Lock()
+	defer l.computeMu.Unlock()
+
+	// Leaderboard may have been computed while waiting
+	if cached, ok := l.cached(key); ok {
+		return cached, nil
+	}
+
+	entries, err := l.repo.GetLeaderboard(metric, since, campaignID)
+	if err != nil {
+		l.logger.Errorf("leaderboard[service]: Ошибка при вычислении рейтинга %s: %s", key, err)
+		return cachedLeaderboard{}, err
+	}
+
+	computed := cachedLeaderboard{entries: entries, generatedAt: time.Now()}
+
+	l.mu.Lock()
+	// Cache is small, so it is simply dropped when full instead of tracking usage
+	if len(l.cache) >= leaderboardCacheSize {
+		l.cache = make(map[string]cachedLeaderboard)
+	}
+	l.cache[key] = computed
+	l.mu.Unlock()
+
+	return computed, nil
+}
+
+// cached returns leaderboard stored under key if it has not expired
+func (l *LeaderboardService) cached(key string) (cachedLeaderboard, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cached, ok := l.cache[key]
+	if !ok || time.Since(cached.generatedAt) > l.cacheTTL {
+		return cachedLeaderboard{}, false
+	}
+	return cached, true
+}
+
+// normalizeLeaderboardOptions fills defaults and validates leaderboard options
+func normalizeLeaderboardOptions(opts models.LeaderboardOptions) (models.LeaderboardOptions, error) {
+	opts.Metric = strings.ToLower(strings.TrimSpace(opts.Metric))
+	if opts.Metric == "" {
+		opts.Metric = models.LeaderboardMetricQualified
+	}
+	switch opts.Metric {
+	case models.LeaderboardMetricReferrals, models.LeaderboardMetricQualified, models.LeaderboardMetricRewards:
+	default:
+		return opts, newFieldError(ErrInvalidLeaderboard, "metric", "неизвестная metric %q", opts.Metric)
+	}
+
+	opts.Window = strings.ToLower(strings.TrimSpace(opts.Window))
+	if opts.Window == "" {
+		opts.Window = models.RewardCapPeriodMonth
+	}
+	if !isRewardCapPeriod(opts.Window) {
+		return opts, newFieldError(ErrInvalidLeaderboard, "window", "неизвестное window %q", opts.Window)
+	}
+
+	if opts.Limit == 0 {
+		opts.Limit = defaultLeaderboardLimit
+	}
+	if opts.Limit < 0 || opts.Limit > maxLeaderboardLimit {
+		return opts, newFieldError(ErrInvalidLeaderboard, "limit", "limit должен быть от 1 до %d",
+			maxLeaderboardLimit)
+	}
+	if opts.Offset < 0 {
+		return opts, newFieldError(ErrInvalidLeaderboard, "offset", "offset не может быть отрицательным")
+	}
+
+	return opts, nil
+}
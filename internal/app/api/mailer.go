@@ -0,0 +1,225 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+)
+
+// Mailer defines methods of system delivering emails
+type Mailer interface {
+	// Name returns name of mailer
+	Name() string
+	// Send delivers email and returns nil once it is accepted for delivery
+	Send(ctx context.Context, email models.Email) error
+}
+
+// newMailer returns mailer configured in config, config accepts only names of known mailers
+func newMailer(cfg *config.Config, logger *logrus.Logger) Mailer {
+	logger.Infof("newMailer[service]: Письма отправляются через %s", cfg.Mailer)
+
+	if cfg.Mailer == "smtp" {
+		return NewSMTPMailer(cfg.SMTPAddr, cfg.SMTPUser, cfg.SMTPPassword, cfg.MailFrom, cfg.MailTimeout, logger)
+	}
+	return NewFileMailer(cfg.MailDir, cfg.MailFrom, logger)
+}
+
+// SMTPMailer sends emails through SMTP server, upgrading connection with STARTTLS when server offers it
+// Local SMTP sinks such as MailHog or Mailpit accept mail without authentication
+type SMTPMailer struct {
+	addr    string
+	host    string
+	auth    smtp.Auth
+	from    string
+	timeout time.Duration
+	logger  *logrus.Logger
+}
+
+// NewSMTPMailer creates new SMTPMailer for server at addr, empty user disables authentication
+func NewSMTPMailer(addr, user, password, from string, timeout time.Duration, logger *logrus.Logger) *SMTPMailer {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	mailer := &SMTPMailer{
+		addr:    addr,
+		host:    host,
+		from:    from,
+		timeout: timeout,
+		logger:  logger,
+	}
+	if user != "" {
+		mailer.auth = smtp.PlainAuth("", user, password, host)
+	}
+	return mailer
+}
+
+// Name returns "smtp"
+func (m *SMTPMailer) Name() string {
+	return "smtp"
+}
+
+// Send delivers email to SMTP server
+func (m *SMTPMailer) Send(ctx context.Context, email models.Email) error {
+	message, err := buildEmail(m.from, email)
+	if err != nil {
+		return err
+	}
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: m.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	if err = conn.SetDeadline(time.Now().Add(m.timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		if err = client.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(sender.Address); err != nil {
+		return err
+	}
+	if err = client.Rcpt(email.To); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(message); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// FileMailer writes every email as .eml file into directory instead of sending it,
+// it is used for local development
+type FileMailer struct {
+	dir    string
+	from   string
+	logger *logrus.Logger
+}
+
+// NewFileMailer creates new FileMailer writing into dir
+func NewFileMailer(dir, from string, logger *logrus.Logger) *FileMailer {
+	return &FileMailer{
+		dir:    dir,
+		from:   from,
+		logger: logger,
+	}
+}
+
+// Name returns "file"
+func (m *FileMailer) Name() string {
+	return "file"
+}
+
+// Send writes email into file named by time and recipient
+func (m *FileMailer) Send(_ context.Context, email models.Email) error {
+	message, err := buildEmail(m.from, email)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	recipient := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, email.To)
+	name := fmt.Sprintf("%s_%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), recipient)
+	path := filepath.Join(m.dir, name)
+
+	if err = os.WriteFile(path, message, 0o644); err != nil {
+		return err
+	}
+
+	m.logger.Infof("Send[file]: Письмо для %s записано в %s", email.To, path)
+	return nil
+}
+
+// buildEmail formats email as RFC 5322 message with UTF-8 plain text body in quoted-printable encoding
+func buildEmail(from string, email models.Email) ([]byte, error) {
+	if _, err := mail.ParseAddress(email.To); err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(email.To, "\r\n") || strings.ContainsAny(from, "\r\n") {
+		return nil, fmt.Errorf("неправильный адрес письма")
+	}
+
+	messageID := make([]byte, 16)
+	if _, err := rand.Read(messageID); err != nil {
+		return nil, err
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = strings.Trim(from[at+1:], "> ")
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", email.To)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(messageID), domain)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&message)
+	if _, err := body.Write([]byte(email.Text)); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
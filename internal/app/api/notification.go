@@ -0,0 +1,293 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+var ErrInvalidNotificationSettings = errors.New("неправильные настройки уведомлений")
+
+const (
+	// notificationBatchSize is number of notifications claimed and sent at once
+	notificationBatchSize = 50
+	// maxNotificationRetryDelay caps exponential delay between attempts to send notification
+	maxNotificationRetryDelay = 6 * time.Hour
+	// maxNotificationErrorLength limits length of stored error of failed attempt
+	maxNotificationErrorLength = 500
+)
+
+// NotificationService represents service for emailing users about their referrals
+// Notifications are queued in database and sent by background worker, so mail delivery never slows down requests.
+// Users choose whether notifications are sent at once, collected into daily digest or not sent at all
+type NotificationService struct {
+	repo          repository.NotificationRepo
+	userRepo      repository.UserRepo
+	mailer        Mailer
+	logger        *logrus.Logger
+	defaultLocale string
+	digestHour    int
+	timeZone      *time.Location
+	pollInterval  time.Duration
+	maxAttempts   int
+	retryBase     time.Duration
+	mailTimeout   time.Duration
+}
+
+// NewNotificationService creates new instance of NotificationService with repositories, mailer
+// and notification settings from config
+func NewNotificationService(repo repository.NotificationRepo, userRepo repository.UserRepo, mailer Mailer,
+	cfg *config.Config, logger *logrus.Logger) *NotificationService {
+	return &NotificationService{
+		repo:          repo,
+		userRepo:      userRepo,
+		mailer:        mailer,
+		defaultLocale: cfg.NotificationLocale,
+		digestHour:    cfg.NotificationDigestHour,
+		timeZone:      cfg.DefaultTimeZone,
+		pollInterval:  cfg.NotificationPollInterval,
+		maxAttempts:   cfg.NotificationMaxAttempts,
+		retryBase:     cfg.NotificationRetryBase,
+		mailTimeout:   cfg.MailTimeout,
+		logger:        logger,
+	}
+}
+
+// GetNotificationSettings returns notification settings of user, locale is default one if user did not choose it
+func (n *NotificationService) GetNotificationSettings(userID int) (models.NotificationSettings, error) {
+	n.logger.Debugf("GetNotificationSettings[service]: Получение настроек уведомлений пользователя с id: %d", userID)
+
+	user, err := n.userRepo.GetByID(userID)
+	if err != nil {
+		return models.NotificationSettings{}, err
+	}
+
+	return n.settings(user), nil
+}
+
+// UpdateNotificationSettings changes notification mode and locale of user, omitted ones are left as they are
+func (n *NotificationService) UpdateNotificationSettings(userID int,
+	input models.NotificationSettingsRequest) (models.NotificationSettings, error) {
+	n.logger.Debugf("UpdateNotificationSettings[service]: Изменение настроек уведомлений пользователя с id: %d",
+		userID)
+
+	user, err := n.userRepo.GetByID(userID)
+	if err != nil {
+		return models.NotificationSettings{}, err
+	}
+
+	if input.Mode != nil {
+		mode := strings.TrimSpace(*input.Mode)
+		if mode != models.NotificationModeImmediate && mode != models.NotificationModeDigest &&
+			mode != models.NotificationModeOff {
+			return models.NotificationSettings{}, newFieldError(ErrInvalidNotificationSettings, "mode",
+				"mode должен быть immediate, digest или off")
+		}
+		user.NotificationMode = mode
+	}
+	if input.Locale != nil {
+		locale := strings.ToLower(strings.TrimSpace(*input.Locale))
+		if _, ok := notificationTemplates[locale]; !ok {
+			return models.NotificationSettings{}, newFieldError(ErrInvalidNotificationSettings, "locale",
+				"locale должен быть ru или en")
+		}
+		user.Locale = locale
+	}
+
+	if err = n.userRepo.UpdateNotificationSettings(userID, user.NotificationMode, user.Locale); err != nil {
+		n.logger.Errorf("UpdateNotificationSettings[service]: Ошибка изменения настроек уведомлений"+
+			" пользователя с id: %d: %s", userID, err)
+		return models.NotificationSettings{}, err
+	}
+
+	return n.settings(user), nil
+}
+
+// NotifyReferralCreated queues notification of referrer about new referral registered with code
+// Referral is already created, so failure is only logged
+func (n *NotificationService) NotifyReferralCreated(referral models.Referral, code string) {
+	data, err := json.Marshal(models.NotificationReferral{ReferralID: referral.ID, Email: referral.Email, Code: code})
+	if err != nil {
+		n.logger.Errorf("NotifyReferralCreated[service]: Ошибка кодирования уведомления: %s", err)
+		return
+	}
+
+	queued, err := n.repo.Enqueue(referral.ReferrerID, models.NotificationReferralCreated, data,
+		n.nextDigestAt(time.Now()))
+	if err != nil {
+		n.logger.Errorf("NotifyReferralCreated[service]: Ошибка постановки уведомления в очередь"+
+			" для пользователя с id: %d: %s", referral.ReferrerID, err)
+		return
+	}
+	if queued {
+		n.logger.Debugf("NotifyReferralCreated[service]: Уведомление о реферале с id: %d поставлено в очередь",
+			referral.ID)
+	}
+}
+
+// RunNotificationWorker sends due notifications every poll interval until ctx is done
+func (n *NotificationService) RunNotificationWorker(ctx context.Context) {
+	n.logger.Infof("RunNotificationWorker[service]: Отправка уведомлений через %s запущена", n.mailer.Name())
+
+	ticker := time.NewTicker(n.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		n.sendDue()
+
+		select {
+		case <-ctx.Done():
+			n.logger.Infof("RunNotificationWorker[service]: Отправка уведомлений остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDue sends due notifications in batches until there are no more due ones
+// Batch holds all due digest notifications of its users, they are sent as one email
+func (n *NotificationService) sendDue() {
+	for {
+		// Lease outlives sending of whole batch, so notification is never claimed again while it is being sent
+		notifications, err := n.repo.Claim(notificationBatchSize, time.Now().Add(2*n.mailTimeout+time.Minute))
+		if err != nil {
+			n.logger.Errorf("sendDue[service]: Ошибка при получении уведомлений: %s", err)
+			return
+		}
+
+		var groups [][]models.Notification
+		digests := make(map[int]int)
+		for _, notification := range notifications {
+			if !notification.Digest {
+				groups = append(groups, []models.Notification{notification})
+				continue
+			}
+			if i, ok := digests[notification.UserID]; ok {
+				groups[i] = append(groups[i], notification)
+				continue
+			}
+			digests[notification.UserID] = len(groups)
+			groups = append(groups, []models.Notification{notification})
+		}
+
+		var wg sync.WaitGroup
+		for _, group := range groups {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				n.send(group)
+			}()
+		}
+		wg.Wait()
+
+		// Batch is never smaller than notifications taken for it, unless there are no more due ones
+		if len(notifications) < notificationBatchSize {
+			return
+		}
+	}
+}
+
+// send emails group of notifications of one user and records result
+// Failed notifications are retried with exponential delay until they run out of attempts
+func (n *NotificationService) send(group []models.Notification) {
+	first := group[0]
+	ids := make([]int64, 0, len(group))
+	for _, notification := range group {
+		ids = append(ids, notification.ID)
+	}
+
+	err := n.deliver(group)
+	if err == nil {
+		if err = n.repo.Complete(ids, models.NotificationStatusSent, time.Now(), nil); err != nil {
+			n.logger.Errorf("send[service]: Ошибка сохранения отправки уведомлений %v: %s", ids, err)
+			return
+		}
+		n.logger.Infof("send[service]: Уведомления %v отправлены пользователю с id: %d", ids, first.UserID)
+		return
+	}
+
+	n.logger.Warnf("send[service]: Ошибка отправки уведомлений %v пользователю с id: %d: %s", ids, first.UserID, err)
+	message := truncate(err.Error(), maxNotificationErrorLength)
+	status, nextAttemptAt := models.NotificationStatusPending, time.Now().Add(n.retryDelay(first.Attempts))
+	if first.Attempts >= n.maxAttempts {
+		status = models.NotificationStatusFailed
+	}
+
+	if err = n.repo.Complete(ids, status, nextAttemptAt, &message); err != nil {
+		n.logger.Errorf("send[service]: Ошибка сохранения попытки отправки уведомлений %v: %s", ids, err)
+	}
+}
+
+// deliver renders email of notifications and sends it
+func (n *NotificationService) deliver(group []models.Notification) error {
+	first := group[0]
+
+	var data interface{}
+	notificationType := first.Type
+	if first.Digest {
+		digest := notificationDigest{}
+		for _, notification := range group {
+			var referral models.NotificationReferral
+			if err := json.Unmarshal(notification.Data, &referral); err != nil {
+				return err
+			}
+			digest.Referrals = append(digest.Referrals, referral)
+		}
+		data, notificationType = digest, digestType
+	} else {
+		var referral models.NotificationReferral
+		if err := json.Unmarshal(first.Data, &referral); err != nil {
+			return err
+		}
+		data = referral
+	}
+
+	subject, text, err := renderNotification(first.Locale, n.defaultLocale, notificationType, data)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), n.mailTimeout)
+	defer cancel()
+
+	return n.mailer.Send(ctx, models.Email{To: first.Email, Subject: subject, Text: text})
+}
+
+// nextDigestAt returns next digest time after now: digest hour of today or of tomorrow in default time zone
+func (n *NotificationService) nextDigestAt(now time.Time) time.Time {
+	local := now.In(n.timeZone)
+	digestAt := time.Date(local.Year(), local.Month(), local.Day(), n.digestHour, 0, 0, 0, n.timeZone)
+	if !digestAt.After(local) {
+		digestAt = digestAt.AddDate(0, 0, 1)
+	}
+	return digestAt
+}
+
+// retryDelay returns delay after failed attempt: retry base doubled for every previous attempt, capped at 6 hours
+func (n *NotificationService) retryDelay(attempt int) time.Duration {
+	delay := n.retryBase
+	for i := 1; i < attempt && delay < maxNotificationRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxNotificationRetryDelay)
+}
+
+// settings returns notification settings of user with default locale filled in
+func (n *NotificationService) settings(user models.User) models.NotificationSettings {
+	settings := models.NotificationSettings{Mode: user.NotificationMode, Locale: user.Locale}
+	if settings.Mode == "" {
+		settings.Mode = models.NotificationModeImmediate
+	}
+	if settings.Locale == "" {
+		settings.Locale = n.defaultLocale
+	}
+	return settings
+}
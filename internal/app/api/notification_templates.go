@@ -0,0 +1,99 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"rest-refs/internal/app/models"
+)
+
+// notificationTemplate is subject and text of email of one notification type in one language
+type notificationTemplate struct {
+	subject *template.Template
+	text    *template.Template
+}
+
+// digestType is key of daily digest templates, it is never type of queued notification
+const digestType = "digest"
+
+// notificationTemplates are email templates by locale and notification type.
+// Templates of notification types get data of notification, digest templates get notificationDigest
+var notificationTemplates = map[string]map[string]notificationTemplate{
+	models.LocaleRU: {
+		models.NotificationReferralCreated: newNotificationTemplate(
+			"Новый реферал по коду {{.Code}}",
+			`Здравствуйте!
+
+По вашему реферальному коду {{.Code}} зарегистрировался новый пользователь: {{.Email}}.
+
+Изменить настройки уведомлений можно через /me/notifications.
+`),
+		digestType: newNotificationTemplate(
+			"Новые рефералы за сутки: {{len .Referrals}}",
+			`Здравствуйте!
+
+За последние сутки по вашим реферальным кодам зарегистрировались:
+{{range .Referrals}}
+  - {{.Email}} (код {{.Code}})
+{{- end}}
+
+Изменить настройки уведомлений можно через /me/notifications.
+`),
+	},
+	models.LocaleEN: {
+		models.NotificationReferralCreated: newNotificationTemplate(
+			"New referral with code {{.Code}}",
+			`Hello!
+
+Someone has just signed up with your referral code {{.Code}}: {{.Email}}.
+
+You can change notification settings at /me/notifications.
+`),
+		digestType: newNotificationTemplate(
+			"New referrals today: {{len .Referrals}}",
+			`Hello!
+
+These people signed up with your referral codes during the last day:
+{{range .Referrals}}
+  - {{.Email}} (code {{.Code}})
+{{- end}}
+
+You can change notification settings at /me/notifications.
+`),
+	},
+}
+
+// notificationDigest is data of daily digest templates
+type notificationDigest struct {
+	Referrals []models.NotificationReferral
+}
+
+// newNotificationTemplate parses subject and text templates, templates are constants, so invalid one panics
+func newNotificationTemplate(subject, text string) notificationTemplate {
+	return notificationTemplate{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		text:    template.Must(template.New("text").Parse(text)),
+	}
+}
+
+// renderNotification renders email of notification type in locale, unknown locale falls back to fallbackLocale
+func renderNotification(locale, fallbackLocale, notificationType string, data interface{}) (string, string, error) {
+	templates, ok := notificationTemplates[locale]
+	if !ok {
+		templates = notificationTemplates[fallbackLocale]
+	}
+	tmpl, ok := templates[notificationType]
+	if !ok {
+		return "", "", fmt.Errorf("нет шаблона уведомления %s", notificationType)
+	}
+
+	var subject, text bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), text.String(), nil
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/repository"
+)
+
+const (
+	// maxOutboxRetryDelay caps exponential delay between attempts to publish message
+	maxOutboxRetryDelay = time.Hour
+	// outboxCleanupInterval is how often published messages older than retention are removed
+	outboxCleanupInterval = time.Hour
+)
+
+// OutboxService represents relay publishing domain events recorded in outbox through EventPublisher
+// Delivery is at least once: message is marked published only after publisher accepted it,
+// messages of one aggregate are published in order of their creation
+type OutboxService struct {
+	repo           repository.OutboxRepo
+	publisher      EventPublisher
+	logger         *logrus.Logger
+	pollInterval   time.Duration
+	batchSize      int
+	retryBase      time.Duration
+	publishTimeout time.Duration
+	retention      time.Duration
+}
+
+// NewOutboxService creates new instance of OutboxService with repository, publisher and relay settings from config
+func NewOutboxService(repo repository.OutboxRepo, publisher EventPublisher, cfg *config.Config,
+	logger *logrus.Logger) *OutboxService {
+	return &OutboxService{
+		repo:           repo,
+		publisher:      publisher,
+		pollInterval:   cfg.OutboxPollInterval,
+		batchSize:      cfg.OutboxBatchSize,
+		retryBase:      cfg.OutboxRetryBase,
+		publishTimeout: cfg.OutboxPublishTimeout,
+		retention:      cfg.OutboxRetention,
+		logger:         logger,
+	}
+}
+
+// RunOutboxRelay publishes pending outbox messages every poll interval until ctx is done
+// Several instances of service may run relay at the same time, each message is handled by one of them
+func (o *OutboxService) RunOutboxRelay(ctx context.Context) {
+	o.logger.Infof("RunOutboxRelay[service]: Публикация событий через %s запущена", o.publisher.Name())
+
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+
+	var cleanedAt time.Time
+	for {
+		o.relayPending()
+
+		if time.Since(cleanedAt) >= outboxCleanupInterval {
+			o.deletePublished()
+			cleanedAt = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			o.logger.Infof("RunOutboxRelay[service]: Публикация событий остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayPending publishes batches of due messages until batch comes out incomplete
+func (o *OutboxService) relayPending() {
+	for {
+		published, err := o.repo.PublishPending(o.batchSize, o.publishTimeout, o.publisher.Publish, o.retryAt)
+		if err != nil {
+			o.logger.Errorf("relayPending[service]: Ошибка публикации событий: %s", err)
+			return
+		}
+		if published > 0 {
+			o.logger.Debugf("relayPending[service]: Опубликовано %d событий", published)
+		}
+
+		if published < o.batchSize {
+			return
+		}
+	}
+}
+
+// deletePublished removes messages published before retention period
+func (o *OutboxService) deletePublished() {
+	deleted, err := o.repo.DeletePublished(time.Now().Add(-o.retention))
+	if err != nil {
+		o.logger.Errorf("deletePublished[service]: Ошибка удаления опубликованных событий: %s", err)
+		return
+	}
+	if deleted > 0 {
+		o.logger.Infof("deletePublished[service]: Удалено %d опубликованных событий", deleted)
+	}
+}
+
+// retryAt returns time of next attempt after failed one: retry base doubled for every previous attempt,
+// capped at one hour
+func (o *OutboxService) retryAt(attempts int) time.Time {
+	delay := o.retryBase
+	for i := 1; i < attempts && delay < maxOutboxRetryDelay; i++ {
+		delay *= 2
+	}
+	return time.Now().Add(min(delay, maxOutboxRetryDelay))
+}
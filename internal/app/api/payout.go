@@ -0,0 +1,218 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+var ErrInvalidPayout = errors.New("неправильные параметры выплаты")
+var ErrInvalidPayoutStatus = errors.New("неизвестный статус выплаты")
+var ErrPayoutNotSent = errors.New("выплата не отправлена")
+
+const maxPayoutDestinationLength = 255
+
+// PayoutService represents service for cashing out rewards: users request payouts, admins approve or reject them
+// and approved payouts are sent through payout provider
+type PayoutService struct {
+	repo             repository.PayoutRepo
+	logger           *logrus.Logger
+	provider         PayoutProvider
+	minAmount        int64
+	chargebackWindow time.Duration
+}
+
+// NewPayoutService creates new instance of PayoutService with repository, payout provider and limits from config
+func NewPayoutService(repo repository.PayoutRepo, provider PayoutProvider, cfg *config.Config,
+	logger *logrus.Logger) *PayoutService {
+	return &PayoutService{
+		repo:             repo,
+		provider:         provider,
+		minAmount:        cfg.PayoutMinAmount,
+		chargebackWindow: cfg.ChargebackWindow,
+		logger:           logger,
+	}
+}
+
+// RequestPayout validates request and reserves requested amount of available rewards of user
+// Rewards of referrals still inside chargeback window are not available
+func (p *PayoutService) RequestPayout(userID int, input models.PayoutRequest) (models.Payout, error) {
+	p.logger.Debugf("RequestPayout[service]: Запрос выплаты пользователем с id: %d", userID)
+
+	payout := models.Payout{
+		UserID:      userID,
+		Amount:      input.Amount,
+		Destination: strings.TrimSpace(input.Destination),
+		Provider:    p.provider.Name(),
+	}
+
+	if payout.Amount < p.minAmount {
+		p.logger.Errorf("RequestPayout[service]: Сумма выплаты %d меньше минимальной %d", payout.Amount, p.minAmount)
+		return models.Payout{}, ErrInvalidPayout
+	}
+
+	if payout.Destination == "" || len(payout.Destination) > maxPayoutDestinationLength {
+		p.logger.Errorf("RequestPayout[service]: Неправильные реквизиты выплаты")
+		return models.Payout{}, ErrInvalidPayout
+	}
+
+	created, err := p.repo.Create(payout, time.Now().Add(-p.chargebackWindow))
+	if err != nil {
+		p.logger.Errorf("RequestPayout[service]: Ошибка создания выплаты: %s", err)
+		return models.Payout{}, err
+	}
+
+	p.logger.Infof("RequestPayout[service]: Выплата с id: %d на сумму %d запрошена", created.ID, created.Amount)
+	return created, nil
+}
+
+// ApprovePayout approves requested payout and sends it through payout provider
+// Payout is paid if provider accepted it, and failed with its amount returned to user only if provider rejected it.
+// Other errors of provider, e.g. timeouts, do not tell whether money was sent, so payout is left approved
+// and ErrPayoutNotSent is returned. Approved payout is sent again on next approval, id of payout
+// is idempotency key of transfer, so provider does not send money twice
+func (p *PayoutService) ApprovePayout(id, adminID int, input models.PayoutDecisionRequest) (models.Payout, error) {
+	p.logger.Debugf("ApprovePayout[service]: Одобрение выплаты с id: %d", id)
+
+	payout, err := p.repo.GetByID(id)
+	if err != nil {
+		p.logger.Errorf("ApprovePayout[service]: Ошибка получения выплаты с id: %d: %s", id, err)
+		return models.Payout{}, err
+	}
+
+	if payout.Status != models.PayoutStatusApproved {
+		approval := models.PayoutStatusChange{
+			ToStatus:  models.PayoutStatusApproved,
+			ChangedBy: &adminID,
+			Reason:    optionalReason(input.Reason),
+		}
+		payout, err = p.repo.ChangeStatus(id, models.PayoutStatusRequested, approval, nil, "")
+		if err != nil {
+			p.logger.Errorf("ApprovePayout[service]: Ошибка одобрения выплаты с id: %d: %s", id, err)
+			return models.Payout{}, err
+		}
+	} else {
+		p.logger.Warnf("ApprovePayout[service]: Повторная отправка одобренной выплаты с id: %d", id)
+	}
+
+	reference, err := p.provider.Send(payout, payoutIdempotencyKey(payout))
+	if err != nil {
+		var rejected *PayoutRejectedError
+		if !errors.As(err, &rejected) {
+			p.logger.Errorf("ApprovePayout[service]: Результат отправки выплаты с id: %d неизвестен, "+
+				"выплата остается одобренной: %s", id, err)
+			return models.Payout{}, fmt.Errorf("%w: %s", ErrPayoutNotSent, err)
+		}
+
+		p.logger.Errorf("ApprovePayout[service]: Провайдер отклонил выплату с id: %d: %s", id, rejected.Reason)
+		failure := models.PayoutStatusChange{ToStatus: models.PayoutStatusFailed, Reason: &rejected.Reason}
+		return p.repo.ChangeStatus(id, models.PayoutStatusApproved, failure, nil, models.RewardKindPayoutRelease)
+	}
+
+	paid := models.PayoutStatusChange{ToStatus: models.PayoutStatusPaid}
+	payout, err = p.repo.ChangeStatus(id, models.PayoutStatusApproved, paid, &reference, models.RewardKindPayoutPaid)
+	if err != nil {
+		p.logger.Errorf("ApprovePayout[service]: Ошибка завершения выплаты с id: %d, ссылка провайдера: %s: %s",
+			id, reference, err)
+		return models.Payout{}, err
+	}
+
+	p.logger.Infof("ApprovePayout[service]: Выплата с id: %d отправлена, ссылка провайдера: %s", id, reference)
+	return payout, nil
+}
+
+// RejectPayout rejects requested payout and returns its amount to user
+func (p *PayoutService) RejectPayout(id, adminID int, input models.PayoutDecisionRequest) (models.Payout, error) {
+	p.logger.Debugf("RejectPayout[service]: Отклонение выплаты с id: %d", id)
+
+	rejection := models.PayoutStatusChange{
+		ToStatus:  models.PayoutStatusRejected,
+		ChangedBy: &adminID,
+		Reason:    optionalReason(input.Reason),
+	}
+	payout, err := p.repo.ChangeStatus(id, models.PayoutStatusRequested, rejection, nil,
+		models.RewardKindPayoutRelease)
+	if err != nil {
+		p.logger.Errorf("RejectPayout[service]: Ошибка отклонения выплаты с id: %d: %s", id, err)
+		return models.Payout{}, err
+	}
+
+	p.logger.Infof("RejectPayout[service]: Выплата с id: %d отклонена", id)
+	return payout, nil
+}
+
+// GetPayoutByID retrieves payout with history of its status changes
+func (p *PayoutService) GetPayoutByID(id int) (models.Payout, error) {
+	p.logger.Debugf("GetPayoutByID[service]: Получение выплаты с id: %d", id)
+	return p.repo.GetByID(id)
+}
+
+// GetPayoutsByUserID retrieves payouts of user from newest to oldest
+func (p *PayoutService) GetPayoutsByUserID(userID int) ([]models.Payout, error) {
+	p.logger.Debugf("GetPayoutsByUserID[service]: Получение выплат пользователя с id: %d", userID)
+
+	payouts, err := p.repo.GetByUserID(userID)
+	if err != nil {
+		p.logger.Errorf("GetPayoutsByUserID[service]: Ошибка получения выплат пользователя с id: %d: %s", userID, err)
+		return nil, err
+	}
+
+	if payouts == nil {
+		payouts = []models.Payout{}
+	}
+	return payouts, nil
+}
+
+// GetPayouts retrieves payouts in order of requests, non-empty statuses limit result to these statuses,
+// e.g. "requested" is queue waiting for admin decision
+func (p *PayoutService) GetPayouts(statuses []string) ([]models.Payout, error) {
+	p.logger.Debugf("GetPayouts[service]: Получение списка выплат")
+
+	for _, status := range statuses {
+		if !isPayoutStatus(status) {
+			p.logger.Errorf("GetPayouts[service]: Неизвестный статус выплаты: %s", status)
+			return nil, ErrInvalidPayoutStatus
+		}
+	}
+
+	payouts, err := p.repo.GetAll(statuses)
+	if err != nil {
+		p.logger.Errorf("GetPayouts[service]: Ошибка получения списка выплат: %s", err)
+		return nil, err
+	}
+
+	if payouts == nil {
+		payouts = []models.Payout{}
+	}
+	return payouts, nil
+}
+
+// payoutIdempotencyKey returns key of transfer of payout, it is the same for every attempt to send payout
+func payoutIdempotencyKey(payout models.Payout) string {
+	return fmt.Sprintf("payout-%d", payout.ID)
+}
+
+// optionalReason returns trimmed reason or nil if it is empty
+func optionalReason(reason string) *string {
+	if reason = strings.TrimSpace(reason); reason == "" {
+		return nil
+	}
+	return &reason
+}
+
+// isPayoutStatus reports whether status is one of known payout statuses
+func isPayoutStatus(status string) bool {
+	switch status {
+	case models.PayoutStatusRequested, models.PayoutStatusApproved, models.PayoutStatusRejected,
+		models.PayoutStatusPaid, models.PayoutStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
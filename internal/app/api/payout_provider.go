@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+)
+
+// PayoutProvider defines methods of external system that sends money for approved payouts
+type PayoutProvider interface {
+	// Name returns name of provider stored with payout
+	Name() string
+	// Send transfers payout amount to its destination and returns reference of transfer in provider
+	// Repeated call with the same idempotency key does not transfer money again and returns reference
+	// of the first transfer. *PayoutRejectedError means payout is definitely not transferred, with any other
+	// error, e.g. timeout, transfer may still have happened
+	Send(payout models.Payout, idempotencyKey string) (string, error)
+}
+
+// PayoutRejectedError is returned by provider that refused payout, e.g. because destination does not exist,
+// so nothing is transferred and sending it again does not help
+type PayoutRejectedError struct {
+	Reason string
+}
+
+// Error returns reason of rejection
+func (e *PayoutRejectedError) Error() string {
+	return fmt.Sprintf("провайдер отклонил выплату: %s", e.Reason)
+}
+
+// FakePayoutProvider pretends to send payouts, it only logs them and returns random reference
+// It is used for local development and tests
+type FakePayoutProvider struct {
+	logger     *logrus.Logger
+	mu         sync.Mutex
+	references map[string]string
+	failures   map[string]error
+}
+
+// NewFakePayoutProvider creates new FakePayoutProvider
+func NewFakePayoutProvider(logger *logrus.Logger) *FakePayoutProvider {
+	return &FakePayoutProvider{
+		logger:     logger,
+		references: make(map[string]string),
+		failures:   make(map[string]error),
+	}
+}
+
+// FailNext makes next Send with idempotency key return err without sending payout
+func (p *FakePayoutProvider) FailNext(idempotencyKey string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failures[idempotencyKey] = err
+}
+
+// Sent returns number of payouts sent, payout sent again with the same idempotency key is counted once
+func (p *FakePayoutProvider) Sent() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.references)
+}
+
+// Name returns "fake"
+func (p *FakePayoutProvider) Name() string {
+	return "fake"
+}
+
+// Send logs payout and returns random reference, reference of repeated call is remembered by idempotency key
+func (p *FakePayoutProvider) Send(payout models.Payout, idempotencyKey string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err, ok := p.failures[idempotencyKey]; ok {
+		delete(p.failures, idempotencyKey)
+		p.logger.Infof("Send[fake]: Выплата с id: %d не отправлена: %s", payout.ID, err)
+		return "", err
+	}
+
+	if reference, ok := p.references[idempotencyKey]; ok {
+		p.logger.Infof("Send[fake]: Выплата с id: %d уже отправлена, ссылка: %s", payout.ID, reference)
+		return reference, nil
+	}
+
+	reference := make([]byte, 8)
+	if _, err := rand.Read(reference); err != nil {
+		return "", err
+	}
+
+	p.logger.Infof("Send[fake]: Выплата с id: %d на сумму %d отправлена на %s",
+		payout.ID, payout.Amount, payout.Destination)
+	p.references[idempotencyKey] = fmt.Sprintf("fake-%d-%s", payout.ID, hex.EncodeToString(reference))
+	return p.references[idempotencyKey], nil
+}
+
+// newPayoutProvider returns provider with given name, config accepts only names of known providers,
+// so fake provider is the only one left
+func newPayoutProvider(name string, logger *logrus.Logger) PayoutProvider {
+	logger.Infof("newPayoutProvider[service]: Выплаты отправляются через провайдера %s", name)
+	return NewFakePayoutProvider(logger)
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+// fakePayoutRepo keeps payouts in memory and records kinds of ledger transactions posted for them
+type fakePayoutRepo struct {
+	payouts map[int]models.Payout
+	ledger  map[int][]string
+}
+
+func newFakePayoutRepo(payouts ...models.Payout) *fakePayoutRepo {
+	repo := &fakePayoutRepo{payouts: make(map[int]models.Payout), ledger: make(map[int][]string)}
+	for _, payout := range payouts {
+		repo.payouts[payout.ID] = payout
+	}
+	return repo
+}
+
+func (r *fakePayoutRepo) Create(payout models.Payout, _ time.Time) (models.Payout, error) {
+	payout.ID = len(r.payouts) + 1
+	payout.Status = models.PayoutStatusRequested
+	r.payouts[payout.ID] = payout
+	r.ledger[payout.ID] = append(r.ledger[payout.ID], models.RewardKindPayoutHold)
+	return payout, nil
+}
+
+func (r *fakePayoutRepo) ChangeStatus(id int, from string, change models.PayoutStatusChange, reference *string,
+	ledgerKind string) (models.Payout, error) {
+	payout, ok := r.payouts[id]
+	if !ok {
+		return models.Payout{}, postgresql.ErrPayoutNotFound
+	}
+	if payout.Status != from {
+		return models.Payout{}, postgresql.ErrPayoutStatusConflict
+	}
+
+	payout.Status = change.ToStatus
+	payout.Reason = change.Reason
+	if reference != nil {
+		payout.ProviderReference = reference
+	}
+	r.payouts[id] = payout
+	if ledgerKind != "" {
+		r.ledger[id] = append(r.ledger[id], ledgerKind)
+	}
+	return payout, nil
+}
+
+func (r *fakePayoutRepo) GetByID(id int) (models.Payout, error) {
+	payout, ok := r.payouts[id]
+	if !ok {
+		return models.Payout{}, postgresql.ErrPayoutNotFound
+	}
+	return payout, nil
+}
+
+func (r *fakePayoutRepo) GetByUserID(int) ([]models.Payout, error) {
+	return nil, nil
+}
+
+func (r *fakePayoutRepo) GetAll([]string) ([]models.Payout, error) {
+	return nil, nil
+}
+
+func testPayoutService(repo *fakePayoutRepo) (*PayoutService, *FakePayoutProvider) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	provider := NewFakePayoutProvider(logger)
+	return NewPayoutService(repo, provider, &config.Config{}, logger), provider
+}
+
+func testPayout(status string) models.Payout {
+	return models.Payout{ID: 1, UserID: 7, Amount: 1000, Destination: "card:1234", Status: status, Provider: "fake"}
+}
+
+func TestApprovePayout(t *testing.T) {
+	key := payoutIdempotencyKey(testPayout(""))
+
+	tests := []struct {
+		name       string
+		status     string
+		sendErr    error
+		err        error
+		wantStatus string
+		wantLedger []string
+		wantSent   int
+	}{
+		{name: "sent", status: models.PayoutStatusRequested, wantStatus: models.PayoutStatusPaid,
+			wantLedger: []string{models.RewardKindPayoutPaid}, wantSent: 1},
+		{name: "rejected by provider", status: models.PayoutStatusRequested,
+			sendErr: &PayoutRejectedError{Reason: "счет закрыт"}, wantStatus: models.PayoutStatusFailed,
+			wantLedger: []string{models.RewardKindPayoutRelease}},
+		{name: "timeout leaves payout approved", status: models.PayoutStatusRequested,
+			sendErr: context.DeadlineExceeded, err: ErrPayoutNotSent, wantStatus: models.PayoutStatusApproved},
+		{name: "server error leaves payout approved", status: models.PayoutStatusRequested,
+			sendErr: errors.New("502 Bad Gateway"), err: ErrPayoutNotSent, wantStatus: models.PayoutStatusApproved},
+		{name: "approved payout is sent again", status: models.PayoutStatusApproved,
+			wantStatus: models.PayoutStatusPaid, wantLedger: []string{models.RewardKindPayoutPaid}, wantSent: 1},
+		{name: "rejected payout", status: models.PayoutStatusRejected, err: postgresql.ErrPayoutStatusConflict,
+			wantStatus: models.PayoutStatusRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakePayoutRepo(testPayout(tt.status))
+			service, provider := testPayoutService(repo)
+			if tt.sendErr != nil {
+				provider.FailNext(key, tt.sendErr)
+			}
+
+			_, err := service.ApprovePayout(1, 99, models.PayoutDecisionRequest{})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ApprovePayout() error = %v, want %v", err, tt.err)
+			}
+
+			payout := repo.payouts[1]
+			if payout.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", payout.Status, tt.wantStatus)
+			}
+			if got := repo.ledger[1]; !reflect.DeepEqual(got, tt.wantLedger) {
+				t.Errorf("ledger = %v, want %v", got, tt.wantLedger)
+			}
+			if got := provider.Sent(); got != tt.wantSent {
+				t.Errorf("provider sent %d payouts, want %d", got, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestApprovePayoutRejectionReason(t *testing.T) {
+	repo := newFakePayoutRepo(testPayout(models.PayoutStatusRequested))
+	service, provider := testPayoutService(repo)
+	provider.FailNext(payoutIdempotencyKey(testPayout("")), &PayoutRejectedError{Reason: "счет закрыт"})
+
+	payout, err := service.ApprovePayout(1, 99, models.PayoutDecisionRequest{})
+	if err != nil {
+		t.Fatalf("ApprovePayout() error = %v", err)
+	}
+	if payout.Reason == nil || *payout.Reason != "счет закрыт" {
+		t.Errorf("reason = %v, want %q", payout.Reason, "счет закрыт")
+	}
+}
+
+func TestApprovePayoutRetryAfterTimeout(t *testing.T) {
+	repo := newFakePayoutRepo(testPayout(models.PayoutStatusRequested))
+	service, provider := testPayoutService(repo)
+	provider.FailNext(payoutIdempotencyKey(testPayout("")), context.DeadlineExceeded)
+
+	if _, err := service.ApprovePayout(1, 99, models.PayoutDecisionRequest{}); !errors.Is(err, ErrPayoutNotSent) {
+		t.Fatalf("ApprovePayout() error = %v, want %v", err, ErrPayoutNotSent)
+	}
+
+	payout, err := service.ApprovePayout(1, 99, models.PayoutDecisionRequest{})
+	if err != nil {
+		t.Fatalf("ApprovePayout() retry error = %v", err)
+	}
+	if payout.Status != models.PayoutStatusPaid || payout.ProviderReference == nil {
+		t.Errorf("payout = %+v, want paid with provider reference", payout)
+	}
+	if got := repo.ledger[1]; len(got) != 1 || got[0] != models.RewardKindPayoutPaid {
+		t.Errorf("ledger = %v, want only %q", got, models.RewardKindPayoutPaid)
+	}
+}
+
+func TestApprovePayoutResendKeepsReference(t *testing.T) {
+	// Provider sent payout, but it was not marked paid, so it is still approved
+	repo := newFakePayoutRepo(testPayout(models.PayoutStatusApproved))
+	service, provider := testPayoutService(repo)
+	reference, err := provider.Send(testPayout(""), payoutIdempotencyKey(testPayout("")))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	payout, err := service.ApprovePayout(1, 99, models.PayoutDecisionRequest{})
+	if err != nil {
+		t.Fatalf("ApprovePayout() error = %v", err)
+	}
+	if payout.ProviderReference == nil || *payout.ProviderReference != reference {
+		t.Errorf("provider reference = %v, want %q of the first transfer", payout.ProviderReference, reference)
+	}
+	if got := provider.Sent(); got != 1 {
+		t.Errorf("provider sent %d payouts, want 1", got)
+	}
+}
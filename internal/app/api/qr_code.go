@@ -0,0 +1,214 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/skip2/go-qrcode"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+var ErrInvalidQRCodeOptions = errors.New("неправильные параметры QR-кода")
+
+const (
+	defaultQRCodeSize   = 256
+	minQRCodeSize       = 64
+	maxQRCodeSize       = 2048
+	defaultQRCodeMargin = 4
+	maxQRCodeMargin     = 16
+	defaultQRCodeLevel  = "M"
+)
+
+// qrCodeCacheSize limits number of rendered images kept in memory
+const qrCodeCacheSize = 256
+
+// qrCodeLevels maps error correction level names to go-qrcode recovery levels
+var qrCodeLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// QRCodeService renders QR codes of referral share links in-process
+// Rendered images are cached by code version (updated_at), so editing the code invalidates its images
+type QRCodeService struct {
+	referralCodeService *ReferralCodeService
+	logger              *logrus.Logger
+
+	mu    sync.Mutex
+	cache map[string][]byte
+}
+
+// NewQRCodeService creates new instance of QRCodeService with referralCodeService
+func NewQRCodeService(referralCodeService *ReferralCodeService, logger *logrus.Logger) *QRCodeService {
+	return &QRCodeService{
+		referralCodeService: referralCodeService,
+		cache:               make(map[string][]byte),
+		logger:              logger,
+	}
+}
+
+// GenerateReferralCodeQR renders QR code of share link for referral code owned by referrer
+// It returns image bytes and entity tag identifying code version and rendering options
+// Codes of other referrers are reported as ErrReferralCodeNotFound
+func (q *QRCodeService) GenerateReferralCodeQR(referrerID, codeID int, opts models.QRCodeOptions) ([]byte, string, error) {
+	q.logger.Debugf("GenerateReferralCodeQR[service]: Генерация QR-кода для реферального кода с id: %d", codeID)
+
+	opts, err := normalizeQRCodeOptions(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	referralCode, err := q.referralCodeService.repo.GetReferralCodeByID(codeID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if referralCode.ReferrerID != referrerID {
+		q.logger.Warnf("GenerateReferralCodeQR[service]: Реферальный код с id: %d не принадлежит"+
+			" пользователю с id: %d", codeID, referrerID)
+		return nil, "", postgresql.ErrReferralCodeNotFound
+	}
+
+	key := fmt.Sprintf("%d:%d:%s:%d:%s:%d", referralCode.ID, referralCode.UpdatedAt.UnixNano(),
+		opts.Format, opts.Size, opts.Level, opts.Margin)
+	sum := sha256.Sum256([]byte(key))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	q.mu.Lock()
+	cached, ok := q.cache[key]
+	q.mu.Unlock()
+	if ok {
+		q.logger.Debugf("GenerateReferralCodeQR[service]: QR-код для реферального кода с id: %d взят из кэша", codeID)
+		return cached, etag, nil
+	}
+
+	qr, err := qrcode.New(q.referralCodeService.ShareURL(referralCode.Code), qrCodeLevels[opts.Level])
+	if err != nil {
+		q.logger.Errorf("GenerateReferralCodeQR[service]: Ошибка кодирования QR-кода: %s", err)
+		return nil, "", err
+	}
+	qr.DisableBorder = true
+
+	var rendered []byte
+	if opts.Format == models.QRCodeFormatSVG {
+		rendered = renderQRCodeSVG(qr.Bitmap(), opts)
+	} else {
+		rendered, err = renderQRCodePNG(qr.Bitmap(), opts)
+		if err != nil {
+			q.logger.Errorf("GenerateReferralCodeQR[service]: Ошибка отрисовки QR-кода: %s", err)
+			return nil, "", err
+		}
+	}
+
+	q.mu.Lock()
+	// Cache is small, so it is simply dropped when full instead of tracking usage
+	if len(q.cache) >= qrCodeCacheSize {
+		q.cache = make(map[string][]byte)
+	}
+	q.cache[key] = rendered
+	q.mu.Unlock()
+
+	q.logger.Infof("GenerateReferralCodeQR[service]: QR-код для реферального кода с id: %d сгенерирован", codeID)
+	return rendered, etag, nil
+}
+
+// normalizeQRCodeOptions fills defaults and validates rendering options
+func normalizeQRCodeOptions(opts models.QRCodeOptions) (models.QRCodeOptions, error) {
+	opts.Format = strings.ToLower(opts.Format)
+	if opts.Format == "" {
+		opts.Format = models.QRCodeFormatPNG
+	}
+	if opts.Format != models.QRCodeFormatPNG && opts.Format != models.QRCodeFormatSVG {
+		return models.QRCodeOptions{}, ErrInvalidQRCodeOptions
+	}
+
+	if opts.Size == 0 {
+		opts.Size = defaultQRCodeSize
+	}
+	if opts.Size < minQRCodeSize || opts.Size > maxQRCodeSize {
+		return models.QRCodeOptions{}, ErrInvalidQRCodeOptions
+	}
+
+	opts.Level = strings.ToUpper(opts.Level)
+	if opts.Level == "" {
+		opts.Level = defaultQRCodeLevel
+	}
+	if _, ok := qrCodeLevels[opts.Level]; !ok {
+		return models.QRCodeOptions{}, ErrInvalidQRCodeOptions
+	}
+
+	if opts.Margin < 0 {
+		opts.Margin = defaultQRCodeMargin
+	}
+	if opts.Margin > maxQRCodeMargin {
+		return models.QRCodeOptions{}, ErrInvalidQRCodeOptions
+	}
+
+	return opts, nil
+}
+
+// renderQRCodePNG draws bitmap with margin into square PNG image of requested size
+// Modules are scaled by whole number of pixels, leftover space is split evenly around the code
+func renderQRCodePNG(bitmap [][]bool, opts models.QRCodeOptions) ([]byte, error) {
+	modules := len(bitmap) + 2*opts.Margin
+	scale := opts.Size / modules
+	if scale < 1 {
+		return nil, ErrInvalidQRCodeOptions
+	}
+	offset := (opts.Size-modules*scale)/2 + opts.Margin*scale
+
+	palette := color.Palette{color.White, color.Black}
+	img := image.NewPaletted(image.Rect(0, 0, opts.Size, opts.Size), palette)
+
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(offset+x*scale+dx, offset+y*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderQRCodeSVG draws bitmap with margin as SVG path in module coordinates scaled to requested size
+func renderQRCodeSVG(bitmap [][]bool, opts models.QRCodeOptions) []byte {
+	modules := len(bitmap) + 2*opts.Margin
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`shape-rendering="crispEdges">`, opts.Size, opts.Size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, modules, modules)
+
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+opts.Margin, y+opts.Margin)
+			}
+		}
+	}
+
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
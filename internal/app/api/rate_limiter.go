@@ -0,0 +1,62 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter limits number of requests per client IP within fixed time window
+// It is shared by REST and gRPC APIs, so the same limits apply to both
+type RateLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	visitors map[string]*visitor
+	sweptAt  time.Time
+}
+
+// visitor holds number of requests made by client in current window
+type visitor struct {
+	count       int
+	windowStart time.Time
+}
+
+// NewRateLimiter creates new RateLimiter allowing limit requests per window for each IP
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:    limit,
+		window:   window,
+		visitors: make(map[string]*visitor),
+		sweptAt:  time.Now(),
+	}
+}
+
+// Allow registers request from ip and reports whether it fits into the limit
+// If request is rejected, it also returns time left until the window resets
+func (rl *RateLimiter) Allow(ip string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	// Forget visitors with finished windows, so map does not grow unbounded
+	if now.Sub(rl.sweptAt) > rl.window {
+		for key, v := range rl.visitors {
+			if now.Sub(v.windowStart) > rl.window {
+				delete(rl.visitors, key)
+			}
+		}
+		rl.sweptAt = now
+	}
+
+	v, ok := rl.visitors[ip]
+	if !ok || now.Sub(v.windowStart) > rl.window {
+		rl.visitors[ip] = &visitor{count: 1, windowStart: now}
+		return true, 0
+	}
+
+	if v.count >= rl.limit {
+		return false, v.windowStart.Add(rl.window).Sub(now)
+	}
+
+	v.count++
+	return true, 0
+}
@@ -1,70 +1,289 @@
 package api
 
 import (
-	"crypto/rand"
 	"encoding/base64"
+	"errors"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
 	"rest-refs/internal/app/models"
 	"rest-refs/internal/app/repository"
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+var ErrInvalidReferralStatus = errors.New("неизвестный статус реферала")
+var ErrInvalidReferralTreeDepth = errors.New("неправильная глубина дерева рефералов")
+var ErrInvalidReferralList = errors.New("неправильные параметры списка рефералов")
+var ErrCampaignStatsForbidden = errors.New("статистика по кампаниям доступна только самому рефереру и администраторам")
+
+// Page sizes of referral listings
+const (
+	defaultReferralListLimit = 50
+	maxReferralListLimit     = 500
 )
 
 // ReferralService represents service for handling referrals
 type ReferralService struct {
-	repo                repository.ReferralRepo
-	logger              *logrus.Logger
-	referralCodeService *ReferralCodeService
+	repo                 repository.ReferralRepo
+	logger               *logrus.Logger
+	referralCodeService  *ReferralCodeService
+	referralClickService *ReferralClickService
+	rewardService        *RewardService
+	fraudService         *FraudService
+	notificationService  *NotificationService
+	treeMaxDepth         int
 }
 
-// NewReferralService creates new instance of ReferralService with repository, authService
-func NewReferralService(repo repository.ReferralRepo, referralCodeService *ReferralCodeService, logger *logrus.Logger) *ReferralService {
+// NewReferralService creates new instance of ReferralService with repository, referralCodeService,
+// referralClickService, rewardService, fraudService, notificationService and maximum depth of referral tree from config
+func NewReferralService(repo repository.ReferralRepo, referralCodeService *ReferralCodeService,
+	referralClickService *ReferralClickService, rewardService *RewardService, fraudService *FraudService,
+	notificationService *NotificationService, cfg *config.Config, logger *logrus.Logger) *ReferralService {
 	return &ReferralService{
-		repo:                repo,
-		referralCodeService: referralCodeService,
-		logger:              logger,
+		repo:                 repo,
+		referralCodeService:  referralCodeService,
+		referralClickService: referralClickService,
+		rewardService:        rewardService,
+		fraudService:         fraudService,
+		notificationService:  notificationService,
+		treeMaxDepth:         cfg.ReferralTreeMaxDepth,
+		logger:               logger,
 	}
 }
 
-// GetReferralsByReferrerID retrieves all referrals associated with referrer ID
-// It fetches the referral data from repository and formats it into response structure
-func (r *ReferralService) GetReferralsByReferrerID(referrerID int) ([]models.ReferralInfoResponse, error) {
+// GetReferralsByReferrerID retrieves page of referrals of referrer matching filters of opts
+// By default newest referrals come first, 50 per page. Page has cursor of next page unless it is last one
+func (r *ReferralService) GetReferralsByReferrerID(referrerID int,
+	opts models.ReferralListOptions) (models.ReferralPage, error) {
 	r.logger.Debugf("GetReferralsByReferrerID[service]: Получение рефералов для пользователя с id: %d", referrerID)
 
-	referrals, err := r.repo.GetReferralsByReferrerID(referrerID)
+	filter, err := referralPageFilter(opts)
+	if err != nil {
+		r.logger.Errorf("GetReferralsByReferrerID[service]: Неправильные параметры списка рефералов: %s", err)
+		return models.ReferralPage{}, err
+	}
+
+	// One referral more than page size tells whether there is next page
+	limit := filter.Limit
+	filter.Limit++
+
+	referrals, total, err := r.repo.GetReferralsByReferrerID(referrerID, filter)
 	if err != nil {
 		r.logger.Errorf("GetReferralsByReferrerID[service]: Ошибка при получении рефералов для пользователя с id: %d: %s", referrerID, err)
-		return nil, err
+		return models.ReferralPage{}, err
+	}
+
+	page := models.ReferralPage{Referrals: []models.ReferralInfoResponse{}, Total: total}
+	if len(referrals) > limit {
+		referrals = referrals[:limit]
+		last := referrals[limit-1]
+		page.NextCursor = encodeReferralCursor(models.ReferralCursor{CreatedAt: last.CreatedAt, ID: last.ID})
 	}
 
-	var response []models.ReferralInfoResponse
 	for _, referral := range referrals {
-		response = append(response, models.ReferralInfoResponse{
-			ReferralID: referral.ID,
-			ReferrerID: referral.ReferrerID,
-			Email:      referral.Email,
-			CreatedAt:  referral.CreatedAt,
+		page.Referrals = append(page.Referrals, models.ReferralInfoResponse{
+			ReferralID:      referral.ID,
+			ReferrerID:      referral.ReferrerID,
+			Email:           referral.Email,
+			CampaignID:      referral.CampaignID,
+			Status:          referral.Status,
+			StatusChangedAt: referral.StatusChangedAt,
+			CreatedAt:       referral.CreatedAt,
 		})
 	}
 
 	r.logger.Infof("GetReferralsByReferrerID[service]: Рефералы успешно получены для пользователя с id: %d", referrerID)
+	return page, nil
+}
+
+// referralFilter validates filters and sort order of listing options and converts them into repository filter
+func referralFilter(opts models.ReferralListOptions) (models.ReferralFilter, error) {
+	for _, status := range opts.Statuses {
+		if !isReferralStatus(status) {
+			return models.ReferralFilter{}, ErrInvalidReferralStatus
+		}
+	}
+
+	filter := models.ReferralFilter{Statuses: opts.Statuses, CodeID: opts.CodeID}
+
+	switch opts.Sort {
+	case "", models.ReferralSortNewest:
+	case models.ReferralSortOldest:
+		filter.Ascending = true
+	default:
+		return models.ReferralFilter{}, newFieldError(ErrInvalidReferralList, "sort", "неизвестная сортировка %q",
+			opts.Sort)
+	}
+
+	if opts.From != "" {
+		from, err := parseStatsBound(opts.From, time.UTC, false)
+		if err != nil {
+			return models.ReferralFilter{}, newFieldError(ErrInvalidReferralList, "from", "неправильный формат from")
+		}
+		filter.From = &from
+	}
+	if opts.To != "" {
+		to, err := parseStatsBound(opts.To, time.UTC, true)
+		if err != nil {
+			return models.ReferralFilter{}, newFieldError(ErrInvalidReferralList, "to", "неправильный формат to")
+		}
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return models.ReferralFilter{}, newFieldError(ErrInvalidReferralList, "to", "to должен быть позже from")
+	}
+
+	return filter, nil
+}
+
+// referralPageFilter validates listing options including page size and cursor
+func referralPageFilter(opts models.ReferralListOptions) (models.ReferralFilter, error) {
+	filter, err := referralFilter(opts)
+	if err != nil {
+		return models.ReferralFilter{}, err
+	}
+
+	filter.Limit = opts.Limit
+	if filter.Limit == 0 {
+		filter.Limit = defaultReferralListLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxReferralListLimit {
+		return models.ReferralFilter{}, newFieldError(ErrInvalidReferralList, "limit", "limit должен быть от 1 до %d",
+			maxReferralListLimit)
+	}
+
+	if opts.Cursor != "" {
+		cursor, err := decodeReferralCursor(opts.Cursor)
+		if err != nil {
+			return models.ReferralFilter{}, newFieldError(ErrInvalidReferralList, "cursor", "неправильный cursor")
+		}
+		filter.After = &cursor
+	}
+
+	return filter, nil
+}
+
+// GetCampaignStatsByReferrerID aggregates codes and referrals of referrer per campaign
+// Stats are returned to referrer itself, or to administrator userID, otherwise ErrCampaignStatsForbidden is returned
+func (r *ReferralService) GetCampaignStatsByReferrerID(userID, referrerID int) ([]models.CampaignReferralStats,
+	error) {
+	r.logger.Debugf("GetCampaignStatsByReferrerID[service]: Получение статистики по кампаниям"+
+		" для пользователя с id: %d", referrerID)
+
+	if userID != referrerID {
+		// Admin flag is read from database, so revoked rights take effect without reissuing token
+		user, err := r.referralCodeService.authService.GetUserByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if !user.IsAdmin {
+			r.logger.Warnf("GetCampaignStatsByReferrerID[service]: Пользователь с id: %d не является администратором",
+				userID)
+			return nil, ErrCampaignStatsForbidden
+		}
+	}
+
+	stats, err := r.repo.GetCampaignStatsByReferrerID(referrerID)
+	if err != nil {
+		r.logger.Errorf("GetCampaignStatsByReferrerID[service]: Ошибка при получении статистики"+
+			" для пользователя с id: %d: %s", referrerID, err)
+		return nil, err
+	}
+
+	if stats == nil {
+		stats = []models.CampaignReferralStats{}
+	}
+	return stats, nil
+}
+
+// GetReferralTree retrieves downline of user up to depth levels with number of referrals per level
+// Zero depth means maximum depth from config, greater depth is ErrInvalidReferralTreeDepth
+func (r *ReferralService) GetReferralTree(userID, depth int) (models.ReferralTreeResponse, error) {
+	r.logger.Debugf("GetReferralTree[service]: Получение дерева рефералов пользователя с id: %d", userID)
+
+	if depth == 0 {
+		depth = r.treeMaxDepth
+	}
+	if depth < 0 || depth > r.treeMaxDepth {
+		r.logger.Errorf("GetReferralTree[service]: Неправильная глубина дерева: %d", depth)
+		return models.ReferralTreeResponse{}, ErrInvalidReferralTreeDepth
+	}
+
+	nodes, err := r.repo.GetDownline(userID, depth)
+	if err != nil {
+		r.logger.Errorf("GetReferralTree[service]: Ошибка при получении дерева рефералов пользователя с id: %d: %s",
+			userID, err)
+		return models.ReferralTreeResponse{}, err
+	}
+
+	response := models.ReferralTreeResponse{
+		UserID:    userID,
+		Depth:     depth,
+		Total:     len(nodes),
+		Levels:    []models.ReferralTreeLevel{},
+		Referrals: buildReferralTree(nodes, userID),
+	}
+	for _, node := range nodes {
+		if len(response.Levels) < node.Level {
+			response.Levels = append(response.Levels, models.ReferralTreeLevel{Level: node.Level})
+		}
+		response.Levels[node.Level-1].Count++
+	}
+
 	return response, nil
 }
 
 // RegisterWithReferralCode registers new user using referral code
 // It validates referral code, registers user, and creates referral in the repository
-func (r *ReferralService) RegisterWithReferralCode(referralCode string, user models.User) error {
+// Client of request is used by fraud checks of referral
+func (r *ReferralService) RegisterWithReferralCode(referralCode string, user models.User,
+	client models.ClientInfo) error {
 	r.logger.Debugf("RegisterWithReferralCode[service]: Регистрация реферала:"+
 		" %s с реферальным кодом: %s", user.Email, referralCode)
 
+	return r.registerWithReferralCode(referralCode, user, nil, client)
+}
+
+// RegisterWithAttribution registers new user attributing sign-up to referral code of earlier click
+// If click is unknown, outside of attribution window or its code is no longer active,
+// user is registered without referral, so sign-up never fails because of attribution
+func (r *ReferralService) RegisterWithAttribution(clickToken string, user models.User,
+	client models.ClientInfo) error {
+	r.logger.Debugf("RegisterWithAttribution[service]: Регистрация пользователя: %s по переходу", user.Email)
+
+	click, err := r.referralClickService.GetAttributedClick(clickToken)
+	if err != nil {
+		r.logger.Infof("RegisterWithAttribution[service]: Переход не атрибутирован: %s", err)
+		return r.referralCodeService.authService.RegisterUser(user)
+	}
+
+	err = r.registerWithReferralCode(click.Code, user, &click.ID, client)
+	if errors.Is(err, postgresql.ErrReferralCodeNotActive) || errors.Is(err, postgresql.ErrReferralCodeNotFound) {
+		r.logger.Infof("RegisterWithAttribution[service]: Реферальный код %s перехода неактивен", click.Code)
+		return r.referralCodeService.authService.RegisterUser(user)
+	}
+
+	return err
+}
+
+// registerWithReferralCode validates referral code, registers user together with referral linked to click,
+// then scores referral by fraud checks. Referrer is notified and rewards are credited only for referral
+// that passed checks, flagged referral waits for manual review
+// User is created in the same transaction as referral, so sign-up rejected because code was used up meanwhile
+// leaves no user behind and may be retried
+func (r *ReferralService) registerWithReferralCode(referralCode string, user models.User, clickID *int,
+	client models.ClientInfo) error {
 	// Attempt to get active referral code
 	codeID, err := r.referralCodeService.GetIDByReferralCode(referralCode)
 	if err != nil {
 		return err
 	}
 
-	// Attempt to create user using service
-	err = r.referralCodeService.authService.RegisterUser(user)
+	// Check email and hash password, user itself is saved with referral
+	user, err = r.referralCodeService.authService.prepareUser(user)
 	if err != nil {
 		return err
 	}
@@ -76,32 +295,119 @@ func (r *ReferralService) RegisterWithReferralCode(referralCode string, user mod
 	}
 
 	referral := models.Referral{
-		Email:          user.Email,
-		ReferralCodeID: codeID,
-		ReferrerID:     referrerID,
+		Email:           user.Email,
+		ReferralCodeID:  codeID,
+		ReferrerID:      referrerID,
+		ReferralClickID: clickID,
 	}
+	referral.IPHash, referral.DeviceHash = r.fraudService.HashClient(client)
 
 	// Save new referral in repository
-	err = r.repo.Create(referral)
+	referral, err = r.repo.Create(referral, user)
 	if err != nil {
 		r.logger.Errorf("RegisterWithReferralCode[service]: Ошибка при создании реферала в базе: %s", err)
 		return err
 	}
 
+	// Referral that could not be scored is held for review as well, so failed checks never let it through
+	assessment, err := r.fraudService.Assess(referral)
+	if err != nil {
+		r.logger.Errorf("RegisterWithReferralCode[service]: Ошибка проверки реферала с id: %d: %s", referral.ID, err)
+		if assessment, err = r.fraudService.HoldForReview(referral, err); err != nil {
+			// User is already registered, so sign-up succeeds, but unscored referral is neither announced nor credited
+			r.logger.Errorf("RegisterWithReferralCode[service]: Реферал с id: %d остался без оценки риска,"+
+				" уведомление и вознаграждения не отправлены", referral.ID)
+			return nil
+		}
+	}
+
+	if assessment.Review {
+		r.logger.Infof("RegisterWithReferralCode[service]: Реферал с id: %d на проверке, уведомление и"+
+			" вознаграждения отложены", referral.ID)
+		return nil
+	}
+
+	r.notificationService.NotifyReferralCreated(referral, r.referralCodeService.displayReferralCode(referralCode))
+
+	// User is already registered, so failed reward is only logged, it is credited again with next referral event
+	if err = r.rewardService.OnReferralCreated(referral.ID); err != nil {
+		r.logger.Errorf("RegisterWithReferralCode[service]: Ошибка начисления вознаграждений за реферала с id: %d: %s",
+			referral.ID, err)
+	}
+
 	r.logger.Infof("RegisterUser[service]: Реферал с email: %s успешно зарегистрирован", user.Email)
 	return nil
 }
 
-// generateReferralCode generates unique referral code by creating random byte array and encoding it
-// The referral code is base64-encoded and converted to uppercase for consistency
-func generateReferralCode() (string, error) {
-	randomBytes := make([]byte, referralCodeLength)
+// buildReferralTree nests flat downline ordered by level under referrals of referred users starting from rootID
+func buildReferralTree(nodes []models.ReferralTreeNode, rootID int) []models.ReferralTreeNode {
+	byReferrer := make(map[int][]models.ReferralTreeNode)
+	for _, node := range nodes {
+		byReferrer[node.ReferrerID] = append(byReferrer[node.ReferrerID], node)
+	}
+
+	var nest func(referrerID, level int) []models.ReferralTreeNode
+	nest = func(referrerID, level int) []models.ReferralTreeNode {
+		children := []models.ReferralTreeNode{}
+		for _, node := range byReferrer[referrerID] {
+			if node.Level != level {
+				continue
+			}
+			node.Children = []models.ReferralTreeNode{}
+			if node.UserID != nil {
+				node.Children = nest(*node.UserID, level+1)
+			}
+			children = append(children, node)
+		}
+		return children
+	}
+
+	return nest(rootID, 1)
+}
+
+// isReferralStatus reports whether status is one of known referral statuses
+func isReferralStatus(status string) bool {
+	switch status {
+	case models.ReferralStatusPending, models.ReferralStatusQualified,
+		models.ReferralStatusRejected, models.ReferralStatusReversed:
+		return true
+	default:
+		return false
+	}
+}
+
+// encodeReferralCursor encodes position in referral listing into opaque URL-safe cursor
+func encodeReferralCursor(cursor models.ReferralCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + ":" + strconv.Itoa(cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
 
-	_, err := rand.Read(randomBytes)
+// decodeReferralCursor decodes cursor made by encodeReferralCursor
+// Only cursor encodeReferralCursor could have made is accepted, so edited cursor, e.g. with negative id,
+// leading zeros or trailing data, is rejected with ErrInvalidReferralList. Cursor is not secret, it only
+// positions listing whose filters are checked on their own
+func decodeReferralCursor(value string) (models.ReferralCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
 	if err != nil {
-		return "", err
+		return models.ReferralCursor{}, err
+	}
+
+	nanos, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return models.ReferralCursor{}, ErrInvalidReferralList
 	}
-	code := base64.RawURLEncoding.EncodeToString(randomBytes)
 
-	return strings.ToUpper(code[:referralCodeLength]), nil
+	createdAt, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return models.ReferralCursor{}, err
+	}
+	cursor := models.ReferralCursor{CreatedAt: time.Unix(0, createdAt).UTC()}
+	if cursor.ID, err = strconv.Atoi(id); err != nil {
+		return models.ReferralCursor{}, err
+	}
+
+	if createdAt < 0 || cursor.ID <= 0 || encodeReferralCursor(cursor) != value {
+		return models.ReferralCursor{}, ErrInvalidReferralList
+	}
+	return cursor, nil
 }
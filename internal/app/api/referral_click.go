@@ -0,0 +1,127 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+var ErrAttributionExpired = errors.New("окно атрибуции перехода истекло")
+
+// clickTokenLength is number of random bytes in attribution token stored in cookie
+const clickTokenLength = 24
+
+// maxUTMLength is maximum length of stored UTM parameter, longer ones are truncated
+const maxUTMLength = 255
+
+// ReferralClickService represents service for tracking clicks on referral share links
+type ReferralClickService struct {
+	repo                repository.ReferralClickRepo
+	logger              *logrus.Logger
+	referralCodeService *ReferralCodeService
+	ipHashSalt          string
+	attributionWindow   time.Duration
+}
+
+// NewReferralClickService creates new instance of ReferralClickService with repository, referralCodeService and config
+func NewReferralClickService(repo repository.ReferralClickRepo, referralCodeService *ReferralCodeService,
+	cfg *config.Config, logger *logrus.Logger) *ReferralClickService {
+	return &ReferralClickService{
+		repo:                repo,
+		referralCodeService: referralCodeService,
+		ipHashSalt:          cfg.IPHashSalt,
+		attributionWindow:   cfg.AttributionWindow,
+		logger:              logger,
+	}
+}
+
+// TrackClick records click on share link of referral code
+// Client IP is stored only as salted hash, UTM parameters are truncated to maxUTMLength.
+// Returned click contains attribution token, which is empty if code is not active, so there is nothing to attribute
+func (r *ReferralClickService) TrackClick(code, ip string, click models.ReferralClick) (models.ReferralClick, error) {
+	r.logger.Debugf("TrackClick[service]: Переход по реферальной ссылке с кодом: %s", code)
+
+	referralCode, err := findReferralCode(r.referralCodeService.generator, code,
+		r.referralCodeService.repo.GetByReferralCode)
+	if err != nil {
+		r.logger.Warnf("TrackClick[service]: Ошибка получения реферального кода %s: %s", code, err)
+		return models.ReferralClick{}, err
+	}
+
+	token, err := generateClickToken()
+	if err != nil {
+		return models.ReferralClick{}, err
+	}
+
+	click.Token = token
+	click.ReferralCodeID = referralCode.ID
+	click.IPHash = r.HashIP(ip)
+	click.UTMSource = truncate(click.UTMSource, maxUTMLength)
+	click.UTMMedium = truncate(click.UTMMedium, maxUTMLength)
+	click.UTMCampaign = truncate(click.UTMCampaign, maxUTMLength)
+	click.UTMTerm = truncate(click.UTMTerm, maxUTMLength)
+	click.UTMContent = truncate(click.UTMContent, maxUTMLength)
+
+	created, err := r.repo.Create(click)
+	if err != nil {
+		r.logger.Errorf("TrackClick[service]: Ошибка сохранения перехода по реферальному коду %s: %s", code, err)
+		return models.ReferralClick{}, err
+	}
+	created.Code = r.referralCodeService.generator.Format(referralCode.Code)
+
+	if referralCodeStatus(referralCode, time.Now()) != models.ReferralCodeStatusActive {
+		created.Token = ""
+	}
+
+	r.logger.Infof("TrackClick[service]: Переход по реферальному коду %s сохранен", referralCode.Code)
+	return created, nil
+}
+
+// GetAttributedClick returns click by attribution token if it happened within attribution window
+func (r *ReferralClickService) GetAttributedClick(token string) (models.ReferralClick, error) {
+	r.logger.Debugf("GetAttributedClick[service]: Получение перехода для атрибуции")
+
+	click, err := r.repo.GetByToken(token)
+	if err != nil {
+		return models.ReferralClick{}, err
+	}
+
+	if time.Since(click.ClickedAt) > r.attributionWindow {
+		r.logger.Infof("GetAttributedClick[service]: Окно атрибуции перехода с id: %d истекло", click.ID)
+		return models.ReferralClick{}, ErrAttributionExpired
+	}
+
+	return click, nil
+}
+
+// HashIP returns hex-encoded HMAC-SHA256 of IP address with configured salt
+func (r *ReferralClickService) HashIP(ip string) string {
+	return hashWithSalt(r.ipHashSalt, ip)
+}
+
+// hashWithSalt returns hex-encoded HMAC-SHA256 of value with salt
+func hashWithSalt(salt, value string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateClickToken generates random hex-encoded attribution token
+func generateClickToken() (string, error) {
+	randomBytes := make([]byte, clickTokenLength)
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(randomBytes), nil
+}
@@ -2,29 +2,47 @@ package api
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
 	"rest-refs/internal/app/models"
 	"rest-refs/internal/app/repository"
+	"rest-refs/internal/app/repository/postgresql"
 )
 
 var ErrReferralCodeAlreadyExists = errors.New("активный реферальный код уже существует")
-
-const referralCodeLength = 8
+var ErrInvalidGracePeriod = errors.New("неправильный льготный период замены реферального кода")
 
 // ReferralCodeService represents service for handling referral codes
 type ReferralCodeService struct {
-	repo        repository.ReferralCodeRepo
-	logger      *logrus.Logger
-	authService *AuthService
+	repo            repository.ReferralCodeRepo
+	logger          *logrus.Logger
+	authService     *AuthService
+	campaignService *CampaignService
+	generator       CodeGenerator
+	defaultTimeZone *time.Location
+	maxLifetime     time.Duration
+	shareBaseURL    string
+	gracePeriod     time.Duration
 }
 
-// NewReferralCodeService creates new instance of ReferralCodeService with repository, authService
-func NewReferralCodeService(repo repository.ReferralCodeRepo, authService *AuthService, logger *logrus.Logger) *ReferralCodeService {
+// NewReferralCodeService creates new instance of ReferralCodeService with repository, authService,
+// campaignService, code generator and config
+func NewReferralCodeService(repo repository.ReferralCodeRepo, authService *AuthService,
+	campaignService *CampaignService, generator CodeGenerator, cfg *config.Config,
+	logger *logrus.Logger) *ReferralCodeService {
 	return &ReferralCodeService{
-		repo:        repo,
-		authService: authService,
-		logger:      logger,
+		repo:            repo,
+		authService:     authService,
+		campaignService: campaignService,
+		generator:       generator,
+		defaultTimeZone: cfg.DefaultTimeZone,
+		maxLifetime:     cfg.ReferralCodeMaxLifetime,
+		shareBaseURL:    strings.TrimRight(cfg.ShareBaseURL, "/"),
+		gracePeriod:     cfg.RotationGracePeriod,
+		logger:          logger,
 	}
 }
 
@@ -33,7 +51,7 @@ func (r *ReferralCodeService) CreateReferralCode(referralCode models.ReferralCod
 	r.logger.Debugf("Create[service]: Создание реферального кода пользователя c id: %d", referralCode.ReferrerID)
 
 	// Generate referral code
-	code, err := generateReferralCode()
+	code, err := r.generator.Generate()
 	if err != nil {
 		return models.ReferralCode{}, err
 	}
@@ -56,6 +74,8 @@ func (r *ReferralCodeService) CreateReferralCode(referralCode models.ReferralCod
 		return models.ReferralCode{}, err
 	}
 
+	createdCode = r.formatReferralCode(createdCode)
+
 	r.logger.Infof("Create[service]: Реферальный код создан для пользователя с id: %d",
 		referralCode.ReferrerID)
 	return createdCode, nil
@@ -106,17 +126,220 @@ func (r *ReferralCodeService) GetReferralCodeByReferrerEmail(email string) (mode
 	}
 
 	r.logger.Infof("GetReferralCodeByReferrerEmail[service]: Реферальный код успешно получен для email: %s", email)
-	return code, nil
+	return r.formatReferralCode(code), nil
 }
 
 // GetIDByReferralCode retrieves ID of a referral code from repository
+// User input is normalized first, so malformed codes are rejected without database lookup
 func (r *ReferralCodeService) GetIDByReferralCode(code string) (int, error) {
 	r.logger.Debugf("GetIDByReferralCode[service]: Получение id реферального кода: %s", code)
-	return r.repo.GetIDByReferralCode(code)
+
+	id, err := findReferralCode(r.generator, code, r.repo.GetIDByReferralCode)
+	if errors.Is(err, ErrReferralCodeMalformed) {
+		r.logger.Warnf("GetIDByReferralCode[service]: Неверный формат реферального кода: %s", code)
+	}
+	return id, err
 }
 
 // GetReferrerIDByReferralCode retrieves referrer ID associated with specific referral code
 func (r *ReferralCodeService) GetReferrerIDByReferralCode(code string) (int, error) {
 	r.logger.Debugf("GetReferrerIDByReferralCode[service]: Получение id реферера по реферальному коду: %s", code)
-	return r.repo.GetReferrerIDByReferralCode(code)
+
+	referrerID, err := findReferralCode(r.generator, code, r.repo.GetReferrerIDByReferralCode)
+	if errors.Is(err, ErrReferralCodeMalformed) {
+		r.logger.Warnf("GetReferrerIDByReferralCode[service]: Неверный формат реферального кода: %s", code)
+	}
+	return referrerID, err
+}
+
+// GetReferralCodeStatus reports whether referral code exists and can be used for registration
+// It never returns ErrReferralCodeNotFound or ErrReferralCodeMalformed, these are reported as statuses
+func (r *ReferralCodeService) GetReferralCodeStatus(code string) (models.ReferralCodeStatusResponse, error) {
+	r.logger.Debugf("GetReferralCodeStatus[service]: Проверка статуса реферального кода: %s", code)
+
+	referralCode, err := findReferralCode(r.generator, code, r.repo.GetByReferralCode)
+	if err != nil {
+		if errors.Is(err, ErrReferralCodeMalformed) {
+			return models.ReferralCodeStatusResponse{
+				Code:   code,
+				Status: models.ReferralCodeStatusMalformed,
+			}, nil
+		}
+		if errors.Is(err, postgresql.ErrReferralCodeNotFound) {
+			return models.ReferralCodeStatusResponse{
+				Code:   r.displayReferralCode(code),
+				Status: models.ReferralCodeStatusNotFound,
+			}, nil
+		}
+
+		r.logger.Errorf("GetReferralCodeStatus[service]: Ошибка при получении реферального кода %s: %s", code, err)
+		return models.ReferralCodeStatusResponse{}, err
+	}
+
+	status := referralCodeStatus(referralCode, time.Now())
+	response := models.ReferralCodeStatusResponse{
+		Code:       r.generator.Format(referralCode.Code),
+		Exists:     true,
+		Active:     status == models.ReferralCodeStatusActive,
+		Status:     status,
+		Expiration: &referralCode.Expiration,
+	}
+
+	// Referrer's display name is optional, so failure to get it does not fail the check
+	referrer, err := r.authService.GetUserByID(referralCode.ReferrerID)
+	if err != nil {
+		r.logger.Warnf("GetReferralCodeStatus[service]: Не удалось получить реферера с id: %d: %s",
+			referralCode.ReferrerID, err)
+	} else {
+		response.ReferrerName = referrer.DisplayName
+	}
+
+	r.logger.Infof("GetReferralCodeStatus[service]: Статус реферального кода %s: %s", referralCode.Code, status)
+	return response, nil
+}
+
+// PauseReferralCode pauses active referral code of referrer, paused code can not be used for registration
+func (r *ReferralCodeService) PauseReferralCode(referrerID int) error {
+	r.logger.Debugf("PauseReferralCode[service]: Приостановка реферального кода пользователя с id: %d", referrerID)
+	return r.setPaused(referrerID, true)
+}
+
+// ResumeReferralCode resumes previously paused referral code of referrer
+func (r *ReferralCodeService) ResumeReferralCode(referrerID int) error {
+	r.logger.Debugf("ResumeReferralCode[service]: Возобновление реферального кода пользователя с id: %d", referrerID)
+	return r.setPaused(referrerID, false)
+}
+
+// setPaused changes pause state of active referral code of referrer
+func (r *ReferralCodeService) setPaused(referrerID int, paused bool) error {
+	referralCode, err := r.repo.GetActiveReferralCodeByUserID(referrerID)
+	if err != nil {
+		r.logger.Errorf("setPaused[service]: Ошибка при получении активного реферального кода"+
+			" для пользователя с id: %d: %s", referrerID, err)
+		return err
+	}
+
+	err = r.repo.SetPausedByID(referralCode.ID, paused)
+	if err != nil {
+		r.logger.Errorf("setPaused[service]: Ошибка при изменении паузы реферального кода"+
+			" для пользователя с id: %d: %s", referrerID, err)
+		return err
+	}
+
+	r.logger.Infof("setPaused[service]: Пауза реферального кода пользователя с id: %d изменена на %t", referrerID, paused)
+	return nil
+}
+
+// RotateReferralCode replaces active referral code of referrer with newly generated one
+// New code inherits expiration, usage limit and campaign of old code and belongs to the same referral link.
+// Old code stays valid for grace period (default from config, zero revokes it immediately)
+func (r *ReferralCodeService) RotateReferralCode(referrerID int,
+	input models.ReferralCodeRotateRequest) (models.ReferralCode, error) {
+	r.logger.Debugf("RotateReferralCode[service]: Замена реферального кода пользователя с id: %d", referrerID)
+
+	gracePeriod := r.gracePeriod
+	if input.GracePeriod != nil {
+		parsed, err := time.ParseDuration(strings.TrimSpace(*input.GracePeriod))
+		if err != nil || parsed < 0 {
+			r.logger.Errorf("RotateReferralCode[service]: Неправильный льготный период: %s", *input.GracePeriod)
+			return models.ReferralCode{}, ErrInvalidGracePeriod
+		}
+		gracePeriod = parsed
+	}
+
+	referralCode, err := r.repo.GetActiveReferralCodeByUserID(referrerID)
+	if err != nil {
+		r.logger.Errorf("RotateReferralCode[service]: Ошибка при получении активного реферального кода"+
+			" для пользователя с id: %d: %s", referrerID, err)
+		return models.ReferralCode{}, err
+	}
+
+	code, err := r.generator.Generate()
+	if err != nil {
+		return models.ReferralCode{}, err
+	}
+
+	// Uses are shared by codes of one link and pause is kept, so rotation does not reset limits of the code
+	replacement := models.ReferralCode{
+		Code:       code,
+		Expiration: referralCode.Expiration,
+		ReferrerID: referrerID,
+		PausedAt:   referralCode.PausedAt,
+		MaxUses:    referralCode.MaxUses,
+		UsesCount:  referralCode.UsesCount,
+		CampaignID: referralCode.CampaignID,
+	}
+
+	rotated, err := r.repo.Rotate(referralCode.ID, replacement, time.Now().Add(gracePeriod))
+	if err != nil {
+		r.logger.Errorf("RotateReferralCode[service]: Ошибка замены реферального кода"+
+			" для пользователя с id: %d: %s", referrerID, err)
+		return models.ReferralCode{}, err
+	}
+
+	rotated = r.formatReferralCode(rotated)
+
+	r.logger.Infof("RotateReferralCode[service]: Реферальный код пользователя с id: %d заменен,"+
+		" старый код действует еще %s", referrerID, gracePeriod)
+	return rotated, nil
+}
+
+// ShareURL returns share link of referral code
+func (r *ReferralCodeService) ShareURL(code string) string {
+	return r.shareBaseURL + "/r/" + r.generator.Format(code)
+}
+
+// formatReferralCode returns referral code with code in form shown to users
+func (r *ReferralCodeService) formatReferralCode(referralCode models.ReferralCode) models.ReferralCode {
+	referralCode.Code = r.generator.Format(referralCode.Code)
+	return referralCode
+}
+
+// displayReferralCode returns code typed by user in form shown to users
+func (r *ReferralCodeService) displayReferralCode(code string) string {
+	if normalized, err := r.generator.Normalize(code); err == nil {
+		return r.generator.Format(normalized)
+	}
+	if legacy, ok := legacyReferralCode(code); ok {
+		return legacy
+	}
+	return code
+}
+
+// findReferralCode looks referral code typed by user up with lookup
+// Input is normalized first, so malformed codes are rejected without database lookup. Codes issued before
+// check characters are not valid codes of generator, so they are looked up exactly as typed
+func findReferralCode[T any](generator CodeGenerator, code string, lookup func(code string) (T, error)) (T, error) {
+	normalized, err := generator.Normalize(code)
+	if err == nil {
+		var value T
+		value, err = lookup(normalized)
+		if !errors.Is(err, postgresql.ErrReferralCodeNotFound) {
+			return value, err
+		}
+	}
+
+	legacy, ok := legacyReferralCode(code)
+	if !ok || legacy == normalized {
+		var zero T
+		return zero, err
+	}
+	return lookup(legacy)
+}
+
+// referralCodeStatus calculates status of referral code at given moment
+// Expiration takes precedence over pause, pause takes precedence over exhausted usage limit
+// Code of campaign that reached its participants limit is exhausted as well
+func referralCodeStatus(referralCode models.ReferralCode, now time.Time) string {
+	switch {
+	case !referralCode.Expiration.After(now):
+		return models.ReferralCodeStatusExpired
+	case referralCode.PausedAt != nil:
+		return models.ReferralCodeStatusPaused
+	case referralCode.MaxUses != nil && referralCode.UsesCount >= *referralCode.MaxUses,
+		referralCode.CampaignFull:
+		return models.ReferralCodeStatusExhausted
+	default:
+		return models.ReferralCodeStatusActive
+	}
 }
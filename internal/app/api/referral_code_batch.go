@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+var ErrInvalidBatchSize = errors.New("неправильный размер пакета реферальных кодов")
+
+// maxReferralCodeBatchSize limits number of codes generated in one batch
+const maxReferralCodeBatchSize = 100000
+
+// defaultBatchMaxUses makes batch codes single-use unless request says otherwise
+const defaultBatchMaxUses = 1
+
+// ReferralCodeBatchService represents service for bulk generation of referral codes for campaigns
+type ReferralCodeBatchService struct {
+	repo                repository.ReferralCodeBatchRepo
+	logger              *logrus.Logger
+	referralCodeService *ReferralCodeService
+}
+
+// NewReferralCodeBatchService creates new instance of ReferralCodeBatchService with repository and referralCodeService
+func NewReferralCodeBatchService(repo repository.ReferralCodeBatchRepo, referralCodeService *ReferralCodeService,
+	logger *logrus.Logger) *ReferralCodeBatchService {
+	return &ReferralCodeBatchService{
+		repo:                repo,
+		referralCodeService: referralCodeService,
+		logger:              logger,
+	}
+}
+
+// CreateReferralCodeBatch generates input.Count unique codes owned by referrer in single batch
+// createdBy is id of admin who requested the batch, nil when batch is created from command line
+func (r *ReferralCodeBatchService) CreateReferralCodeBatch(input models.ReferralCodeBatchCreateRequest,
+	createdBy *int) (models.ReferralCodeBatch, error) {
+	r.logger.Debugf("CreateReferralCodeBatch[service]: Создание пакета из %d реферальных кодов"+
+		" для пользователя с id: %d", input.Count, input.ReferrerID)
+
+	if input.Count <= 0 || input.Count > maxReferralCodeBatchSize {
+		r.logger.Errorf("CreateReferralCodeBatch[service]: Неправильный размер пакета: %d", input.Count)
+		return models.ReferralCodeBatch{}, ErrInvalidBatchSize
+	}
+
+	// Check that owner of the batch exists
+	_, err := r.referralCodeService.authService.GetUserByID(input.ReferrerID)
+	if err != nil {
+		r.logger.Errorf("CreateReferralCodeBatch[service]: Ошибка при получении пользователя"+
+			" с id: %d: %s", input.ReferrerID, err)
+		return models.ReferralCodeBatch{}, err
+	}
+
+	expiration, err := r.referralCodeService.ResolveExpiration(input.ReferralCodeCreateRequest)
+	if err != nil {
+		return models.ReferralCodeBatch{}, err
+	}
+
+	maxUses := input.MaxUses
+	if maxUses == nil {
+		single := defaultBatchMaxUses
+		maxUses = &single
+	}
+
+	batch := models.ReferralCodeBatch{
+		ReferrerID: input.ReferrerID,
+		CreatedBy:  createdBy,
+		CampaignID: input.CampaignID,
+		Size:       input.Count,
+		MaxUses:    maxUses,
+		Expiration: expiration,
+	}
+
+	created, err := r.repo.Create(batch, r.referralCodeService.generator.Generate)
+	if err != nil {
+		r.logger.Errorf("CreateReferralCodeBatch[service]: Ошибка создания пакета реферальных кодов: %s", err)
+		return models.ReferralCodeBatch{}, err
+	}
+
+	r.logger.Infof("CreateReferralCodeBatch[service]: Пакет с id: %d из %d реферальных кодов создан",
+		created.ID, created.Size)
+	return created, nil
+}
+
+// WriteReferralCodeBatchCSV writes codes of batch with their share links as CSV
+func (r *ReferralCodeBatchService) WriteReferralCodeBatchCSV(batchID int, w io.Writer) error {
+	r.logger.Debugf("WriteReferralCodeBatchCSV[service]: Выгрузка пакета реферальных кодов с id: %d", batchID)
+
+	// Check that batch exists, so empty batch is not confused with missing one
+	_, err := r.repo.GetByID(batchID)
+	if err != nil {
+		return err
+	}
+
+	codes, err := r.repo.GetCodesByBatchID(batchID)
+	if err != nil {
+		r.logger.Errorf("WriteReferralCodeBatchCSV[service]: Ошибка при получении реферальных кодов"+
+			" пакета с id: %d: %s", batchID, err)
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err = writer.Write([]string{"code", "share_url", "expires_at", "max_uses", "uses_count"}); err != nil {
+		return err
+	}
+
+	for _, code := range codes {
+		maxUses := ""
+		if code.MaxUses != nil {
+			maxUses = strconv.Itoa(*code.MaxUses)
+		}
+
+		err = writer.Write([]string{
+			r.referralCodeService.generator.Format(code.Code),
+			r.referralCodeService.ShareURL(code.Code),
+			code.Expiration.Format(time.RFC3339),
+			maxUses,
+			strconv.Itoa(code.UsesCount),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		r.logger.Errorf("WriteReferralCodeBatchCSV[service]: Ошибка записи CSV: %s", err)
+		return err
+	}
+
+	r.logger.Infof("WriteReferralCodeBatchCSV[service]: Выгружено %d реферальных кодов пакета с id: %d",
+		len(codes), batchID)
+	return nil
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+var ErrInvalidReferralEvent = errors.New("неправильный формат события реферала")
+
+const (
+	maxEventIDLength   = 255
+	maxEventTypeLength = 64
+)
+
+// ReferralEventService represents service for ingesting external events that move referrals through lifecycle
+type ReferralEventService struct {
+	repo                repository.ReferralEventRepo
+	logger              *logrus.Logger
+	rewardService       *RewardService
+	qualificationEvents []string
+	rejectionEvents     map[string]bool
+	reversalEvents      map[string]bool
+}
+
+// NewReferralEventService creates new instance of ReferralEventService with repository, rewardService
+// and qualification rules from config
+func NewReferralEventService(repo repository.ReferralEventRepo, rewardService *RewardService, cfg *config.Config,
+	logger *logrus.Logger) *ReferralEventService {
+	return &ReferralEventService{
+		repo:                repo,
+		rewardService:       rewardService,
+		qualificationEvents: cfg.QualificationEvents,
+		rejectionEvents:     eventSet(cfg.RejectionEvents),
+		reversalEvents:      eventSet(cfg.ReversalEvents),
+		logger:              logger,
+	}
+}
+
+// IngestReferralEvent validates external event, stores it and applies qualification rules to its referral
+// Repeated event with the same event_id changes nothing and is reported as duplicate
+// Rewards of referral are brought in line with its status after every event, including duplicate one,
+// so event retried after failed reward posting completes it
+func (r *ReferralEventService) IngestReferralEvent(input models.ReferralEventRequest) (models.ReferralEventResponse, error) {
+	r.logger.Debugf("IngestReferralEvent[service]: Получено событие %s типа %s", input.EventID, input.Type)
+
+	event := models.ReferralEvent{
+		EventID:    strings.TrimSpace(input.EventID),
+		Type:       strings.TrimSpace(input.Type),
+		Payload:    input.Payload,
+		OccurredAt: time.Now(),
+	}
+	email := strings.TrimSpace(input.Email)
+
+	if event.EventID == "" || len(event.EventID) > maxEventIDLength {
+		r.logger.Errorf("IngestReferralEvent[service]: Неправильный event_id: %q", input.EventID)
+		return models.ReferralEventResponse{}, ErrInvalidReferralEvent
+	}
+
+	if event.Type == "" || len(event.Type) > maxEventTypeLength {
+		r.logger.Errorf("IngestReferralEvent[service]: Неправильный тип события: %q", input.Type)
+		return models.ReferralEventResponse{}, ErrInvalidReferralEvent
+	}
+
+	if input.ReferralID != nil {
+		event.ReferralID = *input.ReferralID
+	}
+	if event.ReferralID <= 0 && email == "" {
+		r.logger.Errorf("IngestReferralEvent[service]: Не указаны referral_id и email реферала")
+		return models.ReferralEventResponse{}, ErrInvalidReferralEvent
+	}
+
+	if input.OccurredAt != nil {
+		event.OccurredAt = *input.OccurredAt
+	}
+
+	if len(event.Payload) == 0 {
+		event.Payload = json.RawMessage("{}")
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload == nil {
+		r.logger.Errorf("IngestReferralEvent[service]: Данные события %s должны быть JSON-объектом", event.EventID)
+		return models.ReferralEventResponse{}, ErrInvalidReferralEvent
+	}
+
+	result, err := r.repo.Record(event, email, r.nextStatus)
+	if err != nil {
+		r.logger.Errorf("IngestReferralEvent[service]: Ошибка обработки события %s: %s", event.EventID, err)
+		return models.ReferralEventResponse{}, err
+	}
+
+	if err = r.rewardService.OnReferralStatus(result.ReferralID, result.Status); err != nil {
+		r.logger.Errorf("IngestReferralEvent[service]: Ошибка обработки вознаграждений реферала с id: %d: %s",
+			result.ReferralID, err)
+		return models.ReferralEventResponse{}, err
+	}
+
+	r.logger.Infof("IngestReferralEvent[service]: Событие %s обработано, статус реферала с id: %d: %s",
+		event.EventID, result.ReferralID, result.Status)
+	return result, nil
+}
+
+// nextStatus applies qualification rules to referral after event of eventType is received:
+//   - pending referral is rejected by any rejection event
+//   - pending referral is qualified once all qualification events are received, in any order
+//   - qualified referral is reversed by any reversal event
+//
+// Rejected and reversed referrals are final
+func (r *ReferralEventService) nextStatus(status, eventType string, received []string) string {
+	switch status {
+	case models.ReferralStatusPending:
+		if r.rejectionEvents[eventType] {
+			return models.ReferralStatusRejected
+		}
+
+		receivedSet := eventSet(received)
+		for _, required := range r.qualificationEvents {
+			if !receivedSet[required] {
+				return status
+			}
+		}
+		return models.ReferralStatusQualified
+
+	case models.ReferralStatusQualified:
+		if r.reversalEvents[eventType] {
+			return models.ReferralStatusReversed
+		}
+	}
+
+	return status
+}
+
+// eventSet converts list of event types into set
+func eventSet(types []string) map[string]bool {
+	set := make(map[string]bool, len(types))
+	for _, eventType := range types {
+		set[eventType] = true
+	}
+	return set
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+var ErrInvalidReferralExportFormat = errors.New("неизвестный формат выгрузки рефералов")
+
+// referralExportColumns are header of CSV and XLSX exports, in order of referralExportValues
+var referralExportColumns = []string{"referral_id", "referrer_id", "email", "referral_code_id", "code",
+	"campaign_id", "status", "status_changed_at", "created_at"}
+
+// referralExportNumericColumns are indexes of referralExportColumns written as numbers into XLSX
+var referralExportNumericColumns = map[int]bool{0: true, 1: true, 3: true, 5: true}
+
+// referralExportWriter writes referrals in one of export formats
+type referralExportWriter interface {
+	Write(row models.ReferralExportRow) error
+	Close() error
+}
+
+// ReferralExportService represents service for exporting referrals into spreadsheets
+type ReferralExportService struct {
+	repo      repository.ReferralRepo
+	generator CodeGenerator
+	logger    *logrus.Logger
+}
+
+// NewReferralExportService creates new instance of ReferralExportService with repository and code generator
+func NewReferralExportService(repo repository.ReferralRepo, generator CodeGenerator,
+	logger *logrus.Logger) *ReferralExportService {
+	return &ReferralExportService{
+		repo:      repo,
+		generator: generator,
+		logger:    logger,
+	}
+}
+
+// ExportReferrals writes referrals matching filters of opts in csv, xlsx or jsonl format into w
+// Nil referrerID exports referrals of all referrers. Pagination of opts is ignored, all matching referrals
+// are exported. Format and filters are validated before anything is written
+func (r *ReferralExportService) ExportReferrals(referrerID *int, format string, opts models.ReferralListOptions,
+	w io.Writer) error {
+	r.logger.Debugf("ExportReferrals[service]: Выгрузка рефералов в формате %s", format)
+
+	filter, err := referralFilter(opts)
+	if err != nil {
+		r.logger.Errorf("ExportReferrals[service]: Неправильные параметры выгрузки: %s", err)
+		return err
+	}
+
+	var writer referralExportWriter
+	switch format {
+	case models.ReferralExportCSV:
+		writer, err = newCSVReferralExportWriter(w)
+	case models.ReferralExportXLSX:
+		writer, err = newXLSXReferralExportWriter(w)
+	case models.ReferralExportJSONL:
+		writer = newJSONLReferralExportWriter(w)
+	default:
+		r.logger.Errorf("ExportReferrals[service]: Неизвестный формат выгрузки: %s", format)
+		return ErrInvalidReferralExportFormat
+	}
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	err = r.repo.StreamReferrals(referrerID, filter, func(row models.ReferralExportRow) error {
+		count++
+		if row.Code != nil {
+			code := r.generator.Format(*row.Code)
+			row.Code = &code
+		}
+		return writer.Write(row)
+	})
+	if err != nil {
+		r.logger.Errorf("ExportReferrals[service]: Ошибка выгрузки рефералов: %s", err)
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		r.logger.Errorf("ExportReferrals[service]: Ошибка завершения выгрузки: %s", err)
+		return err
+	}
+
+	r.logger.Infof("ExportReferrals[service]: Выгружено %d рефералов в формате %s", count, format)
+	return nil
+}
+
+// referralExportValues formats row as cells in order of referralExportColumns, nulls are empty cells
+func referralExportValues(row models.ReferralExportRow) []string {
+	optionalInt := func(value *int) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.Itoa(*value)
+	}
+
+	code, statusChangedAt := "", ""
+	if row.Code != nil {
+		code = *row.Code
+	}
+	if row.StatusChangedAt != nil {
+		statusChangedAt = row.StatusChangedAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		strconv.Itoa(row.ReferralID),
+		strconv.Itoa(row.ReferrerID),
+		row.Email,
+		optionalInt(row.ReferralCodeID),
+		code,
+		optionalInt(row.CampaignID),
+		row.Status,
+		statusChangedAt,
+		row.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// csvReferralExportWriter writes referrals as CSV with header
+type csvReferralExportWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVReferralExportWriter(w io.Writer) (*csvReferralExportWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(referralExportColumns); err != nil {
+		return nil, err
+	}
+	return &csvReferralExportWriter{writer: writer}, nil
+}
+
+func (c *csvReferralExportWriter) Write(row models.ReferralExportRow) error {
+	return c.writer.Write(referralExportValues(row))
+}
+
+func (c *csvReferralExportWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// jsonlReferralExportWriter writes referrals as JSON Lines, one object per line
+type jsonlReferralExportWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newJSONLReferralExportWriter(w io.Writer) *jsonlReferralExportWriter {
+	buffer := bufio.NewWriter(w)
+	return &jsonlReferralExportWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}
+}
+
+func (j *jsonlReferralExportWriter) Write(row models.ReferralExportRow) error {
+	return j.encoder.Encode(row)
+}
+
+func (j *jsonlReferralExportWriter) Close() error {
+	return j.buffer.Flush()
+}
+
+// xlsxReferralExportWriter writes referrals as single worksheet of XLSX workbook
+type xlsxReferralExportWriter struct {
+	sheet *xlsxSheetWriter
+}
+
+func newXLSXReferralExportWriter(w io.Writer) (*xlsxReferralExportWriter, error) {
+	sheet, err := newXLSXSheetWriter(w, "referrals")
+	if err != nil {
+		return nil, err
+	}
+	if err = sheet.WriteRow(referralExportColumns, nil); err != nil {
+		return nil, err
+	}
+	return &xlsxReferralExportWriter{sheet: sheet}, nil
+}
+
+func (x *xlsxReferralExportWriter) Write(row models.ReferralExportRow) error {
+	return x.sheet.WriteRow(referralExportValues(row), referralExportNumericColumns)
+}
+
+func (x *xlsxReferralExportWriter) Close() error {
+	return x.sheet.Close()
+}
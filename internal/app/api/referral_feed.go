@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+const (
+	// referralFeedBacklogLimit is maximum number of missed events sent to resumed feed
+	referralFeedBacklogLimit = 1000
+	// referralFeedBufferSize is number of live events waiting for slow client before its feed is dropped
+	referralFeedBufferSize = 64
+	// referralFeedReconnectDelay is pause before listener reconnects after losing connection
+	referralFeedReconnectDelay = 5 * time.Second
+)
+
+// ReferralFeedService represents hub pushing changes of referrals and referral codes to referrers in real time
+// Every app instance listens to notifications about outbox messages and fans them out to its own clients,
+// so feed works whichever instance client is connected to
+type ReferralFeedService struct {
+	repo          repository.ReferralFeedRepo
+	logger        *logrus.Logger
+	mu            sync.Mutex
+	subscriptions map[int]map[*ReferralFeedSubscription]struct{}
+}
+
+// ReferralFeedSubscription is feed of one connected client
+type ReferralFeedSubscription struct {
+	// Backlog is events missed since event client saw last, they are sent before live ones.
+	// It starts with reset event if there were more of them than fits into backlog
+	// or some of them were already removed
+	Backlog []models.ReferralFeedEvent
+	userID  int
+	events  chan models.ReferralFeedEvent
+	seen    map[int64]struct{}
+}
+
+// Events returns channel of live events. Channel is closed when client falls behind or listener loses connection,
+// then client has to reconnect and resume from last event it saw
+func (s *ReferralFeedSubscription) Events() <-chan models.ReferralFeedEvent {
+	return s.events
+}
+
+// Duplicate reports whether live event was already sent in backlog
+func (s *ReferralFeedSubscription) Duplicate(event models.ReferralFeedEvent) bool {
+	_, ok := s.seen[event.ID]
+	return ok
+}
+
+// NewReferralFeedService creates new instance of ReferralFeedService with repository
+func NewReferralFeedService(repo repository.ReferralFeedRepo, logger *logrus.Logger) *ReferralFeedService {
+	return &ReferralFeedService{
+		repo:          repo,
+		logger:        logger,
+		subscriptions: make(map[int]map[*ReferralFeedSubscription]struct{}),
+	}
+}
+
+// SubscribeReferralFeed subscribes client of user to live events. If lastEventID is set, events after it
+// are loaded into backlog of subscription. Subscription must be closed by UnsubscribeReferralFeed
+func (f *ReferralFeedService) SubscribeReferralFeed(userID int,
+	lastEventID int64) (*ReferralFeedSubscription, error) {
+	f.logger.Debugf("SubscribeReferralFeed[service]: Подписка пользователя с id: %d на ленту после события %d",
+		userID, lastEventID)
+
+	subscription := &ReferralFeedSubscription{
+		userID: userID,
+		events: make(chan models.ReferralFeedEvent, referralFeedBufferSize),
+		seen:   make(map[int64]struct{}),
+	}
+
+	// Subscription is registered before backlog is loaded, so no event falls between them,
+	// events arriving twice are told apart by Duplicate
+	f.mu.Lock()
+	if f.subscriptions[userID] == nil {
+		f.subscriptions[userID] = make(map[*ReferralFeedSubscription]struct{})
+	}
+	f.subscriptions[userID][subscription] = struct{}{}
+	f.mu.Unlock()
+
+	if lastEventID <= 0 {
+		return subscription, nil
+	}
+
+	events, reset, err := f.repo.GetEventsAfter(userID, lastEventID, referralFeedBacklogLimit)
+	if err != nil {
+		f.logger.Errorf("SubscribeReferralFeed[service]: Ошибка получения пропущенных событий: %s", err)
+		f.UnsubscribeReferralFeed(subscription)
+		return nil, err
+	}
+
+	if reset {
+		subscription.Backlog = append(subscription.Backlog,
+			models.ReferralFeedEvent{Type: models.ReferralFeedReset, CreatedAt: time.Now()})
+	}
+	for _, event := range events {
+		subscription.Backlog = append(subscription.Backlog, event)
+		subscription.seen[event.ID] = struct{}{}
+	}
+
+	return subscription, nil
+}
+
+// UnsubscribeReferralFeed stops sending live events to subscription
+func (f *ReferralFeedService) UnsubscribeReferralFeed(subscription *ReferralFeedSubscription) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.remove(subscription)
+}
+
+// RunReferralFeedListener listens to notifications about outbox messages and dispatches them to subscriptions
+// until ctx is done. When connection is lost, all subscriptions are dropped, so clients resume and get
+// events missed while listener was reconnecting
+func (f *ReferralFeedService) RunReferralFeedListener(ctx context.Context) {
+	f.logger.Infof("RunReferralFeedListener[service]: Лента рефералов запущена")
+
+	for {
+		err := f.repo.Listen(ctx, f.dispatch)
+		if ctx.Err() != nil {
+			f.logger.Infof("RunReferralFeedListener[service]: Лента рефералов остановлена")
+			return
+		}
+
+		f.logger.Errorf("RunReferralFeedListener[service]: Соединение ленты рефералов потеряно: %s", err)
+		f.dropAll()
+
+		select {
+		case <-ctx.Done():
+			f.logger.Infof("RunReferralFeedListener[service]: Лента рефералов остановлена")
+			return
+		case <-time.After(referralFeedReconnectDelay):
+		}
+	}
+}
+
+// dispatch sends event to subscriptions of its owner, client not keeping up loses its subscription
+func (f *ReferralFeedService) dispatch(event models.ReferralFeedEvent) {
+	f.mu.Lock()
+	subscribed := len(f.subscriptions[event.UserID]) > 0
+	f.mu.Unlock()
+	if !subscribed {
+		return
+	}
+
+	// Notification of large message comes without data, it is read from outbox
+	if event.Data == nil {
+		stored, err := f.repo.GetEventByID(event.ID)
+		if err != nil {
+			f.logger.Errorf("dispatch[service]: Ошибка получения события с id: %d: %s", event.ID, err)
+			return
+		}
+		event = stored
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for subscription := range f.subscriptions[event.UserID] {
+		select {
+		case subscription.events <- event:
+		default:
+			f.logger.Warnf("dispatch[service]: Клиент пользователя с id: %d не успевает получать события,"+
+				" подписка закрыта", event.UserID)
+			f.remove(subscription)
+		}
+	}
+}
+
+// dropAll closes all subscriptions
+func (f *ReferralFeedService) dropAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, subscriptions := range f.subscriptions {
+		for subscription := range subscriptions {
+			f.remove(subscription)
+		}
+	}
+}
+
+// remove unregisters subscription and closes its channel, it must be called with mu held
+func (f *ReferralFeedService) remove(subscription *ReferralFeedSubscription) {
+	subscriptions := f.subscriptions[subscription.userID]
+	if _, ok := subscriptions[subscription]; !ok {
+		return
+	}
+
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(f.subscriptions, subscription.userID)
+	}
+	close(subscription.events)
+}
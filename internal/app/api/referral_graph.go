@@ -0,0 +1,105 @@
+package api
+
+import (
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+// ReferralGraphService represents service loading users, referral codes and referrals by many keys at once,
+// it lets GraphQL resolvers batch lookups of one query level into single database query
+type ReferralGraphService struct {
+	repo      repository.ReferralGraphRepo
+	generator CodeGenerator
+	logger    *logrus.Logger
+}
+
+// NewReferralGraphService creates new instance of ReferralGraphService with repository and code generator
+func NewReferralGraphService(repo repository.ReferralGraphRepo, generator CodeGenerator,
+	logger *logrus.Logger) *ReferralGraphService {
+	return &ReferralGraphService{
+		repo:      repo,
+		generator: generator,
+		logger:    logger,
+	}
+}
+
+// GetUsersByIDs returns users by id, missing users are absent from map
+func (r *ReferralGraphService) GetUsersByIDs(ids []int) (map[int]models.User, error) {
+	users, err := r.repo.GetUsersByIDs(ids)
+	if err != nil {
+		r.logger.Errorf("GetUsersByIDs[service]: Ошибка при получении пользователей: %s", err)
+		return nil, err
+	}
+
+	byID := make(map[int]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	return byID, nil
+}
+
+// GetReferralCodesByIDs returns referral codes by id, missing codes are absent from map
+func (r *ReferralGraphService) GetReferralCodesByIDs(ids []int) (map[int]models.ReferralCode, error) {
+	codes, err := r.repo.GetReferralCodesByIDs(ids)
+	if err != nil {
+		r.logger.Errorf("GetReferralCodesByIDs[service]: Ошибка при получении реферальных кодов: %s", err)
+		return nil, err
+	}
+
+	byID := make(map[int]models.ReferralCode, len(codes))
+	for _, code := range codes {
+		code.Code = r.generator.Format(code.Code)
+		byID[code.ID] = code
+	}
+	return byID, nil
+}
+
+// GetReferralCodesByReferrerIDs returns page of referral codes grouped by referrer id from newest to oldest
+func (r *ReferralGraphService) GetReferralCodesByReferrerIDs(ids []int, page models.GraphPage) (map[int][]models.ReferralCode,
+	error) {
+	codes, err := r.repo.GetReferralCodesByReferrerIDs(ids, page)
+	if err != nil {
+		r.logger.Errorf("GetReferralCodesByReferrerIDs[service]: Ошибка при получении реферальных кодов: %s", err)
+		return nil, err
+	}
+
+	byReferrerID := make(map[int][]models.ReferralCode, len(ids))
+	for _, code := range codes {
+		code.Code = r.generator.Format(code.Code)
+		byReferrerID[code.ReferrerID] = append(byReferrerID[code.ReferrerID], code)
+	}
+	return byReferrerID, nil
+}
+
+// GetReferralsByReferralCodeIDs returns page of referrals grouped by id of referral code from newest to oldest
+func (r *ReferralGraphService) GetReferralsByReferralCodeIDs(ids []int, page models.GraphPage) (map[int][]models.Referral,
+	error) {
+	referrals, err := r.repo.GetReferralsByReferralCodeIDs(ids, page)
+	if err != nil {
+		r.logger.Errorf("GetReferralsByReferralCodeIDs[service]: Ошибка при получении рефералов: %s", err)
+		return nil, err
+	}
+
+	byCodeID := make(map[int][]models.Referral, len(ids))
+	for _, referral := range referrals {
+		byCodeID[referral.ReferralCodeID] = append(byCodeID[referral.ReferralCodeID], referral)
+	}
+	return byCodeID, nil
+}
+
+// GetReferralsByReferrerIDs returns page of referrals grouped by referrer id from newest to oldest
+func (r *ReferralGraphService) GetReferralsByReferrerIDs(ids []int, page models.GraphPage) (map[int][]models.Referral,
+	error) {
+	referrals, err := r.repo.GetReferralsByReferrerIDs(ids, page)
+	if err != nil {
+		r.logger.Errorf("GetReferralsByReferrerIDs[service]: Ошибка при получении рефералов: %s", err)
+		return nil, err
+	}
+
+	byReferrerID := make(map[int][]models.Referral, len(ids))
+	for _, referral := range referrals {
+		byReferrerID[referral.ReferrerID] = append(byReferrerID[referral.ReferrerID], referral)
+	}
+	return byReferrerID, nil
+}
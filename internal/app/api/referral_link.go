@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+// ReferralLinkService represents service for reporting referral links, which survive code rotations
+type ReferralLinkService struct {
+	repo      repository.ReferralLinkRepo
+	generator CodeGenerator
+	logger    *logrus.Logger
+}
+
+// NewReferralLinkService creates new instance of ReferralLinkService with repository and code generator
+func NewReferralLinkService(repo repository.ReferralLinkRepo, generator CodeGenerator,
+	logger *logrus.Logger) *ReferralLinkService {
+	return &ReferralLinkService{
+		repo:      repo,
+		generator: generator,
+		logger:    logger,
+	}
+}
+
+// GetReferralLinksByReferrerID retrieves referral links of referrer with full rotation history of each link
+func (r *ReferralLinkService) GetReferralLinksByReferrerID(referrerID int) ([]models.ReferralLink, error) {
+	r.logger.Debugf("GetReferralLinksByReferrerID[service]: Получение реферальных ссылок"+
+		" для пользователя с id: %d", referrerID)
+
+	links, err := r.repo.GetByReferrerID(referrerID)
+	if err != nil {
+		r.logger.Errorf("GetReferralLinksByReferrerID[service]: Ошибка при получении реферальных ссылок"+
+			" для пользователя с id: %d: %s", referrerID, err)
+		return nil, err
+	}
+
+	if links == nil {
+		links = []models.ReferralLink{}
+	}
+	for i := range links {
+		for j := range links[i].Codes {
+			links[i].Codes[j].Code = r.generator.Format(links[i].Codes[j].Code)
+		}
+	}
+	return links, nil
+}
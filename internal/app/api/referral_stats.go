@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+)
+
+var ErrInvalidReferralStats = errors.New("неправильные параметры статистики рефералов")
+
+// defaultReferralStatsRange is range of statistics ending today when request has no from
+const defaultReferralStatsRange = 30 * 24 * time.Hour
+
+// maxReferralStatsPeriods limits number of periods in time series
+const maxReferralStatsPeriods = 400
+
+// ReferralStatsService represents service for statistics and conversion analytics of referrals
+type ReferralStatsService struct {
+	repo      repository.ReferralStatsRepo
+	generator CodeGenerator
+	logger    *logrus.Logger
+	location  *time.Location
+}
+
+// NewReferralStatsService creates new instance of ReferralStatsService with repository, code generator
+// and time zone of periods from config
+func NewReferralStatsService(repo repository.ReferralStatsRepo, generator CodeGenerator, cfg *config.Config,
+	logger *logrus.Logger) *ReferralStatsService {
+	return &ReferralStatsService{
+		repo:      repo,
+		generator: generator,
+		location:  cfg.DefaultTimeZone,
+		logger:    logger,
+	}
+}
+
+// GetReferralStats computes totals, time series, conversion rates and per-code breakdown of referrals
+// By default statistics covers last 30 days including today with daily granularity
+func (r *ReferralStatsService) GetReferralStats(opts models.ReferralStatsOptions) (models.ReferralStatsResponse, error) {
+	r.logger.Debugf("GetReferralStats[service]: Получение статистики рефералов")
+
+	granularity := strings.ToLower(strings.TrimSpace(opts.Granularity))
+	if granularity == "" {
+		granularity = models.ReferralStatsGranularityDay
+	}
+	if granularity != models.ReferralStatsGranularityDay && granularity != models.ReferralStatsGranularityWeek {
+		return models.ReferralStatsResponse{}, newFieldError(ErrInvalidReferralStats, "granularity",
+			"неизвестная granularity %q", granularity)
+	}
+
+	now := time.Now().In(r.location)
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, r.location)
+	if opts.To != "" {
+		parsed, err := parseStatsBound(opts.To, r.location, true)
+		if err != nil {
+			return models.ReferralStatsResponse{}, newFieldError(ErrInvalidReferralStats, "to", "неправильный формат to")
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultReferralStatsRange)
+	if opts.From != "" {
+		parsed, err := parseStatsBound(opts.From, r.location, false)
+		if err != nil {
+			return models.ReferralStatsResponse{}, newFieldError(ErrInvalidReferralStats, "from", "неправильный формат from")
+		}
+		from = parsed
+	}
+
+	if !to.After(from) {
+		return models.ReferralStatsResponse{}, newFieldError(ErrInvalidReferralStats, "to", "to должен быть позже from")
+	}
+
+	period := 24 * time.Hour
+	if granularity == models.ReferralStatsGranularityWeek {
+		period *= 7
+	}
+	if to.Sub(from) > maxReferralStatsPeriods*period {
+		return models.ReferralStatsResponse{}, newFieldError(ErrInvalidReferralStats, "from",
+			"диапазон превышает %d периодов", maxReferralStatsPeriods)
+	}
+
+	stats, err := r.repo.GetReferralStats(opts.ReferrerID, from, to, granularity, r.location.String())
+	if err != nil {
+		r.logger.Errorf("GetReferralStats[service]: Ошибка при получении статистики рефералов: %s", err)
+		return models.ReferralStatsResponse{}, err
+	}
+
+	stats.Conversion = referralConversion(stats.Totals.Clicks, stats.Totals.SignUps, stats.Totals.Qualified)
+	for i := range stats.Codes {
+		code := &stats.Codes[i]
+		code.Code = r.generator.Format(code.Code)
+		code.Conversion = referralConversion(code.Clicks, code.SignUps, code.Qualified)
+	}
+
+	return stats, nil
+}
+
+// parseStatsBound parses RFC 3339 timestamp as is
+// Date-only value means start of that day in given location, or start of next day if it is inclusive end of range
+func parseStatsBound(value string, location *time.Location, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range dateOnlyLayouts {
+		date, err := time.ParseInLocation(layout, value, location)
+		if err != nil {
+			continue
+		}
+
+		if end {
+			date = date.AddDate(0, 0, 1)
+		}
+		return date, nil
+	}
+
+	return time.Time{}, ErrInvalidReferralStats
+}
+
+// referralConversion computes conversion rates rounded to four decimal places
+func referralConversion(clicks, signUps, qualified int) models.ReferralConversion {
+	return models.ReferralConversion{
+		ClickToSignUp:     conversionRate(signUps, clicks),
+		SignUpToQualified: conversionRate(qualified, signUps),
+		ClickToQualified:  conversionRate(qualified, clicks),
+	}
+}
+
+// conversionRate returns converted/total or 0 if total is 0
+func conversionRate(converted, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(converted)/float64(total)*10000) / 10000
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"rest-refs/internal/app/models"
+)
+
+func TestReferralCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor models.ReferralCursor
+	}{
+		{name: "nanoseconds", cursor: models.ReferralCursor{
+			CreatedAt: time.Date(2024, time.November, 13, 12, 30, 15, 123456789, time.UTC), ID: 42}},
+		{name: "whole seconds", cursor: models.ReferralCursor{
+			CreatedAt: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), ID: 1}},
+		{name: "other time zone", cursor: models.ReferralCursor{
+			CreatedAt: time.Date(2024, time.March, 31, 3, 0, 0, 500, time.FixedZone("MSK", 3*60*60)), ID: 7}},
+		{name: "large id", cursor: models.ReferralCursor{
+			CreatedAt: time.Date(2030, time.December, 31, 23, 59, 59, 999999999, time.UTC), ID: 2147483647}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := encodeReferralCursor(tt.cursor)
+			got, err := decodeReferralCursor(encoded)
+			if err != nil {
+				t.Fatalf("decodeReferralCursor(%q) error = %v", encoded, err)
+			}
+			if !got.CreatedAt.Equal(tt.cursor.CreatedAt) || got.ID != tt.cursor.ID {
+				t.Errorf("decodeReferralCursor(%q) = %+v, want %+v", encoded, got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeReferralCursorRejectsMalformed(t *testing.T) {
+	valid := encodeReferralCursor(models.ReferralCursor{
+		CreatedAt: time.Date(2024, time.November, 13, 12, 30, 15, 0, time.UTC), ID: 42})
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "not base64", value: "not a cursor!"},
+		{name: "padded base64", value: base64.URLEncoding.EncodeToString([]byte("1731501015000000000:42"))},
+		{name: "truncated", value: valid[:len(valid)-3]},
+		{name: "trailing data", value: valid + "AA"},
+		{name: "no separator", value: encode("1731501015000000000")},
+		{name: "extra field", value: encode("1731501015000000000:42:7")},
+		{name: "id is not a number", value: encode("1731501015000000000:abc")},
+		{name: "time is not a number", value: encode("yesterday:42")},
+		{name: "zero id", value: encode("1731501015000000000:0")},
+		{name: "negative id", value: encode("1731501015000000000:-42")},
+		{name: "negative time", value: encode("-1:42")},
+		{name: "id with leading zeros", value: encode("1731501015000000000:0042")},
+		{name: "id with plus sign", value: encode("1731501015000000000:+42")},
+		{name: "time out of range", value: encode("99999999999999999999:42")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := decodeReferralCursor(tt.value); err == nil {
+				t.Errorf("decodeReferralCursor(%q) = %+v, want error", tt.value, got)
+			}
+		})
+	}
+}
+
+func TestReferralPageFilterRejectsTamperedCursor(t *testing.T) {
+	cursor := base64.RawURLEncoding.EncodeToString([]byte("1731501015000000000:-1"))
+
+	_, err := referralPageFilter(models.ReferralListOptions{Cursor: cursor})
+	if !errors.Is(err, ErrInvalidReferralList) {
+		t.Fatalf("referralPageFilter() error = %v, want %v", err, ErrInvalidReferralList)
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "cursor" {
+		t.Errorf("referralPageFilter() error = %v, want error of field cursor", err)
+	}
+}
@@ -0,0 +1,384 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+var ErrInvalidRewardDryRun = errors.New("неправильный запрос расчета вознаграждений")
+
+// RewardService represents service for crediting rewards of referral program into rewards ledger
+type RewardService struct {
+	repo             repository.RewardRepo
+	ruleSetRepo      repository.RewardRuleSetRepo
+	logger           *logrus.Logger
+	defaults         models.RewardRules
+	location         *time.Location
+	chargebackWindow time.Duration
+	uplinePercents   []int
+}
+
+// NewRewardService creates new instance of RewardService with repositories of ledger and rule sets,
+// default reward amounts, time zone of cap periods, chargeback window and upline bonuses from config
+func NewRewardService(repo repository.RewardRepo, ruleSetRepo repository.RewardRuleSetRepo, cfg *config.Config,
+	logger *logrus.Logger) *RewardService {
+	return &RewardService{
+		repo:             repo,
+		ruleSetRepo:      ruleSetRepo,
+		location:         cfg.DefaultTimeZone,
+		chargebackWindow: cfg.ChargebackWindow,
+		uplinePercents:   cfg.UplineBonusPercents,
+		defaults: models.RewardRules{
+			Referrer: models.RewardAmounts{
+				Created:   &cfg.ReferrerCreatedReward,
+				Qualified: &cfg.ReferrerQualifiedReward,
+			},
+			Referee: models.RewardAmounts{
+				Created:   &cfg.RefereeCreatedReward,
+				Qualified: &cfg.RefereeQualifiedReward,
+			},
+		},
+		logger: logger,
+	}
+}
+
+// OnReferralCreated credits rewards for newly created referral
+// Calling it again for the same referral changes nothing
+func (r *RewardService) OnReferralCreated(referralID int) error {
+	r.logger.Debugf("OnReferralCreated[service]: Начисление вознаграждений за реферала с id: %d", referralID)
+
+	return r.credit(referralID, models.RewardKindReferralCreated, models.RewardTriggerCreated)
+}
+
+// OnReferralQualified credits rewards for qualified referral
+// Calling it again for the same referral changes nothing
+func (r *RewardService) OnReferralQualified(referralID int) error {
+	r.logger.Debugf("OnReferralQualified[service]: Начисление вознаграждений за квалификацию реферала с id: %d",
+		referralID)
+
+	return r.credit(referralID, models.RewardKindReferralQualified, models.RewardTriggerQualified)
+}
+
+// OnReferralRevoked reverses all rewards credited for rejected or reversed referral
+// Already reversed rewards are not reversed again
+func (r *RewardService) OnReferralRevoked(referralID int) error {
+	r.logger.Debugf("OnReferralRevoked[service]: Сторнирование вознаграждений реферала с id: %d", referralID)
+
+	count, err := r.repo.ReverseByReferralID(referralID)
+	if err != nil {
+		r.logger.Errorf("OnReferralRevoked[service]: Ошибка сторнирования вознаграждений реферала с id: %d: %s",
+			referralID, err)
+		return err
+	}
+
+	r.logger.Infof("OnReferralRevoked[service]: Сторнировано %d транзакций реферала с id: %d", count, referralID)
+	return nil
+}
+
+// OnReferralStatus brings rewards of referral in line with its status:
+// pending referral keeps creation rewards, qualified referral also gets qualification rewards,
+// rejected and reversed referrals have all rewards reversed
+func (r *RewardService) OnReferralStatus(referralID int, status string) error {
+	switch status {
+	case models.ReferralStatusQualified:
+		if err := r.OnReferralCreated(referralID); err != nil {
+			return err
+		}
+		return r.OnReferralQualified(referralID)
+	case models.ReferralStatusRejected, models.ReferralStatusReversed:
+		return r.OnReferralRevoked(referralID)
+	default:
+		return r.OnReferralCreated(referralID)
+	}
+}
+
+// GetRewardsByUserID retrieves reward balance of user, part of it held for chargeback window
+// and history of its changes
+func (r *RewardService) GetRewardsByUserID(userID int) (models.RewardsResponse, error) {
+	r.logger.Debugf("GetRewardsByUserID[service]: Получение вознаграждений пользователя с id: %d", userID)
+
+	rewards, err := r.repo.GetRewardsByUserID(userID)
+	if err != nil {
+		r.logger.Errorf("GetRewardsByUserID[service]: Ошибка при получении вознаграждений пользователя с id: %d: %s",
+			userID, err)
+		return models.RewardsResponse{}, err
+	}
+
+	held, err := r.repo.GetHeldByUserID(userID, time.Now().Add(-r.chargebackWindow))
+	if err != nil {
+		r.logger.Errorf("GetRewardsByUserID[service]: Ошибка при получении удерживаемых вознаграждений"+
+			" пользователя с id: %d: %s", userID, err)
+		return models.RewardsResponse{}, err
+	}
+
+	rewards.Held = min(max(held, 0), max(rewards.Balance, 0))
+	rewards.Available = max(rewards.Balance-rewards.Held, 0)
+	return rewards, nil
+}
+
+// DryRunReward evaluates what referral would earn on creation and on qualification at given moment
+// without posting anything, rule set from request is evaluated instead of active one if given
+func (r *RewardService) DryRunReward(input models.RewardDryRunRequest) (models.RewardDryRunResponse, error) {
+	r.logger.Debugf("DryRunReward[service]: Расчет вознаграждений реферала с id: %d", input.ReferralID)
+
+	if input.ReferralID <= 0 {
+		r.logger.Errorf("DryRunReward[service]: Неправильный id реферала: %d", input.ReferralID)
+		return models.RewardDryRunResponse{}, ErrInvalidRewardDryRun
+	}
+
+	parties, err := r.repo.GetReferralParties(input.ReferralID, len(r.uplinePercents))
+	if err != nil {
+		return models.RewardDryRunResponse{}, err
+	}
+
+	response := models.RewardDryRunResponse{
+		ReferralID:     parties.ReferralID,
+		ReferralNumber: parties.ReferralNumber,
+		At:             time.Now(),
+		Rewards:        []models.RewardEstimate{},
+	}
+	if input.At != nil {
+		response.At = *input.At
+	}
+
+	var definition models.RewardRuleDefinition
+	if input.RuleSet != nil {
+		definition, err = parseRewardRuleDefinition(input.RuleSet.Format, input.RuleSet.Source)
+		if err != nil {
+			r.logger.Errorf("DryRunReward[service]: %s", err)
+			return models.RewardDryRunResponse{}, err
+		}
+	} else {
+		response.RuleSetID, definition, err = r.activeRules(parties.CampaignID)
+		if err != nil {
+			return models.RewardDryRunResponse{}, err
+		}
+	}
+
+	for _, trigger := range []string{models.RewardTriggerCreated, models.RewardTriggerQualified} {
+		estimates, err := r.evaluate(parties, trigger, response.At, definition)
+		if err != nil {
+			return models.RewardDryRunResponse{}, err
+		}
+		response.Rewards = append(response.Rewards, estimates...)
+	}
+
+	return response, nil
+}
+
+// credit posts transaction of given kind moving amounts evaluated for trigger from program account
+// to referrer, referee and upline, parties without positive amount are skipped
+func (r *RewardService) credit(referralID int, kind, trigger string) error {
+	parties, err := r.repo.GetReferralParties(referralID, len(r.uplinePercents))
+	if err != nil {
+		r.logger.Errorf("credit[service]: Ошибка при получении участников реферала с id: %d: %s", referralID, err)
+		return err
+	}
+
+	// Rewards of referral in manual review are withheld, they are credited once referral is approved
+	if parties.ReviewStatus == models.ReviewStatusPending {
+		r.logger.Infof("credit[service]: Реферал с id: %d на проверке, вознаграждения не начислены", referralID)
+		return nil
+	}
+
+	_, definition, err := r.activeRules(parties.CampaignID)
+	if err != nil {
+		return err
+	}
+
+	estimates, err := r.evaluate(parties, trigger, time.Now(), definition)
+	if err != nil {
+		return err
+	}
+
+	transaction := models.RewardTransaction{
+		Kind:           kind,
+		ReferralID:     &parties.ReferralID,
+		IdempotencyKey: fmt.Sprintf("referral:%d:%s", referralID, kind),
+	}
+
+	var total int64
+	for _, estimate := range estimates {
+		if estimate.Amount > 0 && estimate.UserID != nil {
+			transaction.Entries = append(transaction.Entries,
+				models.RewardEntry{UserID: estimate.UserID, Amount: estimate.Amount, Caps: estimate.Caps})
+			total += estimate.Amount
+		}
+	}
+
+	if total == 0 {
+		r.logger.Debugf("credit[service]: Нет вознаграждений %s для реферала с id: %d", kind, referralID)
+		return nil
+	}
+
+	// Program account funds rewards, so transaction stays balanced. Caps estimated above are checked again
+	// when transaction is posted, then program entry is reduced by what capped entries lost
+	transaction.Entries = append(transaction.Entries, models.RewardEntry{Amount: -total})
+
+	if _, err = r.repo.Post(transaction); err != nil {
+		r.logger.Errorf("credit[service]: Ошибка начисления вознаграждений %s для реферала с id: %d: %s",
+			kind, referralID, err)
+		return err
+	}
+
+	return nil
+}
+
+// evaluate computes rewards of referrer and referee for trigger at given moment:
+// base amounts from config and campaign are passed through rules of definition and then limited by its caps
+// Upline bonuses are then added as percents of referrer's final amount, rules and caps do not apply to them
+func (r *RewardService) evaluate(parties models.ReferralParties, trigger string, at time.Time,
+	definition models.RewardRuleDefinition) ([]models.RewardEstimate, error) {
+	base := r.rulesFor(parties)
+	estimates := []models.RewardEstimate{
+		{
+			Trigger:    trigger,
+			Party:      models.RewardPartyReferrer,
+			UserID:     &parties.ReferrerID,
+			BaseAmount: baseAmount(base.Referrer, trigger),
+		},
+		{
+			Trigger:    trigger,
+			Party:      models.RewardPartyReferee,
+			UserID:     parties.RefereeID,
+			BaseAmount: baseAmount(base.Referee, trigger),
+		},
+	}
+
+	for i := range estimates {
+		estimate := &estimates[i]
+		amount, applied, err := applyRewardRules(definition.Rules, *estimate, parties, at)
+		if err != nil {
+			r.logger.Errorf("evaluate[service]: Ошибка расчета вознаграждения %s реферала с id: %d: %s",
+				estimate.Party, parties.ReferralID, err)
+			return nil, err
+		}
+		estimate.Amount, estimate.AppliedRules = amount, applied
+		if estimate.Amount < 0 {
+			estimate.Amount = 0
+		}
+		if estimate.UserID == nil {
+			continue
+		}
+
+		for _, rewardCap := range definition.Caps {
+			if rewardCap.Party != "" && rewardCap.Party != estimate.Party {
+				continue
+			}
+
+			since := capPeriodStart(rewardCap.Period, at, r.location)
+			estimate.Caps = append(estimate.Caps,
+				models.RewardEntryCap{Party: rewardCap.Party, Since: since, Amount: rewardCap.Amount})
+
+			earned, err := r.repo.GetEarnedSince(*estimate.UserID, rewardCap.Party, since)
+			if err != nil {
+				r.logger.Errorf("evaluate[service]: Ошибка при получении заработка пользователя с id: %d: %s",
+					*estimate.UserID, err)
+				return nil, err
+			}
+
+			remaining := rewardCap.Amount - earned
+			if remaining < 0 {
+				remaining = 0
+			}
+			if estimate.CapRemaining == nil || remaining < *estimate.CapRemaining {
+				estimate.CapRemaining = &remaining
+			}
+		}
+
+		if estimate.CapRemaining != nil && estimate.Amount > *estimate.CapRemaining {
+			estimate.Amount = *estimate.CapRemaining
+		}
+	}
+
+	referrerAmount := estimates[0].Amount
+	for i, userID := range parties.Upline {
+		if i >= len(r.uplinePercents) {
+			break
+		}
+
+		estimates = append(estimates, models.RewardEstimate{
+			Trigger:      trigger,
+			Party:        models.RewardPartyUpline,
+			UserID:       &userID,
+			Level:        i + 1,
+			BaseAmount:   referrerAmount,
+			Amount:       referrerAmount * int64(r.uplinePercents[i]) / 100,
+			AppliedRules: []string{},
+		})
+	}
+
+	return estimates, nil
+}
+
+// activeRules retrieves id and rules of rule set active for campaign
+// Without active rule set, rules are empty and base amounts are used as is
+func (r *RewardService) activeRules(campaignID *int) (*int, models.RewardRuleDefinition, error) {
+	ruleSet, err := r.ruleSetRepo.GetActive(campaignID)
+	if errors.Is(err, postgresql.ErrRewardRuleSetNotFound) {
+		return nil, models.RewardRuleDefinition{}, nil
+	}
+	if err != nil {
+		r.logger.Errorf("activeRules[service]: Ошибка при получении активного набора правил: %s", err)
+		return nil, models.RewardRuleDefinition{}, err
+	}
+
+	var definition models.RewardRuleDefinition
+	if err = json.Unmarshal(ruleSet.Rules, &definition); err != nil {
+		r.logger.Errorf("activeRules[service]: Неправильные правила в наборе с id: %d: %s", ruleSet.ID, err)
+		return nil, models.RewardRuleDefinition{}, err
+	}
+
+	return &ruleSet.ID, definition, nil
+}
+
+// rulesFor merges reward rules of referral's campaign over defaults, amounts omitted by campaign keep defaults
+func (r *RewardService) rulesFor(parties models.ReferralParties) models.RewardRules {
+	rules := r.defaults
+	if len(parties.RewardRules) == 0 {
+		return rules
+	}
+
+	var campaignRules models.RewardRules
+	if err := json.Unmarshal(parties.RewardRules, &campaignRules); err != nil {
+		r.logger.Warnf("rulesFor[service]: Неправильные правила вознаграждений кампании реферала с id: %d: %s",
+			parties.ReferralID, err)
+		return rules
+	}
+
+	rules.Referrer = mergeAmounts(rules.Referrer, campaignRules.Referrer)
+	rules.Referee = mergeAmounts(rules.Referee, campaignRules.Referee)
+	return rules
+}
+
+// mergeAmounts returns defaults with amounts set in override replaced
+func mergeAmounts(defaults, override models.RewardAmounts) models.RewardAmounts {
+	if override.Created != nil {
+		defaults.Created = override.Created
+	}
+	if override.Qualified != nil {
+		defaults.Qualified = override.Qualified
+	}
+	return defaults
+}
+
+// baseAmount returns amount for trigger or zero if it is not set
+func baseAmount(amounts models.RewardAmounts, trigger string) int64 {
+	amount := amounts.Created
+	if trigger == models.RewardTriggerQualified {
+		amount = amounts.Qualified
+	}
+
+	if amount == nil {
+		return 0
+	}
+	return *amount
+}
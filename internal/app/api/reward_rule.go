@@ -0,0 +1,323 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+var ErrInvalidRewardRuleSet = errors.New("неправильный набор правил вознаграждений")
+var ErrRewardAmountOutOfRange = errors.New("сумма вознаграждения вне допустимого диапазона")
+
+// Limits of rules: amounts of rules and caps and every amount computed by rules are at most maxRewardAmount,
+// so rewards and sums of them in ledger stay far from overflow of int64
+const (
+	maxRewardAmount     int64 = 1_000_000_000
+	maxRewardMultiplier       = 1000.0
+)
+
+// RewardRuleService represents service for managing declarative reward rule sets
+type RewardRuleService struct {
+	repo            repository.RewardRuleSetRepo
+	logger          *logrus.Logger
+	campaignService *CampaignService
+}
+
+// NewRewardRuleService creates new instance of RewardRuleService with repository and campaignService
+func NewRewardRuleService(repo repository.RewardRuleSetRepo, campaignService *CampaignService,
+	logger *logrus.Logger) *RewardRuleService {
+	return &RewardRuleService{
+		repo:            repo,
+		campaignService: campaignService,
+		logger:          logger,
+	}
+}
+
+// CreateRewardRuleSet parses and validates rule set and stores it
+// createdBy is id of admin who created the rule set
+func (r *RewardRuleService) CreateRewardRuleSet(input models.RewardRuleSetRequest,
+	createdBy *int) (models.RewardRuleSet, error) {
+	r.logger.Debugf("CreateRewardRuleSet[service]: Создание набора правил: %s", input.Name)
+
+	ruleSet, err := r.ruleSetFromRequest(input)
+	if err != nil {
+		return models.RewardRuleSet{}, err
+	}
+	ruleSet.CreatedBy = createdBy
+
+	created, err := r.repo.Create(ruleSet)
+	if err != nil {
+		r.logger.Errorf("CreateRewardRuleSet[service]: Ошибка создания набора правил: %s", err)
+		return models.RewardRuleSet{}, err
+	}
+
+	r.logger.Infof("CreateRewardRuleSet[service]: Набор правил с id: %d создан", created.ID)
+	return r.repo.GetByID(created.ID)
+}
+
+// UpdateRewardRuleSet parses and validates rule set and replaces rule set with given id
+// Rewards already posted are not recalculated
+func (r *RewardRuleService) UpdateRewardRuleSet(id int, input models.RewardRuleSetRequest) (models.RewardRuleSet, error) {
+	r.logger.Debugf("UpdateRewardRuleSet[service]: Изменение набора правил с id: %d", id)
+
+	ruleSet, err := r.ruleSetFromRequest(input)
+	if err != nil {
+		return models.RewardRuleSet{}, err
+	}
+	ruleSet.ID = id
+
+	if err = r.repo.Update(ruleSet); err != nil {
+		r.logger.Errorf("UpdateRewardRuleSet[service]: Ошибка изменения набора правил с id: %d: %s", id, err)
+		return models.RewardRuleSet{}, err
+	}
+
+	r.logger.Infof("UpdateRewardRuleSet[service]: Набор правил с id: %d изменен", id)
+	return r.repo.GetByID(id)
+}
+
+// DeleteRewardRuleSet removes rule set, rewards already posted by its rules are kept
+func (r *RewardRuleService) DeleteRewardRuleSet(id int) error {
+	r.logger.Debugf("DeleteRewardRuleSet[service]: Удаление набора правил с id: %d", id)
+	return r.repo.Delete(id)
+}
+
+// GetRewardRuleSetByID retrieves rule set by id
+func (r *RewardRuleService) GetRewardRuleSetByID(id int) (models.RewardRuleSet, error) {
+	r.logger.Debugf("GetRewardRuleSetByID[service]: Получение набора правил с id: %d", id)
+	return r.repo.GetByID(id)
+}
+
+// GetRewardRuleSets retrieves all rule sets
+func (r *RewardRuleService) GetRewardRuleSets() ([]models.RewardRuleSet, error) {
+	r.logger.Debugf("GetRewardRuleSets[service]: Получение списка наборов правил")
+
+	ruleSets, err := r.repo.GetAll()
+	if err != nil {
+		r.logger.Errorf("GetRewardRuleSets[service]: Ошибка получения списка наборов правил: %s", err)
+		return nil, err
+	}
+
+	if ruleSets == nil {
+		ruleSets = []models.RewardRuleSet{}
+	}
+	return ruleSets, nil
+}
+
+// ruleSetFromRequest validates request and converts it into rule set with rules normalized to JSON
+func (r *RewardRuleService) ruleSetFromRequest(input models.RewardRuleSetRequest) (models.RewardRuleSet, error) {
+	ruleSet := models.RewardRuleSet{
+		Name:       strings.TrimSpace(input.Name),
+		CampaignID: input.CampaignID,
+		Active:     input.Active,
+		Format:     strings.ToLower(strings.TrimSpace(input.Format)),
+		Source:     input.Source,
+	}
+
+	if ruleSet.Name == "" {
+		r.logger.Errorf("ruleSetFromRequest[service]: Не указано название набора правил")
+		return models.RewardRuleSet{}, newFieldError(ErrInvalidRewardRuleSet, "name", "не указано название")
+	}
+
+	if ruleSet.Format == "" {
+		ruleSet.Format = "json"
+	}
+
+	definition, err := parseRewardRuleDefinition(ruleSet.Format, ruleSet.Source)
+	if err != nil {
+		r.logger.Errorf("ruleSetFromRequest[service]: %s", err)
+		return models.RewardRuleSet{}, err
+	}
+
+	if ruleSet.CampaignID != nil {
+		if _, err = r.campaignService.GetCampaignByID(*ruleSet.CampaignID); err != nil {
+			if errors.Is(err, postgresql.ErrCampaignNotFound) {
+				return models.RewardRuleSet{}, newFieldError(ErrInvalidRewardRuleSet, "campaign_id",
+					"кампания с id: %d не найдена", *ruleSet.CampaignID)
+			}
+			return models.RewardRuleSet{}, err
+		}
+	}
+
+	if ruleSet.Rules, err = json.Marshal(definition); err != nil {
+		return models.RewardRuleSet{}, err
+	}
+
+	return ruleSet, nil
+}
+
+// parseRewardRuleDefinition parses rule set source written in format "json" or "yaml" and validates it
+// Unknown fields are errors, so misspelled conditions do not silently match everything
+func parseRewardRuleDefinition(format, source string) (models.RewardRuleDefinition, error) {
+	var definition models.RewardRuleDefinition
+
+	switch format {
+	case "", "json":
+		decoder := json.NewDecoder(strings.NewReader(source))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&definition); err != nil {
+			return models.RewardRuleDefinition{}, newFieldError(ErrInvalidRewardRuleSet, "source", "%s", err)
+		}
+	case "yaml":
+		decoder := yaml.NewDecoder(strings.NewReader(source))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&definition); err != nil {
+			return models.RewardRuleDefinition{}, newFieldError(ErrInvalidRewardRuleSet, "source", "%s", err)
+		}
+	default:
+		return models.RewardRuleDefinition{}, newFieldError(ErrInvalidRewardRuleSet, "format", "неизвестный формат %q",
+			format)
+	}
+
+	if err := validateRewardRuleDefinition(definition); err != nil {
+		return models.RewardRuleDefinition{}, newFieldError(ErrInvalidRewardRuleSet, "source", "%s", err)
+	}
+
+	return definition, nil
+}
+
+// validateRewardRuleDefinition checks triggers, parties, periods and ranges of rules and caps
+func validateRewardRuleDefinition(definition models.RewardRuleDefinition) error {
+	for i, rule := range definition.Rules {
+		switch {
+		case rule.Trigger != models.RewardTriggerCreated && rule.Trigger != models.RewardTriggerQualified:
+			return fmt.Errorf("правило %d: неизвестный trigger %q", i+1, rule.Trigger)
+		case !isRewardParty(rule.Party):
+			return fmt.Errorf("правило %d: неизвестный party %q", i+1, rule.Party)
+		case rule.Amount == nil && rule.Multiplier == nil:
+			return fmt.Errorf("правило %d: не указаны amount и multiplier", i+1)
+		case rule.Amount != nil && (*rule.Amount < 0 || *rule.Amount > maxRewardAmount):
+			return fmt.Errorf("правило %d: amount должен быть от 0 до %d", i+1, maxRewardAmount)
+		case rule.Multiplier != nil && !(*rule.Multiplier >= 0 && *rule.Multiplier <= maxRewardMultiplier):
+			// Negated range also rejects NaN, which YAML allows as .nan
+			return fmt.Errorf("правило %d: multiplier должен быть от 0 до %g", i+1, maxRewardMultiplier)
+		case rule.From != nil && rule.Until != nil && !rule.Until.After(*rule.From):
+			return fmt.Errorf("правило %d: until должен быть позже from", i+1)
+		case rule.MinReferrals < 0 || (rule.MaxReferrals != nil && *rule.MaxReferrals < rule.MinReferrals):
+			return fmt.Errorf("правило %d: неправильный диапазон min_referrals и max_referrals", i+1)
+		}
+	}
+
+	for i, rewardCap := range definition.Caps {
+		switch {
+		case !isRewardParty(rewardCap.Party):
+			return fmt.Errorf("ограничение %d: неизвестный party %q", i+1, rewardCap.Party)
+		case !isRewardCapPeriod(rewardCap.Period):
+			return fmt.Errorf("ограничение %d: неизвестный period %q", i+1, rewardCap.Period)
+		case rewardCap.Amount < 0 || rewardCap.Amount > maxRewardAmount:
+			return fmt.Errorf("ограничение %d: amount должен быть от 0 до %d", i+1, maxRewardAmount)
+		}
+	}
+
+	return nil
+}
+
+// applyRewardRules applies matching rules in order to base amount of estimate
+// and returns resulting amount and names of applied rules
+// Each rule is validated on its own, but several multipliers may still add up, so amount above maxRewardAmount
+// after any rule is rejected with ErrRewardAmountOutOfRange instead of overflowing
+func applyRewardRules(rules []models.RewardRule, estimate models.RewardEstimate, parties models.ReferralParties,
+	at time.Time) (int64, []string, error) {
+	amount := estimate.BaseAmount
+	applied := []string{}
+
+	for i, rule := range rules {
+		if !rewardRuleMatches(rule, estimate, parties, at) {
+			continue
+		}
+
+		if rule.Amount != nil {
+			amount = *rule.Amount
+		}
+
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		if rule.Multiplier != nil {
+			// Float result is checked before conversion, converting NaN or value out of int64 range is undefined
+			multiplied := math.Round(float64(amount) * *rule.Multiplier)
+			if math.IsNaN(multiplied) || math.Abs(multiplied) > float64(maxRewardAmount) {
+				return 0, nil, fmt.Errorf("%w: правило %s дает %g", ErrRewardAmountOutOfRange, name, multiplied)
+			}
+			amount = int64(multiplied)
+		}
+		if amount > maxRewardAmount || amount < -maxRewardAmount {
+			return 0, nil, fmt.Errorf("%w: правило %s дает %d", ErrRewardAmountOutOfRange, name, amount)
+		}
+
+		applied = append(applied, name)
+	}
+
+	return amount, applied, nil
+}
+
+// rewardRuleMatches reports whether all conditions of rule hold for reward described by estimate
+func rewardRuleMatches(rule models.RewardRule, estimate models.RewardEstimate, parties models.ReferralParties,
+	at time.Time) bool {
+	switch {
+	case rule.Trigger != estimate.Trigger:
+		return false
+	case rule.Party != "" && rule.Party != estimate.Party:
+		return false
+	case rule.CampaignID != nil && (parties.CampaignID == nil || *parties.CampaignID != *rule.CampaignID):
+		return false
+	case rule.From != nil && at.Before(*rule.From):
+		return false
+	case rule.Until != nil && !at.Before(*rule.Until):
+		return false
+	case parties.ReferralNumber < rule.MinReferrals:
+		return false
+	case rule.MaxReferrals != nil && parties.ReferralNumber > *rule.MaxReferrals:
+		return false
+	default:
+		return true
+	}
+}
+
+// capPeriodStart returns beginning of calendar period containing at in given location
+// Total period has no beginning, so zero time is returned
+func capPeriodStart(period string, at time.Time, location *time.Location) time.Time {
+	at = at.In(location)
+	year, month, day := at.Date()
+
+	switch period {
+	case models.RewardCapPeriodDay:
+		return time.Date(year, month, day, 0, 0, 0, 0, location)
+	case models.RewardCapPeriodWeek:
+		// Weeks start on Monday
+		offset := (int(at.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, location)
+	case models.RewardCapPeriodMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, location)
+	case models.RewardCapPeriodYear:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, location)
+	default:
+		return time.Time{}
+	}
+}
+
+// isRewardParty reports whether party is referrer, referee or empty, which means both
+func isRewardParty(party string) bool {
+	return party == "" || party == models.RewardPartyReferrer || party == models.RewardPartyReferee
+}
+
+// isRewardCapPeriod reports whether period is one of known cap periods
+func isRewardCapPeriod(period string) bool {
+	switch period {
+	case models.RewardCapPeriodDay, models.RewardCapPeriodWeek, models.RewardCapPeriodMonth,
+		models.RewardCapPeriodYear, models.RewardCapPeriodTotal:
+		return true
+	default:
+		return false
+	}
+}
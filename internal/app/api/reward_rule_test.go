@@ -0,0 +1,289 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func maxRewardAmountPtr() *int64 {
+	return int64Ptr(maxRewardAmount)
+}
+
+func TestParseRewardRuleDefinition(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		source string
+		err    error
+	}{
+		{name: "amount and multiplier", format: "json",
+			source: `{"rules":[{"trigger":"qualified","amount":500,"multiplier":2}]}`},
+		{name: "yaml with cap", format: "yaml", source: "rules:\n  - trigger: created\n    multiplier: 1.5\n" +
+			"caps:\n  - party: referrer\n    period: month\n    amount: 5000\n"},
+		{name: "unknown field", format: "json", source: `{"rules":[{"trigger":"created","amout":5}]}`,
+			err: ErrInvalidRewardRuleSet},
+		{name: "upline party", format: "json",
+			source: `{"rules":[{"trigger":"created","party":"upline","amount":5}]}`, err: ErrInvalidRewardRuleSet},
+		{name: "negative amount", format: "json", source: `{"rules":[{"trigger":"created","amount":-1}]}`,
+			err: ErrInvalidRewardRuleSet},
+		{name: "amount above limit", format: "json",
+			source: `{"rules":[{"trigger":"created","amount":1000000001}]}`, err: ErrInvalidRewardRuleSet},
+		{name: "multiplier above limit", format: "json",
+			source: `{"rules":[{"trigger":"created","multiplier":1e300}]}`, err: ErrInvalidRewardRuleSet},
+		{name: "NaN multiplier", format: "yaml", source: "rules:\n  - trigger: created\n    multiplier: .nan\n",
+			err: ErrInvalidRewardRuleSet},
+		{name: "infinite multiplier", format: "yaml", source: "rules:\n  - trigger: created\n    multiplier: .inf\n",
+			err: ErrInvalidRewardRuleSet},
+		{name: "cap above limit", format: "json",
+			source: `{"rules":[],"caps":[{"period":"day","amount":9223372036854775807}]}`,
+			err:    ErrInvalidRewardRuleSet},
+		{name: "unknown cap period", format: "json", source: `{"rules":[],"caps":[{"period":"hour","amount":5}]}`,
+			err: ErrInvalidRewardRuleSet},
+		{name: "unknown format", format: "toml", source: ``, err: ErrInvalidRewardRuleSet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRewardRuleDefinition(tt.format, tt.source)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("parseRewardRuleDefinition(%q, %q) error = %v, want %v", tt.format, tt.source, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestApplyRewardRules(t *testing.T) {
+	campaignID := 3
+	december := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC)
+	january := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	maxTen := 10
+
+	estimate := models.RewardEstimate{Trigger: models.RewardTriggerQualified, Party: models.RewardPartyReferrer,
+		BaseAmount: 100}
+	parties := models.ReferralParties{CampaignID: &campaignID, ReferralNumber: 5}
+	at := time.Date(2024, time.December, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		rules   []models.RewardRule
+		want    int64
+		applied []string
+		err     error
+	}{
+		{name: "no rules keep base amount", want: 100, applied: []string{}},
+		{name: "amount replaces base", rules: []models.RewardRule{
+			{Name: "flat", Trigger: models.RewardTriggerQualified, Amount: int64Ptr(500)},
+		}, want: 500, applied: []string{"flat"}},
+		{name: "rules apply in order", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, Amount: int64Ptr(500)},
+			{Name: "double", Trigger: models.RewardTriggerQualified, Multiplier: float64Ptr(2)},
+		}, want: 1000, applied: []string{"#1", "double"}},
+		{name: "half is rounded away from zero", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, Multiplier: float64Ptr(0.125)},
+		}, want: 13, applied: []string{"#1"}},
+		{name: "other trigger", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerCreated, Amount: int64Ptr(500)},
+		}, want: 100, applied: []string{}},
+		{name: "other party", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, Party: models.RewardPartyReferee, Amount: int64Ptr(500)},
+		}, want: 100, applied: []string{}},
+		{name: "other campaign", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, CampaignID: &maxTen, Amount: int64Ptr(500)},
+		}, want: 100, applied: []string{}},
+		{name: "inside date range", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, From: &december, Until: &january, Multiplier: float64Ptr(2)},
+		}, want: 200, applied: []string{"#1"}},
+		{name: "until is exclusive", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, From: &december, Until: &at, Multiplier: float64Ptr(2)},
+		}, want: 100, applied: []string{}},
+		{name: "not enough referrals", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, MinReferrals: 6, Amount: int64Ptr(500)},
+		}, want: 100, applied: []string{}},
+		{name: "referrals in range", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, MinReferrals: 5, MaxReferrals: &maxTen, Amount: int64Ptr(500)},
+		}, want: 500, applied: []string{"#1"}},
+		{name: "multipliers add up beyond limit", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, Amount: maxRewardAmountPtr()},
+			{Trigger: models.RewardTriggerQualified, Multiplier: float64Ptr(maxRewardMultiplier)},
+		}, err: ErrRewardAmountOutOfRange},
+		{name: "result beyond int64", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, Amount: maxRewardAmountPtr()},
+			{Trigger: models.RewardTriggerQualified, Multiplier: float64Ptr(math.MaxFloat64)},
+		}, err: ErrRewardAmountOutOfRange},
+		{name: "NaN multiplier of stored rule", rules: []models.RewardRule{
+			{Trigger: models.RewardTriggerQualified, Multiplier: float64Ptr(math.NaN())},
+		}, err: ErrRewardAmountOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, applied, err := applyRewardRules(tt.rules, estimate, parties, at)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("applyRewardRules() error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("applyRewardRules() = %d, want %d", got, tt.want)
+			}
+			if !reflect.DeepEqual(applied, tt.applied) {
+				t.Errorf("applyRewardRules() applied = %v, want %v", applied, tt.applied)
+			}
+		})
+	}
+}
+
+func TestCapPeriodStart(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	// Wednesday in UTC, but already Thursday in Moscow
+	at := time.Date(2024, time.November, 13, 22, 30, 0, 0, time.UTC)
+	sunday := time.Date(2024, time.November, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		period string
+		at     time.Time
+		want   time.Time
+	}{
+		{name: "day", period: models.RewardCapPeriodDay, at: at,
+			want: time.Date(2024, time.November, 14, 0, 0, 0, 0, moscow)},
+		{name: "week starts on Monday", period: models.RewardCapPeriodWeek, at: at,
+			want: time.Date(2024, time.November, 11, 0, 0, 0, 0, moscow)},
+		{name: "Sunday belongs to week of previous Monday", period: models.RewardCapPeriodWeek, at: sunday,
+			want: time.Date(2024, time.November, 11, 0, 0, 0, 0, moscow)},
+		{name: "month", period: models.RewardCapPeriodMonth, at: at,
+			want: time.Date(2024, time.November, 1, 0, 0, 0, 0, moscow)},
+		{name: "year", period: models.RewardCapPeriodYear, at: at,
+			want: time.Date(2024, time.January, 1, 0, 0, 0, 0, moscow)},
+		{name: "total", period: models.RewardCapPeriodTotal, at: at, want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := capPeriodStart(tt.period, tt.at, moscow); !got.Equal(tt.want) {
+				t.Errorf("capPeriodStart(%q, %s) = %s, want %s", tt.period, tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeRewardRepo returns what users earned by party, other methods of ledger are not used by evaluate
+type fakeRewardRepo struct {
+	earned map[string]int64
+}
+
+func (r *fakeRewardRepo) GetReferralParties(int, int) (models.ReferralParties, error) {
+	return models.ReferralParties{}, nil
+}
+
+func (r *fakeRewardRepo) Post(models.RewardTransaction) (bool, error) {
+	return true, nil
+}
+
+func (r *fakeRewardRepo) ReverseByReferralID(int) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeRewardRepo) GetRewardsByUserID(int) (models.RewardsResponse, error) {
+	return models.RewardsResponse{}, nil
+}
+
+func (r *fakeRewardRepo) GetEarnedSince(_ int, party string, _ time.Time) (int64, error) {
+	return r.earned[party], nil
+}
+
+func (r *fakeRewardRepo) GetHeldByUserID(int, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestEvaluateClampsToCaps(t *testing.T) {
+	refereeID := 2
+	parties := models.ReferralParties{ReferralID: 1, ReferrerID: 1, RefereeID: &refereeID, Upline: []int{3}}
+	at := time.Date(2024, time.November, 13, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		caps          []models.RewardCap
+		earned        map[string]int64
+		wantReferrer  int64
+		wantReferee   int64
+		wantRemaining *int64
+	}{
+		{name: "no caps", wantReferrer: 100, wantReferee: 50},
+		{name: "cap not reached", caps: []models.RewardCap{
+			{Party: models.RewardPartyReferrer, Period: models.RewardCapPeriodMonth, Amount: 1000},
+		}, earned: map[string]int64{models.RewardPartyReferrer: 100}, wantReferrer: 100, wantReferee: 50,
+			wantRemaining: int64Ptr(900)},
+		{name: "cap partly used", caps: []models.RewardCap{
+			{Party: models.RewardPartyReferrer, Period: models.RewardCapPeriodMonth, Amount: 1000},
+		}, earned: map[string]int64{models.RewardPartyReferrer: 960}, wantReferrer: 40, wantReferee: 50,
+			wantRemaining: int64Ptr(40)},
+		{name: "cap exceeded", caps: []models.RewardCap{
+			{Party: models.RewardPartyReferrer, Period: models.RewardCapPeriodMonth, Amount: 1000},
+		}, earned: map[string]int64{models.RewardPartyReferrer: 1200}, wantReferrer: 0, wantReferee: 50,
+			wantRemaining: int64Ptr(0)},
+		{name: "earnings of other party do not count", caps: []models.RewardCap{
+			{Party: models.RewardPartyReferrer, Period: models.RewardCapPeriodMonth, Amount: 1000},
+		}, earned: map[string]int64{models.RewardPartyReferee: 1000, models.RewardPartyUpline: 1000},
+			wantReferrer: 100, wantReferee: 50, wantRemaining: int64Ptr(1000)},
+		{name: "tightest cap wins", caps: []models.RewardCap{
+			{Party: models.RewardPartyReferrer, Period: models.RewardCapPeriodTotal, Amount: 1000},
+			{Party: models.RewardPartyReferrer, Period: models.RewardCapPeriodDay, Amount: 30},
+		}, wantReferrer: 30, wantReferee: 50, wantRemaining: int64Ptr(30)},
+		{name: "cap without party limits both", caps: []models.RewardCap{
+			{Period: models.RewardCapPeriodWeek, Amount: 20},
+		}, wantReferrer: 20, wantReferee: 20, wantRemaining: int64Ptr(20)},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cfg := &config.Config{ReferrerCreatedReward: 100, RefereeCreatedReward: 50, UplineBonusPercents: []int{10},
+		DefaultTimeZone: time.UTC}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRewardService(&fakeRewardRepo{earned: tt.earned}, nil, cfg, logger)
+
+			estimates, err := service.evaluate(parties, models.RewardTriggerCreated, at,
+				models.RewardRuleDefinition{Caps: tt.caps})
+			if err != nil {
+				t.Fatalf("evaluate() error = %v", err)
+			}
+			if len(estimates) != 3 {
+				t.Fatalf("evaluate() returned %d estimates, want referrer, referee and upline", len(estimates))
+			}
+
+			referrer, referee, upline := estimates[0], estimates[1], estimates[2]
+			if referrer.Amount != tt.wantReferrer {
+				t.Errorf("referrer amount = %d, want %d", referrer.Amount, tt.wantReferrer)
+			}
+			if referee.Amount != tt.wantReferee {
+				t.Errorf("referee amount = %d, want %d", referee.Amount, tt.wantReferee)
+			}
+			if !reflect.DeepEqual(referrer.CapRemaining, tt.wantRemaining) {
+				t.Errorf("referrer cap remaining = %v, want %v", referrer.CapRemaining, tt.wantRemaining)
+			}
+			if len(referrer.Caps) != len(tt.caps) {
+				t.Errorf("referrer has %d caps to check on posting, want %d", len(referrer.Caps), len(tt.caps))
+			}
+			// Upline bonus follows capped amount of referrer, caps are not applied to it again
+			if want := tt.wantReferrer * 10 / 100; upline.Amount != want || len(upline.Caps) != 0 {
+				t.Errorf("upline amount = %d with %d caps, want %d without caps", upline.Amount, len(upline.Caps), want)
+			}
+		})
+	}
+}
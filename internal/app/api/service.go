@@ -1,8 +1,13 @@
 package api
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"github.com/dgrijalva/jwt-go"
 	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
 	"rest-refs/internal/app/models"
 	"rest-refs/internal/app/repository"
 )
@@ -11,6 +16,7 @@ import (
 type Authorization interface {
 	RegisterUser(user models.User) error
 	GetUserByEmail(email string) (models.User, error)
+	GetUserByID(id int) (models.User, error)
 	GenerateToken(user models.User) (string, error)
 	IsTokenValid(tokenString string) (bool, jwt.MapClaims, error)
 }
@@ -18,35 +24,218 @@ type Authorization interface {
 // ReferralCode defines methods for handling referral codes
 type ReferralCode interface {
 	CreateReferralCode(referralCode models.ReferralCode) (models.ReferralCode, error)
+	ResolveExpiration(input models.ReferralCodeCreateRequest) (time.Time, error)
 	DeleteReferralCode(referrerID int) error
 	GetReferralCodeByReferrerEmail(email string) (models.ReferralCode, error)
 	GetIDByReferralCode(code string) (int, error)
 	GetReferrerIDByReferralCode(code string) (int, error)
+	GetReferralCodeStatus(code string) (models.ReferralCodeStatusResponse, error)
+	PauseReferralCode(referrerID int) error
+	ResumeReferralCode(referrerID int) error
+	RotateReferralCode(referrerID int, input models.ReferralCodeRotateRequest) (models.ReferralCode, error)
+}
+
+// ReferralLink defines methods for reporting referral links across code rotations
+type ReferralLink interface {
+	GetReferralLinksByReferrerID(referrerID int) ([]models.ReferralLink, error)
 }
 
 // Referral defines methods related to referral management
 type Referral interface {
-	GetReferralsByReferrerID(referrerID int) ([]models.ReferralInfoResponse, error)
-	RegisterWithReferralCode(referralCode string, user models.User) error
+	GetReferralsByReferrerID(referrerID int, opts models.ReferralListOptions) (models.ReferralPage, error)
+	RegisterWithReferralCode(referralCode string, user models.User, client models.ClientInfo) error
+	RegisterWithAttribution(clickToken string, user models.User, client models.ClientInfo) error
+	GetCampaignStatsByReferrerID(userID, referrerID int) ([]models.CampaignReferralStats, error)
+	GetReferralTree(userID, depth int) (models.ReferralTreeResponse, error)
+}
+
+// ReferralExport defines methods for exporting referrals into spreadsheets
+type ReferralExport interface {
+	ExportReferrals(referrerID *int, format string, opts models.ReferralListOptions, w io.Writer) error
+}
+
+// ReferralStats defines methods for statistics and conversion analytics of referrals
+type ReferralStats interface {
+	GetReferralStats(opts models.ReferralStatsOptions) (models.ReferralStatsResponse, error)
+}
+
+// Leaderboard defines methods for ranking referrers and managing public display on leaderboards
+type Leaderboard interface {
+	GetLeaderboard(userID int, opts models.LeaderboardOptions) (models.LeaderboardResponse, error)
+	GetLeaderboardSettings(userID int) (models.LeaderboardSettings, error)
+	UpdateLeaderboardSettings(userID int, input models.LeaderboardSettings) (models.LeaderboardSettings, error)
+}
+
+// Fraud defines methods for recognizing devices of users and manual review of risky referrals
+type Fraud interface {
+	RecordUserDevice(email string, client models.ClientInfo) error
+	GetReferralReviews(statuses []string) ([]models.ReferralReview, error)
+	ApproveReferralReview(id, adminID int, input models.ReferralReviewDecisionRequest) (models.ReferralReview, error)
+	RejectReferralReview(id, adminID int, input models.ReferralReviewDecisionRequest) (models.ReferralReview, error)
+}
+
+// ReferralEvent defines methods for ingesting external events about referred users
+type ReferralEvent interface {
+	IngestReferralEvent(input models.ReferralEventRequest) (models.ReferralEventResponse, error)
+}
+
+// ReferralClick defines methods for tracking clicks on referral share links
+type ReferralClick interface {
+	TrackClick(code, ip string, click models.ReferralClick) (models.ReferralClick, error)
+}
+
+// ReferralCodeBatch defines methods for bulk generation of referral codes
+type ReferralCodeBatch interface {
+	CreateReferralCodeBatch(input models.ReferralCodeBatchCreateRequest, createdBy *int) (models.ReferralCodeBatch, error)
+	WriteReferralCodeBatchCSV(batchID int, w io.Writer) error
+}
+
+// QRCode defines methods for rendering QR codes of referral share links
+type QRCode interface {
+	GenerateReferralCodeQR(referrerID, codeID int, opts models.QRCodeOptions) ([]byte, string, error)
+}
+
+// Reward defines methods for reading rewards ledger of users
+type Reward interface {
+	GetRewardsByUserID(userID int) (models.RewardsResponse, error)
+	DryRunReward(input models.RewardDryRunRequest) (models.RewardDryRunResponse, error)
+}
+
+// RewardRule defines methods for managing declarative reward rule sets
+type RewardRule interface {
+	CreateRewardRuleSet(input models.RewardRuleSetRequest, createdBy *int) (models.RewardRuleSet, error)
+	UpdateRewardRuleSet(id int, input models.RewardRuleSetRequest) (models.RewardRuleSet, error)
+	DeleteRewardRuleSet(id int) error
+	GetRewardRuleSetByID(id int) (models.RewardRuleSet, error)
+	GetRewardRuleSets() ([]models.RewardRuleSet, error)
+}
+
+// Payout defines methods for requesting payouts of rewards and processing them by admins
+type Payout interface {
+	RequestPayout(userID int, input models.PayoutRequest) (models.Payout, error)
+	ApprovePayout(id, adminID int, input models.PayoutDecisionRequest) (models.Payout, error)
+	RejectPayout(id, adminID int, input models.PayoutDecisionRequest) (models.Payout, error)
+	GetPayoutByID(id int) (models.Payout, error)
+	GetPayoutsByUserID(userID int) ([]models.Payout, error)
+	GetPayouts(statuses []string) ([]models.Payout, error)
+}
+
+// Campaign defines methods for managing campaigns of referral codes
+type Campaign interface {
+	CreateCampaign(input models.CampaignRequest, createdBy *int) (models.Campaign, error)
+	UpdateCampaign(id int, input models.CampaignRequest) (models.Campaign, error)
+	DeleteCampaign(id int) error
+	GetCampaignByID(id int) (models.Campaign, error)
+	GetCampaigns() ([]models.Campaign, error)
+}
+
+// Webhook defines methods for managing webhook subscriptions, inspecting their deliveries
+// and running dispatcher that sends them
+type Webhook interface {
+	CreateWebhookSubscription(userID int, input models.WebhookSubscriptionRequest) (models.WebhookSubscription, error)
+	GetWebhookSubscriptions(userID int) ([]models.WebhookSubscription, error)
+	DeleteWebhookSubscription(userID, id int) error
+	GetWebhookDeliveries(userID, subscriptionID int, statuses []string) ([]models.WebhookDelivery, error)
+	GetWebhookDelivery(userID, subscriptionID int, deliveryID int64) (models.WebhookDelivery, error)
+	RedeliverWebhook(userID, subscriptionID int, deliveryID int64) (models.WebhookDelivery, error)
+	RunWebhookDispatcher(ctx context.Context)
+}
+
+// Outbox defines method for running relay that publishes domain events recorded in outbox
+type Outbox interface {
+	RunOutboxRelay(ctx context.Context)
+}
+
+// Notification defines methods for managing email notification settings of user
+// and running worker that sends notifications
+type Notification interface {
+	GetNotificationSettings(userID int) (models.NotificationSettings, error)
+	UpdateNotificationSettings(userID int, input models.NotificationSettingsRequest) (models.NotificationSettings, error)
+	RunNotificationWorker(ctx context.Context)
+}
+
+// ReferralFeed defines methods for subscribing to real-time feed of referrals and referral codes
+// and running listener that feeds subscriptions
+type ReferralFeed interface {
+	SubscribeReferralFeed(userID int, lastEventID int64) (*ReferralFeedSubscription, error)
+	UnsubscribeReferralFeed(subscription *ReferralFeedSubscription)
+	RunReferralFeedListener(ctx context.Context)
+}
+
+// ReferralGraph defines methods for loading users, referral codes and referrals by many keys at once
+type ReferralGraph interface {
+	GetUsersByIDs(ids []int) (map[int]models.User, error)
+	GetReferralCodesByIDs(ids []int) (map[int]models.ReferralCode, error)
+	GetReferralCodesByReferrerIDs(ids []int, page models.GraphPage) (map[int][]models.ReferralCode, error)
+	GetReferralsByReferralCodeIDs(ids []int, page models.GraphPage) (map[int][]models.Referral, error)
+	GetReferralsByReferrerIDs(ids []int, page models.GraphPage) (map[int][]models.Referral, error)
 }
 
-// Service aggregates different services related to user authorization, referral codes, and referrals
+// Service aggregates different services related to user authorization, referral codes, referrals,
+// referral link clicks, QR codes, batches of referral codes, campaigns, referral links, referral events, rewards,
+// reward rules, payouts, referral statistics, leaderboards, fraud checks, referral exports, webhooks,
+// outbox relay, email notifications, real-time referral feed and batch loading of referral graph
 type Service struct {
 	Authorization
 	Referral
 	ReferralCode
+	ReferralClick
+	QRCode
+	ReferralCodeBatch
+	Campaign
+	ReferralLink
+	ReferralEvent
+	Reward
+	RewardRule
+	Payout
+	ReferralStats
+	Leaderboard
+	Fraud
+	ReferralExport
+	Webhook
+	Outbox
+	Notification
+	ReferralFeed
+	ReferralGraph
 }
 
 // New returns new instance of Service, initializing dependencies
-// It takes repository that holds database access logic
-func New(repo *repository.Repository, logger *logrus.Logger) *Service {
+// It takes repository that holds database access logic and application config
+func New(repo *repository.Repository, cfg *config.Config, logger *logrus.Logger) *Service {
 	authService := NewAuthService(repo.UserRepo, logger)
-	referralCodeService := NewReferralCodeService(repo.ReferralCodeRepo, authService, logger)
-	referralService := NewReferralService(repo.ReferralRepo, referralCodeService, logger)
+	codeGenerator := NewCrockfordCodeGenerator(cfg.ReferralCodeLength, cfg.ReferralCodeGroupSize)
+	campaignService := NewCampaignService(repo.CampaignRepo, cfg, logger)
+	referralCodeService := NewReferralCodeService(repo.ReferralCodeRepo, authService, campaignService, codeGenerator,
+		cfg, logger)
+	referralClickService := NewReferralClickService(repo.ReferralClickRepo, referralCodeService, cfg, logger)
+	rewardService := NewRewardService(repo.RewardRepo, repo.RewardRuleSetRepo, cfg, logger)
+	fraudService := NewFraudService(repo.FraudRepo, repo.UserRepo, rewardService, cfg, logger)
+	notificationService := NewNotificationService(repo.NotificationRepo, repo.UserRepo, newMailer(cfg, logger), cfg,
+		logger)
+	referralService := NewReferralService(repo.ReferralRepo, referralCodeService, referralClickService, rewardService,
+		fraudService, notificationService, cfg, logger)
 
 	return &Service{
-		Authorization: authService,
-		ReferralCode:  referralCodeService,
-		Referral:      referralService,
+		Authorization:     authService,
+		ReferralCode:      referralCodeService,
+		Referral:          referralService,
+		ReferralClick:     referralClickService,
+		QRCode:            NewQRCodeService(referralCodeService, logger),
+		ReferralCodeBatch: NewReferralCodeBatchService(repo.ReferralCodeBatchRepo, referralCodeService, logger),
+		Campaign:          campaignService,
+		ReferralLink:      NewReferralLinkService(repo.ReferralLinkRepo, codeGenerator, logger),
+		ReferralEvent:     NewReferralEventService(repo.ReferralEventRepo, rewardService, cfg, logger),
+		Reward:            rewardService,
+		RewardRule:        NewRewardRuleService(repo.RewardRuleSetRepo, campaignService, logger),
+		Payout:            NewPayoutService(repo.PayoutRepo, newPayoutProvider(cfg.PayoutProvider, logger), cfg, logger),
+		ReferralStats:     NewReferralStatsService(repo.ReferralStatsRepo, codeGenerator, cfg, logger),
+		Leaderboard:       NewLeaderboardService(repo.LeaderboardRepo, repo.UserRepo, cfg, logger),
+		Fraud:             fraudService,
+		ReferralExport:    NewReferralExportService(repo.ReferralRepo, codeGenerator, logger),
+		Webhook:           NewWebhookService(repo.WebhookRepo, repo.UserRepo, codeGenerator, cfg, logger),
+		Outbox:            NewOutboxService(repo.OutboxRepo, newEventPublisher(cfg, logger), cfg, logger),
+		Notification:      notificationService,
+		ReferralFeed:      NewReferralFeedService(repo.ReferralFeedRepo, logger),
+		ReferralGraph:     NewReferralGraphService(repo.ReferralGraphRepo, codeGenerator, logger),
 	}
 }
@@ -0,0 +1,473 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository"
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+var ErrInvalidWebhookSubscription = errors.New("неправильные параметры подписки на вебхуки")
+var ErrWebhookForbidden = errors.New("подписка на события всех пользователей доступна только администраторам")
+var ErrInvalidWebhookDeliveryStatus = errors.New("неизвестный статус доставки вебхука")
+
+// Headers of webhook deliveries
+const (
+	webhookSignatureHeader = "X-Webhook-Signature"
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const (
+	// webhookSecretLength is number of random bytes in signing secret of subscription
+	webhookSecretLength = 32
+	// maxWebhookURLLength limits length of endpoint URL
+	maxWebhookURLLength = 2048
+	// webhookBatchSize is number of deliveries claimed and sent at once
+	webhookBatchSize = 20
+	// webhookOutboxBatchSize is number of outbox messages turned into webhook events at once
+	webhookOutboxBatchSize = 100
+	// maxWebhookRetryDelay caps exponential delay between attempts
+	maxWebhookRetryDelay = 12 * time.Hour
+	// maxWebhookErrorLength limits length of stored error of failed attempt
+	maxWebhookErrorLength = 500
+	// webhookDeliveriesLimit limits number of deliveries in delivery log
+	webhookDeliveriesLimit = 100
+)
+
+// webhookEventTypes are event types subscriptions can filter on
+var webhookEventTypes = map[string]bool{
+	models.WebhookEventReferralCreated:       true,
+	models.WebhookEventReferralStatusChanged: true,
+	models.WebhookEventReferralCodeCreated:   true,
+	models.WebhookEventReferralCodeRevoked:   true,
+	models.WebhookEventReferralCodeRotated:   true,
+	models.WebhookEventReferralCodePaused:    true,
+	models.WebhookEventReferralCodeResumed:   true,
+}
+
+// WebhookService represents service for webhook subscriptions and for sending events to subscribed endpoints
+// Events are created from outbox messages with their deliveries, dispatcher sends due deliveries signed
+// with HMAC-SHA256 and retries failed ones with exponential delay until they run out of attempts
+type WebhookService struct {
+	repo         repository.WebhookRepo
+	userRepo     repository.UserRepo
+	generator    CodeGenerator
+	logger       *logrus.Logger
+	client       *http.Client
+	maxAttempts  int
+	retryBase    time.Duration
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+// NewWebhookService creates new instance of WebhookService with repositories, code generator formatting codes
+// in events and delivery settings from config
+func NewWebhookService(repo repository.WebhookRepo, userRepo repository.UserRepo, generator CodeGenerator,
+	cfg *config.Config, logger *logrus.Logger) *WebhookService {
+	return &WebhookService{
+		repo:         repo,
+		userRepo:     userRepo,
+		generator:    generator,
+		client:       newWebhookClient(cfg.WebhookTimeout),
+		maxAttempts:  cfg.WebhookMaxAttempts,
+		retryBase:    cfg.WebhookRetryBase,
+		timeout:      cfg.WebhookTimeout,
+		pollInterval: cfg.WebhookPollInterval,
+		logger:       logger,
+	}
+}
+
+// CreateWebhookSubscription subscribes endpoint of user to events and generates signing secret,
+// which is returned only here. Only admins may subscribe to events of all users
+func (w *WebhookService) CreateWebhookSubscription(userID int,
+	input models.WebhookSubscriptionRequest) (models.WebhookSubscription, error) {
+	w.logger.Debugf("CreateWebhookSubscription[service]: Создание подписки на вебхуки пользователем с id: %d", userID)
+
+	endpoint := strings.TrimSpace(input.URL)
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+		len(endpoint) > maxWebhookURLLength {
+		return models.WebhookSubscription{}, newFieldError(ErrInvalidWebhookSubscription, "url",
+			"url должен быть абсолютным http(s) адресом")
+	}
+
+	// Deliveries check address again on every connection, this check rejects obviously internal endpoints early
+	if err = checkWebhookHost(parsed.Hostname()); err != nil {
+		w.logger.Warnf("CreateWebhookSubscription[service]: Адрес вебхука %s отклонен: %s", endpoint, err)
+		return models.WebhookSubscription{}, newFieldError(ErrInvalidWebhookSubscription, "url",
+			"url должен указывать на публичный адрес")
+	}
+
+	events := []string{}
+	seen := make(map[string]bool)
+	for _, event := range input.Events {
+		event = strings.TrimSpace(event)
+		if !webhookEventTypes[event] {
+			return models.WebhookSubscription{}, newFieldError(ErrInvalidWebhookSubscription, "events",
+				"неизвестное событие %q", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+
+	if input.AllUsers {
+		user, err := w.userRepo.GetByID(userID)
+		if err != nil {
+			return models.WebhookSubscription{}, err
+		}
+		if !user.IsAdmin {
+			w.logger.Warnf("CreateWebhookSubscription[service]: Пользователь с id: %d не является администратором",
+				userID)
+			return models.WebhookSubscription{}, ErrWebhookForbidden
+		}
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	subscription, err := w.repo.CreateSubscription(models.WebhookSubscription{
+		UserID:   userID,
+		URL:      endpoint,
+		Events:   events,
+		AllUsers: input.AllUsers,
+		Secret:   secret,
+	})
+	if err != nil {
+		w.logger.Errorf("CreateWebhookSubscription[service]: Ошибка создания подписки: %s", err)
+		return models.WebhookSubscription{}, err
+	}
+
+	w.logger.Infof("CreateWebhookSubscription[service]: Подписка на вебхуки с id: %d создана", subscription.ID)
+	return subscription, nil
+}
+
+// GetWebhookSubscriptions retrieves subscriptions of user without their secrets
+func (w *WebhookService) GetWebhookSubscriptions(userID int) ([]models.WebhookSubscription, error) {
+	w.logger.Debugf("GetWebhookSubscriptions[service]: Получение подписок пользователя с id: %d", userID)
+
+	subscriptions, err := w.repo.GetSubscriptionsByUserID(userID)
+	if err != nil {
+		w.logger.Errorf("GetWebhookSubscriptions[service]: Ошибка при получении подписок: %s", err)
+		return nil, err
+	}
+
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	if subscriptions == nil {
+		subscriptions = []models.WebhookSubscription{}
+	}
+	return subscriptions, nil
+}
+
+// DeleteWebhookSubscription removes subscription of user with its deliveries
+func (w *WebhookService) DeleteWebhookSubscription(userID, id int) error {
+	w.logger.Debugf("DeleteWebhookSubscription[service]: Удаление подписки с id: %d", id)
+
+	if _, err := w.ownSubscription(userID, id); err != nil {
+		return err
+	}
+	return w.repo.DeleteSubscription(id)
+}
+
+// GetWebhookDeliveries retrieves latest deliveries of subscription of user,
+// non-empty statuses limit result to deliveries in these statuses
+func (w *WebhookService) GetWebhookDeliveries(userID, subscriptionID int,
+	statuses []string) ([]models.WebhookDelivery, error) {
+	w.logger.Debugf("GetWebhookDeliveries[service]: Получение доставок подписки с id: %d", subscriptionID)
+
+	for _, status := range statuses {
+		if status != models.WebhookDeliveryPending && status != models.WebhookDeliveryDelivered &&
+			status != models.WebhookDeliveryDead {
+			return nil, ErrInvalidWebhookDeliveryStatus
+		}
+	}
+
+	if _, err := w.ownSubscription(userID, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := w.repo.GetDeliveries(subscriptionID, statuses, webhookDeliveriesLimit)
+	if err != nil {
+		w.logger.Errorf("GetWebhookDeliveries[service]: Ошибка при получении доставок: %s", err)
+		return nil, err
+	}
+
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+// GetWebhookDelivery retrieves delivery of subscription of user with history of its attempts
+func (w *WebhookService) GetWebhookDelivery(userID, subscriptionID int, deliveryID int64) (models.WebhookDelivery,
+	error) {
+	w.logger.Debugf("GetWebhookDelivery[service]: Получение доставки с id: %d", deliveryID)
+
+	if _, err := w.ownSubscription(userID, subscriptionID); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return w.repo.GetDeliveryByID(subscriptionID, deliveryID)
+}
+
+// RedeliverWebhook puts delivered or dead delivery of subscription of user back into queue,
+// it is sent again with full number of attempts
+func (w *WebhookService) RedeliverWebhook(userID, subscriptionID int, deliveryID int64) (models.WebhookDelivery,
+	error) {
+	w.logger.Debugf("RedeliverWebhook[service]: Повторная отправка доставки с id: %d", deliveryID)
+
+	if _, err := w.ownSubscription(userID, subscriptionID); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	if err := w.repo.Redeliver(subscriptionID, deliveryID); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	w.logger.Infof("RedeliverWebhook[service]: Доставка с id: %d поставлена в очередь повторно", deliveryID)
+	return w.repo.GetDeliveryByID(subscriptionID, deliveryID)
+}
+
+// webhookEvent turns outbox message into webhook event of referrer owning its aggregate,
+// referral codes in event are formatted the same way as in responses of API
+func (w *WebhookService) webhookEvent(message models.OutboxMessage) (models.WebhookEvent, error) {
+	var data interface{}
+	switch message.EventType {
+	case models.OutboxEventReferralCreated:
+		data = message.Payload
+	case models.OutboxEventReferralStatusChanged:
+		var status models.OutboxReferralStatus
+		if err := json.Unmarshal(message.Payload, &status); err != nil {
+			return models.WebhookEvent{}, err
+		}
+		data = models.WebhookReferralStatus{ReferralID: status.ReferralID, ReferrerID: *message.UserID,
+			Status: status.Status}
+	case models.OutboxEventReferralCodeCreated, models.OutboxEventReferralCodeRevoked,
+		models.OutboxEventReferralCodePaused, models.OutboxEventReferralCodeResumed:
+		var code models.ReferralCode
+		if err := json.Unmarshal(message.Payload, &code); err != nil {
+			return models.WebhookEvent{}, err
+		}
+		code.Code = w.generator.Format(code.Code)
+		data = code
+	case models.OutboxEventReferralCodeRotated:
+		var rotation models.OutboxReferralCodeRotation
+		if err := json.Unmarshal(message.Payload, &rotation); err != nil {
+			return models.WebhookEvent{}, err
+		}
+		rotation.Previous.Code = w.generator.Format(rotation.Previous.Code)
+		rotation.Current.Code = w.generator.Format(rotation.Current.Code)
+		data = models.WebhookReferralCodeRotation{Previous: rotation.Previous, Current: rotation.Current}
+	default:
+		return models.WebhookEvent{}, fmt.Errorf("неизвестный тип события %s", message.EventType)
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return models.WebhookEvent{}, err
+	}
+	return models.WebhookEvent{Type: message.EventType, UserID: *message.UserID, Data: encoded}, nil
+}
+
+// enqueueEvents turns new outbox messages into webhook events in batches until there are no more new ones
+func (w *WebhookService) enqueueEvents() {
+	for {
+		handled, err := w.repo.CreateEventsFromOutbox(webhookOutboxBatchSize, w.webhookEvent)
+		if err != nil {
+			w.logger.Errorf("enqueueEvents[service]: Ошибка создания событий из outbox: %s", err)
+			return
+		}
+
+		if handled < webhookOutboxBatchSize {
+			return
+		}
+	}
+}
+
+// RunWebhookDispatcher turns new outbox messages into webhook events and sends due deliveries every poll interval
+// until ctx is done
+func (w *WebhookService) RunWebhookDispatcher(ctx context.Context) {
+	w.logger.Infof("RunWebhookDispatcher[service]: Отправка вебхуков запущена")
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.enqueueEvents()
+		w.dispatchDue()
+
+		select {
+		case <-ctx.Done():
+			w.logger.Infof("RunWebhookDispatcher[service]: Отправка вебхуков остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchDue sends due deliveries in batches until there are no more due ones
+func (w *WebhookService) dispatchDue() {
+	for {
+		// Lease outlives attempt, so delivery is never claimed again while it is being sent
+		dispatches, err := w.repo.ClaimDeliveries(webhookBatchSize, time.Now().Add(2*w.timeout+time.Minute))
+		if err != nil {
+			w.logger.Errorf("dispatchDue[service]: Ошибка при получении доставок: %s", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, dispatch := range dispatches {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w.deliver(dispatch)
+			}()
+		}
+		wg.Wait()
+
+		if len(dispatches) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// deliver sends one attempt of delivery and records its result
+// Delivery is delivered on any 2xx response, otherwise it is retried with exponential delay or is dead
+// after last attempt
+func (w *WebhookService) deliver(dispatch models.WebhookDispatch) {
+	body, err := json.Marshal(dispatch.Event)
+	if err != nil {
+		w.logger.Errorf("deliver[service]: Ошибка кодирования события с id: %d: %s", dispatch.Event.ID, err)
+		return
+	}
+
+	attempt := models.WebhookDeliveryAttempt{Attempt: dispatch.Attempt, AttemptedAt: time.Now()}
+	responseStatus, err := w.send(dispatch, body, attempt.AttemptedAt)
+	attempt.DurationMs = int(time.Since(attempt.AttemptedAt).Milliseconds())
+	if responseStatus != 0 {
+		attempt.ResponseStatus = &responseStatus
+	}
+	if err != nil {
+		message := truncate(err.Error(), maxWebhookErrorLength)
+		attempt.Error = &message
+	}
+
+	status, nextAttemptAt := models.WebhookDeliveryDelivered, time.Now()
+	if err != nil {
+		if dispatch.Attempt >= w.maxAttempts {
+			status = models.WebhookDeliveryDead
+		} else {
+			status = models.WebhookDeliveryPending
+			nextAttemptAt = nextAttemptAt.Add(w.retryDelay(dispatch.Attempt))
+		}
+	}
+
+	if err = w.repo.RecordAttempt(dispatch.DeliveryID, attempt, status, nextAttemptAt); err != nil {
+		w.logger.Errorf("deliver[service]: Ошибка сохранения попытки доставки с id: %d: %s", dispatch.DeliveryID, err)
+		return
+	}
+
+	w.logger.Infof("deliver[service]: Доставка с id: %d, попытка %d: %s", dispatch.DeliveryID, dispatch.Attempt, status)
+}
+
+// send posts event to endpoint of subscription and returns response status, non-2xx response is error
+// Response body is never stored, so endpoint can not be used to read responses of internal services
+func (w *WebhookService) send(dispatch models.WebhookDispatch, body []byte, at time.Time) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, dispatch.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "rest-refs-webhooks/1.0")
+	request.Header.Set(webhookEventHeader, dispatch.Event.Type)
+	request.Header.Set(webhookDeliveryHeader, strconv.FormatInt(dispatch.DeliveryID, 10))
+	request.Header.Set(webhookTimestampHeader, timestamp)
+	request.Header.Set(webhookSignatureHeader, "t="+timestamp+",v1="+signWebhook(dispatch.Secret, timestamp, body))
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	// Drain response so connection is reused
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("неуспешный ответ: %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// retryDelay returns delay after failed attempt: retry base doubled for every previous attempt, capped at 12 hours
+func (w *WebhookService) retryDelay(attempt int) time.Duration {
+	delay := w.retryBase
+	for i := 1; i < attempt && delay < maxWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxWebhookRetryDelay)
+}
+
+// ownSubscription retrieves subscription of user, subscription of other user is reported as not found
+func (w *WebhookService) ownSubscription(userID, id int) (models.WebhookSubscription, error) {
+	subscription, err := w.repo.GetSubscriptionByID(id)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	if subscription.UserID != userID {
+		w.logger.Warnf("ownSubscription[service]: Подписка с id: %d не принадлежит пользователю с id: %d", id, userID)
+		return models.WebhookSubscription{}, postgresql.ErrWebhookSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+// signWebhook returns hex-encoded HMAC-SHA256 of timestamp and body joined with dot, keyed by secret of subscription
+// Receivers recompute it from X-Webhook-Timestamp header and raw body and reject stale timestamps
+func signWebhook(secret, timestamp string, body []byte) string {
+	return hashWithSalt(secret, timestamp+"."+string(body))
+}
+
+// generateWebhookSecret generates random signing secret of subscription
+func generateWebhookSecret() (string, error) {
+	randomBytes := make([]byte, webhookSecretLength)
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(randomBytes), nil
+}
+
+// truncate shortens value to at most limit bytes without splitting UTF-8 characters
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return strings.ToValidUTF8(value[:limit], "")
+}
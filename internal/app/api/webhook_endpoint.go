@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+var ErrWebhookAddressForbidden = errors.New("адрес вебхука указывает на внутреннюю сеть")
+
+// webhookResolveTimeout limits time of resolving host of endpoint when subscription is created
+const webhookResolveTimeout = 5 * time.Second
+
+// forbiddenWebhookNetworks are ranges not covered by net.IP methods, which are not reachable from internet
+// or belong to infrastructure: "this" network, carrier-grade NAT, IETF protocol assignments, benchmarking,
+// NAT64 and documentation ranges
+var forbiddenWebhookNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+	"2001:db8::/32",
+)
+
+// newWebhookClient creates HTTP client for webhook deliveries
+// Every connection is checked after host is resolved, so host that resolves to internal address after
+// subscription was created (DNS rebinding) is not reached. Redirects are not followed and proxy from
+// environment is not used, so neither can lead request to internal address
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicWebhookIP(ip) {
+				return fmt.Errorf("%w: %s", ErrWebhookAddressForbidden, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkWebhookHost resolves host of endpoint and returns ErrWebhookAddressForbidden
+// if any of its addresses is not public
+func checkWebhookHost(host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicWebhookIP(ip) {
+			return ErrWebhookAddressForbidden
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+	defer cancel()
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if !isPublicWebhookIP(address.IP) {
+			return ErrWebhookAddressForbidden
+		}
+	}
+	return nil
+}
+
+// isPublicWebhookIP reports whether webhook may be sent to ip
+// Loopback, private, link-local (including cloud metadata 169.254.169.254), multicast and unspecified
+// addresses are forbidden, as well as reserved ranges of forbiddenWebhookNetworks
+func isPublicWebhookIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, network := range forbiddenWebhookNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// mustParseCIDRs parses list of CIDR ranges known at compile time
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsPublicWebhookIP(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		want bool
+	}{
+		{name: "loopback", ip: "127.0.0.1"},
+		{name: "loopback range", ip: "127.10.0.1"},
+		{name: "ipv6 loopback", ip: "::1"},
+		{name: "unspecified", ip: "0.0.0.0"},
+		{name: "ipv6 unspecified", ip: "::"},
+		{name: "rfc1918 10/8", ip: "10.0.0.1"},
+		{name: "rfc1918 172.16/12", ip: "172.16.5.4"},
+		{name: "rfc1918 172.16/12 upper bound", ip: "172.31.255.255"},
+		{name: "rfc1918 192.168/16", ip: "192.168.1.1"},
+		{name: "link-local", ip: "169.254.1.1"},
+		{name: "cloud metadata", ip: "169.254.169.254"},
+		{name: "ipv6 link-local", ip: "fe80::1"},
+		{name: "ipv6 ula", ip: "fd00::1"},
+		{name: "ipv6 ula fc00::/7", ip: "fc12:3456::1"},
+		{name: "ipv4-mapped loopback", ip: "::ffff:127.0.0.1"},
+		{name: "ipv4-mapped rfc1918", ip: "::ffff:10.0.0.1"},
+		{name: "ipv4-mapped metadata", ip: "::ffff:169.254.169.254"},
+		{name: "carrier-grade nat", ip: "100.64.0.1"},
+		{name: "multicast", ip: "224.0.0.1"},
+		{name: "ipv6 multicast", ip: "ff02::1"},
+		{name: "nat64", ip: "64:ff9b::a00:1"},
+		{name: "documentation", ip: "192.0.2.10"},
+		{name: "public", ip: "93.184.216.34", want: true},
+		{name: "public next to rfc1918", ip: "172.32.0.1", want: true},
+		{name: "ipv6 public", ip: "2606:4700:4700::1111", want: true},
+		{name: "ipv4-mapped public", ip: "::ffff:93.184.216.34", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := net.ParseIP(tt.ip)
+			if ip == nil {
+				t.Fatalf("ParseIP(%q) = nil", tt.ip)
+			}
+			if got := isPublicWebhookIP(ip); got != tt.want {
+				t.Errorf("isPublicWebhookIP(%q) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckWebhookHostRejectsInternalAddress(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "10.0.0.1", "169.254.169.254", "fd00::1", "::ffff:192.168.0.1"} {
+		if err := checkWebhookHost(host); !errors.Is(err, ErrWebhookAddressForbidden) {
+			t.Errorf("checkWebhookHost(%q) error = %v, want %v", host, err, ErrWebhookAddressForbidden)
+		}
+	}
+	if err := checkWebhookHost("93.184.216.34"); err != nil {
+		t.Errorf("checkWebhookHost(%q) error = %v, want nil", "93.184.216.34", err)
+	}
+}
+
+func TestWebhookClientRefusesLoopback(t *testing.T) {
+	reached := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		reached = true
+	}))
+	defer server.Close()
+
+	_, err := newWebhookClient(time.Second).Get(server.URL)
+	if !errors.Is(err, ErrWebhookAddressForbidden) {
+		t.Fatalf("Get(%q) error = %v, want %v", server.URL, err, ErrWebhookAddressForbidden)
+	}
+	if reached {
+		t.Error("webhook reached loopback server")
+	}
+}
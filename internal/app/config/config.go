@@ -3,32 +3,666 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var defaultHttpPort = ":8080"
 
-// Config struct holds configuration values for database url and http port
+var defaultGrpcPort = ":9090"
+
+var defaultTimeZone = "UTC"
+
+var defaultReferralCodeMaxLifetime = 365 * 24 * time.Hour
+
+var defaultReferralCodeLength = 8
+
+var defaultReferralCodeGroupSize = 4
+
+var defaultStatusRateLimit = 10
+
+var defaultLandingURL = "/"
+
+var defaultAttributionWindow = 30 * 24 * time.Hour
+
+var defaultShareBaseURL = "http://localhost:8080"
+
+var defaultRotationGracePeriod = 24 * time.Hour
+
+var defaultQualificationEvents = []string{"email_verified", "first_purchase"}
+
+var defaultRejectionEvents = []string{"fraud_detected"}
+
+var defaultReversalEvents = []string{"refund", "chargeback"}
+
+var defaultReferrerQualifiedReward = 100
+
+var defaultRefereeQualifiedReward = 50
+
+var defaultPayoutMinAmount = 1000
+
+var defaultChargebackWindow = 30 * 24 * time.Hour
+
+var defaultPayoutProvider = "fake"
+
+var defaultReferralTreeMaxDepth = 5
+
+var defaultUplineBonusPercents = []string{"10"}
+
+var defaultLeaderboardCacheTTL = time.Minute
+
+var defaultFraudReviewThreshold = 50
+
+var defaultFraudVelocityLimit = 10
+
+var defaultFraudVelocityWindow = time.Hour
+
+var defaultDisposableEmailDomains = []string{"mailinator.com", "guerrillamail.com", "sharklasers.com",
+	"10minutemail.com", "temp-mail.org", "yopmail.com", "trashmail.com", "getnada.com", "maildrop.cc",
+	"dispostable.com", "throwawaymail.com", "mintemail.com"}
+
+var defaultWebhookMaxAttempts = 8
+
+var defaultWebhookRetryBase = 30 * time.Second
+
+var defaultWebhookTimeout = 10 * time.Second
+
+var defaultWebhookPollInterval = 5 * time.Second
+
+var defaultOutboxPublisher = "log"
+
+var defaultOutboxNATSURL = "nats://localhost:4222"
+
+var defaultOutboxSubjectPrefix = "refs"
+
+var defaultOutboxNATSStream = "REFS"
+
+var defaultOutboxPollInterval = time.Second
+
+var defaultOutboxBatchSize = 100
+
+var defaultOutboxRetryBase = 5 * time.Second
+
+var defaultOutboxPublishTimeout = 10 * time.Second
+
+var defaultOutboxRetention = 7 * 24 * time.Hour
+
+var defaultMailer = "file"
+
+var defaultMailDir = "mail"
+
+var defaultSMTPAddr = "localhost:1025"
+
+var defaultMailFrom = "noreply@localhost"
+
+var defaultMailTimeout = 30 * time.Second
+
+var defaultNotificationLocale = "ru"
+
+var defaultNotificationDigestHour = 9
+
+var defaultNotificationPollInterval = 10 * time.Second
+
+var defaultNotificationMaxAttempts = 5
+
+var defaultNotificationRetryBase = time.Minute
+
+var defaultGraphQLMaxDepth = 8
+
+var defaultGraphQLMaxComplexity = 1000
+
+// payoutProviders lists supported payout providers
+var payoutProviders = []string{"fake"}
+
+// outboxPublishers lists supported publishers of outbox messages
+var outboxPublishers = []string{"log", "http", "nats"}
+
+// mailers lists supported ways of sending emails
+var mailers = []string{"smtp", "file"}
+
+// notificationLocales lists languages of notification emails
+var notificationLocales = []string{"ru", "en"}
+
+// minReferralCodeLength keeps at least three random characters besides check character
+var minReferralCodeLength = 4
+
+// Config struct holds configuration values for database url, http and grpc ports and referral code settings
 type Config struct {
 	DbUrl    string
 	HttpPort string
+	GrpcPort string
+
+	// DefaultTimeZone is used to interpret date-only expiration dates when request has no time zone
+	DefaultTimeZone *time.Location
+	// ReferralCodeMaxLifetime limits how far in the future referral code may expire
+	ReferralCodeMaxLifetime time.Duration
+	// ReferralCodeLength is total referral code length including check character
+	ReferralCodeLength int
+	// ReferralCodeGroupSize is number of characters between dashes in referral code, 0 disables grouping
+	ReferralCodeGroupSize int
+	// StatusRateLimit is number of referral code status checks allowed per minute from single IP
+	StatusRateLimit int
+	// LandingURL is where share links /r/{code} redirect after click is recorded
+	LandingURL string
+	// AttributionWindow is how long after click sign-up is attributed to clicked referral code
+	AttributionWindow time.Duration
+	// IPHashSalt is secret used to hash client IP addresses before storing them
+	IPHashSalt string
+	// ShareBaseURL is public base URL of the service used to build share links encoded in QR codes
+	ShareBaseURL string
+	// RotationGracePeriod is how long rotated referral code stays valid when request does not specify it
+	RotationGracePeriod time.Duration
+	// EventsAPIKey authenticates external systems sending referral events, empty key disables ingestion
+	EventsAPIKey string
+	// QualificationEvents must all be received for pending referral to become qualified
+	QualificationEvents []string
+	// RejectionEvents reject pending referral
+	RejectionEvents []string
+	// ReversalEvents reverse qualified referral
+	ReversalEvents []string
+	// ReferrerCreatedReward and ReferrerQualifiedReward are credited to referrer when referral is created
+	// and when it is qualified, unless campaign reward rules say otherwise
+	ReferrerCreatedReward   int64
+	ReferrerQualifiedReward int64
+	// RefereeCreatedReward and RefereeQualifiedReward are credited to referred user in the same way
+	RefereeCreatedReward   int64
+	RefereeQualifiedReward int64
+	// PayoutMinAmount is minimum amount of single payout
+	PayoutMinAmount int64
+	// ChargebackWindow is how long after status change rewards of referral are held and can not be paid out
+	ChargebackWindow time.Duration
+	// PayoutProvider is name of provider sending approved payouts
+	PayoutProvider string
+	// ReferralTreeMaxDepth limits how many levels of downline referral tree may be requested
+	ReferralTreeMaxDepth int
+	// UplineBonusPercents are percents of referrer's reward credited to referrer's referrer, next referrer up and so on
+	UplineBonusPercents []int
+	// LeaderboardCacheTTL is how long computed leaderboard is served from memory before it is computed again
+	LeaderboardCacheTTL time.Duration
+	// FraudReviewThreshold is risk score from which referral is placed into manual review queue
+	FraudReviewThreshold int
+	// FraudVelocityLimit is number of referrals per referral code within FraudVelocityWindow considered normal
+	FraudVelocityLimit  int
+	FraudVelocityWindow time.Duration
+	// DisposableEmailDomains are domains of throwaway mailboxes
+	DisposableEmailDomains []string
+	// WebhookMaxAttempts is number of attempts to send webhook delivery before it is dead
+	WebhookMaxAttempts int
+	// WebhookRetryBase is delay before first retry of webhook delivery, it doubles with every next retry
+	WebhookRetryBase time.Duration
+	// WebhookTimeout limits time of one attempt to send webhook delivery
+	WebhookTimeout time.Duration
+	// WebhookPollInterval is how often due webhook deliveries are looked up
+	WebhookPollInterval time.Duration
+	// OutboxPublisher is name of publisher relaying outbox messages: log, http or nats
+	OutboxPublisher string
+	// OutboxHTTPURL is endpoint receiving outbox messages from http publisher
+	OutboxHTTPURL string
+	// OutboxNATSURL is address of NATS server receiving outbox messages from nats publisher
+	OutboxNATSURL string
+	// OutboxSubjectPrefix is prepended to event type to build NATS subject, e.g. "refs.referral.created"
+	OutboxSubjectPrefix string
+	// OutboxNATSStream is JetStream stream storing outbox messages, it is created for subjects under prefix if missing
+	OutboxNATSStream string
+	// OutboxPollInterval is how often outbox is checked for pending messages
+	OutboxPollInterval time.Duration
+	// OutboxBatchSize is maximum number of messages relayed at once
+	OutboxBatchSize int
+	// OutboxRetryBase is delay before first retry of failed message, it doubles with every next retry
+	OutboxRetryBase time.Duration
+	// OutboxPublishTimeout limits time of publishing one batch of messages
+	OutboxPublishTimeout time.Duration
+	// OutboxRetention is how long published messages are kept in outbox
+	OutboxRetention time.Duration
+	// Mailer is how emails are sent: smtp or file, which writes them into MailDir
+	Mailer  string
+	MailDir string
+	// SMTPAddr is host:port of SMTP server, SMTPUser and SMTPPassword authenticate if set
+	SMTPAddr     string
+	SMTPUser     string
+	SMTPPassword string
+	// MailFrom is sender address of emails
+	MailFrom string
+	// MailTimeout limits time of sending one email
+	MailTimeout time.Duration
+	// NotificationLocale is language of notifications of users who did not choose one: ru or en
+	NotificationLocale string
+	// NotificationDigestHour is hour of day in DefaultTimeZone when daily digests are sent
+	NotificationDigestHour int
+	// NotificationPollInterval is how often due notifications are looked up
+	NotificationPollInterval time.Duration
+	// NotificationMaxAttempts is number of attempts to send notification before it is failed
+	NotificationMaxAttempts int
+	// NotificationRetryBase is delay before first retry of notification, it doubles with every next retry
+	NotificationRetryBase time.Duration
+	// GraphQLMaxDepth is maximum nesting of fields in GraphQL query
+	GraphQLMaxDepth int
+	// GraphQLMaxComplexity is maximum estimated cost of GraphQL query, fields of list types count for several items
+	GraphQLMaxComplexity int
 }
 
 // New creates new Config instance by reading environment variables
-// It checks if required DATABASE_URL is set; if not, it returns error
+// It checks if required DATABASE_URL and IP_HASH_SALT are set; if not, it returns error
 // If HTTP_PORT is not set, it defaults to ":8080".
+// If GRPC_PORT is not set, it defaults to ":9090".
+// If DEFAULT_TIME_ZONE is not set, it defaults to "UTC".
+// If REFERRAL_CODE_MAX_LIFETIME is not set, it defaults to one year
+// If REFERRAL_CODE_LENGTH and REFERRAL_CODE_GROUP_SIZE are not set, codes look like "ABCD-EFGH"
+// If STATUS_RATE_LIMIT is not set, it defaults to 10 requests per minute
+// If LANDING_URL is not set, it defaults to "/", if ATTRIBUTION_WINDOW is not set, it defaults to 30 days
+// If SHARE_BASE_URL is not set, it defaults to "http://localhost:8080"
+// If ROTATION_GRACE_PERIOD is not set, it defaults to 24 hours
+// If EVENTS_API_KEY is not set, referral events ingestion is disabled
+// QUALIFICATION_EVENTS, REJECTION_EVENTS and REVERSAL_EVENTS are comma-separated event types,
+// by default referral is qualified by "email_verified" and "first_purchase", rejected by "fraud_detected"
+// and reversed by "refund" or "chargeback"
+// REFERRER_REWARD_CREATED, REFERRER_REWARD_QUALIFIED, REFEREE_REWARD_CREATED and REFEREE_REWARD_QUALIFIED
+// default to 0, 100, 0 and 50
+// If PAYOUT_MIN_AMOUNT is not set, it defaults to 1000, if CHARGEBACK_WINDOW is not set, it defaults to 30 days
+// PAYOUT_PROVIDER defaults to "fake", which only logs payouts
+// If REFERRAL_TREE_MAX_DEPTH is not set, it defaults to 5
+// UPLINE_BONUS_PERCENTS are comma-separated percents per upline level, by default referrer's referrer gets 10%
+// If LEADERBOARD_CACHE_TTL is not set, it defaults to one minute
+// FRAUD_REVIEW_THRESHOLD defaults to risk score 50, FRAUD_VELOCITY_LIMIT and FRAUD_VELOCITY_WINDOW default
+// to 10 referrals per code per hour, DISPOSABLE_EMAIL_DOMAINS are comma-separated and default to well-known services
+// WEBHOOK_MAX_ATTEMPTS defaults to 8 attempts, WEBHOOK_RETRY_BASE to 30 seconds, WEBHOOK_TIMEOUT to 10 seconds
+// and WEBHOOK_POLL_INTERVAL to 5 seconds
+// OUTBOX_PUBLISHER defaults to "log", which only logs messages; "http" requires OUTBOX_HTTP_URL,
+// "nats" uses OUTBOX_NATS_URL ("nats://localhost:4222" by default), OUTBOX_SUBJECT_PREFIX ("refs" by default)
+// and JetStream stream OUTBOX_NATS_STREAM ("REFS" by default)
+// OUTBOX_POLL_INTERVAL defaults to one second, OUTBOX_BATCH_SIZE to 100 messages, OUTBOX_RETRY_BASE to 5 seconds,
+// OUTBOX_PUBLISH_TIMEOUT to 10 seconds and OUTBOX_RETENTION to 7 days
+// MAILER defaults to "file", which writes emails into MAIL_DIR ("mail" by default); "smtp" sends them to SMTP_ADDR
+// ("localhost:1025" by default) authenticating with SMTP_USER and SMTP_PASSWORD if set
+// MAIL_FROM defaults to "noreply@localhost", MAIL_TIMEOUT to 30 seconds
+// NOTIFICATION_LOCALE defaults to "ru", NOTIFICATION_DIGEST_HOUR to 9, NOTIFICATION_POLL_INTERVAL to 10 seconds,
+// NOTIFICATION_MAX_ATTEMPTS to 5 attempts and NOTIFICATION_RETRY_BASE to one minute
+// GRAPHQL_MAX_DEPTH defaults to 8 levels of fields and GRAPHQL_MAX_COMPLEXITY to 1000
 func New() (*Config, error) {
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL не задан")
 	}
 
-	httpPort := os.Getenv("HTTP_PORT")
-	if httpPort == "" {
-		httpPort = defaultHttpPort
+	// Salt is kept apart from SECRET_KEY, so rotating signing key of tokens does not change hashes of stored IPs
+	ipHashSalt := os.Getenv("IP_HASH_SALT")
+	if ipHashSalt == "" {
+		return nil, fmt.Errorf("IP_HASH_SALT не задан")
+	}
+
+	httpPort := getEnv("HTTP_PORT", defaultHttpPort)
+	grpcPort := getEnv("GRPC_PORT", defaultGrpcPort)
+
+	timeZone, err := time.LoadLocation(getEnv("DEFAULT_TIME_ZONE", defaultTimeZone))
+	if err != nil {
+		return nil, fmt.Errorf("DEFAULT_TIME_ZONE задан неверно: %w", err)
+	}
+
+	maxLifetime, err := getDuration("REFERRAL_CODE_MAX_LIFETIME", defaultReferralCodeMaxLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	codeLength, err := getInt("REFERRAL_CODE_LENGTH", defaultReferralCodeLength)
+	if err != nil {
+		return nil, err
+	}
+	if codeLength < minReferralCodeLength {
+		return nil, fmt.Errorf("REFERRAL_CODE_LENGTH не может быть меньше %d", minReferralCodeLength)
+	}
+
+	codeGroupSize, err := getInt("REFERRAL_CODE_GROUP_SIZE", defaultReferralCodeGroupSize)
+	if err != nil {
+		return nil, err
+	}
+
+	statusRateLimit, err := getInt("STATUS_RATE_LIMIT", defaultStatusRateLimit)
+	if err != nil {
+		return nil, err
+	}
+	if statusRateLimit == 0 {
+		return nil, fmt.Errorf("STATUS_RATE_LIMIT должен быть больше нуля")
+	}
+
+	attributionWindow, err := getDuration("ATTRIBUTION_WINDOW", defaultAttributionWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	rotationGracePeriod, err := getDuration("ROTATION_GRACE_PERIOD", defaultRotationGracePeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	qualificationEvents := getList("QUALIFICATION_EVENTS", defaultQualificationEvents)
+	if len(qualificationEvents) == 0 {
+		return nil, fmt.Errorf("QUALIFICATION_EVENTS не может быть пустым")
+	}
+
+	referrerCreatedReward, err := getInt("REFERRER_REWARD_CREATED", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	referrerQualifiedReward, err := getInt("REFERRER_REWARD_QUALIFIED", defaultReferrerQualifiedReward)
+	if err != nil {
+		return nil, err
+	}
+
+	refereeCreatedReward, err := getInt("REFEREE_REWARD_CREATED", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	refereeQualifiedReward, err := getInt("REFEREE_REWARD_QUALIFIED", defaultRefereeQualifiedReward)
+	if err != nil {
+		return nil, err
+	}
+
+	payoutMinAmount, err := getInt("PAYOUT_MIN_AMOUNT", defaultPayoutMinAmount)
+	if err != nil {
+		return nil, err
+	}
+	if payoutMinAmount == 0 {
+		return nil, fmt.Errorf("PAYOUT_MIN_AMOUNT должен быть больше нуля")
+	}
+
+	chargebackWindow, err := getDuration("CHARGEBACK_WINDOW", defaultChargebackWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	payoutProvider := getEnv("PAYOUT_PROVIDER", defaultPayoutProvider)
+	if !contains(payoutProviders, payoutProvider) {
+		return nil, fmt.Errorf("PAYOUT_PROVIDER задан неверно: %q", payoutProvider)
+	}
+
+	referralTreeMaxDepth, err := getInt("REFERRAL_TREE_MAX_DEPTH", defaultReferralTreeMaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	if referralTreeMaxDepth == 0 {
+		return nil, fmt.Errorf("REFERRAL_TREE_MAX_DEPTH должен быть больше нуля")
+	}
+
+	var uplineBonusPercents []int
+	for _, item := range getList("UPLINE_BONUS_PERCENTS", defaultUplineBonusPercents) {
+		percent, err := strconv.Atoi(item)
+		if err != nil || percent < 0 || percent > 100 {
+			return nil, fmt.Errorf("UPLINE_BONUS_PERCENTS задан неверно: %q", item)
+		}
+		uplineBonusPercents = append(uplineBonusPercents, percent)
+	}
+
+	leaderboardCacheTTL, err := getDuration("LEADERBOARD_CACHE_TTL", defaultLeaderboardCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	fraudReviewThreshold, err := getInt("FRAUD_REVIEW_THRESHOLD", defaultFraudReviewThreshold)
+	if err != nil {
+		return nil, err
+	}
+	if fraudReviewThreshold == 0 || fraudReviewThreshold > 100 {
+		return nil, fmt.Errorf("FRAUD_REVIEW_THRESHOLD должен быть от 1 до 100")
+	}
+
+	fraudVelocityLimit, err := getInt("FRAUD_VELOCITY_LIMIT", defaultFraudVelocityLimit)
+	if err != nil {
+		return nil, err
+	}
+	if fraudVelocityLimit == 0 {
+		return nil, fmt.Errorf("FRAUD_VELOCITY_LIMIT должен быть больше нуля")
+	}
+
+	fraudVelocityWindow, err := getDuration("FRAUD_VELOCITY_WINDOW", defaultFraudVelocityWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookMaxAttempts, err := getInt("WEBHOOK_MAX_ATTEMPTS", defaultWebhookMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if webhookMaxAttempts == 0 {
+		return nil, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS должен быть больше нуля")
+	}
+
+	webhookRetryBase, err := getDuration("WEBHOOK_RETRY_BASE", defaultWebhookRetryBase)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookTimeout, err := getDuration("WEBHOOK_TIMEOUT", defaultWebhookTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookPollInterval, err := getDuration("WEBHOOK_POLL_INTERVAL", defaultWebhookPollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxPublisher := getEnv("OUTBOX_PUBLISHER", defaultOutboxPublisher)
+	if !contains(outboxPublishers, outboxPublisher) {
+		return nil, fmt.Errorf("OUTBOX_PUBLISHER задан неверно: %q", outboxPublisher)
+	}
+
+	outboxHTTPURL := os.Getenv("OUTBOX_HTTP_URL")
+	if outboxPublisher == "http" && outboxHTTPURL == "" {
+		return nil, fmt.Errorf("OUTBOX_HTTP_URL не задан")
+	}
+
+	outboxPollInterval, err := getDuration("OUTBOX_POLL_INTERVAL", defaultOutboxPollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxBatchSize, err := getInt("OUTBOX_BATCH_SIZE", defaultOutboxBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	if outboxBatchSize == 0 {
+		return nil, fmt.Errorf("OUTBOX_BATCH_SIZE должен быть больше нуля")
+	}
+
+	outboxRetryBase, err := getDuration("OUTBOX_RETRY_BASE", defaultOutboxRetryBase)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxPublishTimeout, err := getDuration("OUTBOX_PUBLISH_TIMEOUT", defaultOutboxPublishTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxRetention, err := getDuration("OUTBOX_RETENTION", defaultOutboxRetention)
+	if err != nil {
+		return nil, err
+	}
+
+	mailer := getEnv("MAILER", defaultMailer)
+	if !contains(mailers, mailer) {
+		return nil, fmt.Errorf("MAILER задан неверно: %q", mailer)
+	}
+
+	mailTimeout, err := getDuration("MAIL_TIMEOUT", defaultMailTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	notificationLocale := getEnv("NOTIFICATION_LOCALE", defaultNotificationLocale)
+	if !contains(notificationLocales, notificationLocale) {
+		return nil, fmt.Errorf("NOTIFICATION_LOCALE задан неверно: %q", notificationLocale)
+	}
+
+	notificationDigestHour, err := getInt("NOTIFICATION_DIGEST_HOUR", defaultNotificationDigestHour)
+	if err != nil {
+		return nil, err
+	}
+	if notificationDigestHour > 23 {
+		return nil, fmt.Errorf("NOTIFICATION_DIGEST_HOUR должен быть от 0 до 23")
+	}
+
+	notificationPollInterval, err := getDuration("NOTIFICATION_POLL_INTERVAL", defaultNotificationPollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	notificationMaxAttempts, err := getInt("NOTIFICATION_MAX_ATTEMPTS", defaultNotificationMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if notificationMaxAttempts == 0 {
+		return nil, fmt.Errorf("NOTIFICATION_MAX_ATTEMPTS должен быть больше нуля")
+	}
+
+	notificationRetryBase, err := getDuration("NOTIFICATION_RETRY_BASE", defaultNotificationRetryBase)
+	if err != nil {
+		return nil, err
+	}
+
+	graphQLMaxDepth, err := getInt("GRAPHQL_MAX_DEPTH", defaultGraphQLMaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	if graphQLMaxDepth == 0 {
+		return nil, fmt.Errorf("GRAPHQL_MAX_DEPTH должен быть больше нуля")
+	}
+
+	graphQLMaxComplexity, err := getInt("GRAPHQL_MAX_COMPLEXITY", defaultGraphQLMaxComplexity)
+	if err != nil {
+		return nil, err
+	}
+	if graphQLMaxComplexity == 0 {
+		return nil, fmt.Errorf("GRAPHQL_MAX_COMPLEXITY должен быть больше нуля")
 	}
 
 	return &Config{
-		DbUrl:    dbURL,
-		HttpPort: httpPort,
+		DbUrl:                    dbURL,
+		HttpPort:                 httpPort,
+		GrpcPort:                 grpcPort,
+		DefaultTimeZone:          timeZone,
+		ReferralCodeMaxLifetime:  maxLifetime,
+		ReferralCodeLength:       codeLength,
+		ReferralCodeGroupSize:    codeGroupSize,
+		StatusRateLimit:          statusRateLimit,
+		LandingURL:               getEnv("LANDING_URL", defaultLandingURL),
+		AttributionWindow:        attributionWindow,
+		IPHashSalt:               ipHashSalt,
+		ShareBaseURL:             getEnv("SHARE_BASE_URL", defaultShareBaseURL),
+		RotationGracePeriod:      rotationGracePeriod,
+		EventsAPIKey:             os.Getenv("EVENTS_API_KEY"),
+		QualificationEvents:      qualificationEvents,
+		RejectionEvents:          getList("REJECTION_EVENTS", defaultRejectionEvents),
+		ReversalEvents:           getList("REVERSAL_EVENTS", defaultReversalEvents),
+		ReferrerCreatedReward:    int64(referrerCreatedReward),
+		ReferrerQualifiedReward:  int64(referrerQualifiedReward),
+		RefereeCreatedReward:     int64(refereeCreatedReward),
+		RefereeQualifiedReward:   int64(refereeQualifiedReward),
+		PayoutMinAmount:          int64(payoutMinAmount),
+		ChargebackWindow:         chargebackWindow,
+		PayoutProvider:           payoutProvider,
+		ReferralTreeMaxDepth:     referralTreeMaxDepth,
+		UplineBonusPercents:      uplineBonusPercents,
+		LeaderboardCacheTTL:      leaderboardCacheTTL,
+		FraudReviewThreshold:     fraudReviewThreshold,
+		FraudVelocityLimit:       fraudVelocityLimit,
+		FraudVelocityWindow:      fraudVelocityWindow,
+		DisposableEmailDomains:   getList("DISPOSABLE_EMAIL_DOMAINS", defaultDisposableEmailDomains),
+		WebhookMaxAttempts:       webhookMaxAttempts,
+		WebhookRetryBase:         webhookRetryBase,
+		WebhookTimeout:           webhookTimeout,
+		WebhookPollInterval:      webhookPollInterval,
+		OutboxPublisher:          outboxPublisher,
+		OutboxHTTPURL:            outboxHTTPURL,
+		OutboxNATSURL:            getEnv("OUTBOX_NATS_URL", defaultOutboxNATSURL),
+		OutboxSubjectPrefix:      getEnv("OUTBOX_SUBJECT_PREFIX", defaultOutboxSubjectPrefix),
+		OutboxNATSStream:         getEnv("OUTBOX_NATS_STREAM", defaultOutboxNATSStream),
+		OutboxPollInterval:       outboxPollInterval,
+		OutboxBatchSize:          outboxBatchSize,
+		OutboxRetryBase:          outboxRetryBase,
+		OutboxPublishTimeout:     outboxPublishTimeout,
+		OutboxRetention:          outboxRetention,
+		Mailer:                   mailer,
+		MailDir:                  getEnv("MAIL_DIR", defaultMailDir),
+		SMTPAddr:                 getEnv("SMTP_ADDR", defaultSMTPAddr),
+		SMTPUser:                 os.Getenv("SMTP_USER"),
+		SMTPPassword:             os.Getenv("SMTP_PASSWORD"),
+		MailFrom:                 getEnv("MAIL_FROM", defaultMailFrom),
+		MailTimeout:              mailTimeout,
+		NotificationLocale:       notificationLocale,
+		NotificationDigestHour:   notificationDigestHour,
+		NotificationPollInterval: notificationPollInterval,
+		NotificationMaxAttempts:  notificationMaxAttempts,
+		NotificationRetryBase:    notificationRetryBase,
+		GraphQLMaxDepth:          graphQLMaxDepth,
+		GraphQLMaxComplexity:     graphQLMaxComplexity,
 	}, nil
 }
+
+// getEnv returns value of environment variable or fallback if variable is not set
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getDuration parses environment variable as time.Duration (e.g. "72h")
+// It returns fallback if variable is not set and error if value is malformed or not positive
+func getDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("%s задан неверно: %q", key, value)
+	}
+	return duration, nil
+}
+
+// getInt parses environment variable as non-negative integer
+// It returns fallback if variable is not set and error if value is malformed
+func getInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("%s задан неверно: %q", key, value)
+	}
+	return number, nil
+}
+
+// getList parses environment variable as comma-separated list, empty items are skipped
+// It returns fallback if variable is not set
+func getList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// contains reports whether list contains value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+var (
+	ErrQueryTooDeep    = errors.New("запрос слишком глубокий")
+	ErrQueryTooComplex = errors.New("запрос слишком сложный")
+	ErrInvalidPage     = errors.New("неправильная страница списка")
+)
+
+// listComplexityFactor is number of items field of list type without page arguments is assumed to return
+const listComplexityFactor = 10
+
+// Page sizes of list fields with first and after arguments, first is never above maxPageSize
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// fieldComplexity is cost of fields computed by their own database queries, other fields cost 1
+var fieldComplexity = map[string]int{
+	"User.stats":          10,
+	"User.campaign_stats": 5,
+}
+
+// queryLimits measures depth and complexity of query
+// Depth is number of nested levels of fields. Complexity is sum of costs of fields, where selection
+// of field of list type counts listComplexityFactor times, or as many times as its first argument allows.
+// Introspection fields are not counted
+type queryLimits struct {
+	schema        *graphql.Schema
+	variables     map[string]interface{}
+	fragments     map[string]*ast.FragmentDefinition
+	measured      map[string]measure
+	maxComplexity int
+}
+
+// measure is depth and complexity of selection set
+type measure struct {
+	depth      int
+	complexity int
+}
+
+// checkLimits returns error if any operation of validated document is deeper or more complex than allowed
+// Variables are values of variables of request, they give page sizes passed as variables
+func checkLimits(schema *graphql.Schema, document *ast.Document, variables map[string]interface{},
+	maxDepth, maxComplexity int) error {
+	limits := &queryLimits{
+		schema:        schema,
+		variables:     variables,
+		fragments:     make(map[string]*ast.FragmentDefinition),
+		measured:      make(map[string]measure),
+		maxComplexity: maxComplexity,
+	}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			limits.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok || operation.Operation != ast.OperationTypeQuery {
+			continue
+		}
+
+		m := limits.selectionSet(schema.QueryType(), operation.SelectionSet)
+		if m.depth > maxDepth {
+			return fmt.Errorf("%w: глубина %d превышает максимально допустимую %d", ErrQueryTooDeep, m.depth, maxDepth)
+		}
+		if m.complexity > maxComplexity {
+			return fmt.Errorf("%w: сложность превышает максимально допустимую %d", ErrQueryTooComplex, maxComplexity)
+		}
+	}
+	return nil
+}
+
+// selectionSet measures selection set of object type
+func (l *queryLimits) selectionSet(parent *graphql.Object, set *ast.SelectionSet) measure {
+	var total measure
+	if parent == nil || set == nil {
+		return total
+	}
+
+	for _, selection := range set.Selections {
+		var m measure
+		switch selection := selection.(type) {
+		case *ast.Field:
+			m = l.field(parent, selection)
+		case *ast.InlineFragment:
+			m = l.selectionSet(l.fragmentType(parent, selection.TypeCondition), selection.SelectionSet)
+		case *ast.FragmentSpread:
+			m = l.fragmentSpread(selection.Name.Value)
+		}
+
+		total.depth = max(total.depth, m.depth)
+		total.complexity = l.capped(total.complexity + m.complexity)
+	}
+	return total
+}
+
+// field measures field of object type with its selection set
+func (l *queryLimits) field(parent *graphql.Object, field *ast.Field) measure {
+	name := field.Name.Value
+	if strings.HasPrefix(name, "__") {
+		return measure{}
+	}
+
+	definition, ok := parent.Fields()[name]
+	if !ok {
+		return measure{}
+	}
+
+	cost, ok := fieldComplexity[parent.Name()+"."+name]
+	if !ok {
+		cost = 1
+	}
+
+	// Unwrap type of field, counting lists
+	listSize := listComplexityFactor
+	if hasPageArguments(definition) {
+		listSize = l.pageSize(field)
+	}
+	factor := 1
+	fieldType := definition.Type
+	for {
+		if nonNull, ok := fieldType.(*graphql.NonNull); ok {
+			fieldType = nonNull.OfType
+			continue
+		}
+		if list, ok := fieldType.(*graphql.List); ok {
+			factor = l.capped(factor * listSize)
+			fieldType = list.OfType
+			continue
+		}
+		break
+	}
+
+	var children measure
+	if object, ok := fieldType.(*graphql.Object); ok {
+		children = l.selectionSet(object, field.SelectionSet)
+	}
+
+	return measure{
+		depth:      children.depth + 1,
+		complexity: l.capped(cost + factor*children.complexity),
+	}
+}
+
+// hasPageArguments reports whether field is list field with first and after arguments
+func hasPageArguments(definition *graphql.FieldDefinition) bool {
+	for _, argument := range definition.Args {
+		if argument.Name() == "first" {
+			return true
+		}
+	}
+	return false
+}
+
+// pageSize returns number of items field with first argument returns at most
+// Invalid first is rejected by resolver, it is counted as maxPageSize
+func (l *queryLimits) pageSize(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+
+		var first int
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			parsed, err := strconv.Atoi(value.Value)
+			if err != nil {
+				return maxPageSize
+			}
+			first = parsed
+		case *ast.Variable:
+			switch variable := l.variables[value.Name.Value].(type) {
+			case nil:
+				return defaultPageSize
+			case float64:
+				first = int(variable)
+			case int:
+				first = variable
+			default:
+				return maxPageSize
+			}
+		default:
+			return maxPageSize
+		}
+
+		if first < 1 || first > maxPageSize {
+			return maxPageSize
+		}
+		return first
+	}
+	return defaultPageSize
+}
+
+// fragmentSpread measures named fragment once, every next spread of it reuses the result
+func (l *queryLimits) fragmentSpread(name string) measure {
+	if m, ok := l.measured[name]; ok {
+		return m
+	}
+	fragment, ok := l.fragments[name]
+	if !ok {
+		return measure{}
+	}
+
+	// Validated document has no fragment cycles, entry guards against them anyway
+	l.measured[name] = measure{}
+	m := l.selectionSet(l.fragmentType(nil, fragment.TypeCondition), fragment.SelectionSet)
+	l.measured[name] = m
+	return m
+}
+
+// fragmentType returns object type of fragment type condition, fragment without condition has type of parent
+func (l *queryLimits) fragmentType(parent *graphql.Object, condition *ast.Named) *graphql.Object {
+	if condition == nil {
+		return parent
+	}
+	object, _ := l.schema.Type(condition.Name.Value).(*graphql.Object)
+	return object
+}
+
+// capped limits complexity to just above maximum, so that huge queries do not overflow it
+func (l *queryLimits) capped(complexity int) int {
+	return min(complexity, l.maxComplexity+1)
+}
@@ -0,0 +1,92 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+)
+
+// deepQuery is 6 levels deep: me, referrals, referrer, referrals, referrer, id
+const deepQuery = `{ me { referrals { referrer { referrals { referrer { id } } } } } }`
+
+func testSchema(t *testing.T) *graphql.Schema {
+	t.Helper()
+
+	schema, err := (&Server{}).newSchema()
+	if err != nil {
+		t.Fatalf("newSchema() error = %v", err)
+	}
+	return &schema
+}
+
+func TestCheckLimits(t *testing.T) {
+	schema := testSchema(t)
+
+	tests := []struct {
+		name          string
+		query         string
+		variables     map[string]interface{}
+		maxDepth      int
+		maxComplexity int
+		err           error
+	}{
+		{name: "within limits", query: `{ me { id referrals(first: 5) { id } } }`, maxDepth: 3, maxComplexity: 100},
+		{name: "depth equal to limit", query: deepQuery, maxDepth: 6, maxComplexity: 1_000_000},
+		{name: "too deep", query: deepQuery, maxDepth: 5, maxComplexity: 1_000_000, err: ErrQueryTooDeep},
+		{name: "too deep through fragments", query: `{ me { ...codes } }
+			fragment codes on User { referral_codes { referrals { referrer { ...referrals } } } }
+			fragment referrals on User { referrals { id } }`,
+			maxDepth: 5, maxComplexity: 1_000_000, err: ErrQueryTooDeep},
+		{name: "too deep through inline fragment", query: `{ me { ... on User { referrals { referrer { id } } } } }`,
+			maxDepth: 3, maxComplexity: 1_000_000, err: ErrQueryTooDeep},
+		{name: "introspection is not counted", query: `{ __schema { types { fields { type { name } } } } }`,
+			maxDepth: 1, maxComplexity: 1},
+		{name: "too complex", query: `{ me { referrals(first: 100) { referrer { stats { from } } } } }`,
+			maxDepth: 10, maxComplexity: 1000, err: ErrQueryTooComplex},
+		{name: "page size from variable", query: `query($first: Int) { me { referrals(first: $first) { id } } }`,
+			variables: map[string]interface{}{"first": float64(100)}, maxDepth: 10, maxComplexity: 100,
+			err: ErrQueryTooComplex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if validation := graphql.ValidateDocument(schema, document, nil); !validation.IsValid {
+				t.Fatalf("ValidateDocument() errors = %v", validation.Errors)
+			}
+
+			err = checkLimits(schema, document, tt.variables, tt.maxDepth, tt.maxComplexity)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("checkLimits() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestExecuteRejectsDeepQuery(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	// Service has no implementations, query reaching resolvers would panic
+	server := New(api.Service{}, &config.Config{GraphQLMaxDepth: 5, GraphQLMaxComplexity: 1_000_000}, logger)
+	result := server.Execute(context.Background(), models.GraphQLRequest{Query: deepQuery})
+
+	if result.Data != nil {
+		t.Errorf("Execute() data = %v, want nil", result.Data)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, ErrQueryTooDeep.Error()) {
+		t.Errorf("Execute() errors = %v, want %q", result.Errors, ErrQueryTooDeep)
+	}
+}
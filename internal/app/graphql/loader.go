@@ -0,0 +1,181 @@
+package graphql
+
+import (
+	"errors"
+	"sync"
+
+	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/models"
+)
+
+// loader batches keys requested while one level of query is resolved into single fetch
+// Resolvers call load, which only records key, and return thunk; executor calls thunks after all resolvers
+// of the level returned, so the first thunk fetches all recorded keys at once. Loaded values are kept
+// for the rest of the request, so every key is fetched at most once
+type loader[K comparable, V any] struct {
+	fetch   func(keys []K) (map[K]V, error)
+	failure string
+
+	mu      sync.Mutex
+	pending *loaderBatch[K, V]
+	batches map[K]*loaderBatch[K, V]
+}
+
+// loaderBatch is set of keys fetched together
+type loaderBatch[K comparable, V any] struct {
+	keys   []K
+	once   sync.Once
+	values map[K]V
+	err    error
+}
+
+// newLoader creates new loader, failure is message returned to client instead of error of fetch
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error), failure string) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		failure: failure,
+		batches: make(map[K]*loaderBatch[K, V]),
+	}
+}
+
+// load records key into pending batch and returns thunk returning value of key and whether it exists
+func (l *loader[K, V]) load(key K) func() (V, bool, error) {
+	l.mu.Lock()
+	batch, ok := l.batches[key]
+	if !ok {
+		if l.pending == nil {
+			l.pending = &loaderBatch[K, V]{}
+		}
+		batch = l.pending
+		batch.keys = append(batch.keys, key)
+		l.batches[key] = batch
+	}
+	l.mu.Unlock()
+
+	return func() (V, bool, error) {
+		batch.once.Do(func() {
+			// Keys recorded from now on go into next batch
+			l.mu.Lock()
+			if l.pending == batch {
+				l.pending = nil
+			}
+			l.mu.Unlock()
+
+			batch.values, batch.err = l.fetch(batch.keys)
+			if batch.err != nil {
+				batch.err = errors.New(l.failure)
+			}
+		})
+
+		value, ok := batch.values[key]
+		return value, ok, batch.err
+	}
+}
+
+// pagedKey is key of list loader, the same list with different pages are different keys
+type pagedKey struct {
+	id   int
+	page models.GraphPage
+}
+
+// pagedFetch turns fetch of one page of lists of many ids into fetch of paged keys,
+// keys with the same page are fetched together
+func pagedFetch[V any](fetch func(ids []int, page models.GraphPage) (map[int]V, error)) func(
+	keys []pagedKey) (map[pagedKey]V, error) {
+	return func(keys []pagedKey) (map[pagedKey]V, error) {
+		idsByPage := make(map[models.GraphPage][]int)
+		for _, key := range keys {
+			idsByPage[key.page] = append(idsByPage[key.page], key.id)
+		}
+
+		values := make(map[pagedKey]V, len(keys))
+		for page, ids := range idsByPage {
+			byID, err := fetch(ids, page)
+			if err != nil {
+				return nil, err
+			}
+			for id, value := range byID {
+				values[pagedKey{id: id, page: page}] = value
+			}
+		}
+		return values, nil
+	}
+}
+
+// memo keeps value computed for key for the rest of the request, so field repeated under aliases
+// or reached through several paths is computed once
+type memo[K comparable, V any] struct {
+	compute func(key K) (V, error)
+
+	mu      sync.Mutex
+	entries map[K]*memoEntry[V]
+}
+
+// memoEntry is value of one key of memo
+type memoEntry[V any] struct {
+	once  sync.Once
+	value V
+	err   error
+}
+
+// newMemo creates new memo on top of compute
+func newMemo[K comparable, V any](compute func(key K) (V, error)) *memo[K, V] {
+	return &memo[K, V]{
+		compute: compute,
+		entries: make(map[K]*memoEntry[V]),
+	}
+}
+
+// get returns value of key, computing it on first call
+func (m *memo[K, V]) get(key K) (V, error) {
+	m.mu.Lock()
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &memoEntry[V]{}
+		m.entries[key] = entry
+	}
+	m.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.value, entry.err = m.compute(key)
+	})
+	return entry.value, entry.err
+}
+
+// statsKey is user and arguments of statistics field
+type statsKey struct {
+	userID      int
+	from        string
+	to          string
+	granularity string
+}
+
+// loaders are loaders of one request
+type loaders struct {
+	users                   *loader[int, models.User]
+	referralCodes           *loader[int, models.ReferralCode]
+	referralCodesByReferrer *loader[pagedKey, []models.ReferralCode]
+	referralsByCode         *loader[pagedKey, []models.Referral]
+	referralsByReferrer     *loader[pagedKey, []models.Referral]
+	stats                   *memo[statsKey, models.ReferralStatsResponse]
+}
+
+// newLoaders creates loaders of one request on top of service
+func newLoaders(service api.Service) *loaders {
+	return &loaders{
+		users:         newLoader(service.GetUsersByIDs, "Ошибка получения пользователей"),
+		referralCodes: newLoader(service.GetReferralCodesByIDs, "Ошибка получения реферальных кодов"),
+		referralCodesByReferrer: newLoader(pagedFetch(service.GetReferralCodesByReferrerIDs),
+			"Ошибка получения реферальных кодов"),
+		referralsByCode:     newLoader(pagedFetch(service.GetReferralsByReferralCodeIDs), "Ошибка получения рефералов"),
+		referralsByReferrer: newLoader(pagedFetch(service.GetReferralsByReferrerIDs), "Ошибка получения рефералов"),
+		stats: newMemo(func(key statsKey) (models.ReferralStatsResponse, error) {
+			return service.GetReferralStats(models.ReferralStatsOptions{
+				ReferrerID:  &key.userID,
+				From:        key.from,
+				To:          key.to,
+				Granularity: key.granularity,
+			})
+		}),
+	}
+}
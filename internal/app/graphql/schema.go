@@ -0,0 +1,417 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/models"
+)
+
+// newSchema builds schema of users, referral codes and referrals
+// Field names are the same as in JSON of REST API
+func (s *Server) newSchema() (graphql.Schema, error) {
+	var userType, referralCodeType, referralType *graphql.Object
+
+	referralStatsTotalsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ReferralStatsTotals",
+		Fields: graphql.Fields{
+			"clicks":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"sign_ups":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"pending":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"qualified": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"rejected":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"reversed":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	referralConversionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ReferralConversion",
+		Fields: graphql.Fields{
+			"click_to_sign_up":     &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"sign_up_to_qualified": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"click_to_qualified":   &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+
+	referralStatsPointType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ReferralStatsPoint",
+		Fields: graphql.Fields{
+			"period_start": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"clicks":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"sign_ups":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"qualified":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	referralCodeStatsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ReferralCodeStats",
+		Fields: graphql.Fields{
+			"referral_code_id": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"code":             &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"campaign_id":      &graphql.Field{Type: graphql.Int},
+			"clicks":           &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"sign_ups":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"qualified":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"conversion":       &graphql.Field{Type: graphql.NewNonNull(referralConversionType)},
+		},
+	})
+
+	referralStatsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ReferralStats",
+		Fields: graphql.Fields{
+			"from":        &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"to":          &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"granularity": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"totals":      &graphql.Field{Type: graphql.NewNonNull(referralStatsTotalsType)},
+			"conversion":  &graphql.Field{Type: graphql.NewNonNull(referralConversionType)},
+			"series":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(referralStatsPointType)))},
+			"codes":       &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(referralCodeStatsType)))},
+		},
+	})
+
+	campaignStatsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "CampaignReferralStats",
+		Fields: graphql.Fields{
+			"campaign_id":     &graphql.Field{Type: graphql.Int},
+			"campaign_name":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"codes_count":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"referrals_count": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"email":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"display_name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"is_admin":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+				"created_at":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"referral_codes": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(referralCodeType))),
+					Description: "Referral codes of user from newest to oldest, including paused, expired and rotated ones",
+					Args:        pageArguments(),
+					Resolve:     s.resolveUserReferralCodes,
+				},
+				"referrals": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(referralType))),
+					Description: "Referrals of user from newest to oldest",
+					Args:        pageArguments(),
+					Resolve:     s.resolveUserReferrals,
+				},
+				"stats": &graphql.Field{
+					Type:        graphql.NewNonNull(referralStatsType),
+					Description: "Statistics of referrals of user, the same as GET /referral/stats",
+					Args: graphql.FieldConfigArgument{
+						"from":        &graphql.ArgumentConfig{Type: graphql.String},
+						"to":          &graphql.ArgumentConfig{Type: graphql.String},
+						"granularity": &graphql.ArgumentConfig{Type: graphql.String},
+					},
+					Resolve: s.resolveUserStats,
+				},
+				"campaign_stats": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(campaignStatsType))),
+					Description: "Codes and referrals counts of user grouped by campaign",
+					Resolve:     s.resolveUserCampaignStats,
+				},
+			}
+		}),
+	})
+
+	referralCodeType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "ReferralCode",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":            &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"code":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"expires_at":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"referrer_id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"paused_at":     &graphql.Field{Type: graphql.DateTime},
+				"max_uses":      &graphql.Field{Type: graphql.Int},
+				"uses_count":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"campaign_id":   &graphql.Field{Type: graphql.Int},
+				"link_id":       &graphql.Field{Type: graphql.Int},
+				"superseded_by": &graphql.Field{Type: graphql.Int},
+				"rotated_at":    &graphql.Field{Type: graphql.DateTime},
+				"created_at":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"updated_at":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"referrer": &graphql.Field{
+					Type:    graphql.NewNonNull(userType),
+					Resolve: s.resolveReferralCodeReferrer,
+				},
+				"referrals": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(referralType))),
+					Description: "Referrals registered with code from newest to oldest",
+					Args:        pageArguments(),
+					Resolve:     s.resolveReferralCodeReferrals,
+				},
+			}
+		}),
+	})
+
+	referralType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Referral",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"email": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"referral_code_id": &graphql.Field{
+					Type:        graphql.Int,
+					Description: "ID of referral code, null once code is deleted",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						referral := p.Source.(models.Referral)
+						if referral.ReferralCodeID == 0 {
+							return nil, nil
+						}
+						return referral.ReferralCodeID, nil
+					},
+				},
+				"referrer_id":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"user_id":           &graphql.Field{Type: graphql.Int},
+				"campaign_id":       &graphql.Field{Type: graphql.Int},
+				"status":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"status_changed_at": &graphql.Field{Type: graphql.DateTime},
+				"created_at":        &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"referral_code": &graphql.Field{
+					Type:        referralCodeType,
+					Description: "Referral code referral registered with, null once code is deleted",
+					Resolve:     s.resolveReferralReferralCode,
+				},
+				"referrer": &graphql.Field{
+					Type:    graphql.NewNonNull(userType),
+					Resolve: s.resolveReferralReferrer,
+				},
+			}
+		}),
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "Authenticated user",
+				Resolve:     s.resolveMe,
+			},
+			"user": &graphql.Field{
+				Type:        userType,
+				Description: "User with id, only administrators can query other users",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: s.resolveUser,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// pageArguments returns arguments of list field read by pageArgs
+func pageArguments() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{
+			Type:        graphql.Int,
+			Description: fmt.Sprintf("Number of items, from 1 to %d, %d by default", maxPageSize, defaultPageSize),
+		},
+		"after": &graphql.ArgumentConfig{
+			Type:        graphql.Int,
+			Description: "ID of the last item of previous page",
+		},
+	}
+}
+
+// pageArgs returns page of list field from its first and after arguments
+func pageArgs(p graphql.ResolveParams) (models.GraphPage, error) {
+	page := models.GraphPage{First: defaultPageSize}
+	if first, ok := p.Args["first"].(int); ok {
+		if first < 1 || first > maxPageSize {
+			return page, fmt.Errorf("%w: first должен быть от 1 до %d", ErrInvalidPage, maxPageSize)
+		}
+		page.First = first
+	}
+	if after, ok := p.Args["after"].(int); ok {
+		page.After = after
+	}
+	return page, nil
+}
+
+// resolveMe resolves authenticated user
+func (s *Server) resolveMe(p graphql.ResolveParams) (interface{}, error) {
+	userID, ok := p.Context.Value("UserID").(int)
+	if !ok {
+		return nil, errors.New("Ошибка аутентификации")
+	}
+
+	return s.loadUser(p, userID), nil
+}
+
+// resolveUser resolves user with id, which is the authenticated user or any user for administrators
+func (s *Server) resolveUser(p graphql.ResolveParams) (interface{}, error) {
+	userID, ok := p.Context.Value("UserID").(int)
+	if !ok {
+		return nil, errors.New("Ошибка аутентификации")
+	}
+
+	id := p.Args["id"].(int)
+	if id != userID {
+		// Admin flag is read from database, so revoked rights take effect without reissuing token
+		user, err := s.service.GetUserByID(userID)
+		if err != nil {
+			return nil, errors.New("Проблема на сервере")
+		}
+		if !user.IsAdmin {
+			s.logger.Warnf("resolveUser[graphql]: Пользователь с id: %d не является администратором", userID)
+			return nil, errors.New("Недостаточно прав")
+		}
+	}
+
+	return s.loadUser(p, id), nil
+}
+
+// resolveUserReferralCodes resolves page of referral codes of user
+func (s *Server) resolveUserReferralCodes(p graphql.ResolveParams) (interface{}, error) {
+	user := p.Source.(models.User)
+	page, err := pageArgs(p)
+	if err != nil {
+		return nil, err
+	}
+	thunk := p.Info.RootValue.(*loaders).referralCodesByReferrer.load(pagedKey{id: user.ID, page: page})
+
+	return func() (interface{}, error) {
+		codes, _, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if codes == nil {
+			codes = []models.ReferralCode{}
+		}
+		return codes, nil
+	}, nil
+}
+
+// resolveUserReferrals resolves page of referrals of user
+func (s *Server) resolveUserReferrals(p graphql.ResolveParams) (interface{}, error) {
+	user := p.Source.(models.User)
+	page, err := pageArgs(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loadReferrals(p.Info.RootValue.(*loaders).referralsByReferrer, pagedKey{id: user.ID, page: page}), nil
+}
+
+// resolveUserStats resolves statistics of referrals of user for range and granularity from arguments,
+// statistics of the same user and arguments are computed once per request
+func (s *Server) resolveUserStats(p graphql.ResolveParams) (interface{}, error) {
+	user := p.Source.(models.User)
+	from, _ := p.Args["from"].(string)
+	to, _ := p.Args["to"].(string)
+	granularity, _ := p.Args["granularity"].(string)
+
+	stats, err := p.Info.RootValue.(*loaders).stats.get(statsKey{
+		userID:      user.ID,
+		from:        from,
+		to:          to,
+		granularity: granularity,
+	})
+	if err != nil {
+		if errors.Is(err, api.ErrInvalidReferralStats) {
+			return nil, err
+		}
+		return nil, errors.New("Ошибка получения статистики")
+	}
+
+	return stats, nil
+}
+
+// resolveUserCampaignStats resolves codes and referrals counts of user grouped by campaign
+func (s *Server) resolveUserCampaignStats(p graphql.ResolveParams) (interface{}, error) {
+	user := p.Source.(models.User)
+	userID, ok := p.Context.Value("UserID").(int)
+	if !ok {
+		return nil, errors.New("Ошибка аутентификации")
+	}
+
+	stats, err := s.service.GetCampaignStatsByReferrerID(userID, user.ID)
+	if err != nil {
+		return nil, errors.New("Ошибка получения статистики")
+	}
+
+	return stats, nil
+}
+
+// resolveReferralCodeReferrer resolves owner of referral code
+func (s *Server) resolveReferralCodeReferrer(p graphql.ResolveParams) (interface{}, error) {
+	code := p.Source.(models.ReferralCode)
+
+	return s.loadUser(p, code.ReferrerID), nil
+}
+
+// resolveReferralCodeReferrals resolves page of referrals registered with referral code
+func (s *Server) resolveReferralCodeReferrals(p graphql.ResolveParams) (interface{}, error) {
+	code := p.Source.(models.ReferralCode)
+	page, err := pageArgs(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loadReferrals(p.Info.RootValue.(*loaders).referralsByCode, pagedKey{id: code.ID, page: page}), nil
+}
+
+// resolveReferralReferralCode resolves referral code referral registered with
+func (s *Server) resolveReferralReferralCode(p graphql.ResolveParams) (interface{}, error) {
+	referral := p.Source.(models.Referral)
+	if referral.ReferralCodeID == 0 {
+		return nil, nil
+	}
+	thunk := p.Info.RootValue.(*loaders).referralCodes.load(referral.ReferralCodeID)
+
+	return func() (interface{}, error) {
+		code, ok, err := thunk()
+		if err != nil || !ok {
+			return nil, err
+		}
+		return code, nil
+	}, nil
+}
+
+// resolveReferralReferrer resolves referrer of referral
+func (s *Server) resolveReferralReferrer(p graphql.ResolveParams) (interface{}, error) {
+	referral := p.Source.(models.Referral)
+
+	return s.loadUser(p, referral.ReferrerID), nil
+}
+
+// loadUser returns thunk resolving user with id, missing user is null
+func (s *Server) loadUser(p graphql.ResolveParams, id int) func() (interface{}, error) {
+	thunk := p.Info.RootValue.(*loaders).users.load(id)
+
+	return func() (interface{}, error) {
+		user, ok, err := thunk()
+		if err != nil || !ok {
+			return nil, err
+		}
+		return user, nil
+	}
+}
+
+// loadReferrals returns thunk resolving referrals with key from loader, no referrals are empty list
+func (s *Server) loadReferrals(loader *loader[pagedKey, []models.Referral], key pagedKey) func() (interface{},
+	error) {
+	thunk := loader.load(key)
+
+	return func() (interface{}, error) {
+		referrals, _, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if referrals == nil {
+			referrals = []models.Referral{}
+		}
+		return referrals, nil
+	}
+}
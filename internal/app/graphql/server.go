@@ -0,0 +1,74 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/models"
+)
+
+// Server executes GraphQL queries over users, referral codes and referrals on top of the same api.Service
+// as REST API. Nested fields are loaded in batches, one database query per field of query level,
+// and queries deeper or more complex than configured limits are rejected before execution
+type Server struct {
+	schema        graphql.Schema
+	service       api.Service
+	logger        *logrus.Logger
+	maxDepth      int
+	maxComplexity int
+}
+
+// New creates new instance of Server with service and query limits from config
+func New(service api.Service, cfg *config.Config, logger *logrus.Logger) *Server {
+	s := &Server{
+		service:       service,
+		logger:        logger,
+		maxDepth:      cfg.GraphQLMaxDepth,
+		maxComplexity: cfg.GraphQLMaxComplexity,
+	}
+
+	// Schema is constant, so invalid one is programming error
+	schema, err := s.newSchema()
+	if err != nil {
+		logger.Fatalf("Не удалось построить GraphQL схему: %s", err)
+	}
+	s.schema = schema
+
+	return s
+}
+
+// Execute parses, validates and checks limits of query, then executes it
+// Context must hold id of the authenticated user
+func (s *Server) Execute(ctx context.Context, request models.GraphQLRequest) *graphql.Result {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, document, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	if err = checkLimits(&s.schema, document, request.Variables, s.maxDepth, s.maxComplexity); err != nil {
+		s.logger.Warnf("Execute[graphql]: Запрос отклонен: %s", err)
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		Root:          newLoaders(s.service),
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	})
+}
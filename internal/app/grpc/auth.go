@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"rest-refs/internal/app/grpc/pb"
+	"rest-refs/internal/app/models"
+)
+
+// Register registers new user, sign-up is attributed to click of share link if click token is set
+func (s *Server) Register(ctx context.Context, req *pb.RegisterRequest) (*emptypb.Empty, error) {
+	s.logger.Debugf("Register[grpc]: Регистрация пользователя")
+
+	user := models.User{
+		Email:       req.GetEmail(),
+		Password:    req.GetPassword(),
+		DisplayName: req.GetDisplayName(),
+	}
+
+	var err error
+	if req.GetClickToken() != "" {
+		err = s.service.Referral.RegisterWithAttribution(req.GetClickToken(), user, clientInfo(ctx))
+	} else {
+		err = s.service.Authorization.RegisterUser(user)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordUserDevice(ctx, user.Email)
+
+	s.logger.Infof("Register[grpc]: Регистрация пользователя прошла успешно")
+	return &emptypb.Empty{}, nil
+}
+
+// RegisterWithReferral registers new user with referral code
+func (s *Server) RegisterWithReferral(ctx context.Context, req *pb.RegisterWithReferralRequest) (*emptypb.Empty,
+	error) {
+	s.logger.Debugf("RegisterWithReferral[grpc]: Регистрация реферала")
+
+	if req.GetReferralCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Реферальный код не может быть пустым")
+	}
+
+	user := models.User{
+		Email:       req.GetEmail(),
+		Password:    req.GetPassword(),
+		DisplayName: req.GetDisplayName(),
+	}
+
+	err := s.service.Referral.RegisterWithReferralCode(req.GetReferralCode(), user, clientInfo(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordUserDevice(ctx, user.Email)
+
+	s.logger.Infof("RegisterWithReferral[grpc]: Регистрация реферала прошла успешно")
+	return &emptypb.Empty{}, nil
+}
+
+// Login returns JWT for user credentials
+func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	s.logger.Debugf("Login[grpc]: Логин пользователя")
+
+	user := models.User{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	}
+
+	token, err := s.service.Authorization.GenerateToken(user)
+	if err != nil {
+		return nil, err
+	}
+	s.recordUserDevice(ctx, user.Email)
+
+	s.logger.Debugf("Login[grpc]: Логин пользователя прошел успешно")
+	return &pb.LoginResponse{Token: token}, nil
+}
@@ -0,0 +1,112 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/grpc/pb"
+)
+
+// publicMethods are methods callable without token, the same as REST endpoints
+// that are not wrapped by RequireValidTokenMiddleware
+var publicMethods = map[string]bool{
+	pb.AuthService_Register_FullMethodName:                       true,
+	pb.AuthService_RegisterWithReferral_FullMethodName:           true,
+	pb.AuthService_Login_FullMethodName:                          true,
+	pb.ReferralCodeService_GetReferralCodeByEmail_FullMethodName: true,
+	pb.ReferralCodeService_GetReferralCodeStatus_FullMethodName:  true,
+	pb.ReferralService_ListReferrals_FullMethodName:              true,
+}
+
+// RequireValidTokenInterceptor validates JWT from authorization metadata of calls to non-public methods
+// Like RequireValidTokenMiddleware of REST API, it checks if valid token is provided, extracts user ID from claims,
+// and adds user ID to call context for further use
+func (s *Server) RequireValidTokenInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	// Extract token from authorization metadata
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "Не указан заголовок авторизации")
+	}
+	tokenString := strings.TrimPrefix(values[0], "Bearer ")
+
+	// Check if token is valid, invalid token is mapped to Unauthenticated by ErrorInterceptor
+	authenticated, claims, err := s.service.IsTokenValid(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if !authenticated {
+		return nil, status.Error(codes.Unauthenticated, "Пользователь не авторизован")
+	}
+
+	userID, ok := claims["id"].(float64)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Ошибка токена")
+	}
+
+	ctx = context.WithValue(ctx, "UserID", int(userID))
+	return handler(ctx, req)
+}
+
+// ErrorInterceptor converts errors returned by service into gRPC statuses with the same mapping
+// of sentinel errors REST API uses. Statuses made by methods themselves are passed as is,
+// unknown errors are logged and hidden
+func (s *Server) ErrorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return nil, err
+	}
+
+	if mapping, ok := api.LookupError(err); ok {
+		detail := mapping.Detail
+		if detail == "" {
+			detail = err.Error()
+		}
+
+		var fieldErr *api.FieldError
+		if errors.As(err, &fieldErr) {
+			detail = fieldErr.Field + ": " + fieldErr.Message
+		}
+		return nil, status.Error(mapping.GRPCCode, detail)
+	}
+
+	s.logger.Errorf("ErrorInterceptor[grpc]: Необработанная ошибка %s: %s", info.FullMethod, err)
+	return nil, status.Error(codes.Internal, "Проблема на сервере")
+}
+
+// RateLimitInterceptor rejects calls of public methods exceeding quota of client IP with ResourceExhausted,
+// limits are the same as of REST endpoints and time until window resets is sent in retry-after header
+func (s *Server) RateLimitInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	limiter, ok := s.limiters[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	ip := clientInfo(ctx).IP
+	allowed, retryAfter := limiter.Allow(ip, time.Now())
+	if !allowed {
+		s.logger.Warnf("RateLimitInterceptor[grpc]: Превышен лимит запросов для %s к %s", ip, info.FullMethod)
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(retryAfter.Seconds())+1)))
+		return nil, status.Error(codes.ResourceExhausted, "Слишком много запросов")
+	}
+
+	return handler(ctx, req)
+}
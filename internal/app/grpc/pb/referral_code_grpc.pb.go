@@ -0,0 +1,372 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: refs/v1/referral_code.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ReferralCodeService_CreateReferralCode_FullMethodName     = "/refs.v1.ReferralCodeService/CreateReferralCode"
+	ReferralCodeService_DeleteReferralCode_FullMethodName     = "/refs.v1.ReferralCodeService/DeleteReferralCode"
+	ReferralCodeService_GetReferralCodeByEmail_FullMethodName = "/refs.v1.ReferralCodeService/GetReferralCodeByEmail"
+	ReferralCodeService_PauseReferralCode_FullMethodName      = "/refs.v1.ReferralCodeService/PauseReferralCode"
+	ReferralCodeService_ResumeReferralCode_FullMethodName     = "/refs.v1.ReferralCodeService/ResumeReferralCode"
+	ReferralCodeService_RotateReferralCode_FullMethodName     = "/refs.v1.ReferralCodeService/RotateReferralCode"
+	ReferralCodeService_GetReferralCodeStatus_FullMethodName  = "/refs.v1.ReferralCodeService/GetReferralCodeStatus"
+)
+
+// ReferralCodeServiceClient is the client API for ReferralCodeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ReferralCodeService manages referral code of the authenticated user, it mirrors /referral_code endpoints
+// of REST API
+type ReferralCodeServiceClient interface {
+	// CreateReferralCode creates referral code of the authenticated user
+	CreateReferralCode(ctx context.Context, in *CreateReferralCodeRequest, opts ...grpc.CallOption) (*ReferralCodeResponse, error)
+	// DeleteReferralCode deletes active referral code of the authenticated user
+	DeleteReferralCode(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetReferralCodeByEmail returns active referral code of referrer, it does not require authentication
+	GetReferralCodeByEmail(ctx context.Context, in *GetReferralCodeByEmailRequest, opts ...grpc.CallOption) (*ReferralCodeResponse, error)
+	// PauseReferralCode pauses active referral code of the authenticated user
+	PauseReferralCode(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ResumeReferralCode resumes paused referral code of the authenticated user
+	ResumeReferralCode(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// RotateReferralCode issues new referral code of the authenticated user on the same referral link
+	RotateReferralCode(ctx context.Context, in *RotateReferralCodeRequest, opts ...grpc.CallOption) (*ReferralCode, error)
+	// GetReferralCodeStatus reports whether referral code can be used for registration,
+	// it does not require authentication
+	GetReferralCodeStatus(ctx context.Context, in *GetReferralCodeStatusRequest, opts ...grpc.CallOption) (*ReferralCodeStatus, error)
+}
+
+type referralCodeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReferralCodeServiceClient(cc grpc.ClientConnInterface) ReferralCodeServiceClient {
+	return &referralCodeServiceClient{cc}
+}
+
+func (c *referralCodeServiceClient) CreateReferralCode(ctx context.Context, in *CreateReferralCodeRequest, opts ...grpc.CallOption) (*ReferralCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReferralCodeResponse)
+	err := c.cc.Invoke(ctx, ReferralCodeService_CreateReferralCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referralCodeServiceClient) DeleteReferralCode(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ReferralCodeService_DeleteReferralCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referralCodeServiceClient) GetReferralCodeByEmail(ctx context.Context, in *GetReferralCodeByEmailRequest, opts ...grpc.CallOption) (*ReferralCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReferralCodeResponse)
+	err := c.cc.Invoke(ctx, ReferralCodeService_GetReferralCodeByEmail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referralCodeServiceClient) PauseReferralCode(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ReferralCodeService_PauseReferralCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referralCodeServiceClient) ResumeReferralCode(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ReferralCodeService_ResumeReferralCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referralCodeServiceClient) RotateReferralCode(ctx context.Context, in *RotateReferralCodeRequest, opts ...grpc.CallOption) (*ReferralCode, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReferralCode)
+	err := c.cc.Invoke(ctx, ReferralCodeService_RotateReferralCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referralCodeServiceClient) GetReferralCodeStatus(ctx context.Context, in *GetReferralCodeStatusRequest, opts ...grpc.CallOption) (*ReferralCodeStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReferralCodeStatus)
+	err := c.cc.Invoke(ctx, ReferralCodeService_GetReferralCodeStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReferralCodeServiceServer is the server API for ReferralCodeService service.
+// All implementations must embed UnimplementedReferralCodeServiceServer
+// for forward compatibility.
+//
+// ReferralCodeService manages referral code of the authenticated user, it mirrors /referral_code endpoints
+// of REST API
+type ReferralCodeServiceServer interface {
+	// CreateReferralCode creates referral code of the authenticated user
+	CreateReferralCode(context.Context, *CreateReferralCodeRequest) (*ReferralCodeResponse, error)
+	// DeleteReferralCode deletes active referral code of the authenticated user
+	DeleteReferralCode(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// GetReferralCodeByEmail returns active referral code of referrer, it does not require authentication
+	GetReferralCodeByEmail(context.Context, *GetReferralCodeByEmailRequest) (*ReferralCodeResponse, error)
+	// PauseReferralCode pauses active referral code of the authenticated user
+	PauseReferralCode(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// ResumeReferralCode resumes paused referral code of the authenticated user
+	ResumeReferralCode(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// RotateReferralCode issues new referral code of the authenticated user on the same referral link
+	RotateReferralCode(context.Context, *RotateReferralCodeRequest) (*ReferralCode, error)
+	// GetReferralCodeStatus reports whether referral code can be used for registration,
+	// it does not require authentication
+	GetReferralCodeStatus(context.Context, *GetReferralCodeStatusRequest) (*ReferralCodeStatus, error)
+	mustEmbedUnimplementedReferralCodeServiceServer()
+}
+
+// UnimplementedReferralCodeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReferralCodeServiceServer struct{}
+
+func (UnimplementedReferralCodeServiceServer) CreateReferralCode(context.Context, *CreateReferralCodeRequest) (*ReferralCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReferralCode not implemented")
+}
+func (UnimplementedReferralCodeServiceServer) DeleteReferralCode(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteReferralCode not implemented")
+}
+func (UnimplementedReferralCodeServiceServer) GetReferralCodeByEmail(context.Context, *GetReferralCodeByEmailRequest) (*ReferralCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReferralCodeByEmail not implemented")
+}
+func (UnimplementedReferralCodeServiceServer) PauseReferralCode(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseReferralCode not implemented")
+}
+func (UnimplementedReferralCodeServiceServer) ResumeReferralCode(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeReferralCode not implemented")
+}
+func (UnimplementedReferralCodeServiceServer) RotateReferralCode(context.Context, *RotateReferralCodeRequest) (*ReferralCode, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateReferralCode not implemented")
+}
+func (UnimplementedReferralCodeServiceServer) GetReferralCodeStatus(context.Context, *GetReferralCodeStatusRequest) (*ReferralCodeStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReferralCodeStatus not implemented")
+}
+func (UnimplementedReferralCodeServiceServer) mustEmbedUnimplementedReferralCodeServiceServer() {}
+func (UnimplementedReferralCodeServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeReferralCodeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReferralCodeServiceServer will
+// result in compilation errors.
+type UnsafeReferralCodeServiceServer interface {
+	mustEmbedUnimplementedReferralCodeServiceServer()
+}
+
+func RegisterReferralCodeServiceServer(s grpc.ServiceRegistrar, srv ReferralCodeServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReferralCodeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReferralCodeService_ServiceDesc, srv)
+}
+
+func _ReferralCodeService_CreateReferralCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReferralCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferralCodeServiceServer).CreateReferralCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReferralCodeService_CreateReferralCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferralCodeServiceServer).CreateReferralCode(ctx, req.(*CreateReferralCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReferralCodeService_DeleteReferralCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferralCodeServiceServer).DeleteReferralCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReferralCodeService_DeleteReferralCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferralCodeServiceServer).DeleteReferralCode(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReferralCodeService_GetReferralCodeByEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReferralCodeByEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferralCodeServiceServer).GetReferralCodeByEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReferralCodeService_GetReferralCodeByEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferralCodeServiceServer).GetReferralCodeByEmail(ctx, req.(*GetReferralCodeByEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReferralCodeService_PauseReferralCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferralCodeServiceServer).PauseReferralCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReferralCodeService_PauseReferralCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferralCodeServiceServer).PauseReferralCode(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReferralCodeService_ResumeReferralCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferralCodeServiceServer).ResumeReferralCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReferralCodeService_ResumeReferralCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferralCodeServiceServer).ResumeReferralCode(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReferralCodeService_RotateReferralCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateReferralCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferralCodeServiceServer).RotateReferralCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReferralCodeService_RotateReferralCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferralCodeServiceServer).RotateReferralCode(ctx, req.(*RotateReferralCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReferralCodeService_GetReferralCodeStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReferralCodeStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferralCodeServiceServer).GetReferralCodeStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReferralCodeService_GetReferralCodeStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferralCodeServiceServer).GetReferralCodeStatus(ctx, req.(*GetReferralCodeStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReferralCodeService_ServiceDesc is the grpc.ServiceDesc for ReferralCodeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReferralCodeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "refs.v1.ReferralCodeService",
+	HandlerType: (*ReferralCodeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateReferralCode",
+			Handler:    _ReferralCodeService_CreateReferralCode_Handler,
+		},
+		{
+			MethodName: "DeleteReferralCode",
+			Handler:    _ReferralCodeService_DeleteReferralCode_Handler,
+		},
+		{
+			MethodName: "GetReferralCodeByEmail",
+			Handler:    _ReferralCodeService_GetReferralCodeByEmail_Handler,
+		},
+		{
+			MethodName: "PauseReferralCode",
+			Handler:    _ReferralCodeService_PauseReferralCode_Handler,
+		},
+		{
+			MethodName: "ResumeReferralCode",
+			Handler:    _ReferralCodeService_ResumeReferralCode_Handler,
+		},
+		{
+			MethodName: "RotateReferralCode",
+			Handler:    _ReferralCodeService_RotateReferralCode_Handler,
+		},
+		{
+			MethodName: "GetReferralCodeStatus",
+			Handler:    _ReferralCodeService_GetReferralCodeStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "refs/v1/referral_code.proto",
+}
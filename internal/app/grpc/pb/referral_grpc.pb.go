@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: refs/v1/referral.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ReferralService_ListReferrals_FullMethodName    = "/refs.v1.ReferralService/ListReferrals"
+	ReferralService_GetCampaignStats_FullMethodName = "/refs.v1.ReferralService/GetCampaignStats"
+	ReferralService_GetReferralTree_FullMethodName  = "/refs.v1.ReferralService/GetReferralTree"
+)
+
+// ReferralServiceClient is the client API for ReferralService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ReferralService reports referrals, it mirrors /referral endpoints of REST API
+type ReferralServiceClient interface {
+	// ListReferrals returns page of referrals of referrer, it does not require authentication
+	ListReferrals(ctx context.Context, in *ListReferralsRequest, opts ...grpc.CallOption) (*ListReferralsResponse, error)
+	// GetCampaignStats returns codes and referrals counts of referrer grouped by campaign,
+	// only to referrer itself or to administrator
+	GetCampaignStats(ctx context.Context, in *GetCampaignStatsRequest, opts ...grpc.CallOption) (*GetCampaignStatsResponse, error)
+	// GetReferralTree returns downline of the authenticated user
+	GetReferralTree(ctx context.Context, in *GetReferralTreeRequest, opts ...grpc.CallOption) (*ReferralTree, error)
+}
+
+type referralServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReferralServiceClient(cc grpc.ClientConnInterface) ReferralServiceClient {
+	return &referralServiceClient{cc}
+}
+
+func (c *referralServiceClient) ListReferrals(ctx context.Context, in *ListReferralsRequest, opts ...grpc.CallOption) (*ListReferralsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReferralsResponse)
+	err := c.cc.Invoke(ctx, ReferralService_ListReferrals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referralServiceClient) GetCampaignStats(ctx context.Context, in *GetCampaignStatsRequest, opts ...grpc.CallOption) (*GetCampaignStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCampaignStatsResponse)
+	err := c.cc.Invoke(ctx, ReferralService_GetCampaignStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referralServiceClient) GetReferralTree(ctx context.Context, in *GetReferralTreeRequest, opts ...grpc.CallOption) (*ReferralTree, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReferralTree)
+	err := c.cc.Invoke(ctx, ReferralService_GetReferralTree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReferralServiceServer is the server API for ReferralService service.
+// All implementations must embed UnimplementedReferralServiceServer
+// for forward compatibility.
+//
+// ReferralService reports referrals, it mirrors /referral endpoints of REST API
+type ReferralServiceServer interface {
+	// ListReferrals returns page of referrals of referrer, it does not require authentication
+	ListReferrals(context.Context, *ListReferralsRequest) (*ListReferralsResponse, error)
+	// GetCampaignStats returns codes and referrals counts of referrer grouped by campaign,
+	// only to referrer itself or to administrator
+	GetCampaignStats(context.Context, *GetCampaignStatsRequest) (*GetCampaignStatsResponse, error)
+	// GetReferralTree returns downline of the authenticated user
+	GetReferralTree(context.Context, *GetReferralTreeRequest) (*ReferralTree, error)
+	mustEmbedUnimplementedReferralServiceServer()
+}
+
+// UnimplementedReferralServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReferralServiceServer struct{}
+
+func (UnimplementedReferralServiceServer) ListReferrals(context.Context, *ListReferralsRequest) (*ListReferralsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReferrals not implemented")
+}
+func (UnimplementedReferralServiceServer) GetCampaignStats(context.Context, *GetCampaignStatsRequest) (*GetCampaignStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCampaignStats not implemented")
+}
+func (UnimplementedReferralServiceServer) GetReferralTree(context.Context, *GetReferralTreeRequest) (*ReferralTree, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReferralTree not implemented")
+}
+func (UnimplementedReferralServiceServer) mustEmbedUnimplementedReferralServiceServer() {}
+func (UnimplementedReferralServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeReferralServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReferralServiceServer will
+// result in compilation errors.
+type UnsafeReferralServiceServer interface {
+	mustEmbedUnimplementedReferralServiceServer()
+}
+
+func RegisterReferralServiceServer(s grpc.ServiceRegistrar, srv ReferralServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReferralServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReferralService_ServiceDesc, srv)
+}
+
+func _ReferralService_ListReferrals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReferralsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferralServiceServer).ListReferrals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReferralService_ListReferrals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferralServiceServer).ListReferrals(ctx, req.(*ListReferralsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReferralService_GetCampaignStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCampaignStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferralServiceServer).GetCampaignStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReferralService_GetCampaignStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferralServiceServer).GetCampaignStats(ctx, req.(*GetCampaignStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReferralService_GetReferralTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReferralTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferralServiceServer).GetReferralTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReferralService_GetReferralTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferralServiceServer).GetReferralTree(ctx, req.(*GetReferralTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReferralService_ServiceDesc is the grpc.ServiceDesc for ReferralService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReferralService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "refs.v1.ReferralService",
+	HandlerType: (*ReferralServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListReferrals",
+			Handler:    _ReferralService_ListReferrals_Handler,
+		},
+		{
+			MethodName: "GetCampaignStats",
+			Handler:    _ReferralService_GetCampaignStats_Handler,
+		},
+		{
+			MethodName: "GetReferralTree",
+			Handler:    _ReferralService_GetReferralTree_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "refs/v1/referral.proto",
+}
@@ -0,0 +1,139 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"rest-refs/internal/app/grpc/pb"
+	"rest-refs/internal/app/models"
+)
+
+// ListReferrals returns page of referrals of referrer matching filters
+func (s *Server) ListReferrals(_ context.Context, req *pb.ListReferralsRequest) (*pb.ListReferralsResponse, error) {
+	s.logger.Debugf("ListReferrals[grpc]: Получение рефералов по id реферера")
+
+	if req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "Неправильный limit")
+	}
+
+	opts := models.ReferralListOptions{
+		Statuses: req.GetStatuses(),
+		CodeID:   intPtr(req.CodeId),
+		From:     req.GetFrom(),
+		To:       req.GetTo(),
+		Sort:     req.GetSort(),
+		Cursor:   req.GetCursor(),
+		Limit:    int(req.GetLimit()),
+	}
+
+	page, err := s.service.GetReferralsByReferrerID(int(req.GetReferrerId()), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.ListReferralsResponse{
+		Referrals:  make([]*pb.ReferralInfo, 0, len(page.Referrals)),
+		Total:      int32(page.Total),
+		NextCursor: page.NextCursor,
+	}
+	for _, referral := range page.Referrals {
+		response.Referrals = append(response.Referrals, &pb.ReferralInfo{
+			ReferralId:      int32(referral.ReferralID),
+			ReferrerId:      int32(referral.ReferrerID),
+			Email:           referral.Email,
+			CampaignId:      int32Ptr(referral.CampaignID),
+			Status:          referral.Status,
+			StatusChangedAt: timestamp(referral.StatusChangedAt),
+			CreatedAt:       timestamppb.New(referral.CreatedAt),
+		})
+	}
+
+	s.logger.Debugf("ListReferrals[grpc]: Рефералы успешно получены по id реферера")
+	return response, nil
+}
+
+// GetCampaignStats returns codes and referrals counts of referrer grouped by campaign
+// to referrer itself or to administrator
+func (s *Server) GetCampaignStats(ctx context.Context,
+	req *pb.GetCampaignStatsRequest) (*pb.GetCampaignStatsResponse, error) {
+	s.logger.Debugf("GetCampaignStats[grpc]: Получение статистики по кампаниям")
+
+	userID, ok := ctx.Value("UserID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Ошибка аутентификации")
+	}
+
+	stats, err := s.service.GetCampaignStatsByReferrerID(userID, int(req.GetReferrerId()))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.GetCampaignStatsResponse{Stats: make([]*pb.CampaignReferralStats, 0, len(stats))}
+	for _, campaign := range stats {
+		response.Stats = append(response.Stats, &pb.CampaignReferralStats{
+			CampaignId:     int32Ptr(campaign.CampaignID),
+			CampaignName:   campaign.CampaignName,
+			CodesCount:     int32(campaign.CodesCount),
+			ReferralsCount: int32(campaign.ReferralsCount),
+		})
+	}
+
+	s.logger.Debugf("GetCampaignStats[grpc]: Статистика по кампаниям успешно получена")
+	return response, nil
+}
+
+// GetReferralTree returns downline of the authenticated user
+func (s *Server) GetReferralTree(ctx context.Context, req *pb.GetReferralTreeRequest) (*pb.ReferralTree, error) {
+	s.logger.Debugf("GetReferralTree[grpc]: Получение дерева рефералов")
+
+	userID, ok := ctx.Value("UserID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Ошибка аутентификации")
+	}
+
+	if req.GetDepth() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "Неправильная глубина дерева")
+	}
+
+	tree, err := s.service.GetReferralTree(userID, int(req.GetDepth()))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.ReferralTree{
+		UserId:    int32(tree.UserID),
+		Depth:     int32(tree.Depth),
+		Total:     int32(tree.Total),
+		Levels:    make([]*pb.ReferralTreeLevel, 0, len(tree.Levels)),
+		Referrals: referralTreeNodes(tree.Referrals),
+	}
+	for _, level := range tree.Levels {
+		response.Levels = append(response.Levels, &pb.ReferralTreeLevel{
+			Level: int32(level.Level),
+			Count: int32(level.Count),
+		})
+	}
+
+	s.logger.Debugf("GetReferralTree[grpc]: Дерево рефералов успешно получено")
+	return response, nil
+}
+
+// referralTreeNodes converts nodes of referral tree with their children
+func referralTreeNodes(nodes []models.ReferralTreeNode) []*pb.ReferralTreeNode {
+	converted := make([]*pb.ReferralTreeNode, 0, len(nodes))
+	for _, node := range nodes {
+		converted = append(converted, &pb.ReferralTreeNode{
+			ReferralId: int32(node.ReferralID),
+			UserId:     int32Ptr(node.UserID),
+			ReferrerId: int32(node.ReferrerID),
+			Email:      node.Email,
+			Status:     node.Status,
+			Level:      int32(node.Level),
+			CreatedAt:  timestamppb.New(node.CreatedAt),
+			Children:   referralTreeNodes(node.Children),
+		})
+	}
+	return converted
+}
@@ -0,0 +1,189 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"rest-refs/internal/app/grpc/pb"
+	"rest-refs/internal/app/models"
+)
+
+// CreateReferralCode creates referral code of the authenticated user
+func (s *Server) CreateReferralCode(ctx context.Context,
+	req *pb.CreateReferralCodeRequest) (*pb.ReferralCodeResponse, error) {
+	s.logger.Debugf("CreateReferralCode[grpc]: Создание реферального кода")
+
+	userID, ok := ctx.Value("UserID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Ошибка аутентификации")
+	}
+
+	input := models.ReferralCodeCreateRequest{
+		ExpirationDate: req.GetExpirationDate(),
+		ExpiresIn:      req.GetExpiresIn(),
+		TimeZone:       req.GetTimeZone(),
+		MaxUses:        intPtr(req.MaxUses),
+		CampaignID:     intPtr(req.CampaignId),
+	}
+
+	// Resolve expiration from date, timestamp or relative duration
+	expirationDate, err := s.service.ResolveExpiration(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.MaxUses != nil && *input.MaxUses <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "Лимит использований реферального кода должен быть больше нуля")
+	}
+
+	referralCode := models.ReferralCode{
+		ReferrerID: userID,
+		Expiration: expirationDate,
+		MaxUses:    input.MaxUses,
+		CampaignID: input.CampaignID,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	createdCode, err := s.service.CreateReferralCode(referralCode)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debugf("CreateReferralCode[grpc]: Реферальный код успешно создан")
+	return &pb.ReferralCodeResponse{
+		Code:       createdCode.Code,
+		Expiration: timestamppb.New(createdCode.Expiration),
+	}, nil
+}
+
+// DeleteReferralCode deletes active referral code of the authenticated user
+func (s *Server) DeleteReferralCode(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	s.logger.Debugf("DeleteReferralCode[grpc]: Удаление реферального кода")
+
+	userID, ok := ctx.Value("UserID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Ошибка аутентификации")
+	}
+
+	if err := s.service.DeleteReferralCode(userID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debugf("DeleteReferralCode[grpc]: Реферальный код успешно удален")
+	return &emptypb.Empty{}, nil
+}
+
+// GetReferralCodeByEmail returns active referral code of referrer
+func (s *Server) GetReferralCodeByEmail(_ context.Context,
+	req *pb.GetReferralCodeByEmailRequest) (*pb.ReferralCodeResponse, error) {
+	s.logger.Debugf("GetReferralCodeByEmail[grpc]: Получение реферального кода по email реферера")
+
+	if req.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Email не может быть пустым")
+	}
+
+	referralCode, err := s.service.GetReferralCodeByReferrerEmail(req.GetEmail())
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debugf("GetReferralCodeByEmail[grpc]: Реферальный код успешно получен по email реферера")
+	return &pb.ReferralCodeResponse{
+		Code:       referralCode.Code,
+		Expiration: timestamppb.New(referralCode.Expiration),
+	}, nil
+}
+
+// PauseReferralCode pauses active referral code of the authenticated user
+func (s *Server) PauseReferralCode(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	s.logger.Debugf("PauseReferralCode[grpc]: Приостановка реферального кода")
+
+	userID, ok := ctx.Value("UserID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Ошибка аутентификации")
+	}
+
+	if err := s.service.PauseReferralCode(userID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debugf("PauseReferralCode[grpc]: Реферальный код успешно приостановлен")
+	return &emptypb.Empty{}, nil
+}
+
+// ResumeReferralCode resumes paused referral code of the authenticated user
+func (s *Server) ResumeReferralCode(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	s.logger.Debugf("ResumeReferralCode[grpc]: Возобновление реферального кода")
+
+	userID, ok := ctx.Value("UserID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Ошибка аутентификации")
+	}
+
+	if err := s.service.ResumeReferralCode(userID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debugf("ResumeReferralCode[grpc]: Реферальный код успешно возобновлен")
+	return &emptypb.Empty{}, nil
+}
+
+// RotateReferralCode issues new referral code of the authenticated user on the same referral link
+func (s *Server) RotateReferralCode(ctx context.Context, req *pb.RotateReferralCodeRequest) (*pb.ReferralCode, error) {
+	s.logger.Debugf("RotateReferralCode[grpc]: Замена реферального кода")
+
+	userID, ok := ctx.Value("UserID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Ошибка аутентификации")
+	}
+
+	input := models.ReferralCodeRotateRequest{GracePeriod: req.GracePeriod}
+	referralCode, err := s.service.RotateReferralCode(userID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debugf("RotateReferralCode[grpc]: Реферальный код успешно заменен")
+	return &pb.ReferralCode{
+		Id:           int32(referralCode.ID),
+		Code:         referralCode.Code,
+		ExpiresAt:    timestamppb.New(referralCode.Expiration),
+		ReferrerId:   int32(referralCode.ReferrerID),
+		PausedAt:     timestamp(referralCode.PausedAt),
+		MaxUses:      int32Ptr(referralCode.MaxUses),
+		UsesCount:    int32(referralCode.UsesCount),
+		BatchId:      int32Ptr(referralCode.BatchID),
+		CampaignId:   int32Ptr(referralCode.CampaignID),
+		LinkId:       int32Ptr(referralCode.LinkID),
+		SupersededBy: int32Ptr(referralCode.SupersededBy),
+		RotatedAt:    timestamp(referralCode.RotatedAt),
+		CreatedAt:    timestamppb.New(referralCode.CreatedAt),
+		UpdatedAt:    timestamppb.New(referralCode.UpdatedAt),
+	}, nil
+}
+
+// GetReferralCodeStatus reports whether referral code can be used for registration
+func (s *Server) GetReferralCodeStatus(_ context.Context,
+	req *pb.GetReferralCodeStatusRequest) (*pb.ReferralCodeStatus, error) {
+	s.logger.Debugf("GetReferralCodeStatus[grpc]: Проверка статуса реферального кода")
+
+	codeStatus, err := s.service.GetReferralCodeStatus(req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debugf("GetReferralCodeStatus[grpc]: Статус реферального кода успешно получен")
+	return &pb.ReferralCodeStatus{
+		Code:         codeStatus.Code,
+		Exists:       codeStatus.Exists,
+		Active:       codeStatus.Active,
+		Status:       codeStatus.Status,
+		Expiration:   timestamp(codeStatus.Expiration),
+		ReferrerName: codeStatus.ReferrerName,
+	}, nil
+}
@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/config"
+	"rest-refs/internal/app/grpc/pb"
+	"rest-refs/internal/app/models"
+)
+
+// deviceFingerprintKey is metadata key of device fingerprint, the same as X-Device-Fingerprint header of REST API
+const deviceFingerprintKey = "x-device-fingerprint"
+
+// Server implements gRPC services on top of the same api.Service as REST API, so both behave identically
+type Server struct {
+	pb.UnimplementedAuthServiceServer
+	pb.UnimplementedReferralCodeServiceServer
+	pb.UnimplementedReferralServiceServer
+
+	service  api.Service
+	logger   *logrus.Logger
+	limiters map[string]*api.RateLimiter
+}
+
+// New creates new instance of Server with service
+// Public methods get the same per-IP rate limits as their REST endpoints
+func New(service api.Service, cfg *config.Config, logger *logrus.Logger) *Server {
+	return &Server{
+		service: service,
+		logger:  logger,
+		limiters: map[string]*api.RateLimiter{
+			pb.ReferralCodeService_GetReferralCodeStatus_FullMethodName: api.NewRateLimiter(cfg.StatusRateLimit,
+				time.Minute),
+		},
+	}
+}
+
+// StartServer starts gRPC server on port with error mapping, rate limiting and authentication interceptors
+// and server reflection
+func (s *Server) StartServer(port string) {
+	listener, err := net.Listen("tcp", port)
+	if err != nil {
+		s.logger.Fatalf("Не удалось запустить gRPC сервер: %s", err)
+	}
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(s.ErrorInterceptor, s.RateLimitInterceptor,
+		s.RequireValidTokenInterceptor))
+	pb.RegisterAuthServiceServer(server, s)
+	pb.RegisterReferralCodeServiceServer(server, s)
+	pb.RegisterReferralServiceServer(server, s)
+	reflection.Register(server)
+
+	s.logger.Infof("StartServer[grpc]: gRPC сервер запущен на %s", port)
+	if err = server.Serve(listener); err != nil {
+		s.logger.Fatalf("Не удалось запустить gRPC сервер: %s", err)
+	}
+}
+
+// clientInfo describes client making call by its peer address and device fingerprint from metadata
+func clientInfo(ctx context.Context) models.ClientInfo {
+	var info models.ClientInfo
+
+	if p, ok := peer.FromContext(ctx); ok {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		info.IP = host
+	}
+
+	if values := metadata.ValueFromIncomingContext(ctx, deviceFingerprintKey); len(values) > 0 {
+		info.DeviceFingerprint = values[0]
+	}
+
+	return info
+}
+
+// recordUserDevice remembers device of user, failure never affects response
+func (s *Server) recordUserDevice(ctx context.Context, email string) {
+	if err := s.service.RecordUserDevice(email, clientInfo(ctx)); err != nil {
+		s.logger.Errorf("recordUserDevice[grpc]: Ошибка сохранения устройства пользователя %s: %s", email, err)
+	}
+}
+
+// timestamp converts optional time into protobuf timestamp, nil stays unset
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// int32Ptr converts optional int into optional protobuf int32
+func int32Ptr(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+	converted := int32(*value)
+	return &converted
+}
+
+// intPtr converts optional protobuf int32 into optional int
+func intPtr(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+	converted := int(*value)
+	return &converted
+}
@@ -2,25 +2,24 @@ package http
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 
-	"rest-refs/internal/app/api"
 	"rest-refs/internal/app/models"
-	"rest-refs/internal/app/repository/postgresql"
 )
 
 // RegisterUserHandler handles user registration
 // @Summary Register a new user
-// @Description Registers a new user with email and password
+// @Description Registers a new user with email and password.
+// @Description If request has attribution cookie set by share link /r/{code}, sign-up is attributed to that code
+// @Description within attribution window. Optional X-Device-Fingerprint header identifies device for fraud checks
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param input body models.RegisterRequest true "User data"
 // @Success 201 {object} models.User "User successfully registered"
-// @Failure 400 {string} string "Invalid data format"
-// @Failure 409 {string} string "User already exists"
-// @Failure 500 {string} string "Server error"
+// @Failure 400 {object} models.Problem "Invalid data format"
+// @Failure 409 {object} models.Problem "User already exists"
+// @Failure 500 {object} models.Problem "Server error"
 // @Router /auth/register [post]
 func (h *Handler) RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debugf("RegisterUserHandler[http]: Регистрация пользователя")
@@ -29,28 +28,32 @@ func (h *Handler) RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Decode request body into input struct
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Неправильный формат данных", http.StatusBadRequest)
+		h.writeDecodeError(w, r, err)
 		return
 	}
 
 	user := models.User{
-		Email:    input.Email,
-		Password: input.Password,
+		Email:       input.Email,
+		Password:    input.Password,
+		DisplayName: input.DisplayName,
 	}
 
-	// Attempt to create user using service
-	err := h.service.Authorization.RegisterUser(user)
+	// Attempt to create user using service, attributing sign-up to earlier click on share link if any
+	var err error
+	if cookie, cookieErr := r.Cookie(attributionCookieName); cookieErr == nil && cookie.Value != "" {
+		err = h.service.Referral.RegisterWithAttribution(cookie.Value, user, clientInfo(r))
+	} else {
+		err = h.service.Authorization.RegisterUser(user)
+	}
 	if err != nil {
-		if errors.Is(err, api.ErrUserAlreadyExists) {
-			http.Error(w, "Такой пользователь уже существует", http.StatusConflict)
-			return
-		}
-
-		http.Error(w, "Проблема на сервере", http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
+	h.recordUserDevice(user.Email, r)
+
 	// Respond with created user
+	clearAttributionCookie(w)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	h.logger.Infof("RegisterUserHandler[http]: Регистрация пользователя прошла успешно")
@@ -64,8 +67,9 @@ func (h *Handler) RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param input body models.LoginRequest true "User credentials"
 // @Success 200 {object} string "Successfully authenticated"
-// @Failure 400 {string} string "Invalid data format"
-// @Failure 500 {string} string "Server error"
+// @Failure 400 {object} models.Problem "Invalid data format"
+// @Failure 401 {object} models.Problem "Invalid email or password"
+// @Failure 500 {object} models.Problem "Server error"
 // @Router /auth/login [post]
 func (h *Handler) LoginUserHandler(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debugf("LoginUserHandler[http]: Логин пользователя")
@@ -74,7 +78,7 @@ func (h *Handler) LoginUserHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Decode request body into input struct
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Неправильный формат данных", http.StatusBadRequest)
+		h.writeDecodeError(w, r, err)
 		return
 	}
 
@@ -86,9 +90,11 @@ func (h *Handler) LoginUserHandler(w http.ResponseWriter, r *http.Request) {
 	// Attempt to generate token using service
 	token, err := h.service.Authorization.GenerateToken(user)
 	if err != nil {
-		http.Error(w, "Проблема на сервере", http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
+	h.recordUserDevice(user.Email, r)
+
 	// Respond with created user
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -99,16 +105,17 @@ func (h *Handler) LoginUserHandler(w http.ResponseWriter, r *http.Request) {
 
 // RegisterWithReferralHandler registers a user with a referral code
 // @Summary Register a user with a referral code
-// @Description Registers a new user with a referral code
+// @Description Registers a new user with a referral code. Referral is scored by fraud checks using client IP
+// @Description and optional X-Device-Fingerprint header, rewards for high-risk referrals wait for manual review
 // @Tags Referral
 // @Accept json
 // @Produce json
 // @Param input body models.RegisterRequest true "User data with referral code"
 // @Success 201 {object} models.User "User successfully registered"
-// @Failure 400 {string} string "Invalid referral code or data"
-// @Failure 404 {string} string "Referral code not found"
-// @Failure 409 {string} string "User already exists"
-// @Failure 500 {string} string "Server error"
+// @Failure 400 {object} models.Problem "Invalid or malformed referral code or data"
+// @Failure 404 {object} models.Problem "Referral code not found"
+// @Failure 409 {object} models.Problem "User already exists"
+// @Failure 500 {object} models.Problem "Server error"
 // @Router /auth/register/referral [post]
 func (h *Handler) RegisterWithReferralHandler(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debugf("RegisterWithReferralHandler[http]: Регистрация реферала")
@@ -117,41 +124,28 @@ func (h *Handler) RegisterWithReferralHandler(w http.ResponseWriter, r *http.Req
 
 	// Decode request body into input struct
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Неправильный формат данных", http.StatusBadRequest)
+		h.writeDecodeError(w, r, err)
 		return
 	}
 
 	if input.ReferralCode == "" {
-		http.Error(w, "Реферальный код не может быть пустым", http.StatusBadRequest)
+		h.writeInvalidParameter(w, r, "referral_code", "Реферальный код не может быть пустым")
 		return
 	}
 
 	user := models.User{
-		Email:    input.Email,
-		Password: input.Password,
+		Email:       input.Email,
+		Password:    input.Password,
+		DisplayName: input.DisplayName,
 	}
 
 	// Attempt to register referral using service
-	err := h.service.Referral.RegisterWithReferralCode(input.ReferralCode, user)
+	err := h.service.Referral.RegisterWithReferralCode(input.ReferralCode, user, clientInfo(r))
 	if err != nil {
-		if errors.Is(err, postgresql.ErrReferralCodeNotFound) {
-			http.Error(w, "Введенный реферальный код не существует", http.StatusNotFound)
-			return
-		}
-
-		if errors.Is(err, postgresql.ErrReferralCodeNotActive) {
-			http.Error(w, "Введенный реферальный код неактивен", http.StatusBadRequest)
-			return
-		}
-
-		if errors.Is(err, api.ErrUserAlreadyExists) {
-			http.Error(w, "Пользователь с указанными данными уже зарегистрирован", http.StatusConflict)
-			return
-		}
-
-		http.Error(w, "Проблема на сервере", http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
+	h.recordUserDevice(user.Email, r)
 
 	// Respond with created user
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"rest-refs/internal/app/models"
+)
+
+// CreateCampaignHandler creates new campaign
+// @Summary Create campaign
+// @Description Creates campaign grouping referral codes (admin only).
+// @Description default_expires_in is lifetime of campaign codes created without expiration,
+// @Description reward_rules is JSON object with campaign-wide reward settings
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param CampaignRequest body models.CampaignRequest true "Campaign request"
+// @Success 201 {object} models.Campaign "Campaign created"
+// @Failure 400 {object} models.Problem "Invalid data format or campaign parameters"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/campaign [post]
+func (h *Handler) CreateCampaignHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("CreateCampaignHandler[http]: Создание кампании")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	var input models.CampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	campaign, err := h.service.CreateCampaign(input, &userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(campaign)
+
+	h.logger.Debugf("CreateCampaignHandler[http]: Кампания успешно создана")
+}
+
+// UpdateCampaignHandler updates campaign settings
+// @Summary Update campaign
+// @Description Replaces campaign settings (admin only). Existing codes keep their expiration
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Campaign ID"
+// @Param CampaignRequest body models.CampaignRequest true "Campaign request"
+// @Success 200 {object} models.Campaign "Campaign updated"
+// @Failure 400 {object} models.Problem "Invalid ID, data format or campaign parameters"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Campaign not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/campaign/{id} [put]
+func (h *Handler) UpdateCampaignHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("UpdateCampaignHandler[http]: Изменение кампании")
+
+	vars := mux.Vars(r)
+	campaignID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	var input models.CampaignRequest
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	campaign, err := h.service.UpdateCampaign(campaignID, input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(campaign)
+
+	h.logger.Debugf("UpdateCampaignHandler[http]: Кампания успешно изменена")
+}
+
+// DeleteCampaignHandler deletes campaign
+// @Summary Delete campaign
+// @Description Deletes campaign (admin only). Its codes stay valid as regular codes of their referrers
+// @Tags admin
+// @Param id path int true "Campaign ID"
+// @Success 204 "Campaign deleted"
+// @Failure 400 {object} models.Problem "Invalid ID format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Campaign not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/campaign/{id} [delete]
+func (h *Handler) DeleteCampaignHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("DeleteCampaignHandler[http]: Удаление кампании")
+
+	vars := mux.Vars(r)
+	campaignID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	err = h.service.DeleteCampaign(campaignID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	h.logger.Debugf("DeleteCampaignHandler[http]: Кампания успешно удалена")
+}
+
+// GetCampaignsHandler lists campaigns
+// @Summary List campaigns
+// @Description Returns all campaigns with their codes and participants counts
+// @Tags campaign
+// @Produce  json
+// @Success 200 {array} models.Campaign "List of campaigns"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /campaign [get]
+func (h *Handler) GetCampaignsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetCampaignsHandler[http]: Получение списка кампаний")
+
+	campaigns, err := h.service.GetCampaigns()
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(campaigns); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetCampaignsHandler[http]: Список кампаний успешно получен")
+}
+
+// GetCampaignByIDHandler retrieves campaign by ID
+// @Summary Get campaign
+// @Description Returns campaign with its codes and participants counts
+// @Tags campaign
+// @Produce  json
+// @Param id path int true "Campaign ID"
+// @Success 200 {object} models.Campaign "Campaign"
+// @Failure 400 {object} models.Problem "Invalid ID format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Campaign not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /campaign/{id} [get]
+func (h *Handler) GetCampaignByIDHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetCampaignByIDHandler[http]: Получение кампании")
+
+	vars := mux.Vars(r)
+	campaignID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	campaign, err := h.service.GetCampaignByID(campaignID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(campaign); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetCampaignByIDHandler[http]: Кампания успешно получена")
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"rest-refs/internal/app/models"
+)
+
+// deviceFingerprintHeader is header in which client sends opaque identifier of its device
+const deviceFingerprintHeader = "X-Device-Fingerprint"
+
+// GetReferralReviewsHandler lists referrals in manual review queue
+// @Summary List referral reviews
+// @Description Returns referrals placed into manual review queue by fraud checks with their risk score and signals
+// @Description (admin only). Without status only pending reviews are returned
+// @Tags admin
+// @Produce  json
+// @Param status query string false "Comma-separated review statuses: pending, approved, rejected"
+// @Success 200 {array} models.ReferralReview "List of referral reviews"
+// @Failure 400 {object} models.Problem "Invalid status"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/referral_reviews [get]
+func (h *Handler) GetReferralReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetReferralReviewsHandler[http]: Получение рефералов на проверке")
+
+	reviews, err := h.service.GetReferralReviews(queryList(r, "status"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(reviews); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetReferralReviewsHandler[http]: Рефералы на проверке успешно получены")
+}
+
+// ApproveReferralReviewHandler approves referral from manual review queue
+// @Summary Approve referral review
+// @Description Approves referral held for manual review and credits rewards withheld during review (admin only).
+// @Description Request body is optional
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Referral ID"
+// @Param ReferralReviewDecisionRequest body models.ReferralReviewDecisionRequest false "Comment"
+// @Success 200 {object} models.ReferralReview "Reviewed referral"
+// @Failure 400 {object} models.Problem "Invalid ID or data format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Referral review not found"
+// @Failure 409 {object} models.Problem "Referral is not waiting for review"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/referral_reviews/{id}/approve [post]
+func (h *Handler) ApproveReferralReviewHandler(w http.ResponseWriter, r *http.Request) {
+	h.decideReferralReview(w, r, "ApproveReferralReviewHandler", h.service.ApproveReferralReview)
+}
+
+// RejectReferralReviewHandler rejects referral from manual review queue
+// @Summary Reject referral review
+// @Description Rejects referral held for manual review and reverses its rewards (admin only).
+// @Description Request body is optional
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Referral ID"
+// @Param ReferralReviewDecisionRequest body models.ReferralReviewDecisionRequest false "Reason of rejection"
+// @Success 200 {object} models.ReferralReview "Reviewed referral"
+// @Failure 400 {object} models.Problem "Invalid ID or data format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Referral review not found"
+// @Failure 409 {object} models.Problem "Referral is not waiting for review"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/referral_reviews/{id}/reject [post]
+func (h *Handler) RejectReferralReviewHandler(w http.ResponseWriter, r *http.Request) {
+	h.decideReferralReview(w, r, "RejectReferralReviewHandler", h.service.RejectReferralReview)
+}
+
+// decideReferralReview applies admin decision to referral review from path and writes reviewed referral
+func (h *Handler) decideReferralReview(w http.ResponseWriter, r *http.Request, name string,
+	decide func(id, adminID int, input models.ReferralReviewDecisionRequest) (models.ReferralReview, error)) {
+	h.logger.Debugf("%s[http]: Решение по проверке реферала", name)
+
+	adminID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	referralID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	var input models.ReferralReviewDecisionRequest
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	review, err := decide(referralID, adminID, input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(review); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("%s[http]: Реферал с id: %d проверен, статус проверки %s", name, review.ReferralID,
+		review.ReviewStatus)
+}
+
+// clientInfo describes client of request for fraud checks
+func clientInfo(r *http.Request) models.ClientInfo {
+	return models.ClientInfo{
+		IP:                clientIP(r),
+		DeviceFingerprint: r.Header.Get(deviceFingerprintHeader),
+	}
+}
+
+// recordUserDevice remembers device of user, failure never affects response
+func (h *Handler) recordUserDevice(email string, r *http.Request) {
+	if err := h.service.RecordUserDevice(email, clientInfo(r)); err != nil {
+		h.logger.Errorf("recordUserDevice[http]: Ошибка сохранения устройства пользователя %s: %s", email, err)
+	}
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"rest-refs/internal/app/models"
+)
+
+// GraphQLHandler executes GraphQL query of the authenticated user
+// @Summary Execute GraphQL query
+// @Description Executes query over the authenticated user (me), other users (user, admin only), their referral codes,
+// @Description referrals, statistics and campaign statistics in single round trip. Nested fields are loaded
+// @Description in batches. Queries deeper than GRAPHQL_MAX_DEPTH or more complex than GRAPHQL_MAX_COMPLEXITY
+// @Description are rejected. Errors of query are returned in errors field of response with status 200
+// @Tags graphql
+// @Accept  json
+// @Produce  json
+// @Param GraphQLRequest body models.GraphQLRequest true "GraphQL query"
+// @Success 200 {object} map[string]interface{} "GraphQL response with data and errors"
+// @Failure 400 {object} models.Problem "Invalid data format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /graphql [post]
+func (h *Handler) GraphQLHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GraphQLHandler[http]: Выполнение GraphQL запроса")
+
+	var request models.GraphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	result := h.graphql.Execute(r.Context(), request)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GraphQLHandler[http]: GraphQL запрос выполнен, ошибок: %d", len(result.Errors))
+}
@@ -2,24 +2,38 @@ package http
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/config"
+	graphqlServer "rest-refs/internal/app/graphql"
 )
 
 // Handler struct wraps service interface, which interacts with business logic
 type Handler struct {
-	service api.Service
-	logger  *logrus.Logger
+	service           api.Service
+	logger            *logrus.Logger
+	statusLimiter     *api.RateLimiter
+	landingURL        string
+	attributionWindow time.Duration
+	eventsAPIKey      string
+	graphql           *graphqlServer.Server
 }
 
-// New creates new Handler instance and takes api.Service and logger as parameters
-func New(service api.Service, logger *logrus.Logger) *Handler {
+// New creates new Handler instance and takes api.Service, config and logger as parameters
+func New(service api.Service, cfg *config.Config, logger *logrus.Logger) *Handler {
 	return &Handler{
-		service: service,
-		logger:  logger,
+		service:           service,
+		logger:            logger,
+		statusLimiter:     api.NewRateLimiter(cfg.StatusRateLimit, time.Minute),
+		landingURL:        cfg.LandingURL,
+		attributionWindow: cfg.AttributionWindow,
+		eventsAPIKey:      cfg.EventsAPIKey,
+		graphql:           graphqlServer.New(service, cfg, logger),
 	}
 }
 
@@ -45,13 +59,250 @@ func (h *Handler) RegisterRoutes(r *mux.Router) {
 	// @Router /referral_code [delete]
 	referralCodeRouter.Handle("", h.RequireValidTokenMiddleware(deleteReferralCodeRouter)).Methods("DELETE")
 
+	pauseReferralCodeRouter := http.HandlerFunc(h.PauseReferralCodeHandler)
+	// @Router /referral_code/pause [put]
+	referralCodeRouter.Handle("/pause", h.RequireValidTokenMiddleware(pauseReferralCodeRouter)).Methods("PUT")
+
+	resumeReferralCodeRouter := http.HandlerFunc(h.ResumeReferralCodeHandler)
+	// @Router /referral_code/resume [put]
+	referralCodeRouter.Handle("/resume", h.RequireValidTokenMiddleware(resumeReferralCodeRouter)).Methods("PUT")
+
+	rotateReferralCodeRouter := http.HandlerFunc(h.RotateReferralCodeHandler)
+	// @Router /referral_code/rotate [post]
+	referralCodeRouter.Handle("/rotate", h.RequireValidTokenMiddleware(rotateReferralCodeRouter)).Methods("POST")
+
+	referralLinksRouter := http.HandlerFunc(h.GetReferralLinksHandler)
+	// @Router /referral_code/history [get]
+	referralCodeRouter.Handle("/history", h.RequireValidTokenMiddleware(referralLinksRouter)).Methods("GET")
+
 	// @Router /referral_code/email/{email} [get]
 	referralCodeRouter.HandleFunc("/email/{email}", h.GetReferralCodeByEmailHandler).Methods("GET")
 
+	referralCodeQRRouter := http.HandlerFunc(h.GetReferralCodeQRHandler)
+	// @Router /referral_code/{id}/qr [get]
+	referralCodeRouter.Handle("/{id:[0-9]+}/qr", h.RequireValidTokenMiddleware(referralCodeQRRouter)).Methods("GET")
+
+	referralCodeStatusRouter := http.HandlerFunc(h.GetReferralCodeStatusHandler)
+	// @Router /referral_code/{code}/status [get]
+	referralCodeRouter.Handle("/{code}/status", h.RateLimitMiddleware(h.statusLimiter)(referralCodeStatusRouter)).Methods("GET")
+
 	referralRouter := r.PathPrefix("/referral").Subrouter()
 	// @Router /referral/id/{referrer_id} [get]
 	referralRouter.HandleFunc("/id/{referrer_id}", h.GetReferralsByReferrerIDHandler).Methods("GET")
 
+	campaignStatsRouter := http.HandlerFunc(h.GetCampaignStatsByReferrerIDHandler)
+	// @Router /referral/id/{referrer_id}/campaigns [get]
+	referralRouter.Handle("/id/{referrer_id}/campaigns",
+		h.RequireValidTokenMiddleware(campaignStatsRouter)).Methods("GET")
+
+	referralTreeRouter := http.HandlerFunc(h.GetReferralTreeHandler)
+	// @Router /referral/tree [get]
+	referralRouter.Handle("/tree", h.RequireValidTokenMiddleware(referralTreeRouter)).Methods("GET")
+
+	referralStatsRouter := http.HandlerFunc(h.GetReferralStatsHandler)
+	// @Router /referral/stats [get]
+	referralRouter.Handle("/stats", h.RequireValidTokenMiddleware(referralStatsRouter)).Methods("GET")
+
+	exportReferralsRouter := http.HandlerFunc(h.ExportReferralsHandler)
+	// @Router /referral/export [get]
+	referralRouter.Handle("/export", h.RequireValidTokenMiddleware(exportReferralsRouter)).Methods("GET")
+
+	streamReferralFeedRouter := http.HandlerFunc(h.StreamReferralFeedHandler)
+	// @Router /referral/stream [get]
+	referralRouter.Handle("/stream",
+		h.QueryTokenMiddleware(h.RequireValidTokenMiddleware(streamReferralFeedRouter))).Methods("GET")
+
+	streamReferralFeedWebSocketRouter := http.HandlerFunc(h.StreamReferralFeedWebSocketHandler)
+	// @Router /referral/stream/ws [get]
+	referralRouter.Handle("/stream/ws",
+		h.QueryTokenMiddleware(h.RequireValidTokenMiddleware(streamReferralFeedWebSocketRouter))).Methods("GET")
+
+	ingestReferralEventRouter := http.HandlerFunc(h.IngestReferralEventHandler)
+	// @Router /referral/events [post]
+	referralRouter.Handle("/events", h.RequireAPIKeyMiddleware(ingestReferralEventRouter)).Methods("POST")
+
+	campaignRouter := r.PathPrefix("/campaign").Subrouter()
+
+	getCampaignsRouter := http.HandlerFunc(h.GetCampaignsHandler)
+	// @Router /campaign [get]
+	campaignRouter.Handle("", h.RequireValidTokenMiddleware(getCampaignsRouter)).Methods("GET")
+
+	getCampaignRouter := http.HandlerFunc(h.GetCampaignByIDHandler)
+	// @Router /campaign/{id} [get]
+	campaignRouter.Handle("/{id:[0-9]+}", h.RequireValidTokenMiddleware(getCampaignRouter)).Methods("GET")
+
+	meRouter := r.PathPrefix("/me").Subrouter()
+
+	getMyRewardsRouter := http.HandlerFunc(h.GetMyRewardsHandler)
+	// @Router /me/rewards [get]
+	meRouter.Handle("/rewards", h.RequireValidTokenMiddleware(getMyRewardsRouter)).Methods("GET")
+
+	requestPayoutRouter := http.HandlerFunc(h.RequestPayoutHandler)
+	// @Router /me/payouts [post]
+	meRouter.Handle("/payouts", h.RequireValidTokenMiddleware(requestPayoutRouter)).Methods("POST")
+
+	getMyPayoutsRouter := http.HandlerFunc(h.GetMyPayoutsHandler)
+	// @Router /me/payouts [get]
+	meRouter.Handle("/payouts", h.RequireValidTokenMiddleware(getMyPayoutsRouter)).Methods("GET")
+
+	getLeaderboardSettingsRouter := http.HandlerFunc(h.GetLeaderboardSettingsHandler)
+	// @Router /me/leaderboard [get]
+	meRouter.Handle("/leaderboard", h.RequireValidTokenMiddleware(getLeaderboardSettingsRouter)).Methods("GET")
+
+	updateLeaderboardSettingsRouter := http.HandlerFunc(h.UpdateLeaderboardSettingsHandler)
+	// @Router /me/leaderboard [put]
+	meRouter.Handle("/leaderboard", h.RequireValidTokenMiddleware(updateLeaderboardSettingsRouter)).Methods("PUT")
+
+	getNotificationSettingsRouter := http.HandlerFunc(h.GetNotificationSettingsHandler)
+	// @Router /me/notifications [get]
+	meRouter.Handle("/notifications", h.RequireValidTokenMiddleware(getNotificationSettingsRouter)).Methods("GET")
+
+	updateNotificationSettingsRouter := http.HandlerFunc(h.UpdateNotificationSettingsHandler)
+	// @Router /me/notifications [put]
+	meRouter.Handle("/notifications", h.RequireValidTokenMiddleware(updateNotificationSettingsRouter)).Methods("PUT")
+
+	getLeaderboardRouter := http.HandlerFunc(h.GetLeaderboardHandler)
+	// @Router /leaderboard [get]
+	r.Handle("/leaderboard", h.RequireValidTokenMiddleware(getLeaderboardRouter)).Methods("GET")
+
+	webhookRouter := r.PathPrefix("/webhooks").Subrouter()
+
+	createWebhookSubscriptionRouter := http.HandlerFunc(h.CreateWebhookSubscriptionHandler)
+	// @Router /webhooks [post]
+	webhookRouter.Handle("", h.RequireValidTokenMiddleware(createWebhookSubscriptionRouter)).Methods("POST")
+
+	getWebhookSubscriptionsRouter := http.HandlerFunc(h.GetWebhookSubscriptionsHandler)
+	// @Router /webhooks [get]
+	webhookRouter.Handle("", h.RequireValidTokenMiddleware(getWebhookSubscriptionsRouter)).Methods("GET")
+
+	deleteWebhookSubscriptionRouter := http.HandlerFunc(h.DeleteWebhookSubscriptionHandler)
+	// @Router /webhooks/{id} [delete]
+	webhookRouter.Handle("/{id:[0-9]+}", h.RequireValidTokenMiddleware(deleteWebhookSubscriptionRouter)).Methods("DELETE")
+
+	getWebhookDeliveriesRouter := http.HandlerFunc(h.GetWebhookDeliveriesHandler)
+	// @Router /webhooks/{id}/deliveries [get]
+	webhookRouter.Handle("/{id:[0-9]+}/deliveries",
+		h.RequireValidTokenMiddleware(getWebhookDeliveriesRouter)).Methods("GET")
+
+	getWebhookDeliveryRouter := http.HandlerFunc(h.GetWebhookDeliveryHandler)
+	// @Router /webhooks/{id}/deliveries/{delivery_id} [get]
+	webhookRouter.Handle("/{id:[0-9]+}/deliveries/{delivery_id:[0-9]+}",
+		h.RequireValidTokenMiddleware(getWebhookDeliveryRouter)).Methods("GET")
+
+	redeliverWebhookRouter := http.HandlerFunc(h.RedeliverWebhookHandler)
+	// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+	webhookRouter.Handle("/{id:[0-9]+}/deliveries/{delivery_id:[0-9]+}/redeliver",
+		h.RequireValidTokenMiddleware(redeliverWebhookRouter)).Methods("POST")
+
+	adminRouter := r.PathPrefix("/admin").Subrouter()
+
+	createReferralCodeBatchRouter := http.HandlerFunc(h.CreateReferralCodeBatchHandler)
+	// @Router /admin/referral_code/batch [post]
+	adminRouter.Handle("/referral_code/batch",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(createReferralCodeBatchRouter))).Methods("POST")
+
+	getReferralCodeBatchCSVRouter := http.HandlerFunc(h.GetReferralCodeBatchCSVHandler)
+	// @Router /admin/referral_code/batch/{id}/csv [get]
+	adminRouter.Handle("/referral_code/batch/{id:[0-9]+}/csv",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(getReferralCodeBatchCSVRouter))).Methods("GET")
+
+	createCampaignRouter := http.HandlerFunc(h.CreateCampaignHandler)
+	// @Router /admin/campaign [post]
+	adminRouter.Handle("/campaign",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(createCampaignRouter))).Methods("POST")
+
+	updateCampaignRouter := http.HandlerFunc(h.UpdateCampaignHandler)
+	// @Router /admin/campaign/{id} [put]
+	adminRouter.Handle("/campaign/{id:[0-9]+}",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(updateCampaignRouter))).Methods("PUT")
+
+	deleteCampaignRouter := http.HandlerFunc(h.DeleteCampaignHandler)
+	// @Router /admin/campaign/{id} [delete]
+	adminRouter.Handle("/campaign/{id:[0-9]+}",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(deleteCampaignRouter))).Methods("DELETE")
+
+	createRewardRuleSetRouter := http.HandlerFunc(h.CreateRewardRuleSetHandler)
+	// @Router /admin/reward_rules [post]
+	adminRouter.Handle("/reward_rules",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(createRewardRuleSetRouter))).Methods("POST")
+
+	getRewardRuleSetsRouter := http.HandlerFunc(h.GetRewardRuleSetsHandler)
+	// @Router /admin/reward_rules [get]
+	adminRouter.Handle("/reward_rules",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(getRewardRuleSetsRouter))).Methods("GET")
+
+	dryRunRewardRouter := http.HandlerFunc(h.DryRunRewardHandler)
+	// @Router /admin/reward_rules/dry_run [post]
+	adminRouter.Handle("/reward_rules/dry_run",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(dryRunRewardRouter))).Methods("POST")
+
+	getRewardRuleSetRouter := http.HandlerFunc(h.GetRewardRuleSetByIDHandler)
+	// @Router /admin/reward_rules/{id} [get]
+	adminRouter.Handle("/reward_rules/{id:[0-9]+}",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(getRewardRuleSetRouter))).Methods("GET")
+
+	updateRewardRuleSetRouter := http.HandlerFunc(h.UpdateRewardRuleSetHandler)
+	// @Router /admin/reward_rules/{id} [put]
+	adminRouter.Handle("/reward_rules/{id:[0-9]+}",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(updateRewardRuleSetRouter))).Methods("PUT")
+
+	deleteRewardRuleSetRouter := http.HandlerFunc(h.DeleteRewardRuleSetHandler)
+	// @Router /admin/reward_rules/{id} [delete]
+	adminRouter.Handle("/reward_rules/{id:[0-9]+}",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(deleteRewardRuleSetRouter))).Methods("DELETE")
+
+	getPayoutsRouter := http.HandlerFunc(h.GetPayoutsHandler)
+	// @Router /admin/payouts [get]
+	adminRouter.Handle("/payouts",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(getPayoutsRouter))).Methods("GET")
+
+	getPayoutRouter := http.HandlerFunc(h.GetPayoutByIDHandler)
+	// @Router /admin/payouts/{id} [get]
+	adminRouter.Handle("/payouts/{id:[0-9]+}",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(getPayoutRouter))).Methods("GET")
+
+	approvePayoutRouter := http.HandlerFunc(h.ApprovePayoutHandler)
+	// @Router /admin/payouts/{id}/approve [post]
+	adminRouter.Handle("/payouts/{id:[0-9]+}/approve",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(approvePayoutRouter))).Methods("POST")
+
+	rejectPayoutRouter := http.HandlerFunc(h.RejectPayoutHandler)
+	// @Router /admin/payouts/{id}/reject [post]
+	adminRouter.Handle("/payouts/{id:[0-9]+}/reject",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(rejectPayoutRouter))).Methods("POST")
+
+	getReferralReviewsRouter := http.HandlerFunc(h.GetReferralReviewsHandler)
+	// @Router /admin/referral_reviews [get]
+	adminRouter.Handle("/referral_reviews",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(getReferralReviewsRouter))).Methods("GET")
+
+	approveReferralReviewRouter := http.HandlerFunc(h.ApproveReferralReviewHandler)
+	// @Router /admin/referral_reviews/{id}/approve [post]
+	adminRouter.Handle("/referral_reviews/{id:[0-9]+}/approve",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(approveReferralReviewRouter))).Methods("POST")
+
+	rejectReferralReviewRouter := http.HandlerFunc(h.RejectReferralReviewHandler)
+	// @Router /admin/referral_reviews/{id}/reject [post]
+	adminRouter.Handle("/referral_reviews/{id:[0-9]+}/reject",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(rejectReferralReviewRouter))).Methods("POST")
+
+	programReferralStatsRouter := http.HandlerFunc(h.GetProgramReferralStatsHandler)
+	// @Router /admin/referral/stats [get]
+	adminRouter.Handle("/referral/stats",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(programReferralStatsRouter))).Methods("GET")
+
+	exportProgramReferralsRouter := http.HandlerFunc(h.ExportProgramReferralsHandler)
+	// @Router /admin/referral/export [get]
+	adminRouter.Handle("/referral/export",
+		h.RequireValidTokenMiddleware(h.RequireAdminMiddleware(exportProgramReferralsRouter))).Methods("GET")
+
+	graphQLRouter := http.HandlerFunc(h.GraphQLHandler)
+	// @Router /graphql [post]
+	r.Handle("/graphql", h.RequireValidTokenMiddleware(graphQLRouter)).Methods("POST")
+
+	// @Router /r/{code} [get]
+	r.HandleFunc("/r/{code}", h.ReferralRedirectHandler).Methods("GET")
+
 	// Swagger documentation endpoint
 	r.PathPrefix("/docs/swagger/").Handler(httpSwagger.WrapHandler)
 	r.HandleFunc("/docs/swagger/index.html", httpSwagger.WrapHandler)
@@ -65,7 +316,24 @@ func (h *Handler) StartServer(port string) {
 	router.Use(h.RequestIDMiddleware)
 	h.RegisterRoutes(router)
 
+	// Middlewares run only for matched routes, so replies to unmatched requests get request ID on their own
+	router.NotFoundHandler = h.RequestIDMiddleware(http.HandlerFunc(h.notFoundHandler))
+	router.MethodNotAllowedHandler = h.RequestIDMiddleware(http.HandlerFunc(h.methodNotAllowedHandler))
+
 	if err := http.ListenAndServe(port, router); err != nil {
 		h.logger.Fatalf("Не удалось запустить сервер: %s", err)
 	}
 }
+
+// queryList returns values of query parameter given as comma-separated list or as repeated parameter
+func queryList(r *http.Request, key string) []string {
+	var values []string
+	for _, value := range r.URL.Query()[key] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
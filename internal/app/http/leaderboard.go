@@ -0,0 +1,161 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+// GetLeaderboardHandler ranks referrers
+// @Summary Get leaderboard
+// @Description Ranks referrers by metric over calendar window up to now, optionally within campaign,
+// @Description and returns page of ranking with rank of the authenticated user.
+// @Description Users who did not opt in are listed without display name. Ranking is cached for a short time
+// @Tags leaderboard
+// @Produce  json
+// @Param metric query string false "referrals, qualified (default) or rewards"
+// @Param window query string false "day, week, month (default), year or total"
+// @Param campaign_id query int false "Campaign ID"
+// @Param limit query int false "Page size, from 1 to 100, default 20"
+// @Param offset query int false "Number of ranked users to skip"
+// @Success 200 {object} models.LeaderboardResponse "Leaderboard"
+// @Failure 400 {object} models.Problem "Invalid parameters"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /leaderboard [get]
+func (h *Handler) GetLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetLeaderboardHandler[http]: Получение рейтинга")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	opts := models.LeaderboardOptions{
+		Metric: query.Get("metric"),
+		Window: query.Get("window"),
+	}
+
+	if value := query.Get("campaign_id"); value != "" {
+		campaignID, err := strconv.Atoi(value)
+		if err != nil {
+			h.writeInvalidParameter(w, r, "campaign_id", "Неправильный формат ID кампании")
+			return
+		}
+		opts.CampaignID = &campaignID
+	}
+
+	var err error
+	if value := query.Get("limit"); value != "" {
+		if opts.Limit, err = strconv.Atoi(value); err != nil || opts.Limit <= 0 {
+			h.writeInvalidParameter(w, r, "limit", "Неправильный limit")
+			return
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if opts.Offset, err = strconv.Atoi(value); err != nil {
+			h.writeInvalidParameter(w, r, "offset", "Неправильный offset")
+			return
+		}
+	}
+
+	leaderboard, err := h.service.GetLeaderboard(userID, opts)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(leaderboard); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetLeaderboardHandler[http]: Рейтинг успешно получен")
+}
+
+// GetLeaderboardSettingsHandler returns leaderboard settings of the authenticated user
+// @Summary Get leaderboard settings
+// @Description Returns whether the authenticated user is shown on leaderboards by display name
+// @Tags leaderboard
+// @Produce  json
+// @Success 200 {object} models.LeaderboardSettings "Leaderboard settings"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /me/leaderboard [get]
+func (h *Handler) GetLeaderboardSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetLeaderboardSettingsHandler[http]: Получение настроек рейтинга")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	settings, err := h.service.GetLeaderboardSettings(userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(settings); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetLeaderboardSettingsHandler[http]: Настройки рейтинга успешно получены")
+}
+
+// UpdateLeaderboardSettingsHandler opts the authenticated user in or out of public display on leaderboards
+// @Summary Update leaderboard settings
+// @Description Opts the authenticated user in or out of public display on leaderboards.
+// @Description Display name is required to opt in, email is never shown
+// @Tags leaderboard
+// @Accept  json
+// @Produce  json
+// @Param LeaderboardSettings body models.LeaderboardSettings true "Leaderboard settings"
+// @Success 200 {object} models.LeaderboardSettings "Leaderboard settings"
+// @Failure 400 {object} models.Problem "Invalid data format or display name"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /me/leaderboard [put]
+func (h *Handler) UpdateLeaderboardSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("UpdateLeaderboardSettingsHandler[http]: Изменение настроек рейтинга")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	var input models.LeaderboardSettings
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	settings, err := h.service.UpdateLeaderboardSettings(userID, input)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrUserNotFound) {
+			h.writeUnauthenticated(w, r)
+			return
+		}
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(settings); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("UpdateLeaderboardSettingsHandler[http]: Настройки рейтинга успешно изменены")
+}
@@ -2,21 +2,65 @@ package http
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// RequestIDMiddleware adds requested endpoint to request context for further use
-// This middleware extracts endpoint from request URL and stores it in the context
+// maxRequestIDLength is maximum length of request ID accepted from client
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware adds request ID and requested endpoint to request context for further use
+// Request ID is taken from X-Request-ID header or generated, and is returned in the same header
+// and in every error response, so that client reports can be matched with server logs
 func (h *Handler) RequestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+
 		// Extract endpoint information from request
 		endpoint := r.URL.Path
 		ctx := context.WithValue(r.Context(), "endpoint", endpoint)
+		ctx = context.WithValue(ctx, "RequestID", id)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// requestID returns request ID stored by RequestIDMiddleware, or empty string outside of it
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value("RequestID").(string)
+	return id
+}
+
+// validRequestID reports whether request ID from client is short and consists of printable ASCII characters
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates random request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
 // RequireValidTokenMiddleware validates JWT from Authorization header
 // This middleware checks if valid token is provided, extracts user ID from claims,
 // and adds user ID to request context for further use
@@ -29,7 +73,7 @@ func (h *Handler) RequireValidTokenMiddleware(next http.Handler) http.Handler {
 
 		// Check if the Authorization header is missing
 		if authHeader == "" {
-			http.Error(w, "Не указан заголовок авторизации", http.StatusInternalServerError)
+			h.writeProblem(w, r, http.StatusUnauthorized, codeMissingAuthorization, "Не указан заголовок авторизации")
 			return
 		}
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
@@ -37,19 +81,19 @@ func (h *Handler) RequireValidTokenMiddleware(next http.Handler) http.Handler {
 		// Check if token is valid
 		authenticated, claims, err := h.service.IsTokenValid(tokenString)
 		if err != nil {
-			http.Error(w, "Проблема на сервере", http.StatusInternalServerError)
+			h.writeError(w, r, err)
 			return
 		}
 
 		// If token is not valid, return unauthorized status
 		if !authenticated {
-			http.Error(w, "Пользователь не авторизован", http.StatusUnauthorized)
+			h.writeProblem(w, r, http.StatusUnauthorized, codeInvalidToken, "Пользователь не авторизован")
 			return
 		}
 
 		userID, ok := claims["id"].(float64)
 		if !ok {
-			http.Error(w, "Ошибка токена", http.StatusUnauthorized)
+			h.writeProblem(w, r, http.StatusUnauthorized, codeInvalidToken, "Ошибка токена")
 			return
 		}
 
@@ -58,3 +102,58 @@ func (h *Handler) RequireValidTokenMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// QueryTokenMiddleware takes JWT from access_token query parameter when Authorization header is missing
+// Browser EventSource and WebSocket cannot set headers, so streaming endpoints accept token in URL
+func (h *Handler) QueryTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("access_token")
+		if token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAdminMiddleware allows request only for administrators
+// It must be wrapped by RequireValidTokenMiddleware, which puts user ID into request context
+func (h *Handler) RequireAdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("UserID").(int)
+		if !ok {
+			h.writeUnauthenticated(w, r)
+			return
+		}
+
+		// Admin flag is read from database, so revoked rights take effect without reissuing token
+		user, err := h.service.GetUserByID(userID)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+
+		if !user.IsAdmin {
+			h.logger.Warnf("RequireAdminMiddleware[http]: Пользователь с id: %d не является администратором", userID)
+			h.writeProblem(w, r, http.StatusForbidden, codeForbidden, "Недостаточно прав")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAPIKeyMiddleware allows request only with X-Api-Key header matching configured events API key
+// If key is not configured, all requests are rejected
+func (h *Handler) RequireAPIKeyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-Api-Key")
+
+		if h.eventsAPIKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(h.eventsAPIKey)) != 1 {
+			h.logger.Warnf("RequireAPIKeyMiddleware[http]: Неверный API-ключ для %s", r.URL.Path)
+			h.writeProblem(w, r, http.StatusUnauthorized, codeInvalidAPIKey, "Неверный API-ключ")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
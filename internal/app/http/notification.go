@@ -0,0 +1,95 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+// GetNotificationSettingsHandler returns email notification settings of the authenticated user
+// @Summary Get notification settings
+// @Description Returns how the authenticated user is emailed about new referrals and language of emails
+// @Tags notifications
+// @Produce  json
+// @Success 200 {object} models.NotificationSettings "Notification settings"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /me/notifications [get]
+func (h *Handler) GetNotificationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetNotificationSettingsHandler[http]: Получение настроек уведомлений")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	settings, err := h.service.GetNotificationSettings(userID)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrUserNotFound) {
+			h.writeUnauthenticated(w, r)
+			return
+		}
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(settings); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetNotificationSettingsHandler[http]: Настройки уведомлений успешно получены")
+}
+
+// UpdateNotificationSettingsHandler changes email notification settings of the authenticated user
+// @Summary Update notification settings
+// @Description Sets whether the authenticated user is emailed about every new referral at once (immediate),
+// @Description once a day in digest (digest) or not at all (off), and language of emails (ru or en).
+// @Description Notifications already queued are not sent after mode is set to off. Omitted fields are left unchanged
+// @Tags notifications
+// @Accept  json
+// @Produce  json
+// @Param NotificationSettings body models.NotificationSettingsRequest true "Notification settings"
+// @Success 200 {object} models.NotificationSettings "Notification settings"
+// @Failure 400 {object} models.Problem "Invalid data format, mode or locale"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /me/notifications [put]
+func (h *Handler) UpdateNotificationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("UpdateNotificationSettingsHandler[http]: Изменение настроек уведомлений")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	var input models.NotificationSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	settings, err := h.service.UpdateNotificationSettings(userID, input)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrUserNotFound) {
+			h.writeUnauthenticated(w, r)
+			return
+		}
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(settings); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("UpdateNotificationSettingsHandler[http]: Настройки уведомлений успешно изменены")
+}
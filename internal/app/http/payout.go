@@ -0,0 +1,240 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"rest-refs/internal/app/models"
+)
+
+// RequestPayoutHandler requests payout of available rewards
+// @Summary Request payout
+// @Description Reserves amount of available rewards of the authenticated user for payout to destination.
+// @Description Amount must not be less than minimum payout, rewards of referrals still inside chargeback window
+// @Description are not available
+// @Tags rewards
+// @Accept  json
+// @Produce  json
+// @Param PayoutRequest body models.PayoutRequest true "Payout request"
+// @Success 201 {object} models.Payout "Payout requested"
+// @Failure 400 {object} models.Problem "Invalid data format, amount or destination"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 409 {object} models.Problem "Not enough available rewards"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /me/payouts [post]
+func (h *Handler) RequestPayoutHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("RequestPayoutHandler[http]: Запрос выплаты")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	var input models.PayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	payout, err := h.service.RequestPayout(userID, input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payout)
+
+	h.logger.Debugf("RequestPayoutHandler[http]: Выплата успешно запрошена")
+}
+
+// GetMyPayoutsHandler lists payouts of the authenticated user
+// @Summary Get my payouts
+// @Description Returns payouts of the authenticated user from newest to oldest
+// @Tags rewards
+// @Produce  json
+// @Success 200 {array} models.Payout "List of payouts"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /me/payouts [get]
+func (h *Handler) GetMyPayoutsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetMyPayoutsHandler[http]: Получение выплат")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	payouts, err := h.service.GetPayoutsByUserID(userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(payouts); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetMyPayoutsHandler[http]: Выплаты успешно получены")
+}
+
+// GetPayoutsHandler lists payouts for admins
+// @Summary List payouts
+// @Description Returns payouts in order of requests (admin only), status=requested is approval queue
+// @Tags admin
+// @Produce  json
+// @Param status query string false "Comma-separated statuses: requested, approved, rejected, paid, failed"
+// @Success 200 {array} models.Payout "List of payouts"
+// @Failure 400 {object} models.Problem "Invalid status"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/payouts [get]
+func (h *Handler) GetPayoutsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetPayoutsHandler[http]: Получение списка выплат")
+
+	payouts, err := h.service.GetPayouts(queryList(r, "status"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(payouts); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetPayoutsHandler[http]: Список выплат успешно получен")
+}
+
+// GetPayoutByIDHandler retrieves payout with its audit history
+// @Summary Get payout
+// @Description Returns payout with history of its status changes (admin only)
+// @Tags admin
+// @Produce  json
+// @Param id path int true "Payout ID"
+// @Success 200 {object} models.Payout "Payout"
+// @Failure 400 {object} models.Problem "Invalid ID format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Payout not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/payouts/{id} [get]
+func (h *Handler) GetPayoutByIDHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetPayoutByIDHandler[http]: Получение выплаты")
+
+	vars := mux.Vars(r)
+	payoutID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	payout, err := h.service.GetPayoutByID(payoutID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(payout); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetPayoutByIDHandler[http]: Выплата успешно получена")
+}
+
+// ApprovePayoutHandler approves requested payout and sends it
+// @Summary Approve payout
+// @Description Approves requested payout and sends it through payout provider (admin only).
+// @Description Payout becomes paid, or failed with its amount returned to user if provider declined it.
+// @Description If provider did not answer whether payout was sent, payout is left approved and 502 is returned.
+// @Description Payout left approved is sent again with the same idempotency key.
+// @Description Request body is optional
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Payout ID"
+// @Param PayoutDecisionRequest body models.PayoutDecisionRequest false "Comment"
+// @Success 200 {object} models.Payout "Processed payout"
+// @Failure 400 {object} models.Problem "Invalid ID or data format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Payout not found"
+// @Failure 409 {object} models.Problem "Payout is not waiting for approval or sending"
+// @Failure 500 {object} models.Problem "Server error"
+// @Failure 502 {object} models.Problem "Provider did not confirm sending, approve again later"
+// @Router /admin/payouts/{id}/approve [post]
+func (h *Handler) ApprovePayoutHandler(w http.ResponseWriter, r *http.Request) {
+	h.decidePayout(w, r, "ApprovePayoutHandler", h.service.ApprovePayout)
+}
+
+// RejectPayoutHandler rejects requested payout
+// @Summary Reject payout
+// @Description Rejects requested payout and returns its amount to user (admin only). Request body is optional
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Payout ID"
+// @Param PayoutDecisionRequest body models.PayoutDecisionRequest false "Reason of rejection"
+// @Success 200 {object} models.Payout "Rejected payout"
+// @Failure 400 {object} models.Problem "Invalid ID or data format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Payout not found"
+// @Failure 409 {object} models.Problem "Payout is not waiting for approval"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/payouts/{id}/reject [post]
+func (h *Handler) RejectPayoutHandler(w http.ResponseWriter, r *http.Request) {
+	h.decidePayout(w, r, "RejectPayoutHandler", h.service.RejectPayout)
+}
+
+// decidePayout applies admin decision to payout from path and writes processed payout
+func (h *Handler) decidePayout(w http.ResponseWriter, r *http.Request, name string,
+	decide func(id, adminID int, input models.PayoutDecisionRequest) (models.Payout, error)) {
+	h.logger.Debugf("%s[http]: Решение по выплате", name)
+
+	adminID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	payoutID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	var input models.PayoutDecisionRequest
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	payout, err := decide(payoutID, adminID, input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(payout); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("%s[http]: Выплата с id: %d в статусе %s", name, payout.ID, payout.Status)
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/models"
+)
+
+// problemTypePrefix is prepended to code of error to build type URI of problem
+const problemTypePrefix = "urn:rest-refs:problem:"
+
+// Codes of errors found by handlers and middlewares themselves, errors of service get codes from api.LookupError
+const (
+	codeInternalError          = "internal_error"
+	codeResponseEncodingFailed = "response_encoding_failed"
+	codeMissingAuthorization   = "missing_authorization"
+	codeInvalidToken           = "invalid_token"
+	codeUnauthenticated        = "unauthenticated"
+	codeForbidden              = "forbidden"
+	codeInvalidAPIKey          = "invalid_api_key"
+	codeRateLimited            = "rate_limited"
+	codeInvalidBody            = "invalid_body"
+	codeInvalidParameter       = "invalid_parameter"
+	codeNotFound               = "not_found"
+	codeMethodNotAllowed       = "method_not_allowed"
+)
+
+// writeError writes problem mapped from error returned by service
+// Validation errors of one field are reported in errors of problem, unknown errors are logged and hidden
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if mapping, ok := api.LookupError(err); ok {
+		detail := mapping.Detail
+		if detail == "" {
+			detail = err.Error()
+		}
+
+		var fields []models.ProblemField
+		var fieldErr *api.FieldError
+		if errors.As(err, &fieldErr) {
+			fields = append(fields, models.ProblemField{Field: fieldErr.Field, Message: fieldErr.Message})
+		}
+
+		h.writeProblem(w, r, mapping.HTTPStatus, mapping.Code, detail, fields...)
+		return
+	}
+
+	h.logger.Errorf("writeError[http]: Необработанная ошибка %s %s: %s", r.Method, r.URL.Path, err)
+	h.writeProblem(w, r, http.StatusInternalServerError, codeInternalError, "Проблема на сервере")
+}
+
+// writeDecodeError writes problem about malformed body of request, naming field of wrong type if there is one
+func (h *Handler) writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		h.writeProblem(w, r, http.StatusBadRequest, codeInvalidBody, "Неправильный формат данных",
+			models.ProblemField{Field: typeErr.Field, Message: "ожидается " + typeErr.Type.String()})
+		return
+	}
+
+	h.writeProblem(w, r, http.StatusBadRequest, codeInvalidBody, "Неправильный формат данных")
+}
+
+// writeInvalidParameter writes problem about invalid parameter of path or query
+func (h *Handler) writeInvalidParameter(w http.ResponseWriter, r *http.Request, field, detail string) {
+	h.writeProblem(w, r, http.StatusBadRequest, codeInvalidParameter, detail,
+		models.ProblemField{Field: field, Message: detail})
+}
+
+// writeUnauthenticated writes problem about request without authenticated user in context
+func (h *Handler) writeUnauthenticated(w http.ResponseWriter, r *http.Request) {
+	h.writeProblem(w, r, http.StatusUnauthorized, codeUnauthenticated, "Ошибка аутентификации")
+}
+
+// writeEncodeError writes problem about response that could not be encoded
+// Headers may already be sent, then problem only ends up after partial body
+func (h *Handler) writeEncodeError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Errorf("writeEncodeError[http]: Ошибка кодирования ответа %s %s: %s", r.Method, r.URL.Path, err)
+	h.writeProblem(w, r, http.StatusInternalServerError, codeResponseEncodingFailed, "Ошибка кодирования ответа")
+}
+
+// notFoundHandler writes problem for request that matches no route
+func (h *Handler) notFoundHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeProblem(w, r, http.StatusNotFound, codeNotFound, "Ресурс не найден")
+}
+
+// methodNotAllowedHandler writes problem for request with method not supported by matched route
+func (h *Handler) methodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeProblem(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Метод не поддерживается")
+}
+
+// writeProblem writes RFC 7807 application/problem+json response with request ID of request
+func (h *Handler) writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string,
+	fields ...models.ProblemField) {
+	problem := models.Problem{
+		Type:      problemTypePrefix + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: requestID(r),
+		Errors:    fields,
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		h.logger.Errorf("writeProblem[http]: Ошибка кодирования ответа: %s", err)
+	}
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/postgresql"
+)
+
+func testHandler() *Handler {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return &Handler{logger: logger}
+}
+
+// testWriteError writes problem of err and decodes it
+func testWriteError(t *testing.T, err error) (*httptest.ResponseRecorder, models.Problem) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	testHandler().writeError(recorder, httptest.NewRequest(http.MethodGet, "/referral", nil), err)
+
+	var problem models.Problem
+	if err := json.NewDecoder(recorder.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	return recorder, problem
+}
+
+// TestWriteErrorSentinels pins status and type of problem of every sentinel error, they are part of API
+func TestWriteErrorSentinels(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{api.ErrUserAlreadyExists, http.StatusConflict, "user_already_exists"},
+		{api.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+		{api.ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+		{postgresql.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+		{api.ErrReferralCodeMalformed, http.StatusBadRequest, "referral_code_malformed"},
+		{postgresql.ErrReferralCodeNotFound, http.StatusNotFound, "referral_code_not_found"},
+		{postgresql.ErrReferralCodeNotActive, http.StatusBadRequest, "referral_code_not_active"},
+		{api.ErrReferralCodeAlreadyExists, http.StatusConflict, "referral_code_already_exists"},
+		{api.ErrInvalidExpiration, http.StatusBadRequest, "invalid_expiration"},
+		{api.ErrExpirationInPast, http.StatusBadRequest, "expiration_in_past"},
+		{api.ErrExpirationTooFar, http.StatusBadRequest, "expiration_too_far"},
+		{api.ErrUnknownTimeZone, http.StatusBadRequest, "unknown_time_zone"},
+		{api.ErrInvalidGracePeriod, http.StatusBadRequest, "invalid_grace_period"},
+		{api.ErrInvalidQRCodeOptions, http.StatusBadRequest, "invalid_qr_code_options"},
+		{api.ErrInvalidBatchSize, http.StatusBadRequest, "invalid_batch_size"},
+		{postgresql.ErrReferralCodeBatchNotFound, http.StatusNotFound, "referral_code_batch_not_found"},
+		{api.ErrInvalidCampaign, http.StatusBadRequest, "invalid_campaign"},
+		{api.ErrCampaignNotActive, http.StatusConflict, "campaign_not_active"},
+		{postgresql.ErrCampaignNotFound, http.StatusNotFound, "campaign_not_found"},
+		{postgresql.ErrReferralNotFound, http.StatusNotFound, "referral_not_found"},
+		{api.ErrInvalidReferralStatus, http.StatusBadRequest, "invalid_referral_status"},
+		{api.ErrCampaignStatsForbidden, http.StatusForbidden, "campaign_stats_forbidden"},
+		{api.ErrInvalidReferralList, http.StatusBadRequest, "invalid_referral_list"},
+		{api.ErrInvalidReferralTreeDepth, http.StatusBadRequest, "invalid_referral_tree_depth"},
+		{api.ErrInvalidReferralExportFormat, http.StatusBadRequest, "invalid_export_format"},
+		{api.ErrInvalidReferralStats, http.StatusBadRequest, "invalid_referral_stats"},
+		{api.ErrInvalidReferralEvent, http.StatusBadRequest, "invalid_referral_event"},
+		{api.ErrInvalidReviewStatus, http.StatusBadRequest, "invalid_review_status"},
+		{postgresql.ErrReferralReviewNotFound, http.StatusNotFound, "referral_review_not_found"},
+		{postgresql.ErrReferralReviewConflict, http.StatusConflict, "referral_review_conflict"},
+		{api.ErrInvalidRewardRuleSet, http.StatusBadRequest, "invalid_reward_rule_set"},
+		{api.ErrRewardAmountOutOfRange, http.StatusBadRequest, "reward_amount_out_of_range"},
+		{postgresql.ErrRewardRuleSetNotFound, http.StatusNotFound, "reward_rule_set_not_found"},
+		{api.ErrInvalidRewardDryRun, http.StatusBadRequest, "invalid_reward_dry_run"},
+		{api.ErrInvalidPayout, http.StatusBadRequest, "invalid_payout"},
+		{api.ErrInvalidPayoutStatus, http.StatusBadRequest, "invalid_payout_status"},
+		{api.ErrPayoutNotSent, http.StatusBadGateway, "payout_not_sent"},
+		{postgresql.ErrPayoutNotFound, http.StatusNotFound, "payout_not_found"},
+		{postgresql.ErrPayoutStatusConflict, http.StatusConflict, "payout_status_conflict"},
+		{api.ErrInvalidLeaderboard, http.StatusBadRequest, "invalid_leaderboard_query"},
+		{api.ErrInvalidLeaderboardSettings, http.StatusBadRequest, "invalid_leaderboard_settings"},
+		{api.ErrInvalidNotificationSettings, http.StatusBadRequest, "invalid_notification_settings"},
+		{api.ErrInvalidWebhookSubscription, http.StatusBadRequest, "invalid_webhook_subscription"},
+		{api.ErrWebhookForbidden, http.StatusForbidden, "webhook_forbidden"},
+		{api.ErrInvalidWebhookDeliveryStatus, http.StatusBadRequest, "invalid_webhook_delivery_status"},
+		{postgresql.ErrWebhookSubscriptionNotFound, http.StatusNotFound, "webhook_subscription_not_found"},
+		{postgresql.ErrWebhookDeliveryNotFound, http.StatusNotFound, "webhook_delivery_not_found"},
+		{postgresql.ErrWebhookDeliveryPending, http.StatusConflict, "webhook_delivery_pending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			recorder, problem := testWriteError(t, fmt.Errorf("%w: подробности", tt.err))
+
+			if recorder.Code != tt.status || problem.Status != tt.status {
+				t.Errorf("status = %d, problem status = %d, want %d", recorder.Code, problem.Status, tt.status)
+			}
+			if want := problemTypePrefix + tt.code; problem.Type != want || problem.Code != tt.code {
+				t.Errorf("type = %q, code = %q, want %q", problem.Type, problem.Code, want)
+			}
+			if problem.Title != http.StatusText(tt.status) || problem.Detail == "" {
+				t.Errorf("title = %q, detail = %q, want status text and detail", problem.Title, problem.Detail)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "application/problem+json" {
+				t.Errorf("Content-Type = %q, want application/problem+json", got)
+			}
+		})
+	}
+}
+
+func TestWriteErrorFieldError(t *testing.T) {
+	err := &api.FieldError{Err: api.ErrInvalidReferralList, Field: "cursor", Message: "неправильный cursor"}
+
+	recorder, problem := testWriteError(t, err)
+	if recorder.Code != http.StatusBadRequest || problem.Code != "invalid_referral_list" {
+		t.Errorf("status = %d, code = %q, want %d invalid_referral_list", recorder.Code, problem.Code,
+			http.StatusBadRequest)
+	}
+	// Mapping has no detail, so text of error explains what is wrong
+	if problem.Detail != err.Error() {
+		t.Errorf("detail = %q, want %q", problem.Detail, err.Error())
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "cursor" {
+		t.Errorf("errors = %+v, want one error of field cursor", problem.Errors)
+	}
+}
+
+func TestWriteErrorHidesUnknownError(t *testing.T) {
+	recorder, problem := testWriteError(t, errors.New("pq: пароль postgres неверен"))
+
+	if recorder.Code != http.StatusInternalServerError || problem.Type != problemTypePrefix+codeInternalError {
+		t.Errorf("status = %d, type = %q, want %d %q", recorder.Code, problem.Type, http.StatusInternalServerError,
+			problemTypePrefix+codeInternalError)
+	}
+	if problem.Detail != "Проблема на сервере" {
+		t.Errorf("detail = %q, want text that does not reveal error", problem.Detail)
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"rest-refs/internal/app/models"
+)
+
+// GetReferralCodeQRHandler renders QR code of referral share link
+// @Summary Get QR code of referral share link
+// @Description Renders PNG or SVG QR code encoding share link /r/{code} of the authenticated user's referral code
+// @Tags referral_code
+// @Produce  png
+// @Produce  image/svg+xml
+// @Param id path int true "Referral code ID"
+// @Param format query string false "Image format: png (default) or svg"
+// @Param size query int false "Image width and height in pixels, 64-2048 (default 256)"
+// @Param level query string false "Error correction level: L, M (default), Q or H"
+// @Param margin query int false "Quiet zone width in modules, 0-16 (default 4)"
+// @Success 200 {file} file "QR code image"
+// @Success 304 "Not modified"
+// @Failure 400 {object} models.Problem "Invalid parameters"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Referral code not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /referral_code/{id}/qr [get]
+func (h *Handler) GetReferralCodeQRHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetReferralCodeQRHandler[http]: Получение QR-кода реферального кода")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	codeID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	query := r.URL.Query()
+	opts := models.QRCodeOptions{
+		Format: query.Get("format"),
+		Level:  query.Get("level"),
+		Margin: -1,
+	}
+
+	if sizeStr := query.Get("size"); sizeStr != "" {
+		if opts.Size, err = strconv.Atoi(sizeStr); err != nil {
+			h.writeInvalidParameter(w, r, "size", "Неправильный размер QR-кода")
+			return
+		}
+	}
+
+	if marginStr := query.Get("margin"); marginStr != "" {
+		if opts.Margin, err = strconv.Atoi(marginStr); err != nil || opts.Margin < 0 {
+			h.writeInvalidParameter(w, r, "margin", "Неправильный отступ QR-кода")
+			return
+		}
+	}
+
+	image, etag, err := h.service.GenerateReferralCodeQR(userID, codeID, opts)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if opts.Format == models.QRCodeFormatSVG {
+		w.Header().Set("Content-Type", "image/svg+xml")
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(image)
+
+	h.logger.Debugf("GetReferralCodeQRHandler[http]: QR-код реферального кода успешно получен")
+}
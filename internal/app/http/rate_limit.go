@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rest-refs/internal/app/api"
+)
+
+// RateLimitMiddleware rejects requests exceeding limiter's quota for client IP with 429 status
+func (h *Handler) RateLimitMiddleware(limiter *api.RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientIP(r)
+
+			allowed, retryAfter := limiter.Allow(ip, time.Now())
+			if !allowed {
+				h.logger.Warnf("RateLimitMiddleware[http]: Превышен лимит запросов для %s к %s", ip, r.URL.Path)
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+				h.writeProblem(w, r, http.StatusTooManyRequests, codeRateLimited, "Слишком много запросов")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP extracts client IP address from request's remote address
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
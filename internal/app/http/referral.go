@@ -2,23 +2,35 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"rest-refs/internal/app/models"
 )
 
 // GetReferralsByReferrerIDHandler retrieves referrals based on referrer ID
 // @Summary Get referrals by referrer ID
-// @Description Retrieves a list of referrals based on the referrer's ID
+// @Description Retrieves a page of referrals based on the referrer's ID, newest first by default.
+// @Description Number of referrals matching filters is returned in X-Total-Count header. Cursor of next page is
+// @Description returned in X-Next-Cursor header and in Link header with rel="next", both are absent on last page
 // @Tags referral
 // @Accept  json
 // @Produce  json
 // @Param referrer_id path int true "Referrer ID"
-// @Success 200 {array} models.ReferralInfoResponse "List of referrals"
-// @Failure 400 {string} string "Invalid ID format"
-// @Failure 404 {string} string "Referrals not found"
-// @Failure 500 {string} string "Internal server error"
+// @Param status query string false "Comma-separated statuses: pending, qualified, rejected, reversed"
+// @Param code_id query int false "Referral code ID"
+// @Param from query string false "Start of creation range, RFC 3339 timestamp or date"
+// @Param to query string false "End of creation range, RFC 3339 timestamp or date (inclusive)"
+// @Param sort query string false "Sort order: -created_at (default) or created_at"
+// @Param cursor query string false "Cursor of page from X-Next-Cursor header of previous page"
+// @Param limit query int false "Page size, from 1 to 500, default 50"
+// @Success 200 {array} models.ReferralInfoResponse "Page of referrals"
+// @Header 200 {integer} X-Total-Count "Number of referrals matching filters"
+// @Header 200 {string} X-Next-Cursor "Cursor of next page"
+// @Failure 400 {object} models.Problem "Invalid ID format, status or listing parameters"
+// @Failure 500 {object} models.Problem "Internal server error"
 // @Router /referral/id/{referrer_id} [get]
 func (h *Handler) GetReferralsByReferrerIDHandler(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debugf("GetReferralsByReferrerIDHandler[http]: Получение рефералов по id реферера")
@@ -29,26 +41,160 @@ func (h *Handler) GetReferralsByReferrerIDHandler(w http.ResponseWriter, r *http
 	// Convert ID string to integer
 	referrerID, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Неправильный формат ID", http.StatusBadRequest)
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
 		return
 	}
 
-	referrals, err := h.service.GetReferralsByReferrerID(referrerID)
-	if err != nil {
-		http.Error(w, "Ошибка получения рефералов", http.StatusInternalServerError)
+	opts, ok := h.referralListOptions(w, r)
+	if !ok {
 		return
 	}
+	query := r.URL.Query()
 
-	if len(referrals) == 0 {
-		http.Error(w, "Рефералы не найдены", http.StatusNotFound)
+	page, err := h.service.GetReferralsByReferrerID(referrerID, opts)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		next := *r.URL
+		query.Set("cursor", page.NextCursor)
+		next.RawQuery = query.Encode()
+
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if err = json.NewEncoder(w).Encode(referrals); err != nil {
-		http.Error(w, "Ошибка кодирования ответа", http.StatusInternalServerError)
+	if err = json.NewEncoder(w).Encode(page.Referrals); err != nil {
+		h.writeEncodeError(w, r, err)
 		return
 	}
 
 	h.logger.Debugf("GetReferralsByReferrerIDHandler[http]: Рефералы успешно получены по id реферера")
 }
+
+// referralListOptions reads filters, sort order and page of referral listing from query
+// On invalid value it writes error response and returns false
+func (h *Handler) referralListOptions(w http.ResponseWriter, r *http.Request) (models.ReferralListOptions, bool) {
+	query := r.URL.Query()
+	opts := models.ReferralListOptions{
+		Statuses: queryList(r, "status"),
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		Sort:     query.Get("sort"),
+		Cursor:   query.Get("cursor"),
+	}
+
+	if value := query.Get("code_id"); value != "" {
+		codeID, err := strconv.Atoi(value)
+		if err != nil {
+			h.writeInvalidParameter(w, r, "code_id", "Неправильный code_id")
+			return opts, false
+		}
+		opts.CodeID = &codeID
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			h.writeInvalidParameter(w, r, "limit", "Неправильный limit")
+			return opts, false
+		}
+		opts.Limit = limit
+	}
+
+	return opts, true
+}
+
+// GetCampaignStatsByReferrerIDHandler aggregates referrals of referrer per campaign
+// @Summary Get referral stats per campaign
+// @Description Returns codes and referrals counts of referrer grouped by campaign.
+// @Description Codes outside of any campaign are reported with null campaign_id.
+// @Description Stats are returned only to referrer itself and to administrators
+// @Tags referral
+// @Produce  json
+// @Param referrer_id path int true "Referrer ID"
+// @Success 200 {array} models.CampaignReferralStats "Stats per campaign"
+// @Failure 400 {object} models.Problem "Invalid ID format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Stats of another referrer"
+// @Failure 500 {object} models.Problem "Internal server error"
+// @Router /referral/id/{referrer_id}/campaigns [get]
+func (h *Handler) GetCampaignStatsByReferrerIDHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetCampaignStatsByReferrerIDHandler[http]: Получение статистики по кампаниям")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	referrerID, err := strconv.Atoi(vars["referrer_id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	stats, err := h.service.GetCampaignStatsByReferrerID(userID, referrerID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(stats); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetCampaignStatsByReferrerIDHandler[http]: Статистика по кампаниям успешно получена")
+}
+
+// GetReferralTreeHandler retrieves downline of the authenticated user
+// @Summary Get referral tree
+// @Description Returns referrals of the authenticated user, referrals of referred users and so on
+// @Description up to depth levels, with number of referrals per level. Depth defaults to maximum allowed
+// @Tags referral
+// @Produce  json
+// @Param depth query int false "Number of levels, from 1 to maximum allowed"
+// @Success 200 {object} models.ReferralTreeResponse "Referral tree"
+// @Failure 400 {object} models.Problem "Invalid depth"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Internal server error"
+// @Router /referral/tree [get]
+func (h *Handler) GetReferralTreeHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetReferralTreeHandler[http]: Получение дерева рефералов")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	var depth int
+	if value := r.URL.Query().Get("depth"); value != "" {
+		var err error
+		if depth, err = strconv.Atoi(value); err != nil || depth <= 0 {
+			h.writeInvalidParameter(w, r, "depth", "Неправильная глубина дерева")
+			return
+		}
+	}
+
+	tree, err := h.service.GetReferralTree(userID, depth)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(tree); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetReferralTreeHandler[http]: Дерево рефералов успешно получено")
+}
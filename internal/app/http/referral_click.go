@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"rest-refs/internal/app/models"
+)
+
+// attributionCookieName is name of cookie holding attribution token of the last click on share link
+const attributionCookieName = "ref_click"
+
+// ReferralRedirectHandler records click on referral share link and redirects to landing page
+// @Summary Follow referral share link
+// @Description Records click (time, hashed IP, user agent, referer and UTM parameters), sets attribution cookie
+// @Description for active code and redirects to landing page with code in "ref" query parameter.
+// @Description Unknown codes are redirected to landing page without cookie
+// @Tags referral_code
+// @Param code path string true "Referral code"
+// @Param utm_source query string false "UTM source"
+// @Param utm_medium query string false "UTM medium"
+// @Param utm_campaign query string false "UTM campaign"
+// @Param utm_term query string false "UTM term"
+// @Param utm_content query string false "UTM content"
+// @Success 302 "Redirect to landing page"
+// @Router /r/{code} [get]
+func (h *Handler) ReferralRedirectHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("ReferralRedirectHandler[http]: Переход по реферальной ссылке")
+
+	vars := mux.Vars(r)
+	code := vars["code"]
+	query := r.URL.Query()
+
+	click := models.ReferralClick{
+		UserAgent:   r.UserAgent(),
+		Referer:     r.Referer(),
+		UTMSource:   query.Get("utm_source"),
+		UTMMedium:   query.Get("utm_medium"),
+		UTMCampaign: query.Get("utm_campaign"),
+		UTMTerm:     query.Get("utm_term"),
+		UTMContent:  query.Get("utm_content"),
+	}
+
+	// Share link must always lead somewhere, so failure to track click only skips the cookie
+	created, err := h.service.TrackClick(code, clientIP(r), click)
+	if err != nil {
+		h.logger.Warnf("ReferralRedirectHandler[http]: Переход по коду %s не сохранен: %s", code, err)
+	}
+
+	landing, err := url.Parse(h.landingURL)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if created.Token != "" {
+		http.SetCookie(w, &http.Cookie{
+			Name:     attributionCookieName,
+			Value:    created.Token,
+			Path:     "/",
+			MaxAge:   int(h.attributionWindow.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		landingQuery := landing.Query()
+		landingQuery.Set("ref", created.Code)
+		landing.RawQuery = landingQuery.Encode()
+	}
+
+	http.Redirect(w, r, landing.String(), http.StatusFound)
+
+	h.logger.Debugf("ReferralRedirectHandler[http]: Переход по реферальной ссылке обработан")
+}
+
+// clearAttributionCookie removes attribution cookie after sign-up
+func clearAttributionCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     attributionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
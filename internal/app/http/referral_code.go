@@ -2,53 +2,55 @@ package http
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gorilla/mux"
-	"rest-refs/internal/app/api"
 	"rest-refs/internal/app/models"
-	"rest-refs/internal/app/repository/postgresql"
 )
 
 // CreateReferralCodeHandler creates a new referral code
 // @Summary Create a new referral code
-// @Description Creates a referral code for the authenticated user
+// @Description Creates a referral code for the authenticated user.
+// @Description Expiration is set either by expiration_date (RFC 3339 timestamp or date-only value meaning
+// @Description end of day in time_zone) or by relative expires_in duration (e.g. "72h").
+// @Description Code may be linked to active campaign by campaign_id, then expiration defaults to campaign's
+// @Description default lifetime and never exceeds end of campaign
 // @Tags referral_code
 // @Accept  json
 // @Produce  json
 // @Param ReferralCodeCreateRequest body models.ReferralCodeCreateRequest true "Referral code request"
 // @Success 201 {object} models.ReferralCodeResponse "Referral code created"
-// @Failure 400 {string} string "Invalid data format or date"
-// @Failure 401 {string} string "Authentication error"
-// @Failure 409 {string} string "Referral code already exists"
-// @Failure 500 {string} string "Server error"
+// @Failure 400 {object} models.Problem "Invalid data format, date, time zone or usage limit"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Campaign not found"
+// @Failure 409 {object} models.Problem "Referral code already exists or campaign is not active"
+// @Failure 500 {object} models.Problem "Server error"
 // @Router /referral_code [post]
 func (h *Handler) CreateReferralCodeHandler(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debugf("CreateReferralCodeHandler[http]: Создание реферального кода")
 
 	userID, ok := r.Context().Value("UserID").(int)
 	if !ok {
-		http.Error(w, "Ошибка аутентификации", http.StatusUnauthorized)
+		h.writeUnauthenticated(w, r)
 		return
 	}
 
 	var input models.ReferralCodeCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Неправильный формат данных", http.StatusBadRequest)
+		h.writeDecodeError(w, r, err)
 		return
 	}
 
-	// Parse expiration date from string to time
-	expirationDate, err := time.Parse("02.01.2006", input.ExpirationDate)
+	// Resolve expiration from date, timestamp or relative duration
+	expirationDate, err := h.service.ResolveExpiration(input)
 	if err != nil {
-		http.Error(w, "Неправильный формат даты", http.StatusBadRequest)
+		h.writeError(w, r, err)
 		return
 	}
 
-	if expirationDate.Before(time.Now()) {
-		http.Error(w, "Срок годности реферального кода не может быть в прошлом", http.StatusBadRequest)
+	if input.MaxUses != nil && *input.MaxUses <= 0 {
+		h.writeInvalidParameter(w, r, "max_uses", "Лимит использований реферального кода должен быть больше нуля")
 		return
 	}
 
@@ -56,18 +58,15 @@ func (h *Handler) CreateReferralCodeHandler(w http.ResponseWriter, r *http.Reque
 	referralCode := models.ReferralCode{
 		ReferrerID: userID,
 		Expiration: expirationDate,
+		MaxUses:    input.MaxUses,
+		CampaignID: input.CampaignID,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
 
 	createdCode, err := h.service.CreateReferralCode(referralCode)
 	if err != nil {
-		if errors.Is(err, api.ErrReferralCodeAlreadyExists) {
-			http.Error(w, "Активный реферальный код уже существует", http.StatusConflict)
-			return
-		}
-
-		http.Error(w, "Проблема на сервере", http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
@@ -86,27 +85,23 @@ func (h *Handler) CreateReferralCodeHandler(w http.ResponseWriter, r *http.Reque
 // @Description Deletes the referral code of the authenticated user
 // @Tags referral_code
 // @Success 204 "Referral code deleted"
-// @Failure 401 {string} string "Authentication error"
-// @Failure 404 {string} string "Referral code not found"
-// @Failure 500 {string} string "Server error"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Referral code not found"
+// @Failure 500 {object} models.Problem "Server error"
 // @Router /referral_code [delete]
 func (h *Handler) DeleteReferralCodeHandler(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debugf("DeleteReferralCodeHandler[http]: Удаление реферального кода")
 
 	userID, ok := r.Context().Value("UserID").(int)
 	if !ok {
-		http.Error(w, "Ошибка аутентификации", http.StatusUnauthorized)
+		h.writeUnauthenticated(w, r)
 		return
 	}
 
 	// Call the service to delete the referral code
 	err := h.service.DeleteReferralCode(userID)
 	if err != nil {
-		if errors.Is(err, postgresql.ErrReferralCodeNotFound) {
-			http.Error(w, "Активный реферальный код не найден", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "Проблема на сервере", http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
@@ -123,9 +118,9 @@ func (h *Handler) DeleteReferralCodeHandler(w http.ResponseWriter, r *http.Reque
 // @Produce  json
 // @Param email path string true "Referrer email"
 // @Success 200 {object} models.ReferralCodeResponse "Referral code found"
-// @Failure 400 {string} string "Email cannot be empty"
-// @Failure 404 {string} string "Referral code not found"
-// @Failure 500 {string} string "Server error"
+// @Failure 400 {object} models.Problem "Email cannot be empty"
+// @Failure 404 {object} models.Problem "Referral code not found"
+// @Failure 500 {object} models.Problem "Server error"
 // @Router /referral_code/email/{email} [get]
 func (h *Handler) GetReferralCodeByEmailHandler(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debugf("GetReferralCodeByEmailHandler[http]: Получение реферального кода по email реферера")
@@ -134,17 +129,13 @@ func (h *Handler) GetReferralCodeByEmailHandler(w http.ResponseWriter, r *http.R
 	email := vars["email"]
 
 	if email == "" {
-		http.Error(w, "Email не может быть пустым", http.StatusBadRequest)
+		h.writeInvalidParameter(w, r, "email", "Email не может быть пустым")
 		return
 	}
 
 	referralCode, err := h.service.GetReferralCodeByReferrerEmail(email)
 	if err != nil {
-		if errors.Is(err, postgresql.ErrReferralCodeNotFound) {
-			http.Error(w, "Реферальный код не найден", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "Проблема на сервере", http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
@@ -158,3 +149,93 @@ func (h *Handler) GetReferralCodeByEmailHandler(w http.ResponseWriter, r *http.R
 
 	h.logger.Debugf("GetReferralCodeByEmailHandler[http]: Реферальный код успешно получен по email реферера")
 }
+
+// PauseReferralCodeHandler pauses the active referral code
+// @Summary Pause an active referral code
+// @Description Pauses the referral code of the authenticated user, paused code can not be used for registration
+// @Tags referral_code
+// @Success 204 "Referral code paused"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Referral code not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /referral_code/pause [put]
+func (h *Handler) PauseReferralCodeHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("PauseReferralCodeHandler[http]: Приостановка реферального кода")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	err := h.service.PauseReferralCode(userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	h.logger.Debugf("PauseReferralCodeHandler[http]: Реферальный код успешно приостановлен")
+}
+
+// ResumeReferralCodeHandler resumes the paused referral code
+// @Summary Resume a paused referral code
+// @Description Resumes the referral code of the authenticated user
+// @Tags referral_code
+// @Success 204 "Referral code resumed"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Referral code not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /referral_code/resume [put]
+func (h *Handler) ResumeReferralCodeHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("ResumeReferralCodeHandler[http]: Возобновление реферального кода")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	err := h.service.ResumeReferralCode(userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	h.logger.Debugf("ResumeReferralCodeHandler[http]: Реферальный код успешно возобновлен")
+}
+
+// GetReferralCodeStatusHandler reports whether referral code can be used for registration
+// @Summary Check referral code status
+// @Description Public endpoint for sign-up form: reports whether the code exists and is active,
+// @Description expired, paused or exhausted, and returns referrer's public display name.
+// @Description Requests are rate limited per IP to prevent code enumeration
+// @Tags referral_code
+// @Produce  json
+// @Param code path string true "Referral code"
+// @Success 200 {object} models.ReferralCodeStatusResponse "Referral code status"
+// @Failure 429 {object} models.Problem "Too many requests"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /referral_code/{code}/status [get]
+func (h *Handler) GetReferralCodeStatusHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetReferralCodeStatusHandler[http]: Проверка статуса реферального кода")
+
+	vars := mux.Vars(r)
+	code := vars["code"]
+
+	status, err := h.service.GetReferralCodeStatus(code)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(status)
+
+	h.logger.Debugf("GetReferralCodeStatusHandler[http]: Статус реферального кода успешно получен")
+}
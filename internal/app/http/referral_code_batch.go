@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"rest-refs/internal/app/models"
+)
+
+// CreateReferralCodeBatchHandler generates batch of referral codes
+// @Summary Generate batch of referral codes
+// @Description Generates count unique referral codes owned by referrer in one batch (admin only).
+// @Description Codes are single-use unless max_uses is set, expiration fields are the same as for single code
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param ReferralCodeBatchCreateRequest body models.ReferralCodeBatchCreateRequest true "Batch request"
+// @Success 201 {object} models.ReferralCodeBatch "Batch created"
+// @Failure 400 {object} models.Problem "Invalid data format, count or date"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Referrer or campaign not found"
+// @Failure 409 {object} models.Problem "Campaign is not active"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/referral_code/batch [post]
+func (h *Handler) CreateReferralCodeBatchHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("CreateReferralCodeBatchHandler[http]: Создание пакета реферальных кодов")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	var input models.ReferralCodeBatchCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	if input.MaxUses != nil && *input.MaxUses <= 0 {
+		h.writeInvalidParameter(w, r, "max_uses", "Лимит использований реферального кода должен быть больше нуля")
+		return
+	}
+
+	batch, err := h.service.CreateReferralCodeBatch(input, &userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(batch)
+
+	h.logger.Debugf("CreateReferralCodeBatchHandler[http]: Пакет реферальных кодов успешно создан")
+}
+
+// GetReferralCodeBatchCSVHandler downloads codes of batch as CSV
+// @Summary Download batch of referral codes as CSV
+// @Description Returns CSV with code, share_url, expires_at, max_uses and uses_count columns (admin only)
+// @Tags admin
+// @Produce  text/csv
+// @Param id path int true "Batch ID"
+// @Success 200 {file} file "CSV file"
+// @Failure 400 {object} models.Problem "Invalid ID format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Batch not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/referral_code/batch/{id}/csv [get]
+func (h *Handler) GetReferralCodeBatchCSVHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetReferralCodeBatchCSVHandler[http]: Выгрузка пакета реферальных кодов")
+
+	vars := mux.Vars(r)
+	batchID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="referral_codes_batch_%d.csv"`, batchID))
+
+	// Batch is checked before anything is written, so errors still can be reported with proper status
+	err = h.service.WriteReferralCodeBatchCSV(batchID, w)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetReferralCodeBatchCSVHandler[http]: Пакет реферальных кодов успешно выгружен")
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"rest-refs/internal/app/models"
+)
+
+// IngestReferralEventHandler receives external event about referred user
+// @Summary Ingest referral event
+// @Description Receives event from external system (e.g. email_verified, first_purchase, refund) authenticated
+// @Description by X-Api-Key header. Events move referral from pending to qualified, rejected or reversed
+// @Description according to configured rules. Repeated event_id is accepted once, later copies are reported as duplicate
+// @Tags referral
+// @Accept  json
+// @Produce  json
+// @Param X-Api-Key header string true "Events API key"
+// @Param ReferralEventRequest body models.ReferralEventRequest true "Referral event"
+// @Success 201 {object} models.ReferralEventResponse "Event processed"
+// @Success 200 {object} models.ReferralEventResponse "Duplicate event, nothing changed"
+// @Failure 400 {object} models.Problem "Invalid event"
+// @Failure 401 {object} models.Problem "Invalid API key"
+// @Failure 404 {object} models.Problem "Referral not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /referral/events [post]
+func (h *Handler) IngestReferralEventHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("IngestReferralEventHandler[http]: Получение события реферала")
+
+	var input models.ReferralEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	result, err := h.service.IngestReferralEvent(input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if result.Duplicate {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(result)
+
+	h.logger.Debugf("IngestReferralEventHandler[http]: Событие реферала успешно обработано")
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rest-refs/internal/app/models"
+)
+
+// referralExportContentTypes are content types of referral export formats
+var referralExportContentTypes = map[string]string{
+	models.ReferralExportCSV:   "text/csv; charset=utf-8",
+	models.ReferralExportXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	models.ReferralExportJSONL: "application/x-ndjson",
+}
+
+// ExportReferralsHandler downloads referrals of the authenticated user
+// @Summary Export referrals
+// @Description Downloads referrals of the authenticated user as CSV, XLSX or JSON Lines.
+// @Description Accepts the same filters and sort order as referral listing, all matching referrals are exported.
+// @Description Rows are streamed from database with chunked transfer encoding
+// @Tags referral
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce  application/x-ndjson
+// @Param format query string false "Export format: csv (default), xlsx or jsonl"
+// @Param status query string false "Comma-separated statuses: pending, qualified, rejected, reversed"
+// @Param code_id query int false "Referral code ID"
+// @Param from query string false "Start of creation range, RFC 3339 timestamp or date"
+// @Param to query string false "End of creation range, RFC 3339 timestamp or date (inclusive)"
+// @Param sort query string false "Sort order: -created_at (default) or created_at"
+// @Success 200 {file} file "Export file"
+// @Failure 400 {object} models.Problem "Invalid format, status or filters"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /referral/export [get]
+func (h *Handler) ExportReferralsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("ExportReferralsHandler[http]: Выгрузка рефералов")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	h.writeReferralExport(w, r, &userID)
+}
+
+// ExportProgramReferralsHandler downloads referrals of whole referral program or of one referrer
+// @Summary Export referrals of program
+// @Description Downloads referrals of all referrers, or of one referrer if referrer_id is set, as CSV, XLSX
+// @Description or JSON Lines (admin only). Accepts the same filters and sort order as referral listing.
+// @Description Rows are streamed from database with chunked transfer encoding
+// @Tags admin
+// @Produce  text/csv
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce  application/x-ndjson
+// @Param referrer_id query int false "Referrer ID"
+// @Param format query string false "Export format: csv (default), xlsx or jsonl"
+// @Param status query string false "Comma-separated statuses: pending, qualified, rejected, reversed"
+// @Param code_id query int false "Referral code ID"
+// @Param from query string false "Start of creation range, RFC 3339 timestamp or date"
+// @Param to query string false "End of creation range, RFC 3339 timestamp or date (inclusive)"
+// @Param sort query string false "Sort order: -created_at (default) or created_at"
+// @Success 200 {file} file "Export file"
+// @Failure 400 {object} models.Problem "Invalid referrer ID, format, status or filters"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/referral/export [get]
+func (h *Handler) ExportProgramReferralsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("ExportProgramReferralsHandler[http]: Выгрузка рефералов программы")
+
+	var referrerID *int
+	if value := r.URL.Query().Get("referrer_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			h.writeInvalidParameter(w, r, "referrer_id", "Неправильный формат referrer_id")
+			return
+		}
+		referrerID = &id
+	}
+
+	h.writeReferralExport(w, r, referrerID)
+}
+
+// writeReferralExport streams referrals matching filters from query in requested format
+func (h *Handler) writeReferralExport(w http.ResponseWriter, r *http.Request, referrerID *int) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = models.ReferralExportCSV
+	}
+	contentType, ok := referralExportContentTypes[format]
+	if !ok {
+		h.writeInvalidParameter(w, r, "format", "Неизвестный формат выгрузки")
+		return
+	}
+
+	opts, ok := h.referralListOptions(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="referrals_%s.%s"`,
+		time.Now().UTC().Format("20060102"), format))
+
+	// Format and filters are checked before anything is written, so their errors still get proper status.
+	// Once streaming started, status is already sent and failed export is only logged
+	out := &flushWriter{w: w, controller: http.NewResponseController(w)}
+	err := h.service.ExportReferrals(referrerID, format, opts, out)
+	if err != nil {
+		if out.written {
+			h.logger.Errorf("writeReferralExport[http]: Выгрузка рефералов прервана: %s", err)
+			return
+		}
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("writeReferralExport[http]: Рефералы успешно выгружены")
+}
+
+// flushWriter flushes every write to client, so large responses go out in chunks as they are produced
+type flushWriter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	written    bool
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	f.written = true
+
+	n, err := f.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if err = f.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return n, err
+	}
+	return n, nil
+}
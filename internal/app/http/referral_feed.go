@@ -0,0 +1,230 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/websocket"
+	"rest-refs/internal/app/api"
+	"rest-refs/internal/app/models"
+)
+
+const (
+	// referralFeedHeartbeat is interval of keep-alive messages keeping idle feed open through proxies
+	referralFeedHeartbeat = 15 * time.Second
+	// referralFeedRetry is delay before EventSource reconnects after feed is closed
+	referralFeedRetry = 3 * time.Second
+)
+
+// StreamReferralFeedHandler streams new referrals and changes of referral codes of the authenticated user
+// @Summary Stream referral feed
+// @Description Pushes events of the authenticated user as Server-Sent Events: referral.created,
+// @Description referral.status_changed and referral_code.created, revoked, rotated, paused and resumed.
+// @Description Every event has id, type of event is its SSE event name and event JSON is its data.
+// @Description Reconnecting client sends Last-Event-ID header (EventSource does it automatically) or last_event_id
+// @Description and gets events it missed. Event "reset" means that too many events were missed or Last-Event-ID
+// @Description is older than events still kept, so state must be reloaded.
+// @Description Browser EventSource cannot set headers, so token may be passed as access_token
+// @Tags referral
+// @Produce  text/event-stream
+// @Param Last-Event-ID header int false "ID of last event seen by client"
+// @Param last_event_id query int false "ID of last event seen by client, used when header is not set"
+// @Param access_token query string false "JWT, used when Authorization header is not set"
+// @Success 200 {object} models.ReferralFeedEvent "Stream of events"
+// @Failure 400 {object} models.Problem "Invalid last event ID"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /referral/stream [get]
+func (h *Handler) StreamReferralFeedHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("StreamReferralFeedHandler[http]: Подключение к ленте рефералов")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	lastEventID, err := referralFeedLastEventID(r)
+	if err != nil {
+		h.writeInvalidParameter(w, r, "last_event_id", "Неправильный идентификатор последнего события")
+		return
+	}
+
+	subscription, err := h.service.SubscribeReferralFeed(userID, lastEventID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	defer h.service.UnsubscribeReferralFeed(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	send := func(event models.ReferralFeedEvent) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if event.ID > 0 {
+			fmt.Fprintf(w, "id: %d\n", event.ID)
+		}
+		if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+	ping := func() error {
+		if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+
+	if _, err = fmt.Fprintf(w, "retry: %d\n\n", referralFeedRetry.Milliseconds()); err == nil {
+		err = controller.Flush()
+	}
+	if err == nil {
+		err = h.streamReferralFeed(r.Context(), subscription, send, ping)
+	}
+	if err != nil {
+		h.logger.Infof("StreamReferralFeedHandler[http]: Лента рефералов прервана: %s", err)
+		return
+	}
+
+	h.logger.Debugf("StreamReferralFeedHandler[http]: Лента рефералов закрыта")
+}
+
+// StreamReferralFeedWebSocketHandler streams referral feed of the authenticated user over WebSocket
+// @Summary Stream referral feed over WebSocket
+// @Description WebSocket alternative to /referral/stream. Every event is sent as JSON text message,
+// @Description connection is kept alive by ping frames, messages from client are ignored.
+// @Description Reconnecting client passes last_event_id and gets events it missed, "reset" is sent as in SSE feed.
+// @Description Browser WebSocket cannot set headers, so token may be passed as access_token
+// @Tags referral
+// @Produce  json
+// @Param last_event_id query int false "ID of last event seen by client"
+// @Param access_token query string false "JWT, used when Authorization header is not set"
+// @Success 101 {object} models.ReferralFeedEvent "Stream of events"
+// @Failure 400 {object} models.Problem "Invalid last event ID or WebSocket handshake"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /referral/stream/ws [get]
+func (h *Handler) StreamReferralFeedWebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("StreamReferralFeedWebSocketHandler[http]: Подключение к ленте рефералов")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	lastEventID, err := referralFeedLastEventID(r)
+	if err != nil {
+		h.writeInvalidParameter(w, r, "last_event_id", "Неправильный идентификатор последнего события")
+		return
+	}
+
+	subscription, err := h.service.SubscribeReferralFeed(userID, lastEventID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	defer h.service.UnsubscribeReferralFeed(subscription)
+
+	server := websocket.Server{
+		// Clients authenticate with token rather than cookies, so connections from any origin are accepted
+		Handshake: func(*websocket.Config, *http.Request) error {
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) {
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			// Messages from client are not expected, reading only notices that connection is closed
+			go func() {
+				defer cancel()
+				var message string
+				for websocket.Message.Receive(conn, &message) == nil {
+				}
+			}()
+
+			send := func(event models.ReferralFeedEvent) error {
+				return websocket.JSON.Send(conn, event)
+			}
+			ping := func() error {
+				conn.PayloadType = websocket.PingFrame
+				defer func() { conn.PayloadType = websocket.TextFrame }()
+				_, err := conn.Write(nil)
+				return err
+			}
+
+			if err := h.streamReferralFeed(ctx, subscription, send, ping); err != nil {
+				h.logger.Infof("StreamReferralFeedWebSocketHandler[http]: Лента рефералов прервана: %s", err)
+				return
+			}
+
+			h.logger.Debugf("StreamReferralFeedWebSocketHandler[http]: Лента рефералов закрыта")
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// streamReferralFeed sends backlog and then live events of subscription with heartbeat in between
+// until client disconnects or subscription is dropped, then client reconnects and resumes feed
+func (h *Handler) streamReferralFeed(ctx context.Context, subscription *api.ReferralFeedSubscription,
+	send func(event models.ReferralFeedEvent) error, ping func() error) error {
+	for _, event := range subscription.Backlog {
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+
+	heartbeat := time.NewTicker(referralFeedHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return nil
+			}
+			if subscription.Duplicate(event) {
+				continue
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if err := ping(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// referralFeedLastEventID returns ID of last event seen by client from Last-Event-ID header
+// or last_event_id query parameter, zero if neither is set
+func referralFeedLastEventID(r *http.Request) (int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("неправильный идентификатор события: %s", value)
+	}
+	return id, nil
+}
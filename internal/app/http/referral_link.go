@@ -0,0 +1,87 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"rest-refs/internal/app/models"
+)
+
+// RotateReferralCodeHandler replaces the active referral code with a new one
+// @Summary Rotate referral code
+// @Description Issues new referral code for the authenticated user on the same referral link.
+// @Description Old code stays valid for grace_period (default from config), "0s" revokes it immediately.
+// @Description Request body is optional
+// @Tags referral_code
+// @Accept  json
+// @Produce  json
+// @Param ReferralCodeRotateRequest body models.ReferralCodeRotateRequest false "Rotation request"
+// @Success 201 {object} models.ReferralCode "New referral code"
+// @Failure 400 {object} models.Problem "Invalid data format or grace period"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Referral code not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /referral_code/rotate [post]
+func (h *Handler) RotateReferralCodeHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("RotateReferralCodeHandler[http]: Замена реферального кода")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	var input models.ReferralCodeRotateRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	referralCode, err := h.service.RotateReferralCode(userID, input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(referralCode)
+
+	h.logger.Debugf("RotateReferralCodeHandler[http]: Реферальный код успешно заменен")
+}
+
+// GetReferralLinksHandler lists referral links of the authenticated user
+// @Summary Get referral links with rotation history
+// @Description Returns referral links of the authenticated user, each with all its codes in order of rotation
+// @Description and referrals and clicks counts summed over these codes
+// @Tags referral_code
+// @Produce  json
+// @Success 200 {array} models.ReferralLink "Referral links"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /referral_code/history [get]
+func (h *Handler) GetReferralLinksHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetReferralLinksHandler[http]: Получение реферальных ссылок")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	links, err := h.service.GetReferralLinksByReferrerID(userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(links); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetReferralLinksHandler[http]: Реферальные ссылки успешно получены")
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"rest-refs/internal/app/models"
+)
+
+// GetReferralStatsHandler returns statistics of referrals of the authenticated user
+// @Summary Get referral statistics
+// @Description Returns totals, time series of clicks, sign-ups and qualifications, conversion rates
+// @Description and breakdown by referral code for referrals of the authenticated user.
+// @Description Range defaults to last 30 days including today, dates are interpreted in default time zone
+// @Tags referral
+// @Produce  json
+// @Param from query string false "Start of range: RFC 3339 timestamp or date, e.g. 2024-10-01"
+// @Param to query string false "End of range: RFC 3339 timestamp or date, date is inclusive"
+// @Param granularity query string false "Period of time series: day (default) or week"
+// @Success 200 {object} models.ReferralStatsResponse "Referral statistics"
+// @Failure 400 {object} models.Problem "Invalid range or granularity"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Internal server error"
+// @Router /referral/stats [get]
+func (h *Handler) GetReferralStatsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetReferralStatsHandler[http]: Получение статистики рефералов")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	h.writeReferralStats(w, r, &userID)
+}
+
+// GetProgramReferralStatsHandler returns statistics of whole referral program or of one referrer
+// @Summary Get referral program statistics
+// @Description Returns statistics of whole referral program, or of one referrer if referrer_id is set (admin only).
+// @Description Breakdown of whole program lists only codes with clicks or sign-ups in range
+// @Tags admin
+// @Produce  json
+// @Param referrer_id query int false "Referrer ID"
+// @Param from query string false "Start of range: RFC 3339 timestamp or date, e.g. 2024-10-01"
+// @Param to query string false "End of range: RFC 3339 timestamp or date, date is inclusive"
+// @Param granularity query string false "Period of time series: day (default) or week"
+// @Success 200 {object} models.ReferralStatsResponse "Referral statistics"
+// @Failure 400 {object} models.Problem "Invalid referrer ID, range or granularity"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 500 {object} models.Problem "Internal server error"
+// @Router /admin/referral/stats [get]
+func (h *Handler) GetProgramReferralStatsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetProgramReferralStatsHandler[http]: Получение статистики реферальной программы")
+
+	var referrerID *int
+	if value := r.URL.Query().Get("referrer_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+			return
+		}
+		referrerID = &id
+	}
+
+	h.writeReferralStats(w, r, referrerID)
+}
+
+// writeReferralStats computes statistics for range and granularity from query and writes it
+func (h *Handler) writeReferralStats(w http.ResponseWriter, r *http.Request, referrerID *int) {
+	query := r.URL.Query()
+	stats, err := h.service.GetReferralStats(models.ReferralStatsOptions{
+		ReferrerID:  referrerID,
+		From:        query.Get("from"),
+		To:          query.Get("to"),
+		Granularity: query.Get("granularity"),
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(stats); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("writeReferralStats[http]: Статистика рефералов успешно получена")
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetMyRewardsHandler shows rewards of the authenticated user
+// @Summary Get my rewards
+// @Description Returns reward balance of the authenticated user and history of ledger entries from newest to oldest.
+// @Description Rewards are credited when referral is created or qualified and reversed when it is rejected or reversed
+// @Tags rewards
+// @Produce  json
+// @Success 200 {object} models.RewardsResponse "Balance and history"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /me/rewards [get]
+func (h *Handler) GetMyRewardsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetMyRewardsHandler[http]: Получение вознаграждений")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	rewards, err := h.service.GetRewardsByUserID(userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(rewards); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetMyRewardsHandler[http]: Вознаграждения успешно получены")
+}
@@ -0,0 +1,236 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"rest-refs/internal/app/models"
+)
+
+// CreateRewardRuleSetHandler creates new reward rule set
+// @Summary Create reward rule set
+// @Description Creates set of reward rules written in JSON or YAML (admin only).
+// @Description Rules set or multiply reward amounts by trigger, party, campaign, date range and referral number,
+// @Description caps limit what party may earn per period. Active rule set replaces previously active one
+// @Description of the same campaign, or the global one if campaign_id is not set
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param RewardRuleSetRequest body models.RewardRuleSetRequest true "Rule set request"
+// @Success 201 {object} models.RewardRuleSet "Rule set created"
+// @Failure 400 {object} models.Problem "Invalid data format or rules"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/reward_rules [post]
+func (h *Handler) CreateRewardRuleSetHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("CreateRewardRuleSetHandler[http]: Создание набора правил вознаграждений")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	var input models.RewardRuleSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	ruleSet, err := h.service.CreateRewardRuleSet(input, &userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ruleSet)
+
+	h.logger.Debugf("CreateRewardRuleSetHandler[http]: Набор правил успешно создан")
+}
+
+// UpdateRewardRuleSetHandler replaces reward rule set
+// @Summary Update reward rule set
+// @Description Replaces rules of rule set (admin only). Rewards already posted are not recalculated
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Rule set ID"
+// @Param RewardRuleSetRequest body models.RewardRuleSetRequest true "Rule set request"
+// @Success 200 {object} models.RewardRuleSet "Rule set updated"
+// @Failure 400 {object} models.Problem "Invalid ID, data format or rules"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Rule set not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/reward_rules/{id} [put]
+func (h *Handler) UpdateRewardRuleSetHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("UpdateRewardRuleSetHandler[http]: Изменение набора правил вознаграждений")
+
+	vars := mux.Vars(r)
+	ruleSetID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	var input models.RewardRuleSetRequest
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	ruleSet, err := h.service.UpdateRewardRuleSet(ruleSetID, input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ruleSet)
+
+	h.logger.Debugf("UpdateRewardRuleSetHandler[http]: Набор правил успешно изменен")
+}
+
+// DeleteRewardRuleSetHandler deletes reward rule set
+// @Summary Delete reward rule set
+// @Description Deletes rule set (admin only). Rewards already posted by its rules are kept
+// @Tags admin
+// @Param id path int true "Rule set ID"
+// @Success 204 "Rule set deleted"
+// @Failure 400 {object} models.Problem "Invalid ID format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Rule set not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/reward_rules/{id} [delete]
+func (h *Handler) DeleteRewardRuleSetHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("DeleteRewardRuleSetHandler[http]: Удаление набора правил вознаграждений")
+
+	vars := mux.Vars(r)
+	ruleSetID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	err = h.service.DeleteRewardRuleSet(ruleSetID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	h.logger.Debugf("DeleteRewardRuleSetHandler[http]: Набор правил успешно удален")
+}
+
+// GetRewardRuleSetsHandler lists reward rule sets
+// @Summary List reward rule sets
+// @Description Returns all reward rule sets from newest to oldest (admin only)
+// @Tags admin
+// @Produce  json
+// @Success 200 {array} models.RewardRuleSet "List of rule sets"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/reward_rules [get]
+func (h *Handler) GetRewardRuleSetsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetRewardRuleSetsHandler[http]: Получение списка наборов правил")
+
+	ruleSets, err := h.service.GetRewardRuleSets()
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(ruleSets); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetRewardRuleSetsHandler[http]: Список наборов правил успешно получен")
+}
+
+// GetRewardRuleSetByIDHandler retrieves reward rule set by ID
+// @Summary Get reward rule set
+// @Description Returns rule set with its source and normalized rules (admin only)
+// @Tags admin
+// @Produce  json
+// @Param id path int true "Rule set ID"
+// @Success 200 {object} models.RewardRuleSet "Rule set"
+// @Failure 400 {object} models.Problem "Invalid ID format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Rule set not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/reward_rules/{id} [get]
+func (h *Handler) GetRewardRuleSetByIDHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetRewardRuleSetByIDHandler[http]: Получение набора правил")
+
+	vars := mux.Vars(r)
+	ruleSetID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	ruleSet, err := h.service.GetRewardRuleSetByID(ruleSetID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(ruleSet); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetRewardRuleSetByIDHandler[http]: Набор правил успешно получен")
+}
+
+// DryRunRewardHandler shows what referral would earn
+// @Summary Dry run of reward rules
+// @Description Evaluates rewards of referrer and referee for creation and qualification of referral
+// @Description at given moment without posting them (admin only). If rule_set is given, it is evaluated
+// @Description instead of active rule sets
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param RewardDryRunRequest body models.RewardDryRunRequest true "Dry run request"
+// @Success 200 {object} models.RewardDryRunResponse "Evaluated rewards"
+// @Failure 400 {object} models.Problem "Invalid data format or rules"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Not an administrator"
+// @Failure 404 {object} models.Problem "Referral not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /admin/reward_rules/dry_run [post]
+func (h *Handler) DryRunRewardHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("DryRunRewardHandler[http]: Расчет вознаграждений")
+
+	var input models.RewardDryRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	response, err := h.service.DryRunReward(input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("DryRunRewardHandler[http]: Вознаграждения успешно рассчитаны")
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"rest-refs/internal/app/models"
+)
+
+// CreateWebhookSubscriptionHandler subscribes endpoint to referral events
+// @Summary Create webhook subscription
+// @Description Subscribes endpoint of the authenticated user to events of their referrals and referral codes.
+// @Description Empty events subscribe to all events, all_users subscribes to events of all users (admin only).
+// @Description Signing secret is returned only in this response. Every delivery is POST with JSON body
+// @Description {id, type, created_at, data} and headers X-Webhook-Event, X-Webhook-Delivery, X-Webhook-Timestamp and
+// @Description X-Webhook-Signature "t=<timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>" keyed by secret>".
+// @Description Non-2xx responses are retried with exponential delay, delivery is dead after last attempt
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param WebhookSubscriptionRequest body models.WebhookSubscriptionRequest true "Webhook subscription"
+// @Success 201 {object} models.WebhookSubscription "Subscription with signing secret"
+// @Failure 400 {object} models.Problem "Invalid data format, URL or event type"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 403 {object} models.Problem "Subscription to all users requires administrator"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /webhooks [post]
+func (h *Handler) CreateWebhookSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("CreateWebhookSubscriptionHandler[http]: Создание подписки на вебхуки")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	var input models.WebhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+
+	subscription, err := h.service.CreateWebhookSubscription(userID, input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subscription)
+
+	h.logger.Debugf("CreateWebhookSubscriptionHandler[http]: Подписка на вебхуки успешно создана")
+}
+
+// GetWebhookSubscriptionsHandler lists webhook subscriptions of the authenticated user
+// @Summary List webhook subscriptions
+// @Description Returns webhook subscriptions of the authenticated user without their secrets
+// @Tags webhooks
+// @Produce  json
+// @Success 200 {array} models.WebhookSubscription "List of subscriptions"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /webhooks [get]
+func (h *Handler) GetWebhookSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetWebhookSubscriptionsHandler[http]: Получение подписок на вебхуки")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	subscriptions, err := h.service.GetWebhookSubscriptions(userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(subscriptions); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetWebhookSubscriptionsHandler[http]: Подписки на вебхуки успешно получены")
+}
+
+// DeleteWebhookSubscriptionHandler deletes webhook subscription of the authenticated user
+// @Summary Delete webhook subscription
+// @Description Deletes webhook subscription of the authenticated user with its delivery log
+// @Tags webhooks
+// @Param id path int true "Subscription ID"
+// @Success 204 "Subscription deleted"
+// @Failure 400 {object} models.Problem "Invalid ID format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Subscription not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /webhooks/{id} [delete]
+func (h *Handler) DeleteWebhookSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("DeleteWebhookSubscriptionHandler[http]: Удаление подписки на вебхуки")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	subscriptionID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	err = h.service.DeleteWebhookSubscription(userID, subscriptionID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	h.logger.Debugf("DeleteWebhookSubscriptionHandler[http]: Подписка на вебхуки успешно удалена")
+}
+
+// GetWebhookDeliveriesHandler lists delivery log of webhook subscription
+// @Summary List webhook deliveries
+// @Description Returns latest 100 deliveries of webhook subscription of the authenticated user, newest first
+// @Tags webhooks
+// @Produce  json
+// @Param id path int true "Subscription ID"
+// @Param status query string false "Comma-separated delivery statuses: pending, delivered, dead"
+// @Success 200 {array} models.WebhookDelivery "List of deliveries"
+// @Failure 400 {object} models.Problem "Invalid ID format or status"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Subscription not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *Handler) GetWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debugf("GetWebhookDeliveriesHandler[http]: Получение доставок вебхуков")
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	subscriptionID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	deliveries, err := h.service.GetWebhookDeliveries(userID, subscriptionID, queryList(r, "status"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(deliveries); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("GetWebhookDeliveriesHandler[http]: Доставки вебхуков успешно получены")
+}
+
+// GetWebhookDeliveryHandler retrieves webhook delivery with history of its attempts
+// @Summary Get webhook delivery
+// @Description Returns delivery of webhook subscription of the authenticated user with event and all attempts
+// @Tags webhooks
+// @Produce  json
+// @Param id path int true "Subscription ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 200 {object} models.WebhookDelivery "Delivery with attempts"
+// @Failure 400 {object} models.Problem "Invalid ID format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Subscription or delivery not found"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /webhooks/{id}/deliveries/{delivery_id} [get]
+func (h *Handler) GetWebhookDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeWebhookDelivery(w, r, "GetWebhookDeliveryHandler", h.service.GetWebhookDelivery)
+}
+
+// RedeliverWebhookHandler queues webhook delivery again
+// @Summary Redeliver webhook
+// @Description Queues delivered or dead delivery of webhook subscription of the authenticated user to be sent again
+// @Description with full number of attempts
+// @Tags webhooks
+// @Produce  json
+// @Param id path int true "Subscription ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 200 {object} models.WebhookDelivery "Queued delivery"
+// @Failure 400 {object} models.Problem "Invalid ID format"
+// @Failure 401 {object} models.Problem "Authentication error"
+// @Failure 404 {object} models.Problem "Subscription or delivery not found"
+// @Failure 409 {object} models.Problem "Delivery is still pending"
+// @Failure 500 {object} models.Problem "Server error"
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *Handler) RedeliverWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeWebhookDelivery(w, r, "RedeliverWebhookHandler", h.service.RedeliverWebhook)
+}
+
+// writeWebhookDelivery applies action to webhook delivery from path and writes resulting delivery
+func (h *Handler) writeWebhookDelivery(w http.ResponseWriter, r *http.Request, name string,
+	action func(userID, subscriptionID int, deliveryID int64) (models.WebhookDelivery, error)) {
+	h.logger.Debugf("%s[http]: Обработка доставки вебхука", name)
+
+	userID, ok := r.Context().Value("UserID").(int)
+	if !ok {
+		h.writeUnauthenticated(w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	subscriptionID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+	deliveryID, err := strconv.ParseInt(vars["delivery_id"], 10, 64)
+	if err != nil {
+		h.writeInvalidParameter(w, r, "id", "Неправильный формат ID")
+		return
+	}
+
+	delivery, err := action(userID, subscriptionID, deliveryID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(delivery); err != nil {
+		h.writeEncodeError(w, r, err)
+		return
+	}
+
+	h.logger.Debugf("%s[http]: Доставка вебхука с id: %d обработана", name, delivery.ID)
+}
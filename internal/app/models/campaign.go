@@ -0,0 +1,22 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Campaign struct {
+	ID                  int             `json:"id"`
+	Name                string          `json:"name"`
+	StartsAt            time.Time       `json:"starts_at"`
+	EndsAt              *time.Time      `json:"ends_at,omitempty"`
+	DefaultCodeLifetime *time.Duration  `json:"-"`
+	DefaultExpiresIn    string          `json:"default_expires_in,omitempty" example:"720h0m0s"`
+	RewardRules         json.RawMessage `json:"reward_rules" swaggertype:"object"`
+	MaxParticipants     *int            `json:"max_participants,omitempty"`
+	CodesCount          int             `json:"codes_count"`
+	ParticipantsCount   int             `json:"participants_count"`
+	CreatedBy           *int            `json:"created_by,omitempty"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+}
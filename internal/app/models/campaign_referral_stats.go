@@ -0,0 +1,10 @@
+package models
+
+// CampaignReferralStats aggregates referrals of referrer within one campaign
+// CampaignID is nil for codes that do not belong to any campaign
+type CampaignReferralStats struct {
+	CampaignID     *int   `json:"campaign_id"`
+	CampaignName   string `json:"campaign_name,omitempty"`
+	CodesCount     int    `json:"codes_count"`
+	ReferralsCount int    `json:"referrals_count"`
+}
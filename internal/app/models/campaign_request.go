@@ -0,0 +1,17 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CampaignRequest describes campaign on creation and update
+// StartsAt defaults to current time, DefaultExpiresIn is lifetime of campaign codes created without expiration
+type CampaignRequest struct {
+	Name             string          `json:"name" example:"Новогодняя акция"`
+	StartsAt         time.Time       `json:"starts_at" example:"2024-12-01T00:00:00+03:00"`
+	EndsAt           *time.Time      `json:"ends_at,omitempty" example:"2025-01-01T00:00:00+03:00"`
+	DefaultExpiresIn string          `json:"default_expires_in,omitempty" example:"720h"`
+	RewardRules      json.RawMessage `json:"reward_rules,omitempty" swaggertype:"object"`
+	MaxParticipants  *int            `json:"max_participants,omitempty" example:"1000"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// ClientInfo describes client making request, it is used to recognize devices of users
+type ClientInfo struct {
+	// IP is address of client
+	IP string
+	// DeviceFingerprint is opaque identifier of device sent by client in X-Device-Fingerprint header
+	DeviceFingerprint string
+}
@@ -0,0 +1,8 @@
+package models
+
+// GraphPage is page of list field of GraphQL query, list of every key holds at most First items
+// from newest to oldest, starting after item with id After; zero After means the first page
+type GraphPage struct {
+	First int
+	After int
+}
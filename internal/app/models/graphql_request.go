@@ -0,0 +1,8 @@
+package models
+
+// GraphQLRequest is GraphQL query with variables, OperationName selects operation of document with several ones
+type GraphQLRequest struct {
+	Query         string                 `json:"query" example:"{ me { id email referral_codes { code referrals { email status } } } }"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
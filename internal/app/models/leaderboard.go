@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Leaderboard metrics: referrals not rejected or reversed, qualified referrals and rewards earned
+const (
+	LeaderboardMetricReferrals = "referrals"
+	LeaderboardMetricQualified = "qualified"
+	LeaderboardMetricRewards   = "rewards"
+)
+
+// LeaderboardOptions describes which leaderboard is requested and which page of it
+type LeaderboardOptions struct {
+	// Metric is "referrals", "qualified" or "rewards"
+	Metric string
+	// Window is calendar period counted up to now: "day", "week", "month", "year" or "total"
+	Window string
+	// CampaignID limits leaderboard to referrals of campaign codes
+	CampaignID *int
+	Limit      int
+	Offset     int
+}
+
+// LeaderboardResponse is page of leaderboard with rank of the requesting user
+// Me is nil if user has no score in the window
+type LeaderboardResponse struct {
+	Metric      string             `json:"metric" example:"qualified"`
+	Window      string             `json:"window" example:"month"`
+	CampaignID  *int               `json:"campaign_id,omitempty"`
+	Since       *time.Time         `json:"since,omitempty"`
+	Total       int                `json:"total" example:"42"`
+	Limit       int                `json:"limit" example:"20"`
+	Offset      int                `json:"offset" example:"0"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	Entries     []LeaderboardEntry `json:"entries"`
+	Me          *LeaderboardEntry  `json:"me,omitempty"`
+}
+
+// LeaderboardEntry is ranked user, users with equal score share rank
+// DisplayName is set only for users who opted in, others are listed anonymously
+type LeaderboardEntry struct {
+	Rank        int     `json:"rank" example:"1"`
+	UserID      int     `json:"-"`
+	DisplayName *string `json:"display_name,omitempty" example:"Alice"`
+	Score       int64   `json:"score" example:"15"`
+	IsMe        bool    `json:"is_me,omitempty"`
+}
+
+// LeaderboardSettings controls whether user is shown on leaderboards by display name
+type LeaderboardSettings struct {
+	OptIn       bool   `json:"opt_in"`
+	DisplayName string `json:"display_name" example:"Alice"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Notification modes: every notification is emailed at once, collected into daily digest or not sent at all
+const (
+	NotificationModeImmediate = "immediate"
+	NotificationModeDigest    = "digest"
+	NotificationModeOff       = "off"
+)
+
+// Locales of notification emails
+const (
+	LocaleRU = "ru"
+	LocaleEN = "en"
+)
+
+// Notification statuses, failed notification ran out of attempts
+const (
+	NotificationStatusPending = "pending"
+	NotificationStatusSent    = "sent"
+	NotificationStatusFailed  = "failed"
+)
+
+// Notification types
+const (
+	NotificationReferralCreated = "referral.created"
+)
+
+// NotificationSettings controls how user is notified and in which language
+type NotificationSettings struct {
+	Mode   string `json:"mode" example:"immediate"`
+	Locale string `json:"locale" example:"ru"`
+}
+
+// NotificationSettingsRequest changes notification settings, omitted fields are left as they are
+type NotificationSettingsRequest struct {
+	Mode   *string `json:"mode,omitempty" example:"digest"`
+	Locale *string `json:"locale,omitempty" example:"en"`
+}
+
+// Notification is queued email to user
+// Email and Locale are settings of recipient at the time notification is sent, empty Locale means default one
+type Notification struct {
+	ID        int64
+	UserID    int
+	Email     string
+	Locale    string
+	Type      string
+	Data      json.RawMessage
+	Digest    bool
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// NotificationReferral is data of referral.created notification
+type NotificationReferral struct {
+	ReferralID int    `json:"referral_id"`
+	Email      string `json:"email"`
+	Code       string `json:"code"`
+}
+
+// Email is message sent by Mailer
+type Email struct {
+	To      string
+	Subject string
+	Text    string
+}
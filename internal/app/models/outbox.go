@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Aggregates whose changes are recorded in outbox, messages of one aggregate are published in order
+const (
+	OutboxAggregateReferral     = "referral"
+	OutboxAggregateReferralCode = "referral_code"
+)
+
+// Domain events recorded in outbox, payload of referral_code.created, referral_code.revoked, referral_code.paused
+// and referral_code.resumed events is ReferralCode, payload of referral.created is Referral
+const (
+	OutboxEventReferralCreated       = "referral.created"
+	OutboxEventReferralStatusChanged = "referral.status_changed"
+	OutboxEventReferralCodeCreated   = "referral_code.created"
+	OutboxEventReferralCodeRevoked   = "referral_code.revoked"
+	OutboxEventReferralCodeRotated   = "referral_code.rotated"
+	OutboxEventReferralCodePaused    = "referral_code.paused"
+	OutboxEventReferralCodeResumed   = "referral_code.resumed"
+)
+
+// OutboxMessage is domain event waiting in outbox to be published
+// ID identifies message across redeliveries, consumers use it to drop duplicates
+// UserID is referrer owning aggregate, nil if aggregate is already gone
+type OutboxMessage struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int             `json:"aggregate_id"`
+	EventType     string          `json:"type"`
+	Payload       json.RawMessage `json:"data"`
+	UserID        *int            `json:"-"`
+	Attempts      int             `json:"-"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// OutboxReferralStatus is payload of referral.status_changed event
+type OutboxReferralStatus struct {
+	ReferralID int    `json:"referral_id"`
+	Status     string `json:"status"`
+}
+
+// OutboxReferralCodeRotation is payload of referral_code.rotated event
+type OutboxReferralCodeRotation struct {
+	PreviousID int          `json:"previous_id"`
+	GraceUntil time.Time    `json:"grace_until"`
+	Previous   ReferralCode `json:"previous"`
+	Current    ReferralCode `json:"current"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Payout statuses: requested payout is approved or rejected by admin, approved payout is paid or failed by provider
+const (
+	PayoutStatusRequested = "requested"
+	PayoutStatusApproved  = "approved"
+	PayoutStatusRejected  = "rejected"
+	PayoutStatusPaid      = "paid"
+	PayoutStatusFailed    = "failed"
+)
+
+// Payout is request to cash out rewards, its amount is reserved on payout account while it is processed
+type Payout struct {
+	ID                int                  `json:"id"`
+	UserID            int                  `json:"user_id"`
+	Amount            int64                `json:"amount" example:"1000"`
+	Destination       string               `json:"destination" example:"card:4276********1234"`
+	Status            string               `json:"status" example:"requested"`
+	Provider          string               `json:"provider" example:"fake"`
+	ProviderReference *string              `json:"provider_reference,omitempty"`
+	Reason            *string              `json:"reason,omitempty"`
+	History           []PayoutStatusChange `json:"history,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
+}
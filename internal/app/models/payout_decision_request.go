@@ -0,0 +1,6 @@
+package models
+
+// PayoutDecisionRequest carries optional comment of admin approving or rejecting payout
+type PayoutDecisionRequest struct {
+	Reason string `json:"reason,omitempty" example:"Подозрение на мошенничество"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// PayoutRequest asks to cash out amount of available rewards to destination, e.g. card or wallet
+type PayoutRequest struct {
+	Amount      int64  `json:"amount" example:"1000"`
+	Destination string `json:"destination" example:"card:4276********1234"`
+}
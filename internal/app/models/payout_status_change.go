@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PayoutStatusChange is audit record of payout status transition
+// ChangedBy is nil for transitions made by payout provider
+type PayoutStatusChange struct {
+	ID         int       `json:"id"`
+	PayoutID   int       `json:"payout_id"`
+	FromStatus *string   `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  *int      `json:"changed_by,omitempty"`
+	Reason     *string   `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// Problem is error response in RFC 7807 application/problem+json format
+// Code is stable machine-readable error code, Detail is human-readable message in Russian
+type Problem struct {
+	Type      string         `json:"type" example:"urn:rest-refs:problem:user_already_exists"`
+	Title     string         `json:"title" example:"Conflict"`
+	Status    int            `json:"status" example:"409"`
+	Detail    string         `json:"detail" example:"Такой пользователь уже существует"`
+	Instance  string         `json:"instance" example:"/auth/register"`
+	Code      string         `json:"code" example:"user_already_exists"`
+	RequestID string         `json:"request_id" example:"3f2b8c1d9e4a7b6c5d0e1f2a3b4c5d6e"`
+	Errors    []ProblemField `json:"errors,omitempty"`
+}
+
+// ProblemField is validation error of one field of request
+type ProblemField struct {
+	Field   string `json:"field" example:"limit"`
+	Message string `json:"message" example:"limit должен быть от 1 до 100"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// QR code image formats
+const (
+	QRCodeFormatPNG = "png"
+	QRCodeFormatSVG = "svg"
+)
+
+// QRCodeOptions describes how QR code of referral share link is rendered
+type QRCodeOptions struct {
+	// Format is image format: "png" or "svg"
+	Format string
+	// Size is image width and height in pixels
+	Size int
+	// Level is error correction level: "L", "M", "Q" or "H"
+	Level string
+	// Margin is width of quiet zone around QR code in modules, negative value selects default
+	Margin int
+}
@@ -4,11 +4,26 @@ import (
 	"time"
 )
 
+// Referral statuses, referral counts for referrer only when it is qualified
+const (
+	ReferralStatusPending   = "pending"
+	ReferralStatusQualified = "qualified"
+	ReferralStatusRejected  = "rejected"
+	ReferralStatusReversed  = "reversed"
+)
+
 type Referral struct {
-	ID             int       `json:"id"`
-	Email          string    `json:"email"`
-	ReferralCodeID int       `json:"referral_code_id"`
-	ReferrerID     int       `json:"referrer_id"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID              int        `json:"id"`
+	Email           string     `json:"email"`
+	ReferralCodeID  int        `json:"referral_code_id"`
+	ReferrerID      int        `json:"referrer_id"`
+	UserID          *int       `json:"user_id,omitempty"`
+	IPHash          string     `json:"-"`
+	DeviceHash      string     `json:"-"`
+	ReferralClickID *int       `json:"referral_click_id,omitempty"`
+	CampaignID      *int       `json:"campaign_id,omitempty"`
+	Status          string     `json:"status"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type ReferralClick struct {
+	ID             int       `json:"id"`
+	Token          string    `json:"-"`
+	ReferralCodeID int       `json:"referral_code_id"`
+	Code           string    `json:"code,omitempty"`
+	IPHash         string    `json:"ip_hash"`
+	UserAgent      string    `json:"user_agent"`
+	Referer        string    `json:"referer"`
+	UTMSource      string    `json:"utm_source,omitempty"`
+	UTMMedium      string    `json:"utm_medium,omitempty"`
+	UTMCampaign    string    `json:"utm_campaign,omitempty"`
+	UTMTerm        string    `json:"utm_term,omitempty"`
+	UTMContent     string    `json:"utm_content,omitempty"`
+	ClickedAt      time.Time `json:"clicked_at"`
+}
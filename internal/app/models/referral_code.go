@@ -3,11 +3,20 @@ package models
 import "time"
 
 type ReferralCode struct {
-	ID         int        `json:"id"`
-	Code       string     `json:"code"`
-	Expiration time.Time  `json:"expires_at"`
-	ReferrerID int        `json:"referrer_id"`
-	Referrals  []Referral `json:"referrals,omitempty"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
+	ID           int        `json:"id"`
+	Code         string     `json:"code"`
+	Expiration   time.Time  `json:"expires_at"`
+	ReferrerID   int        `json:"referrer_id"`
+	PausedAt     *time.Time `json:"paused_at,omitempty"`
+	MaxUses      *int       `json:"max_uses,omitempty"`
+	UsesCount    int        `json:"uses_count"`
+	BatchID      *int       `json:"batch_id,omitempty"`
+	CampaignID   *int       `json:"campaign_id,omitempty"`
+	CampaignFull bool       `json:"-"` // campaign of the code reached its participants limit
+	LinkID       *int       `json:"link_id,omitempty"`
+	SupersededBy *int       `json:"superseded_by,omitempty"`
+	RotatedAt    *time.Time `json:"rotated_at,omitempty"`
+	Referrals    []Referral `json:"referrals,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type ReferralCodeBatch struct {
+	ID         int       `json:"id"`
+	ReferrerID int       `json:"referrer_id"`
+	CreatedBy  *int      `json:"created_by,omitempty"`
+	CampaignID *int      `json:"campaign_id,omitempty"`
+	Size       int       `json:"size"`
+	MaxUses    *int      `json:"max_uses,omitempty"`
+	Expiration time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// ReferralCodeBatchCreateRequest describes batch of referral codes owned by referrer
+// Expiration fields have the same meaning as in ReferralCodeCreateRequest, MaxUses defaults to 1
+type ReferralCodeBatchCreateRequest struct {
+	ReferrerID int `json:"referrer_id" example:"1"`
+	Count      int `json:"count" example:"1000"`
+	ReferralCodeCreateRequest
+}
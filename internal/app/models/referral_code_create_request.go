@@ -1,5 +1,14 @@
 package models
 
+// ReferralCodeCreateRequest describes referral code expiration
+// Either ExpirationDate (RFC 3339 timestamp or date-only "02.01.2006" / "2006-01-02")
+// or ExpiresIn (duration such as "72h") must be set
+// MaxUses optionally limits number of registrations with the code
+// CampaignID optionally links code to campaign, campaign's default lifetime is used when expiration is omitted
 type ReferralCodeCreateRequest struct {
-	ExpirationDate string `json:"expiration_date" binding:"required"`
+	ExpirationDate string `json:"expiration_date,omitempty" example:"2024-10-20T18:00:00+03:00"`
+	ExpiresIn      string `json:"expires_in,omitempty" example:"72h"`
+	TimeZone       string `json:"time_zone,omitempty" example:"Europe/Moscow"`
+	MaxUses        *int   `json:"max_uses,omitempty" example:"100"`
+	CampaignID     *int   `json:"campaign_id,omitempty" example:"1"`
 }
@@ -0,0 +1,7 @@
+package models
+
+// ReferralCodeRotateRequest describes how long replaced referral code stays valid
+// GracePeriod is duration such as "24h", "0s" revokes old code immediately, omitted value means default from config
+type ReferralCodeRotateRequest struct {
+	GracePeriod *string `json:"grace_period,omitempty" example:"24h"`
+}
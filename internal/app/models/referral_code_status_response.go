@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Referral code statuses returned by public validation endpoint
+const (
+	ReferralCodeStatusActive    = "active"
+	ReferralCodeStatusExpired   = "expired"
+	ReferralCodeStatusPaused    = "paused"
+	ReferralCodeStatusExhausted = "exhausted"
+	ReferralCodeStatusNotFound  = "not_found"
+	ReferralCodeStatusMalformed = "malformed"
+)
+
+type ReferralCodeStatusResponse struct {
+	Code         string     `json:"code"`
+	Exists       bool       `json:"exists"`
+	Active       bool       `json:"active"`
+	Status       string     `json:"status" example:"active"`
+	Expiration   *time.Time `json:"expiration,omitempty"`
+	ReferrerName string     `json:"referrer_name,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ReferralEvent is external event about referred user, such as email verification or purchase
+// EventID is assigned by sender and makes ingestion idempotent
+type ReferralEvent struct {
+	ID         int             `json:"id"`
+	EventID    string          `json:"event_id"`
+	ReferralID int             `json:"referral_id"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload" swaggertype:"object"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	ReceivedAt time.Time       `json:"received_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ReferralEventRequest describes external event, referral is found by ReferralID or by Email of referred user
+// OccurredAt defaults to time of receipt
+type ReferralEventRequest struct {
+	EventID    string          `json:"event_id" example:"shop-order-10042"`
+	Type       string          `json:"type" example:"first_purchase"`
+	ReferralID *int            `json:"referral_id,omitempty" example:"1"`
+	Email      string          `json:"email,omitempty" example:"friend@example.com"`
+	OccurredAt *time.Time      `json:"occurred_at,omitempty" example:"2024-10-26T12:00:00Z"`
+	Payload    json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+}
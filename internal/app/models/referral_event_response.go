@@ -0,0 +1,12 @@
+package models
+
+// ReferralEventResponse reports referral status after event is processed
+// Duplicate is true when event with the same EventID was already received, then nothing is changed
+type ReferralEventResponse struct {
+	EventID       string `json:"event_id"`
+	ReferralID    int    `json:"referral_id"`
+	ReferrerID    int    `json:"-"`
+	Status        string `json:"status" example:"qualified"`
+	Duplicate     bool   `json:"duplicate"`
+	StatusChanged bool   `json:"-"` // event moved referral to new status
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ReferralFeedReset is type of feed event telling client that events were missed and state must be reloaded
+const ReferralFeedReset = "reset"
+
+// ReferralFeedEvent is change of referrals or referral codes of referrer pushed to real-time feed
+// ID is position of outbox message in feed taken when it is committed, client passes last seen one back
+// as Last-Event-ID to resume feed
+type ReferralFeedEvent struct {
+	ID        int64           `json:"id" example:"42"`
+	Type      string          `json:"type" example:"referral.created"`
+	UserID    int             `json:"-"`
+	Data      json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -3,8 +3,11 @@ package models
 import "time"
 
 type ReferralInfoResponse struct {
-	ReferralID int       `json:"referral_id"`
-	ReferrerID int       `json:"referrer_id"`
-	Email      string    `json:"email"`
-	CreatedAt  time.Time `json:"created_at"`
+	ReferralID      int        `json:"referral_id"`
+	ReferrerID      int        `json:"referrer_id"`
+	Email           string     `json:"email"`
+	CampaignID      *int       `json:"campaign_id,omitempty"`
+	Status          string     `json:"status" example:"qualified"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ReferralLink groups all codes that replaced each other by rotation
+// Counts are summed over all codes of the link, so stats do not restart after rotation
+type ReferralLink struct {
+	ID             int            `json:"id"`
+	ReferrerID     int            `json:"referrer_id"`
+	Codes          []ReferralCode `json:"codes"`
+	ReferralsCount int            `json:"referrals_count"`
+	ClicksCount    int            `json:"clicks_count"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
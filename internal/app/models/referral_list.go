@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Sort orders of referral listings, both are paginated by keyset of created_at and id
+const (
+	ReferralSortNewest = "-created_at"
+	ReferralSortOldest = "created_at"
+)
+
+// ReferralListOptions describes filters, sort order and page of referral listing as requested by client
+type ReferralListOptions struct {
+	// Statuses limit listing to referrals in these statuses
+	Statuses []string
+	// CodeID limits listing to referrals of referral code
+	CodeID *int
+	// From and To limit creation time of referrals, RFC 3339 timestamp or date, To date is inclusive
+	From string
+	To   string
+	// Sort is "-created_at" for newest first or "created_at" for oldest first
+	Sort string
+	// Cursor is opaque position after which page starts, it is taken from previous page
+	Cursor string
+	Limit  int
+}
+
+// ReferralFilter is validated ReferralListOptions, After is keyset of last referral of previous page
+type ReferralFilter struct {
+	Statuses  []string
+	CodeID    *int
+	From      *time.Time
+	To        *time.Time
+	Ascending bool
+	After     *ReferralCursor
+	Limit     int
+}
+
+// ReferralCursor is position in referral listing ordered by created_at and id
+type ReferralCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// ReferralPage is page of referral listing with number of referrals matching filters
+// NextCursor is empty on last page
+type ReferralPage struct {
+	Referrals  []ReferralInfoResponse
+	Total      int
+	NextCursor string
+}
+
+// Formats of referral export
+const (
+	ReferralExportCSV   = "csv"
+	ReferralExportXLSX  = "xlsx"
+	ReferralExportJSONL = "jsonl"
+)
+
+// ReferralExportRow is referral as written to export, one row per referral
+type ReferralExportRow struct {
+	ReferralID      int        `json:"referral_id"`
+	ReferrerID      int        `json:"referrer_id"`
+	Email           string     `json:"email"`
+	ReferralCodeID  *int       `json:"referral_code_id"`
+	Code            *string    `json:"code"`
+	CampaignID      *int       `json:"campaign_id"`
+	Status          string     `json:"status"`
+	StatusChangedAt *time.Time `json:"status_changed_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
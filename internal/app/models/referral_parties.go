@@ -0,0 +1,19 @@
+package models
+
+import "encoding/json"
+
+// ReferralParties describes who is rewarded for referral and by which campaign rules
+// RefereeID is nil if referred user can not be found
+// Upline lists referrer's referrer, next referrer up and so on, nearest first
+// ReviewStatus is empty unless fraud checks placed referral into manual review queue
+// ReferralNumber is position of referral among referrer's not rejected and not reversed referrals, starting at 1
+type ReferralParties struct {
+	ReferralID     int
+	ReferrerID     int
+	RefereeID      *int
+	Upline         []int
+	CampaignID     *int
+	RewardRules    json.RawMessage
+	ReferralNumber int
+	ReviewStatus   string
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Review statuses of referrals placed into manual review queue by fraud checks
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// Fraud checks run on every referral
+const (
+	FraudCheckSelfReferral    = "self_referral"
+	FraudCheckSameIP          = "same_ip"
+	FraudCheckSameDevice      = "same_device"
+	FraudCheckVelocity        = "velocity"
+	FraudCheckDisposableEmail = "disposable_email"
+	// FraudCheckFailed marks referral that could not be scored because checks themselves failed
+	FraudCheckFailed = "check_failed"
+)
+
+// RiskSignal is result of fraud check that found referral suspicious, Score is its contribution to risk score
+type RiskSignal struct {
+	Check  string `json:"check" example:"same_ip"`
+	Score  int    `json:"score" example:"40"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// RiskAssessment is outcome of all fraud checks of referral, risk score is capped at 100
+type RiskAssessment struct {
+	Score   int
+	Signals []RiskSignal
+	Review  bool
+}
+
+// ReferralReview is referral with its risk assessment and manual review decision
+type ReferralReview struct {
+	ReferralID     int          `json:"referral_id"`
+	Email          string       `json:"email"`
+	ReferrerID     int          `json:"referrer_id"`
+	ReferralCodeID *int         `json:"referral_code_id,omitempty"`
+	Status         string       `json:"status" example:"pending"`
+	RiskScore      int          `json:"risk_score" example:"70"`
+	RiskSignals    []RiskSignal `json:"risk_signals"`
+	ReviewStatus   string       `json:"review_status" example:"pending"`
+	ReviewedBy     *int         `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time   `json:"reviewed_at,omitempty"`
+	ReviewReason   *string      `json:"review_reason,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// ReferralReviewDecisionRequest is optional comment of admin approving or rejecting referral
+type ReferralReviewDecisionRequest struct {
+	Reason string `json:"reason,omitempty" example:"Same household, confirmed by support"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Granularities of referral statistics time series
+const (
+	ReferralStatsGranularityDay  = "day"
+	ReferralStatsGranularityWeek = "week"
+)
+
+// ReferralStatsOptions describes which referrals statistics is computed for
+type ReferralStatsOptions struct {
+	// ReferrerID limits statistics to one referrer, nil means whole program
+	ReferrerID *int
+	// From and To are RFC 3339 timestamps or dates ("2024-10-20") in default time zone, To date is inclusive
+	From string
+	To   string
+	// Granularity is "day" or "week", weeks start on Monday
+	Granularity string
+}
+
+// ReferralStatsResponse is statistics of referrals signed up in [From, To)
+type ReferralStatsResponse struct {
+	ReferrerID  *int                 `json:"referrer_id,omitempty"`
+	From        time.Time            `json:"from"`
+	To          time.Time            `json:"to"`
+	Granularity string               `json:"granularity" example:"day"`
+	Totals      ReferralStatsTotals  `json:"totals"`
+	Conversion  ReferralConversion   `json:"conversion"`
+	Series      []ReferralStatsPoint `json:"series"`
+	Codes       []ReferralCodeStats  `json:"codes"`
+}
+
+// ReferralStatsTotals counts clicks made and referrals signed up in range, referrals are counted by current status
+type ReferralStatsTotals struct {
+	Clicks    int `json:"clicks" example:"120"`
+	SignUps   int `json:"sign_ups" example:"30"`
+	Pending   int `json:"pending" example:"10"`
+	Qualified int `json:"qualified" example:"15"`
+	Rejected  int `json:"rejected" example:"3"`
+	Reversed  int `json:"reversed" example:"2"`
+}
+
+// ReferralConversion is share of clicks turned into sign-ups and of sign-ups turned into qualified referrals
+// Rate is 0 when there is nothing to convert
+type ReferralConversion struct {
+	ClickToSignUp     float64 `json:"click_to_sign_up" example:"0.25"`
+	SignUpToQualified float64 `json:"sign_up_to_qualified" example:"0.5"`
+	ClickToQualified  float64 `json:"click_to_qualified" example:"0.125"`
+}
+
+// ReferralStatsPoint counts clicks, sign-ups and qualifications that happened within one period
+// Periods without activity are included with zero counts
+type ReferralStatsPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	Clicks      int       `json:"clicks" example:"12"`
+	SignUps     int       `json:"sign_ups" example:"3"`
+	Qualified   int       `json:"qualified" example:"1"`
+}
+
+// ReferralCodeStats is breakdown of statistics by referral code
+type ReferralCodeStats struct {
+	ReferralCodeID int                `json:"referral_code_id"`
+	Code           string             `json:"code" example:"ABCD-EFGH"`
+	CampaignID     *int               `json:"campaign_id,omitempty"`
+	Clicks         int                `json:"clicks" example:"40"`
+	SignUps        int                `json:"sign_ups" example:"10"`
+	Qualified      int                `json:"qualified" example:"5"`
+	Conversion     ReferralConversion `json:"conversion"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ReferralTreeResponse is downline of user: referrals of user, their referrals and so on up to Depth levels
+type ReferralTreeResponse struct {
+	UserID    int                 `json:"user_id"`
+	Depth     int                 `json:"depth" example:"3"`
+	Total     int                 `json:"total" example:"7"`
+	Levels    []ReferralTreeLevel `json:"levels"`
+	Referrals []ReferralTreeNode  `json:"referrals"`
+}
+
+// ReferralTreeLevel is number of referrals at given distance from user, direct referrals are level 1
+type ReferralTreeLevel struct {
+	Level int `json:"level" example:"1"`
+	Count int `json:"count" example:"4"`
+}
+
+// ReferralTreeNode is referral in downline with referrals of referred user as children
+// UserID is nil if referred user is not registered or was deleted, such node has no children
+type ReferralTreeNode struct {
+	ReferralID int                `json:"referral_id"`
+	UserID     *int               `json:"user_id,omitempty"`
+	ReferrerID int                `json:"referrer_id"`
+	Email      string             `json:"email"`
+	Status     string             `json:"status" example:"qualified"`
+	Level      int                `json:"level" example:"1"`
+	CreatedAt  time.Time          `json:"created_at"`
+	Children   []ReferralTreeNode `json:"children"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Kinds of reward transactions
+const (
+	RewardKindReferralCreated   = "referral_created"
+	RewardKindReferralQualified = "referral_qualified"
+	RewardKindReversal          = "reversal"
+	RewardKindPayoutHold        = "payout_hold"
+	RewardKindPayoutRelease     = "payout_release"
+	RewardKindPayoutPaid        = "payout_paid"
+)
+
+// RewardTransaction is balanced set of ledger entries posted at once, amounts of its entries sum up to zero
+// IdempotencyKey makes repeated posting of the same reward a no-op
+type RewardTransaction struct {
+	ID             int           `json:"id"`
+	Kind           string        `json:"kind"`
+	ReferralID     *int          `json:"referral_id,omitempty"`
+	IdempotencyKey string        `json:"-"`
+	ReversesID     *int          `json:"reverses_id,omitempty"`
+	Entries        []RewardEntry `json:"entries,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// RewardEntry is immutable change of account balance, positive amount credits account
+// UserID is nil for program account which funds rewards
+type RewardEntry struct {
+	ID            int       `json:"id"`
+	TransactionID int       `json:"transaction_id"`
+	UserID        *int      `json:"-"`
+	Kind          string    `json:"kind,omitempty"`
+	ReferralID    *int      `json:"referral_id,omitempty"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+	// Caps limit amount of entry by what user already earned, they are checked when entry is posted
+	Caps []RewardEntryCap `json:"-"`
+}
+
+// RewardEntryCap limits what user may earn from referrals as Party since Since to Amount,
+// empty Party limits what user earns as referrer and as referee together
+type RewardEntryCap struct {
+	Party  string
+	Since  time.Time
+	Amount int64
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// RewardDryRunRequest asks what referral would earn at given moment (now by default)
+// If RuleSet is set, it is evaluated instead of active rule sets, so rules can be checked before saving
+type RewardDryRunRequest struct {
+	ReferralID int                   `json:"referral_id" example:"1"`
+	At         *time.Time            `json:"at,omitempty" example:"2024-12-15T12:00:00Z"`
+	RuleSet    *RewardRuleSetRequest `json:"rule_set,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// RewardDryRunResponse shows rewards referral would earn without posting them
+type RewardDryRunResponse struct {
+	ReferralID     int              `json:"referral_id"`
+	RuleSetID      *int             `json:"rule_set_id,omitempty"`
+	ReferralNumber int              `json:"referral_number"`
+	At             time.Time        `json:"at"`
+	Rewards        []RewardEstimate `json:"rewards"`
+}
+
+// RewardEstimate is reward of one party for one trigger
+// BaseAmount comes from config and campaign, Amount is result after rules and caps
+// Upline bonus is percent of referrer's final amount, Level is its distance from referrer
+type RewardEstimate struct {
+	Trigger      string   `json:"trigger" example:"qualified"`
+	Party        string   `json:"party" example:"referrer"`
+	UserID       *int     `json:"user_id,omitempty"`
+	Level        int      `json:"level,omitempty" example:"1"`
+	BaseAmount   int64    `json:"base_amount" example:"100"`
+	Amount       int64    `json:"amount" example:"200"`
+	AppliedRules []string `json:"applied_rules"`
+	CapRemaining *int64   `json:"cap_remaining,omitempty"`
+	// Caps are checked again when reward is posted, so concurrent rewards do not exceed them together
+	Caps []RewardEntryCap `json:"-"`
+}
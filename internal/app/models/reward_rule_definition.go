@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Reward rule triggers and parties, upline is credited with bonus from referrer's reward and is not valid in rules
+const (
+	RewardTriggerCreated   = "created"
+	RewardTriggerQualified = "qualified"
+
+	RewardPartyReferrer = "referrer"
+	RewardPartyReferee  = "referee"
+	RewardPartyUpline   = "upline"
+)
+
+// Reward cap periods, day, week, month and year are calendar periods in default time zone
+const (
+	RewardCapPeriodDay   = "day"
+	RewardCapPeriodWeek  = "week"
+	RewardCapPeriodMonth = "month"
+	RewardCapPeriodYear  = "year"
+	RewardCapPeriodTotal = "total"
+)
+
+// RewardRuleDefinition is declarative description of rewards, e.g. in YAML:
+//
+//	rules:
+//	  - name: after 10 referrals
+//	    trigger: qualified
+//	    party: referrer
+//	    min_referrals: 10
+//	    amount: 500
+//	  - name: double december
+//	    trigger: qualified
+//	    multiplier: 2
+//	    from: 2024-12-01T00:00:00Z
+//	    until: 2025-01-01T00:00:00Z
+//	caps:
+//	  - party: referrer
+//	    period: month
+//	    amount: 5000
+//
+// Rules are applied in order to base amount from config and campaign reward_rules,
+// cap limits what party may earn from referrals during period
+type RewardRuleDefinition struct {
+	Rules []RewardRule `json:"rules" yaml:"rules"`
+	Caps  []RewardCap  `json:"caps,omitempty" yaml:"caps"`
+}
+
+// RewardRule matches reward by trigger, party, campaign, date range [From, Until) and referral number of referrer
+// Matching rule replaces amount with Amount and then multiplies it by Multiplier, empty Party matches both parties
+type RewardRule struct {
+	Name         string     `json:"name,omitempty" yaml:"name"`
+	Trigger      string     `json:"trigger" yaml:"trigger"`
+	Party        string     `json:"party,omitempty" yaml:"party"`
+	CampaignID   *int       `json:"campaign_id,omitempty" yaml:"campaign_id"`
+	From         *time.Time `json:"from,omitempty" yaml:"from"`
+	Until        *time.Time `json:"until,omitempty" yaml:"until"`
+	MinReferrals int        `json:"min_referrals,omitempty" yaml:"min_referrals"`
+	MaxReferrals *int       `json:"max_referrals,omitempty" yaml:"max_referrals"`
+	Amount       *int64     `json:"amount,omitempty" yaml:"amount"`
+	Multiplier   *float64   `json:"multiplier,omitempty" yaml:"multiplier"`
+}
+
+// RewardCap limits amount party may earn from referrals during period, empty Party limits both parties
+type RewardCap struct {
+	Party  string `json:"party,omitempty" yaml:"party"`
+	Period string `json:"period" yaml:"period"`
+	Amount int64  `json:"amount" yaml:"amount"`
+}
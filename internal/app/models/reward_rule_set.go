@@ -0,0 +1,22 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RewardRuleSet is versioned set of reward rules, Source keeps rules as they were written
+// and Rules keeps them normalized to JSON
+// Active rule set of campaign overrides active global rule set (CampaignID is nil) for referrals of campaign
+type RewardRuleSet struct {
+	ID         int             `json:"id"`
+	Name       string          `json:"name"`
+	CampaignID *int            `json:"campaign_id,omitempty"`
+	Active     bool            `json:"active"`
+	Format     string          `json:"format" example:"yaml"`
+	Source     string          `json:"source"`
+	Rules      json.RawMessage `json:"rules" swaggertype:"object"`
+	CreatedBy  *int            `json:"created_by,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
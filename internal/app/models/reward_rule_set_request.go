@@ -0,0 +1,12 @@
+package models
+
+// RewardRuleSetRequest describes rule set on creation, update and dry run
+// Source is RewardRuleDefinition written in Format, "json" (default) or "yaml"
+// Activating rule set deactivates previously active rule set of the same campaign or the global one
+type RewardRuleSetRequest struct {
+	Name       string `json:"name" example:"Декабрь"`
+	CampaignID *int   `json:"campaign_id,omitempty"`
+	Active     bool   `json:"active"`
+	Format     string `json:"format,omitempty" example:"yaml"`
+	Source     string `json:"source"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// RewardRules describes rewards of referrer and referee, it is stored as campaign's reward_rules, e.g.
+// {"referrer": {"created": 0, "qualified": 200}, "referee": {"qualified": 50}}
+// Omitted amounts fall back to defaults from config
+type RewardRules struct {
+	Referrer RewardAmounts `json:"referrer"`
+	Referee  RewardAmounts `json:"referee"`
+}
+
+// RewardAmounts are amounts credited when referral is created and when it is qualified
+type RewardAmounts struct {
+	Created   *int64 `json:"created,omitempty"`
+	Qualified *int64 `json:"qualified,omitempty"`
+}
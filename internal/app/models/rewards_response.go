@@ -0,0 +1,10 @@
+package models
+
+// RewardsResponse contains reward balance of user and ledger entries of user's account from newest to oldest
+// Held is part of balance earned from referrals still inside chargeback window, the rest is available for payout
+type RewardsResponse struct {
+	Balance   int64         `json:"balance" example:"150"`
+	Held      int64         `json:"held" example:"100"`
+	Available int64         `json:"available" example:"50"`
+	History   []RewardEntry `json:"history"`
+}
@@ -3,10 +3,15 @@ package models
 import "time"
 
 type User struct {
-	ID        int        `json:"id"`
-	Email     string     `json:"email"`
-	Password  string     `json:"password"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	Referrals []Referral `json:"referrals"`
+	ID               int        `json:"id"`
+	Email            string     `json:"email"`
+	Password         string     `json:"password"`
+	DisplayName      string     `json:"display_name,omitempty"`
+	IsAdmin          bool       `json:"is_admin"`
+	LeaderboardOptIn bool       `json:"leaderboard_opt_in"`
+	NotificationMode string     `json:"-"`
+	Locale           string     `json:"-"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	Referrals        []Referral `json:"referrals"`
 }
@@ -3,6 +3,7 @@ package models
 type RegisterRequest struct {
 	Email        string `json:"email" binding:"required"`
 	Password     string `json:"password" binding:"required"`
+	DisplayName  string `json:"display_name,omitempty"`
 	ReferralCode string `json:"referral_code,omitempty"`
 }
 
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of events sent to webhook subscriptions
+const (
+	WebhookEventReferralCreated       = "referral.created"
+	WebhookEventReferralStatusChanged = "referral.status_changed"
+	WebhookEventReferralCodeCreated   = "referral_code.created"
+	WebhookEventReferralCodeRevoked   = "referral_code.revoked"
+	WebhookEventReferralCodeRotated   = "referral_code.rotated"
+	WebhookEventReferralCodePaused    = "referral_code.paused"
+	WebhookEventReferralCodeResumed   = "referral_code.resumed"
+)
+
+// Webhook delivery statuses: pending delivery is retried until it is delivered or runs out of attempts and is dead
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription is endpoint receiving events of its owner, or events of all users if AllUsers is set
+// Empty Events means all event types. Secret signs deliveries, it is returned only when subscription is created
+type WebhookSubscription struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	URL       string    `json:"url" example:"https://crm.example.com/hooks/referrals"`
+	Events    []string  `json:"events" example:"referral.created,referral_code.created"`
+	AllUsers  bool      `json:"all_users"`
+	Secret    string    `json:"secret,omitempty" example:"whsec_5f2b..."`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookEvent is something that happened to referrals or referral codes of user
+type WebhookEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type" example:"referral.created"`
+	UserID    int             `json:"-"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// WebhookDelivery is event sent to one subscription with result of its last attempt
+type WebhookDelivery struct {
+	ID                 int64                    `json:"id"`
+	SubscriptionID     int                      `json:"subscription_id"`
+	Event              WebhookEvent             `json:"event"`
+	Status             string                   `json:"status" example:"pending"`
+	Attempts           int                      `json:"attempts" example:"1"`
+	NextAttemptAt      *time.Time               `json:"next_attempt_at,omitempty"`
+	LastAttemptAt      *time.Time               `json:"last_attempt_at,omitempty"`
+	LastResponseStatus *int                     `json:"last_response_status,omitempty" example:"502"`
+	LastError          *string                  `json:"last_error,omitempty"`
+	DeliveredAt        *time.Time               `json:"delivered_at,omitempty"`
+	History            []WebhookDeliveryAttempt `json:"history,omitempty"`
+	CreatedAt          time.Time                `json:"created_at"`
+}
+
+// WebhookDeliveryAttempt is one attempt to send delivery, ResponseStatus is nil if endpoint was not reached
+type WebhookDeliveryAttempt struct {
+	Attempt        int       `json:"attempt" example:"1"`
+	ResponseStatus *int      `json:"response_status,omitempty" example:"200"`
+	Error          *string   `json:"error,omitempty"`
+	DurationMs     int       `json:"duration_ms" example:"120"`
+	AttemptedAt    time.Time `json:"attempted_at"`
+}
+
+// WebhookDispatch is delivery claimed for sending with everything needed to send it
+type WebhookDispatch struct {
+	DeliveryID int64
+	Attempt    int
+	URL        string
+	Secret     string
+	Event      WebhookEvent
+}
+
+// WebhookReferralStatus is data of referral.status_changed event
+type WebhookReferralStatus struct {
+	ReferralID int    `json:"referral_id"`
+	ReferrerID int    `json:"referrer_id"`
+	Status     string `json:"status"`
+}
+
+// WebhookReferralCodeRotation is data of referral_code.rotated event
+type WebhookReferralCodeRotation struct {
+	Previous ReferralCode `json:"previous"`
+	Current  ReferralCode `json:"current"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// WebhookSubscriptionRequest is input for subscribing endpoint to events
+// AllUsers subscribes to events of all users and is allowed only for admins
+type WebhookSubscriptionRequest struct {
+	URL      string   `json:"url" example:"https://crm.example.com/hooks/referrals"`
+	Events   []string `json:"events,omitempty" example:"referral.created,referral_code.created"`
+	AllUsers bool     `json:"all_users,omitempty"`
+}
@@ -0,0 +1,383 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+var ErrCampaignNotFound = errors.New("кампания не найдена")
+
+// campaignColumns lists campaign columns together with its codes and participants counts
+const campaignColumns = `c.id, c.name, c.starts_at, c.ends_at, c.default_code_lifetime_seconds, c.reward_rules,
+       c.max_participants,
+       (SELECT COUNT(*) FROM referral_codes rc WHERE rc.campaign_id = c.id),
+       (SELECT COUNT(*) FROM referrals r JOIN referral_codes rc ON rc.id = r.referral_code_id
+        WHERE rc.campaign_id = c.id),
+       c.created_by, c.created_at, c.updated_at`
+
+// CampaignPostgres implements the CampaignRepo interface for PostgreSQL database operations related to campaigns
+type CampaignPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewCampaignPostgres creates new CampaignPostgres instance with provided database connection and logger
+func NewCampaignPostgres(db database.Database, logger *logrus.Logger) *CampaignPostgres {
+	return &CampaignPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create inserts new campaign into the campaigns table and returns it with generated id and timestamps
+func (r *CampaignPostgres) Create(campaign models.Campaign) (models.Campaign, error) {
+	r.logger.Debugf("Create[repo]: Создание кампании: %s", campaign.Name)
+
+	query := `INSERT INTO campaigns (name, starts_at, ends_at, default_code_lifetime_seconds, reward_rules,
+              max_participants, created_by, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+              RETURNING id, created_at, updated_at`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get campaign from goroutine
+	campaignChan := make(chan models.Campaign)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		err = tx.QueryRow(ctx, query, campaign.Name, campaign.StartsAt, campaign.EndsAt,
+			lifetimeSeconds(campaign.DefaultCodeLifetime), campaign.RewardRules, campaign.MaxParticipants,
+			campaign.CreatedBy).Scan(&campaign.ID, &campaign.CreatedAt, &campaign.UpdatedAt)
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка создания кампании: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		campaignChan <- campaign
+	}()
+
+	select {
+	case created := <-campaignChan:
+		r.logger.Infof("Create[repo]: Кампания с id: %d успешно создана", created.ID)
+		return created, nil
+	case err := <-errChan:
+		return models.Campaign{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("Create[repo]: Время ожидания превышено")
+		return models.Campaign{}, ctx.Err()
+	}
+}
+
+// Update replaces editable fields of campaign with given id
+// If campaign not found, returns ErrCampaignNotFound
+func (r *CampaignPostgres) Update(campaign models.Campaign) error {
+	r.logger.Debugf("Update[repo]: Изменение кампании с id: %d", campaign.ID)
+
+	query := `UPDATE campaigns SET name = $2, starts_at = $3, ends_at = $4, default_code_lifetime_seconds = $5,
+              reward_rules = $6, max_participants = $7, updated_at = NOW()
+              WHERE id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Update[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		result, err := tx.Exec(ctx, query, campaign.ID, campaign.Name, campaign.StartsAt, campaign.EndsAt,
+			lifetimeSeconds(campaign.DefaultCodeLifetime), campaign.RewardRules, campaign.MaxParticipants)
+		if err != nil {
+			r.logger.Errorf("Update[repo]: Ошибка изменения кампании с id: %d: %s", campaign.ID, err)
+			errChan <- err
+			return
+		}
+
+		if result.RowsAffected() == 0 {
+			r.logger.Warnf("Update[repo]: Кампания с id: %d не найдена", campaign.ID)
+			errChan <- ErrCampaignNotFound
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Update[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		r.logger.Infof("Update[repo]: Кампания с id: %d успешно изменена", campaign.ID)
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		r.logger.Errorf("Update[repo]: Время ожидания превышено для кампании с id: %d", campaign.ID)
+		return ctx.Err()
+	}
+}
+
+// Delete removes campaign by id, its codes stay valid but no longer belong to any campaign
+// If campaign not found, returns ErrCampaignNotFound
+func (r *CampaignPostgres) Delete(id int) error {
+	r.logger.Debugf("Delete[repo]: Удаление кампании с id: %d", id)
+
+	query := `DELETE FROM campaigns WHERE id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Delete[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		result, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			r.logger.Errorf("Delete[repo]: Ошибка удаления кампании с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		if result.RowsAffected() == 0 {
+			r.logger.Warnf("Delete[repo]: Кампания с id: %d не найдена для удаления", id)
+			errChan <- ErrCampaignNotFound
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Delete[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		r.logger.Infof("Delete[repo]: Кампания с id: %d успешно удалена", id)
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		r.logger.Errorf("Delete[repo]: Время ожидания превышено для кампании с id: %d", id)
+		return ctx.Err()
+	}
+}
+
+// GetByID retrieves campaign with its codes and participants counts by id
+// If campaign not found, returns ErrCampaignNotFound
+func (r *CampaignPostgres) GetByID(id int) (models.Campaign, error) {
+	r.logger.Debugf("GetByID[repo]: Получение кампании с id: %d", id)
+
+	query := `SELECT ` + campaignColumns + ` FROM campaigns c WHERE c.id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get campaign from goroutine
+	campaignChan := make(chan models.Campaign)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetByID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		campaign, err := scanCampaign(tx.QueryRow(ctx, query, id))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("GetByID[repo]: Кампания с id: %d не найдена", id)
+				errChan <- ErrCampaignNotFound
+				return
+			}
+
+			r.logger.Errorf("GetByID[repo]: Ошибка при получении кампании с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetByID[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		campaignChan <- campaign
+	}()
+
+	select {
+	case campaign := <-campaignChan:
+		r.logger.Infof("GetByID[repo]: Кампания с id: %d получена", id)
+		return campaign, nil
+	case err := <-errChan:
+		return models.Campaign{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetByID[repo]: Время ожидания превышено для кампании с id: %d", id)
+		return models.Campaign{}, ctx.Err()
+	}
+}
+
+// GetAll retrieves all campaigns ordered from newest to oldest
+func (r *CampaignPostgres) GetAll() ([]models.Campaign, error) {
+	r.logger.Debugf("GetAll[repo]: Получение списка кампаний")
+
+	query := `SELECT ` + campaignColumns + ` FROM campaigns c ORDER BY c.starts_at DESC, c.id DESC`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	// Use a channel to get campaigns from goroutine
+	campaignsChan := make(chan []models.Campaign)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetAll[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, query)
+		if err != nil {
+			r.logger.Errorf("GetAll[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		var campaigns []models.Campaign
+		for rows.Next() {
+			campaign, err := scanCampaign(rows)
+			if err != nil {
+				r.logger.Errorf("GetAll[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			campaigns = append(campaigns, campaign)
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetAll[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetAll[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		campaignsChan <- campaigns
+	}()
+
+	select {
+	case campaigns := <-campaignsChan:
+		r.logger.Infof("GetAll[repo]: Получено %d кампаний", len(campaigns))
+		return campaigns, nil
+	case err := <-errChan:
+		return nil, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetAll[repo]: Время ожидания превышено")
+		return nil, ctx.Err()
+	}
+}
+
+// scanCampaign scans row selected with campaignColumns into campaign
+func scanCampaign(row pgx.Row) (models.Campaign, error) {
+	var campaign models.Campaign
+	var lifetime *int64
+
+	err := row.Scan(&campaign.ID, &campaign.Name, &campaign.StartsAt, &campaign.EndsAt, &lifetime,
+		&campaign.RewardRules, &campaign.MaxParticipants, &campaign.CodesCount, &campaign.ParticipantsCount,
+		&campaign.CreatedBy, &campaign.CreatedAt, &campaign.UpdatedAt)
+	if err != nil {
+		return models.Campaign{}, err
+	}
+
+	if lifetime != nil {
+		duration := time.Duration(*lifetime) * time.Second
+		campaign.DefaultCodeLifetime = &duration
+		campaign.DefaultExpiresIn = duration.String()
+	}
+
+	return campaign, nil
+}
+
+// lifetimeSeconds converts optional lifetime into seconds stored in database
+func lifetimeSeconds(lifetime *time.Duration) *int64 {
+	if lifetime == nil {
+		return nil
+	}
+	seconds := int64(lifetime.Seconds())
+	return &seconds
+}
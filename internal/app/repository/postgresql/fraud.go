@@ -0,0 +1,411 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+var ErrReferralReviewNotFound = errors.New("реферал на проверке не найден")
+var ErrReferralReviewConflict = errors.New("реферал не ожидает проверки")
+
+// referralReviewColumns lists columns of referral review in order expected by scanReferralReview
+const referralReviewColumns = `id, email, referrer_id, referral_code_id, status, risk_score, risk_signals,
+    review_status, reviewed_by, reviewed_at, review_reason, created_at`
+
+// FraudPostgres implements the FraudRepo interface for PostgreSQL storage of fraud signals and review queue
+type FraudPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewFraudPostgres creates new FraudPostgres instance with provided database connection and logger
+func NewFraudPostgres(db database.Database, logger *logrus.Logger) *FraudPostgres {
+	return &FraudPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordDevice remembers IP address and device user was seen with, both are hashed by caller
+func (r *FraudPostgres) RecordDevice(userID int, ipHash, deviceHash string) error {
+	r.logger.Debugf("RecordDevice[repo]: Сохранение устройства пользователя с id: %d", userID)
+
+	query := `INSERT INTO user_devices (user_id, ip_hash, device_hash, first_seen_at, last_seen_at)
+              VALUES ($1, $2, $3, NOW(), NOW())
+              ON CONFLICT (user_id, ip_hash, device_hash) DO UPDATE SET last_seen_at = NOW()`
+
+	return r.exec("RecordDevice", query, userID, ipHash, deviceHash)
+}
+
+// MatchDevice reports whether user was seen with given IP address or device, empty hashes never match
+func (r *FraudPostgres) MatchDevice(userID int, ipHash, deviceHash string) (bool, bool, error) {
+	r.logger.Debugf("MatchDevice[repo]: Сравнение устройств пользователя с id: %d", userID)
+
+	query := `SELECT COALESCE(bool_or($2 <> '' AND ip_hash = $2), false),
+                     COALESCE(bool_or($3 <> '' AND device_hash = $3), false)
+              FROM user_devices WHERE user_id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get matches from goroutine
+	matchChan := make(chan [2]bool)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("MatchDevice[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		var match [2]bool
+		if err = tx.QueryRow(ctx, query, userID, ipHash, deviceHash).Scan(&match[0], &match[1]); err != nil {
+			r.logger.Errorf("MatchDevice[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("MatchDevice[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		matchChan <- match
+	}()
+
+	select {
+	case match := <-matchChan:
+		return match[0], match[1], nil
+	case err := <-errChan:
+		return false, false, err
+	case <-ctx.Done():
+		r.logger.Errorf("MatchDevice[repo]: Время ожидания превышено для пользователя с id: %d", userID)
+		return false, false, ctx.Err()
+	}
+}
+
+// CountReferralsByCodeSince counts referrals created with referral code since given moment
+func (r *FraudPostgres) CountReferralsByCodeSince(codeID int, since time.Time) (int, error) {
+	r.logger.Debugf("CountReferralsByCodeSince[repo]: Подсчет рефералов кода с id: %d с %s", codeID, since)
+
+	query := `SELECT COUNT(*) FROM referrals WHERE referral_code_id = $1 AND created_at >= $2`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get count from goroutine
+	countChan := make(chan int)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("CountReferralsByCodeSince[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		var count int
+		if err = tx.QueryRow(ctx, query, codeID, since).Scan(&count); err != nil {
+			r.logger.Errorf("CountReferralsByCodeSince[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("CountReferralsByCodeSince[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		countChan <- count
+	}()
+
+	select {
+	case count := <-countChan:
+		return count, nil
+	case err := <-errChan:
+		return 0, err
+	case <-ctx.Done():
+		r.logger.Errorf("CountReferralsByCodeSince[repo]: Время ожидания превышено для кода с id: %d", codeID)
+		return 0, ctx.Err()
+	}
+}
+
+// SaveAssessment stores risk score and signals of referral and places it into review queue if assessment says so
+func (r *FraudPostgres) SaveAssessment(referralID int, assessment models.RiskAssessment) error {
+	r.logger.Debugf("SaveAssessment[repo]: Сохранение оценки риска реферала с id: %d", referralID)
+
+	query := `UPDATE referrals SET risk_score = $2, risk_signals = $3,
+              review_status = CASE WHEN $4 THEN 'pending' ELSE review_status END
+              WHERE id = $1`
+
+	signals := assessment.Signals
+	if signals == nil {
+		signals = []models.RiskSignal{}
+	}
+	encoded, err := json.Marshal(signals)
+	if err != nil {
+		return err
+	}
+
+	return r.exec("SaveAssessment", query, referralID, assessment.Score, json.RawMessage(encoded), assessment.Review)
+}
+
+// GetReviews retrieves referrals that were placed into review queue in order of creation,
+// non-empty statuses limit result to these review statuses, e.g. "pending" is queue waiting for decision
+func (r *FraudPostgres) GetReviews(statuses []string) ([]models.ReferralReview, error) {
+	r.logger.Debugf("GetReviews[repo]: Получение очереди проверки рефералов")
+
+	query := `SELECT ` + referralReviewColumns + ` FROM referrals
+              WHERE review_status IS NOT NULL
+                AND (COALESCE(cardinality($1::text[]), 0) = 0 OR review_status = ANY($1))
+              ORDER BY created_at, id`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get reviews from goroutine
+	reviewsChan := make(chan []models.ReferralReview)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetReviews[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, query, statuses)
+		if err != nil {
+			r.logger.Errorf("GetReviews[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		var reviews []models.ReferralReview
+		for rows.Next() {
+			review, err := scanReferralReview(rows)
+			if err != nil {
+				r.logger.Errorf("GetReviews[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			reviews = append(reviews, review)
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetReviews[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetReviews[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		reviewsChan <- reviews
+	}()
+
+	select {
+	case reviews := <-reviewsChan:
+		r.logger.Infof("GetReviews[repo]: Получено %d рефералов на проверке", len(reviews))
+		return reviews, nil
+	case err := <-errChan:
+		return nil, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetReviews[repo]: Время ожидания превышено")
+		return nil, ctx.Err()
+	}
+}
+
+// Decide records admin decision on referral waiting for review
+// Rejected referral is rejected if it is pending or reversed if it was qualified while waiting
+// If referral not found in review queue, returns ErrReferralReviewNotFound,
+// if it was already reviewed, returns ErrReferralReviewConflict
+func (r *FraudPostgres) Decide(referralID, adminID int, decision string, reason *string) (models.ReferralReview, error) {
+	r.logger.Debugf("Decide[repo]: Решение %s по рефералу с id: %d", decision, referralID)
+
+	lockQuery := `SELECT ` + referralReviewColumns + ` FROM referrals
+                  WHERE id = $1 AND review_status IS NOT NULL FOR UPDATE`
+	updateQuery := `UPDATE referrals SET review_status = $2, reviewed_by = $3, reviewed_at = NOW(), review_reason = $4,
+                    status = CASE WHEN $2 <> 'rejected' THEN status
+                                  WHEN status = 'pending' THEN 'rejected'
+                                  WHEN status = 'qualified' THEN 'reversed'
+                                  ELSE status END,
+                    status_changed_at = CASE WHEN $2 = 'rejected' AND status IN ('pending', 'qualified') THEN NOW()
+                                             ELSE status_changed_at END
+                    WHERE id = $1
+                    RETURNING ` + referralReviewColumns
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get review from goroutine
+	reviewChan := make(chan models.ReferralReview)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Decide[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		review, err := scanReferralReview(tx.QueryRow(ctx, lockQuery, referralID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("Decide[repo]: Реферал с id: %d не найден в очереди проверки", referralID)
+				errChan <- ErrReferralReviewNotFound
+				return
+			}
+
+			r.logger.Errorf("Decide[repo]: Ошибка при получении реферала с id: %d: %s", referralID, err)
+			errChan <- err
+			return
+		}
+
+		if review.ReviewStatus != models.ReviewStatusPending {
+			r.logger.Warnf("Decide[repo]: Реферал с id: %d уже проверен: %s", referralID, review.ReviewStatus)
+			errChan <- ErrReferralReviewConflict
+			return
+		}
+
+		previousStatus := review.Status
+		review, err = scanReferralReview(tx.QueryRow(ctx, updateQuery, referralID, decision, adminID, reason))
+		if err != nil {
+			r.logger.Errorf("Decide[repo]: Ошибка сохранения решения по рефералу с id: %d: %s", referralID, err)
+			errChan <- err
+			return
+		}
+
+		if review.Status != previousStatus {
+			err = enqueueOutbox(ctx, tx, models.OutboxAggregateReferral, referralID,
+				models.OutboxEventReferralStatusChanged,
+				models.OutboxReferralStatus{ReferralID: referralID, Status: review.Status})
+			if err != nil {
+				r.logger.Errorf("Decide[repo]: Ошибка записи события в outbox: %s", err)
+				errChan <- err
+				return
+			}
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Decide[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		reviewChan <- review
+	}()
+
+	select {
+	case review := <-reviewChan:
+		r.logger.Infof("Decide[repo]: Реферал с id: %d проверен: %s", referralID, review.ReviewStatus)
+		return review, nil
+	case err := <-errChan:
+		return models.ReferralReview{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("Decide[repo]: Время ожидания превышено для реферала с id: %d", referralID)
+		return models.ReferralReview{}, ctx.Err()
+	}
+}
+
+// exec runs single statement in its own transaction
+func (r *FraudPostgres) exec(method, query string, args ...interface{}) error {
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка начала транзакции: %s", method, err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка при выполнении запроса: %s", method, err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка коммита транзакции: %s", method, err)
+			errChan <- err
+			return
+		}
+
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		r.logger.Errorf("%s[repo]: Время ожидания превышено", method)
+		return ctx.Err()
+	}
+}
+
+// scanReferralReview scans row selected with referralReviewColumns into referral review
+func scanReferralReview(row pgx.Row) (models.ReferralReview, error) {
+	var review models.ReferralReview
+
+	err := row.Scan(&review.ReferralID, &review.Email, &review.ReferrerID, &review.ReferralCodeID, &review.Status,
+		&review.RiskScore, &review.RiskSignals, &review.ReviewStatus, &review.ReviewedBy, &review.ReviewedAt,
+		&review.ReviewReason, &review.CreatedAt)
+	return review, err
+}
@@ -0,0 +1,134 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+// leaderboardScoreQueries compute score of each user per metric since $1, $2 is campaign id or NULL
+var leaderboardScoreQueries = map[string]string{
+	models.LeaderboardMetricReferrals: `SELECT r.referrer_id AS user_id, COUNT(*) AS score
+        FROM referrals r LEFT JOIN referral_codes rc ON rc.id = r.referral_code_id
+        WHERE r.created_at >= $1 AND r.status NOT IN ('rejected', 'reversed')
+          AND ($2::int IS NULL OR rc.campaign_id = $2)
+        GROUP BY r.referrer_id`,
+	models.LeaderboardMetricQualified: `SELECT r.referrer_id AS user_id, COUNT(*) AS score
+        FROM referrals r LEFT JOIN referral_codes rc ON rc.id = r.referral_code_id
+        WHERE r.status = 'qualified' AND r.status_changed_at >= $1
+          AND ($2::int IS NULL OR rc.campaign_id = $2)
+        GROUP BY r.referrer_id`,
+	models.LeaderboardMetricRewards: `SELECT a.user_id, SUM(e.amount)::bigint AS score
+        FROM reward_entries e
+        JOIN reward_accounts a ON a.id = e.account_id
+        JOIN reward_transactions t ON t.id = e.transaction_id
+        JOIN referrals r ON r.id = t.referral_id
+        LEFT JOIN referral_codes rc ON rc.id = r.referral_code_id
+        WHERE a.user_id IS NOT NULL AND t.created_at >= $1
+          AND ($2::int IS NULL OR rc.campaign_id = $2)
+        GROUP BY a.user_id`,
+}
+
+// LeaderboardPostgres implements the LeaderboardRepo interface for PostgreSQL ranking of referrers
+type LeaderboardPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewLeaderboardPostgres creates new LeaderboardPostgres instance with provided database connection and logger
+func NewLeaderboardPostgres(db database.Database, logger *logrus.Logger) *LeaderboardPostgres {
+	return &LeaderboardPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetLeaderboard ranks users with positive score by metric since given moment, optionally within campaign
+// Users with equal score share rank, display name is returned only for users who opted in
+func (l *LeaderboardPostgres) GetLeaderboard(metric string, since time.Time,
+	campaignID *int) ([]models.LeaderboardEntry, error) {
+	l.logger.Debugf("GetLeaderboard[repo]: Получение рейтинга по %s с %s", metric, since)
+
+	scoreQuery, ok := leaderboardScoreQueries[metric]
+	if !ok {
+		return nil, fmt.Errorf("неизвестная метрика рейтинга: %s", metric)
+	}
+
+	query := fmt.Sprintf(`SELECT RANK() OVER (ORDER BY s.score DESC), s.user_id,
+              CASE WHEN u.leaderboard_opt_in THEN NULLIF(u.display_name, '') END, s.score
+              FROM (%s) s JOIN users u ON u.id = s.user_id
+              WHERE s.score > 0
+              ORDER BY s.score DESC, s.user_id`, scoreQuery)
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	// Use a channel to get entries from goroutine
+	entriesChan := make(chan []models.LeaderboardEntry)
+
+	go func() {
+		// Begin transaction
+		tx, err := l.db.GetPool().BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+		if err != nil {
+			l.logger.Errorf("GetLeaderboard[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, query, since, campaignID)
+		if err != nil {
+			l.logger.Errorf("GetLeaderboard[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		var entries []models.LeaderboardEntry
+		for rows.Next() {
+			var entry models.LeaderboardEntry
+			if err = rows.Scan(&entry.Rank, &entry.UserID, &entry.DisplayName, &entry.Score); err != nil {
+				l.logger.Errorf("GetLeaderboard[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			entries = append(entries, entry)
+		}
+
+		if err = rows.Err(); err != nil {
+			l.logger.Errorf("GetLeaderboard[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			l.logger.Errorf("GetLeaderboard[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		entriesChan <- entries
+	}()
+
+	select {
+	case entries := <-entriesChan:
+		l.logger.Infof("GetLeaderboard[repo]: Рейтинг по %s получен, участников: %d", metric, len(entries))
+		return entries, nil
+	case err := <-errChan:
+		return nil, err
+	case <-ctx.Done():
+		l.logger.Errorf("GetLeaderboard[repo]: Время ожидания превышено для рейтинга по %s", metric)
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,130 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+// NotificationPostgres implements the NotificationRepo interface for PostgreSQL storage of notification queue
+type NotificationPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewNotificationPostgres creates new NotificationPostgres instance with provided database connection and logger
+func NewNotificationPostgres(db database.Database, logger *logrus.Logger) *NotificationPostgres {
+	return &NotificationPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Enqueue queues notification of user according to notification mode of user:
+// immediate one is due at once, digest one is due at digestAt, nothing is queued if notifications are off.
+// It returns whether notification was queued
+func (r *NotificationPostgres) Enqueue(userID int, notificationType string, data []byte,
+	digestAt time.Time) (bool, error) {
+	r.logger.Debugf("Enqueue[repo]: Уведомление %s для пользователя с id: %d", notificationType, userID)
+
+	query := `INSERT INTO notifications (user_id, type, data, digest, next_attempt_at, created_at)
+              SELECT id, $2, $3, notification_mode = 'digest',
+                     CASE WHEN notification_mode = 'digest' THEN $4 ELSE NOW() END, NOW()
+              FROM users WHERE id = $1 AND notification_mode <> 'off'`
+
+	var queued bool
+	err := withTx(r.db, r.logger, "Enqueue", func(ctx context.Context, tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, userID, notificationType, data, digestAt)
+		queued = tag.RowsAffected() > 0
+		return err
+	})
+	return queued, err
+}
+
+// Claim takes due notifications with email and locale of their recipients and counts attempt.
+// Up to limit due notifications are taken, together with all other due digest notifications of their recipients,
+// so digest of user is never split between batches. Claimed notifications are not due again until leaseUntil,
+// so concurrent workers never send them twice, unless worker dies before completing them.
+// Pending notifications of users who turned notifications off are dropped instead of being sent
+func (r *NotificationPostgres) Claim(limit int, leaseUntil time.Time) ([]models.Notification, error) {
+	// Workers claim one at a time, otherwise two of them could take parts of the same digest
+	lockQuery := `SELECT pg_advisory_xact_lock(hashtext('notifications_claim'))`
+	dropQuery := `DELETE FROM notifications n USING users u
+                  WHERE u.id = n.user_id AND u.notification_mode = 'off'
+                    AND n.status = 'pending' AND n.next_attempt_at <= NOW()`
+	query := `WITH due AS (
+                  SELECT id, user_id, digest FROM notifications
+                  WHERE status = 'pending' AND next_attempt_at <= NOW()
+                  ORDER BY next_attempt_at, id
+                  LIMIT $1
+              ), claimed AS (
+                  SELECT id FROM due WHERE NOT digest
+                  UNION
+                  SELECT id FROM notifications
+                  WHERE status = 'pending' AND digest AND next_attempt_at <= NOW()
+                    AND user_id IN (SELECT user_id FROM due WHERE digest)
+              )
+              UPDATE notifications n SET attempts = n.attempts + 1, next_attempt_at = $2
+              FROM claimed, users u
+              WHERE n.id = claimed.id AND u.id = n.user_id
+              RETURNING n.id, n.user_id, u.email, COALESCE(u.locale, ''), n.type, n.data, n.digest, n.attempts,
+                        n.created_at`
+
+	var notifications []models.Notification
+	err := withTx(r.db, r.logger, "Claim", func(ctx context.Context, tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, lockQuery); err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, dropQuery)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() > 0 {
+			r.logger.Infof("Claim[repo]: Удалено %d уведомлений пользователей с отключенными уведомлениями",
+				tag.RowsAffected())
+		}
+
+		rows, err := tx.Query(ctx, query, limit, leaseUntil)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var notification models.Notification
+			err = rows.Scan(&notification.ID, &notification.UserID, &notification.Email, &notification.Locale,
+				&notification.Type, &notification.Data, &notification.Digest, &notification.Attempts,
+				&notification.CreatedAt)
+			if err != nil {
+				return err
+			}
+			notifications = append(notifications, notification)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// Complete records result of sending notifications: sent ones are done, pending ones are due again
+// at nextAttemptAt, failed ones ran out of attempts
+func (r *NotificationPostgres) Complete(ids []int64, status string, nextAttemptAt time.Time, lastError *string) error {
+	r.logger.Debugf("Complete[repo]: %d уведомлений: %s", len(ids), status)
+
+	query := `UPDATE notifications SET status = $2, next_attempt_at = $3, last_error = $4,
+              sent_at = CASE WHEN $2 = 'sent' THEN NOW() END
+              WHERE id = ANY($1)`
+
+	return withTx(r.db, r.logger, "Complete", func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, query, ids, status, nextAttemptAt, lastError)
+		return err
+	})
+}
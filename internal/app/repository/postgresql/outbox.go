@@ -0,0 +1,206 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+// OutboxPostgres implements the OutboxRepo interface for PostgreSQL storage of outbox messages
+type OutboxPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewOutboxPostgres creates new OutboxPostgres instance with provided database connection and logger
+func NewOutboxPostgres(db database.Database, logger *logrus.Logger) *OutboxPostgres {
+	return &OutboxPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// PublishPending hands due outbox messages to publish and marks published ones, at most limit messages at once.
+// Only oldest unpublished message of every aggregate is due, so messages of one aggregate are published
+// in order, and failed one holds back the rest of its aggregate until it is published.
+// Messages stay locked until transaction ends, concurrent relays skip them. If publishing succeeds but commit
+// fails, messages are published again, consumers must tolerate duplicates.
+// Failed message is retried at time returned by retryAt for its number of attempts.
+// It returns number of published messages
+func (r *OutboxPostgres) PublishPending(limit int, timeout time.Duration,
+	publish func(ctx context.Context, message models.OutboxMessage) error,
+	retryAt func(attempts int) time.Time) (int, error) {
+	query := `SELECT o.id, o.aggregate_type, o.aggregate_id, o.event_type, o.payload, o.attempts, o.created_at
+              FROM outbox o
+              WHERE o.published_at IS NULL AND o.next_attempt_at <= NOW()
+                AND NOT EXISTS (SELECT 1 FROM outbox p
+                                WHERE p.aggregate_type = o.aggregate_type AND p.aggregate_id = o.aggregate_id
+                                  AND p.published_at IS NULL AND p.id < o.id)
+              ORDER BY o.id
+              LIMIT $1
+              FOR UPDATE SKIP LOCKED`
+	publishedQuery := `UPDATE outbox SET published_at = NOW(), attempts = attempts + 1, last_error = NULL
+                       WHERE id = $1`
+	failedQuery := `UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long,
+	// publishing stops halfway, so there is time left to store results
+	ctx, cancel := context.WithTimeout(ctx, 2*timeout)
+	defer cancel() // Cancel context after function ends
+	publishDeadline := time.Now().Add(timeout)
+
+	// Use a channel to get number of published messages from goroutine
+	countChan := make(chan int)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("PublishPending[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, query, limit)
+		if err != nil {
+			r.logger.Errorf("PublishPending[repo]: Ошибка при получении сообщений: %s", err)
+			errChan <- err
+			return
+		}
+
+		var messages []models.OutboxMessage
+		for rows.Next() {
+			var message models.OutboxMessage
+			err = rows.Scan(&message.ID, &message.AggregateType, &message.AggregateID, &message.EventType,
+				&message.Payload, &message.Attempts, &message.CreatedAt)
+			if err != nil {
+				rows.Close()
+				r.logger.Errorf("PublishPending[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			messages = append(messages, message)
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("PublishPending[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		published := 0
+		for _, message := range messages {
+			// Messages left unpublished stay due and are picked up by next call
+			if time.Now().After(publishDeadline) {
+				r.logger.Warnf("PublishPending[repo]: Время публикации истекло, опубликовано %d из %d сообщений",
+					published, len(messages))
+				break
+			}
+
+			if publishErr := publish(ctx, message); publishErr != nil {
+				r.logger.Warnf("PublishPending[repo]: Ошибка публикации сообщения с id: %d: %s", message.ID, publishErr)
+				_, err = tx.Exec(ctx, failedQuery, message.ID, publishErr.Error(), retryAt(message.Attempts+1))
+			} else {
+				published++
+				_, err = tx.Exec(ctx, publishedQuery, message.ID)
+			}
+			if err != nil {
+				r.logger.Errorf("PublishPending[repo]: Ошибка сохранения результата публикации сообщения с id: %d: %s",
+					message.ID, err)
+				errChan <- err
+				return
+			}
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("PublishPending[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		countChan <- published
+	}()
+
+	select {
+	case count := <-countChan:
+		return count, nil
+	case err := <-errChan:
+		return 0, err
+	case <-ctx.Done():
+		r.logger.Errorf("PublishPending[repo]: Время ожидания превышено")
+		return 0, ctx.Err()
+	}
+}
+
+// DeletePublished removes messages published before given time and returns their number,
+// messages not yet turned into webhook events are kept. Messages are removed from the start of referral feed
+// up to first one to be kept, so feed resumed after oldest retained message does not miss any event
+func (r *OutboxPostgres) DeletePublished(before time.Time) (int64, error) {
+	r.logger.Debugf("DeletePublished[repo]: Удаление опубликованных сообщений до %s", before)
+
+	query := `DELETE FROM outbox
+              WHERE published_at < $1 AND webhook_enqueued_at IS NOT NULL
+                AND feed_position < COALESCE((SELECT MIN(feed_position) FROM outbox
+                                              WHERE published_at IS NULL OR published_at >= $1
+                                                 OR webhook_enqueued_at IS NULL), 9223372036854775807)`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get number of deleted messages from goroutine
+	countChan := make(chan int64)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		result, err := r.db.GetPool().Exec(ctx, query, before)
+		if err != nil {
+			r.logger.Errorf("DeletePublished[repo]: Ошибка удаления сообщений: %s", err)
+			errChan <- err
+			return
+		}
+
+		countChan <- result.RowsAffected()
+	}()
+
+	select {
+	case count := <-countChan:
+		return count, nil
+	case err := <-errChan:
+		return 0, err
+	case <-ctx.Done():
+		r.logger.Errorf("DeletePublished[repo]: Время ожидания превышено")
+		return 0, ctx.Err()
+	}
+}
+
+// enqueueOutbox records domain event about aggregate in outbox within transaction of the change it describes,
+// so event is published if and only if change is committed
+func enqueueOutbox(ctx context.Context, tx pgx.Tx, aggregateType string, aggregateID int, eventType string,
+	payload interface{}) error {
+	query := `INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload, created_at)
+              VALUES ($1, $2, $3, $4, NOW())`
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, aggregateType, aggregateID, eventType, json.RawMessage(encoded))
+	return err
+}
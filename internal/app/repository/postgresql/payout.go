@@ -0,0 +1,506 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+var ErrPayoutNotFound = errors.New("выплата не найдена")
+var ErrPayoutStatusConflict = errors.New("выплата находится в другом статусе")
+var ErrInsufficientRewardBalance = errors.New("недостаточно доступных вознаграждений")
+
+// payoutColumns lists columns of payout in order expected by scanPayout
+const payoutColumns = `id, user_id, amount, destination, status, provider, provider_reference, reason,
+       created_at, updated_at`
+
+// PayoutPostgres implements the PayoutRepo interface for PostgreSQL database operations related to payouts
+type PayoutPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewPayoutPostgres creates new PayoutPostgres instance with provided database connection and logger
+func NewPayoutPostgres(db database.Database, logger *logrus.Logger) *PayoutPostgres {
+	return &PayoutPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create checks that user has enough available rewards, stores payout in requested status and reserves
+// its amount by moving it from user's account to payout account in the same transaction
+// Rewards of referrals whose status changed after heldSince are not available
+// If available amount is less than payout amount, returns ErrInsufficientRewardBalance
+func (r *PayoutPostgres) Create(payout models.Payout, heldSince time.Time) (models.Payout, error) {
+	r.logger.Debugf("Create[repo]: Создание выплаты пользователю с id: %d", payout.UserID)
+
+	lockAccountQuery := `SELECT id FROM reward_accounts WHERE user_id = $1 FOR UPDATE`
+	insertQuery := `INSERT INTO payouts (user_id, amount, destination, status, provider, created_at, updated_at)
+                    VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+                    RETURNING id, status, created_at, updated_at`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get payout from goroutine
+	payoutChan := make(chan models.Payout)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		// Lock account, so concurrent payouts of the same user can not spend the same rewards
+		var accountID int
+		err = tx.QueryRow(ctx, lockAccountQuery, payout.UserID).Scan(&accountID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Warnf("Create[repo]: У пользователя с id: %d нет вознаграждений", payout.UserID)
+			errChan <- ErrInsufficientRewardBalance
+			return
+		}
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка блокировки счета: %s", err)
+			errChan <- err
+			return
+		}
+
+		var balance, held int64
+		if err = tx.QueryRow(ctx, rewardBalanceQuery, payout.UserID).Scan(&balance); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка при получении баланса: %s", err)
+			errChan <- err
+			return
+		}
+		if err = tx.QueryRow(ctx, heldRewardsQuery, payout.UserID, heldSince).Scan(&held); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка при получении удерживаемых вознаграждений: %s", err)
+			errChan <- err
+			return
+		}
+
+		if available := balance - max(held, 0); payout.Amount > available {
+			r.logger.Warnf("Create[repo]: Пользователю с id: %d доступно %d, запрошено %d",
+				payout.UserID, available, payout.Amount)
+			errChan <- ErrInsufficientRewardBalance
+			return
+		}
+
+		err = tx.QueryRow(ctx, insertQuery, payout.UserID, payout.Amount, payout.Destination,
+			models.PayoutStatusRequested, payout.Provider).Scan(&payout.ID, &payout.Status, &payout.CreatedAt,
+			&payout.UpdatedAt)
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка создания выплаты: %s", err)
+			errChan <- err
+			return
+		}
+
+		change := models.PayoutStatusChange{ToStatus: payout.Status, ChangedBy: &payout.UserID}
+		if err = insertPayoutStatusChange(ctx, tx, payout.ID, change); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка записи истории выплаты с id: %d: %s", payout.ID, err)
+			errChan <- err
+			return
+		}
+
+		if err = postPayoutTransaction(ctx, tx, payout, models.RewardKindPayoutHold); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка резервирования выплаты с id: %d: %s", payout.ID, err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		payoutChan <- payout
+	}()
+
+	select {
+	case created := <-payoutChan:
+		r.logger.Infof("Create[repo]: Выплата с id: %d создана", created.ID)
+		return created, nil
+	case err := <-errChan:
+		return models.Payout{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("Create[repo]: Время ожидания превышено для пользователя с id: %d", payout.UserID)
+		return models.Payout{}, ctx.Err()
+	}
+}
+
+// ChangeStatus moves payout from status from to change.ToStatus, records change in payout history
+// and, if ledgerKind is not empty, posts ledger transaction of this kind:
+//   - RewardKindPayoutRelease returns reserved amount from payout account to user's account
+//   - RewardKindPayoutPaid moves reserved amount from payout account to program account
+//
+// reference is stored as provider reference if not nil
+// If payout not found, returns ErrPayoutNotFound, if it is not in status from, returns ErrPayoutStatusConflict
+func (r *PayoutPostgres) ChangeStatus(id int, from string, change models.PayoutStatusChange, reference *string,
+	ledgerKind string) (models.Payout, error) {
+	r.logger.Debugf("ChangeStatus[repo]: Изменение статуса выплаты с id: %d с %s на %s", id, from, change.ToStatus)
+
+	lockQuery := `SELECT ` + payoutColumns + ` FROM payouts WHERE id = $1 FOR UPDATE`
+	updateQuery := `UPDATE payouts SET status = $2, provider_reference = COALESCE($3, provider_reference),
+                    reason = COALESCE($4, reason), updated_at = NOW()
+                    WHERE id = $1
+                    RETURNING updated_at`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get payout from goroutine
+	payoutChan := make(chan models.Payout)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("ChangeStatus[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		payout, err := scanPayout(tx.QueryRow(ctx, lockQuery, id))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("ChangeStatus[repo]: Выплата с id: %d не найдена", id)
+				errChan <- ErrPayoutNotFound
+				return
+			}
+
+			r.logger.Errorf("ChangeStatus[repo]: Ошибка при получении выплаты с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		if payout.Status != from {
+			r.logger.Warnf("ChangeStatus[repo]: Выплата с id: %d в статусе %s, ожидался %s", id, payout.Status, from)
+			errChan <- ErrPayoutStatusConflict
+			return
+		}
+
+		err = tx.QueryRow(ctx, updateQuery, id, change.ToStatus, reference, change.Reason).Scan(&payout.UpdatedAt)
+		if err != nil {
+			r.logger.Errorf("ChangeStatus[repo]: Ошибка изменения статуса выплаты с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		change.FromStatus = &from
+		if err = insertPayoutStatusChange(ctx, tx, id, change); err != nil {
+			r.logger.Errorf("ChangeStatus[repo]: Ошибка записи истории выплаты с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		if ledgerKind != "" {
+			if err = postPayoutTransaction(ctx, tx, payout, ledgerKind); err != nil {
+				r.logger.Errorf("ChangeStatus[repo]: Ошибка проводки выплаты с id: %d: %s", id, err)
+				errChan <- err
+				return
+			}
+		}
+
+		payout.Status = change.ToStatus
+		if reference != nil {
+			payout.ProviderReference = reference
+		}
+		if change.Reason != nil {
+			payout.Reason = change.Reason
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("ChangeStatus[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		payoutChan <- payout
+	}()
+
+	select {
+	case payout := <-payoutChan:
+		r.logger.Infof("ChangeStatus[repo]: Выплата с id: %d переведена в статус %s", id, payout.Status)
+		return payout, nil
+	case err := <-errChan:
+		return models.Payout{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("ChangeStatus[repo]: Время ожидания превышено для выплаты с id: %d", id)
+		return models.Payout{}, ctx.Err()
+	}
+}
+
+// GetByID retrieves payout with history of its status changes
+// If payout not found, returns ErrPayoutNotFound
+func (r *PayoutPostgres) GetByID(id int) (models.Payout, error) {
+	r.logger.Debugf("GetByID[repo]: Получение выплаты с id: %d", id)
+
+	query := `SELECT ` + payoutColumns + ` FROM payouts WHERE id = $1`
+	historyQuery := `SELECT id, payout_id, from_status, to_status, changed_by, reason, created_at
+                     FROM payout_status_changes WHERE payout_id = $1 ORDER BY id`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get payout from goroutine
+	payoutChan := make(chan models.Payout)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetByID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		payout, err := scanPayout(tx.QueryRow(ctx, query, id))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("GetByID[repo]: Выплата с id: %d не найдена", id)
+				errChan <- ErrPayoutNotFound
+				return
+			}
+
+			r.logger.Errorf("GetByID[repo]: Ошибка при получении выплаты с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		rows, err := tx.Query(ctx, historyQuery, id)
+		if err != nil {
+			r.logger.Errorf("GetByID[repo]: Ошибка при получении истории выплаты: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var change models.PayoutStatusChange
+			err = rows.Scan(&change.ID, &change.PayoutID, &change.FromStatus, &change.ToStatus, &change.ChangedBy,
+				&change.Reason, &change.CreatedAt)
+			if err != nil {
+				r.logger.Errorf("GetByID[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			payout.History = append(payout.History, change)
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetByID[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetByID[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		payoutChan <- payout
+	}()
+
+	select {
+	case payout := <-payoutChan:
+		r.logger.Infof("GetByID[repo]: Выплата с id: %d получена", id)
+		return payout, nil
+	case err := <-errChan:
+		return models.Payout{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetByID[repo]: Время ожидания превышено для выплаты с id: %d", id)
+		return models.Payout{}, ctx.Err()
+	}
+}
+
+// GetByUserID retrieves payouts of user from newest to oldest
+func (r *PayoutPostgres) GetByUserID(userID int) ([]models.Payout, error) {
+	r.logger.Debugf("GetByUserID[repo]: Получение выплат пользователя с id: %d", userID)
+
+	query := `SELECT ` + payoutColumns + ` FROM payouts WHERE user_id = $1 ORDER BY id DESC`
+
+	return r.getList("GetByUserID", query, userID)
+}
+
+// GetAll retrieves payouts from oldest to newest, so queue is processed in order of requests
+// Non-empty statuses limit result to payouts in these statuses
+func (r *PayoutPostgres) GetAll(statuses []string) ([]models.Payout, error) {
+	r.logger.Debugf("GetAll[repo]: Получение списка выплат")
+
+	query := `SELECT ` + payoutColumns + ` FROM payouts
+              WHERE COALESCE(cardinality($1::text[]), 0) = 0 OR status = ANY($1)
+              ORDER BY id`
+
+	return r.getList("GetAll", query, statuses)
+}
+
+// getList retrieves payouts selected by query with given argument
+func (r *PayoutPostgres) getList(method, query string, arg interface{}) ([]models.Payout, error) {
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get payouts from goroutine
+	payoutsChan := make(chan []models.Payout)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка начала транзакции: %s", method, err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, query, arg)
+		if err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка при выполнении запроса: %s", method, err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		var payouts []models.Payout
+		for rows.Next() {
+			payout, err := scanPayout(rows)
+			if err != nil {
+				r.logger.Errorf("%s[repo]: Ошибка сканировании строки: %s", method, err)
+				errChan <- err
+				return
+			}
+			payouts = append(payouts, payout)
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка после итерации по строкам: %s", method, err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка коммита транзакции: %s", method, err)
+			errChan <- err
+			return
+		}
+
+		payoutsChan <- payouts
+	}()
+
+	select {
+	case payouts := <-payoutsChan:
+		r.logger.Infof("%s[repo]: Получено %d выплат", method, len(payouts))
+		return payouts, nil
+	case err := <-errChan:
+		return nil, err
+	case <-ctx.Done():
+		r.logger.Errorf("%s[repo]: Время ожидания превышено", method)
+		return nil, ctx.Err()
+	}
+}
+
+// insertPayoutStatusChange records status change of payout in its history
+func insertPayoutStatusChange(ctx context.Context, tx pgx.Tx, payoutID int, change models.PayoutStatusChange) error {
+	query := `INSERT INTO payout_status_changes (payout_id, from_status, to_status, changed_by, reason, created_at)
+              VALUES ($1, $2, $3, $4, $5, NOW())`
+
+	_, err := tx.Exec(ctx, query, payoutID, change.FromStatus, change.ToStatus, change.ChangedBy, change.Reason)
+	return err
+}
+
+// postPayoutTransaction posts balanced ledger transaction of given kind for payout:
+// hold moves amount from user's account to payout account, release moves it back,
+// paid moves it from payout account to program account
+func postPayoutTransaction(ctx context.Context, tx pgx.Tx, payout models.Payout, kind string) error {
+	userAccountQuery := `SELECT id FROM reward_accounts WHERE user_id = $1`
+	systemAccountQuery := `SELECT id FROM reward_accounts WHERE kind = $1`
+	transactionQuery := `INSERT INTO reward_transactions (kind, payout_id, idempotency_key, created_at)
+                         VALUES ($1, $2, $3, NOW())
+                         RETURNING id`
+	entryQuery := `INSERT INTO reward_entries (transaction_id, account_id, amount, created_at)
+                   VALUES ($1, $2, $3, NOW())`
+
+	var userAccountID, payoutAccountID, programAccountID int
+	if err := tx.QueryRow(ctx, userAccountQuery, payout.UserID).Scan(&userAccountID); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(ctx, systemAccountQuery, "payout").Scan(&payoutAccountID); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(ctx, systemAccountQuery, "program").Scan(&programAccountID); err != nil {
+		return err
+	}
+
+	// Accounts amount is moved from and to
+	var from, to int
+	switch kind {
+	case models.RewardKindPayoutHold:
+		from, to = userAccountID, payoutAccountID
+	case models.RewardKindPayoutRelease:
+		from, to = payoutAccountID, userAccountID
+	case models.RewardKindPayoutPaid:
+		from, to = payoutAccountID, programAccountID
+	default:
+		return fmt.Errorf("неизвестный вид проводки выплаты: %s", kind)
+	}
+
+	var transactionID int
+	err := tx.QueryRow(ctx, transactionQuery, kind, payout.ID, fmt.Sprintf("payout:%d:%s", payout.ID, kind)).
+		Scan(&transactionID)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, entryQuery, transactionID, from, -payout.Amount); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, entryQuery, transactionID, to, payout.Amount)
+	return err
+}
+
+// scanPayout scans row selected with payoutColumns into payout
+func scanPayout(row pgx.Row) (models.Payout, error) {
+	var payout models.Payout
+
+	err := row.Scan(&payout.ID, &payout.UserID, &payout.Amount, &payout.Destination, &payout.Status,
+		&payout.Provider, &payout.ProviderReference, &payout.Reason, &payout.CreatedAt, &payout.UpdatedAt)
+	return payout, err
+}
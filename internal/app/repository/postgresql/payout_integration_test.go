@@ -0,0 +1,184 @@
+//go:build integration
+
+package postgresql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+// testPayoutPostings returns entries of payout transaction of kind by kind of account
+func testPayoutPostings(t *testing.T, db database.Database, payoutID int, kind string) map[string]int64 {
+	t.Helper()
+
+	return testPostings(t, db, `SELECT id FROM reward_transactions WHERE payout_id = $1 AND kind = $2`, payoutID, kind)
+}
+
+// testRequestedPayout credits user with 1000 and requests payout of 600 of it
+func testRequestedPayout(t *testing.T, db database.Database, repo *PayoutPostgres) models.Payout {
+	t.Helper()
+
+	userID := testUser(t, db)
+	testReward(t, db, userID, 1000)
+
+	payout, err := repo.Create(models.Payout{UserID: userID, Amount: 600, Destination: "card:1234", Provider: "fake"},
+		time.Now())
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return payout
+}
+
+func TestPayoutCreateHoldsAmount(t *testing.T) {
+	db := testDatabase(t)
+	repo := NewPayoutPostgres(db, testLogger())
+
+	payout := testRequestedPayout(t, db, repo)
+	if payout.Status != models.PayoutStatusRequested {
+		t.Errorf("status = %q, want %q", payout.Status, models.PayoutStatusRequested)
+	}
+	if got := testBalance(t, db, payout.UserID); got != 400 {
+		t.Errorf("balance = %d, want 400", got)
+	}
+	want := map[string]int64{"user": -600, "payout": 600}
+	if got := testPayoutPostings(t, db, payout.ID, models.RewardKindPayoutHold); !reflect.DeepEqual(got, want) {
+		t.Errorf("hold postings = %v, want %v", got, want)
+	}
+
+	_, err := repo.Create(models.Payout{UserID: payout.UserID, Amount: 500, Destination: "card:1234",
+		Provider: "fake"}, time.Now())
+	if !errors.Is(err, ErrInsufficientRewardBalance) {
+		t.Errorf("Create() over balance error = %v, want %v", err, ErrInsufficientRewardBalance)
+	}
+}
+
+func TestPayoutChangeStatusPostings(t *testing.T) {
+	type step struct {
+		from       string
+		to         string
+		ledgerKind string
+	}
+	approve := step{models.PayoutStatusRequested, models.PayoutStatusApproved, ""}
+	paid := step{models.PayoutStatusApproved, models.PayoutStatusPaid, models.RewardKindPayoutPaid}
+	failed := step{models.PayoutStatusApproved, models.PayoutStatusFailed, models.RewardKindPayoutRelease}
+	rejected := step{models.PayoutStatusRequested, models.PayoutStatusRejected, models.RewardKindPayoutRelease}
+
+	tests := []struct {
+		name         string
+		steps        []step
+		wantBalance  int64
+		wantKind     string
+		wantPostings map[string]int64
+	}{
+		{
+			name:         "rejected payout is released",
+			steps:        []step{rejected},
+			wantBalance:  1000,
+			wantKind:     models.RewardKindPayoutRelease,
+			wantPostings: map[string]int64{"payout": -600, "user": 600},
+		},
+		{
+			name:         "approval posts nothing",
+			steps:        []step{approve},
+			wantBalance:  400,
+			wantKind:     models.RewardKindPayoutPaid,
+			wantPostings: nil,
+		},
+		{
+			name:         "paid payout moves to program account",
+			steps:        []step{approve, paid},
+			wantBalance:  400,
+			wantKind:     models.RewardKindPayoutPaid,
+			wantPostings: map[string]int64{"payout": -600, "program": 600},
+		},
+		{
+			name:         "failed payout is released",
+			steps:        []step{approve, failed},
+			wantBalance:  1000,
+			wantKind:     models.RewardKindPayoutRelease,
+			wantPostings: map[string]int64{"payout": -600, "user": 600},
+		},
+	}
+
+	db := testDatabase(t)
+	repo := NewPayoutPostgres(db, testLogger())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payout := testRequestedPayout(t, db, repo)
+
+			var err error
+			for _, s := range tt.steps {
+				payout, err = repo.ChangeStatus(payout.ID, s.from, models.PayoutStatusChange{ToStatus: s.to}, nil,
+					s.ledgerKind)
+				if err != nil {
+					t.Fatalf("ChangeStatus(%s -> %s) error = %v", s.from, s.to, err)
+				}
+			}
+
+			if want := tt.steps[len(tt.steps)-1].to; payout.Status != want {
+				t.Errorf("status = %q, want %q", payout.Status, want)
+			}
+			if got := testBalance(t, db, payout.UserID); got != tt.wantBalance {
+				t.Errorf("balance = %d, want %d", got, tt.wantBalance)
+			}
+			if got := testPayoutPostings(t, db, payout.ID, tt.wantKind); !reflect.DeepEqual(got, tt.wantPostings) {
+				t.Errorf("%s postings = %v, want %v", tt.wantKind, got, tt.wantPostings)
+			}
+		})
+	}
+}
+
+func TestPayoutChangeStatusStoresReference(t *testing.T) {
+	db := testDatabase(t)
+	repo := NewPayoutPostgres(db, testLogger())
+	payout := testRequestedPayout(t, db, repo)
+
+	if _, err := repo.ChangeStatus(payout.ID, models.PayoutStatusRequested,
+		models.PayoutStatusChange{ToStatus: models.PayoutStatusApproved}, nil, ""); err != nil {
+		t.Fatalf("ChangeStatus() approve error = %v", err)
+	}
+	reference := "fake-ref"
+	if _, err := repo.ChangeStatus(payout.ID, models.PayoutStatusApproved,
+		models.PayoutStatusChange{ToStatus: models.PayoutStatusPaid}, &reference, models.RewardKindPayoutPaid); err != nil {
+		t.Fatalf("ChangeStatus() paid error = %v", err)
+	}
+
+	stored, err := repo.GetByID(payout.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.ProviderReference == nil || *stored.ProviderReference != reference {
+		t.Errorf("provider reference = %v, want %q", stored.ProviderReference, reference)
+	}
+	if len(stored.History) != 3 {
+		t.Errorf("history has %d changes, want 3", len(stored.History))
+	}
+}
+
+func TestPayoutChangeStatusConflict(t *testing.T) {
+	db := testDatabase(t)
+	repo := NewPayoutPostgres(db, testLogger())
+	payout := testRequestedPayout(t, db, repo)
+
+	// Requested payout can not be paid without approval, nothing is posted then
+	_, err := repo.ChangeStatus(payout.ID, models.PayoutStatusApproved,
+		models.PayoutStatusChange{ToStatus: models.PayoutStatusPaid}, nil, models.RewardKindPayoutPaid)
+	if !errors.Is(err, ErrPayoutStatusConflict) {
+		t.Fatalf("ChangeStatus() error = %v, want %v", err, ErrPayoutStatusConflict)
+	}
+	if got := testPayoutPostings(t, db, payout.ID, models.RewardKindPayoutPaid); got != nil {
+		t.Errorf("paid postings = %v, want none", got)
+	}
+
+	_, err = repo.ChangeStatus(-1, models.PayoutStatusRequested,
+		models.PayoutStatusChange{ToStatus: models.PayoutStatusApproved}, nil, "")
+	if !errors.Is(err, ErrPayoutNotFound) {
+		t.Errorf("ChangeStatus() of unknown payout error = %v, want %v", err, ErrPayoutNotFound)
+	}
+}
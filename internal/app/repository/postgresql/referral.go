@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v4"
@@ -27,10 +28,39 @@ func NewReferralPostgres(db database.Database, logger *logrus.Logger) *ReferralP
 	}
 }
 
-func (r *ReferralPostgres) GetReferralsByReferrerID(referrerID int) ([]models.Referral, error) {
+// referralFilterCondition matches referrals of referrer $1 by filters: statuses $2, referral code $3
+// and creation time from $4 inclusive to $5 exclusive, NULL filters match everything
+const referralFilterCondition = `($1::int IS NULL OR r.referrer_id = $1)
+                AND (COALESCE(cardinality($2::text[]), 0) = 0 OR r.status = ANY($2))
+                AND ($3::int IS NULL OR r.referral_code_id = $3)
+                AND ($4::timestamptz IS NULL OR r.created_at >= $4)
+                AND ($5::timestamptz IS NULL OR r.created_at < $5)`
+
+// GetReferralsByReferrerID retrieves page of referrals of referrer matching filter and total number of matching
+// referrals. Page is ordered by created_at and id and starts after keyset of filter.After if it is set
+func (r *ReferralPostgres) GetReferralsByReferrerID(referrerID int,
+	filter models.ReferralFilter) ([]models.Referral, int, error) {
 	r.logger.Debugf("GetReferralsByReferrerID[repo]: Получение рефералов для реферера с id: %d", referrerID)
 
-	query := `SELECT id, email, referral_code_id, referrer_id, created_at FROM referrals WHERE referrer_id = $1;`
+	keyset, order := "<", "DESC"
+	if filter.Ascending {
+		keyset, order = ">", "ASC"
+	}
+
+	countQuery := `SELECT COUNT(*) FROM referrals r WHERE ` + referralFilterCondition
+	query := `SELECT r.id, r.email, r.referral_code_id, r.referrer_id, rc.campaign_id, r.status,
+              r.status_changed_at, r.created_at
+              FROM referrals r LEFT JOIN referral_codes rc ON rc.id = r.referral_code_id
+              WHERE ` + referralFilterCondition + `
+                AND ($6::timestamptz IS NULL OR (r.created_at, r.id) ` + keyset + ` ($6, $7::int))
+              ORDER BY r.created_at ` + order + `, r.id ` + order + `
+              LIMIT $8`
+
+	var afterCreatedAt *time.Time
+	var afterID *int
+	if filter.After != nil {
+		afterCreatedAt, afterID = &filter.After.CreatedAt, &filter.After.ID
+	}
 	ctx := context.Background()
 
 	// Create context with timeout to cancel query execution if it takes too long
@@ -43,9 +73,12 @@ func (r *ReferralPostgres) GetReferralsByReferrerID(referrerID int) ([]models.Re
 	// Use a channel to get referrals from goroutine
 	referralsChan := make(chan []models.Referral)
 
+	// Use a channel to get total from goroutine
+	totalChan := make(chan int, 1)
+
 	go func() {
-		// Begin transaction
-		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		// Begin transaction, count and page are read from the same snapshot
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
 		if err != nil {
 			r.logger.Errorf("GetReferralsByReferrerID[repo]: Ошибка начала транзакции: %s", err)
 			errChan <- err
@@ -53,7 +86,17 @@ func (r *ReferralPostgres) GetReferralsByReferrerID(referrerID int) ([]models.Re
 		}
 		defer tx.Rollback(ctx) // Rollback transaction if function returns error
 
-		rows, err := tx.Query(ctx, query, referrerID)
+		var total int
+		err = tx.QueryRow(ctx, countQuery, referrerID, filter.Statuses, filter.CodeID, filter.From,
+			filter.To).Scan(&total)
+		if err != nil {
+			r.logger.Errorf("GetReferralsByReferrerID[repo]: Ошибка при подсчете рефералов: %s", err)
+			errChan <- err
+			return
+		}
+
+		rows, err := tx.Query(ctx, query, referrerID, filter.Statuses, filter.CodeID, filter.From, filter.To,
+			afterCreatedAt, afterID, filter.Limit)
 		if err != nil {
 			r.logger.Errorf("GetReferralsByReferrerID[repo]: Ошибка при выполнении запроса: %s", err)
 			errChan <- err
@@ -66,7 +109,8 @@ func (r *ReferralPostgres) GetReferralsByReferrerID(referrerID int) ([]models.Re
 		for rows.Next() {
 			var referral models.Referral
 			err = rows.Scan(&referral.ID, &referral.Email, &referralCodeID,
-				&referral.ReferrerID, &referral.CreatedAt)
+				&referral.ReferrerID, &referral.CampaignID, &referral.Status, &referral.StatusChangedAt,
+				&referral.CreatedAt)
 			if err != nil {
 				r.logger.Errorf("GetReferralsByReferrerID[repo]: Ошибка сканировании строки: %s", err)
 				errChan <- err
@@ -84,8 +128,8 @@ func (r *ReferralPostgres) GetReferralsByReferrerID(referrerID int) ([]models.Re
 		}
 
 		if rows.Err() != nil {
-			r.logger.Errorf("GetReferralsByReferrerID[repo]: Ошибка после итерации по строкам: %s", err)
-			errChan <- err
+			r.logger.Errorf("GetReferralsByReferrerID[repo]: Ошибка после итерации по строкам: %s", rows.Err())
+			errChan <- rows.Err()
 			return
 		}
 
@@ -96,32 +140,162 @@ func (r *ReferralPostgres) GetReferralsByReferrerID(referrerID int) ([]models.Re
 			return
 		}
 
+		totalChan <- total
 		referralsChan <- referrals
 	}()
 
 	select {
 	case referrals := <-referralsChan:
 		r.logger.Infof("GetReferralsByReferrerID[repo]: Рефералы успешно получены для реферера с id: %d", referrerID)
-		return referrals, nil
+		return referrals, <-totalChan, nil
 	case err := <-errChan:
-		return nil, err
+		return nil, 0, err
 	case <-ctx.Done():
 		r.logger.Errorf("GetReferralsByReferrerID[repo]: Время ожидания превышено для реферера с id: %d", referrerID)
-		return nil, ctx.Err()
+		return nil, 0, ctx.Err()
 	}
 }
 
-func (r *ReferralPostgres) Create(referral models.Referral) error {
+// referralExportFetchSize is number of rows fetched from export cursor at once
+const referralExportFetchSize = 1000
+
+// referralExportTimeout limits duration of export, rows are written to client while cursor is open
+const referralExportTimeout = 30 * time.Minute
+
+// StreamReferrals passes referrals matching filter to fn one by one in order of filter, reading them from
+// server-side cursor in chunks, so export of any size never is held in memory
+// Nil referrerID streams referrals of all referrers, error returned by fn stops streaming
+func (r *ReferralPostgres) StreamReferrals(referrerID *int, filter models.ReferralFilter,
+	fn func(row models.ReferralExportRow) error) error {
+	r.logger.Debugf("StreamReferrals[repo]: Выгрузка рефералов")
+
+	order := "DESC"
+	if filter.Ascending {
+		order = "ASC"
+	}
+
+	declareQuery := `DECLARE referrals_export NO SCROLL CURSOR FOR
+              SELECT r.id, r.referrer_id, r.email, r.referral_code_id, rc.code, rc.campaign_id, r.status,
+              r.status_changed_at, r.created_at
+              FROM referrals r LEFT JOIN referral_codes rc ON rc.id = r.referral_code_id
+              WHERE ` + referralFilterCondition + `
+              ORDER BY r.created_at ` + order + `, r.id ` + order
+	fetchQuery := fmt.Sprintf(`FETCH %d FROM referrals_export`, referralExportFetchSize)
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, referralExportTimeout)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction, cursor lives until end of transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+		if err != nil {
+			r.logger.Errorf("StreamReferrals[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		_, err = tx.Exec(ctx, declareQuery, referrerID, filter.Statuses, filter.CodeID, filter.From, filter.To)
+		if err != nil {
+			r.logger.Errorf("StreamReferrals[repo]: Ошибка открытия курсора: %s", err)
+			errChan <- err
+			return
+		}
+
+		total := 0
+		for {
+			rows, err := tx.Query(ctx, fetchQuery)
+			if err != nil {
+				r.logger.Errorf("StreamReferrals[repo]: Ошибка чтения курсора: %s", err)
+				errChan <- err
+				return
+			}
+
+			fetched := 0
+			for rows.Next() {
+				var row models.ReferralExportRow
+				err = rows.Scan(&row.ReferralID, &row.ReferrerID, &row.Email, &row.ReferralCodeID, &row.Code,
+					&row.CampaignID, &row.Status, &row.StatusChangedAt, &row.CreatedAt)
+				if err == nil {
+					err = fn(row)
+				}
+				if err != nil {
+					rows.Close()
+					r.logger.Errorf("StreamReferrals[repo]: Выгрузка прервана после %d рефералов: %s", total, err)
+					errChan <- err
+					return
+				}
+				fetched++
+				total++
+			}
+			rows.Close()
+
+			if rows.Err() != nil {
+				r.logger.Errorf("StreamReferrals[repo]: Ошибка после итерации по строкам: %s", rows.Err())
+				errChan <- rows.Err()
+				return
+			}
+			if fetched < referralExportFetchSize {
+				break
+			}
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("StreamReferrals[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		r.logger.Infof("StreamReferrals[repo]: Выгружено %d рефералов", total)
+		errChan <- nil
+	}()
+
+	// Goroutine writes rows through fn, so it is awaited even after timeout: its queries fail on cancelled context
+	// and nothing is written through fn once StreamReferrals returns
+	err := <-errChan
+	if ctx.Err() != nil {
+		r.logger.Errorf("StreamReferrals[repo]: Время ожидания выгрузки превышено")
+		return ctx.Err()
+	}
+	return err
+}
+
+// Create inserts user registered with referral code and new referral of the user in pending status
+// in one transaction and returns referral with generated id. Password of user must already be hashed
+// Referral code is checked again under lock of the code, its link and its campaign, so usage limit of code
+// and participants limit of campaign hold for concurrent referrals. Returns ErrReferralCodeNotActive
+// if code can not be used anymore, then user is not created either
+func (r *ReferralPostgres) Create(referral models.Referral, user models.User) (models.Referral, error) {
 	r.logger.Debugf("Create[repo]: Создание нового реферала")
 
-	query := `INSERT INTO referrals (email, referral_code_id, referrer_id, created_at) 
-	          VALUES ($1, $2, $3, NOW()) RETURNING id, created_at`
+	userQuery := `INSERT INTO users (email, password, display_name, created_at)
+	              VALUES ($1, $2, NULLIF($3, ''), NOW()) RETURNING id`
+	query := `INSERT INTO referrals (email, referral_code_id, referrer_id, referral_click_id, user_id, ip_hash,
+	          device_hash, created_at) 
+	          VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NOW())
+	          RETURNING id, user_id, status, created_at`
+	lockCodeQuery := `SELECT expires_at, paused_at, max_uses, link_id, campaign_id FROM referral_codes WHERE id = $1
+	                  FOR NO KEY UPDATE`
+	lockLinkQuery := `SELECT id FROM referral_links WHERE id = $1 FOR NO KEY UPDATE`
+	lockCampaignQuery := `SELECT max_participants FROM campaigns WHERE id = $1 FOR NO KEY UPDATE`
+	usesQuery := `SELECT ` + usesCountColumn + ` FROM referral_codes rc WHERE rc.id = $1`
+	participantsQuery := `SELECT COUNT(*) FROM referrals r JOIN referral_codes rc ON rc.id = r.referral_code_id
+	                      WHERE rc.campaign_id = $1`
 	ctx := context.Background()
 
 	// Create context with timeout to cancel query execution if it takes too long
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel() // Cancel context after function ends
 
+	// Use a channel to get referral from goroutine
+	referralChan := make(chan models.Referral)
+
 	// Use a channel to get error from goroutine
 	errChan := make(chan error)
 
@@ -135,15 +309,106 @@ func (r *ReferralPostgres) Create(referral models.Referral) error {
 		}
 		defer tx.Rollback(ctx) // Rollback transaction if function returns error
 
+		// Lock referral code and its link, so concurrent referrals can not exceed usage limit
+		var expiresAt time.Time
+		var pausedAt *time.Time
+		var maxUses, linkID, campaignID *int
+		err = tx.QueryRow(ctx, lockCodeQuery, referral.ReferralCodeID).Scan(&expiresAt, &pausedAt, &maxUses, &linkID,
+			&campaignID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("Create[repo]: Реферальный код с id: %d не найден", referral.ReferralCodeID)
+				errChan <- ErrReferralCodeNotFound
+				return
+			}
+
+			r.logger.Errorf("Create[repo]: Ошибка блокировки реферального кода с id: %d: %s", referral.ReferralCodeID, err)
+			errChan <- err
+			return
+		}
+
+		if linkID != nil {
+			if _, err = tx.Exec(ctx, lockLinkQuery, *linkID); err != nil {
+				r.logger.Errorf("Create[repo]: Ошибка блокировки реферальной ссылки с id: %d: %s", *linkID, err)
+				errChan <- err
+				return
+			}
+		}
+
+		// Check code again under lock, it may have changed since it was looked up
+		if time.Now().After(expiresAt) || pausedAt != nil {
+			r.logger.Infof("Create[repo]: Реферальный код с id: %d неактивен", referral.ReferralCodeID)
+			errChan <- ErrReferralCodeNotActive
+			return
+		}
+
+		if maxUses != nil {
+			var usesCount int
+			if err = tx.QueryRow(ctx, usesQuery, referral.ReferralCodeID).Scan(&usesCount); err != nil {
+				r.logger.Errorf("Create[repo]: Ошибка подсчета использований реферального кода с id: %d: %s",
+					referral.ReferralCodeID, err)
+				errChan <- err
+				return
+			}
+
+			if usesCount >= *maxUses {
+				r.logger.Infof("Create[repo]: Реферальный код с id: %d неактивен (исчерпан лимит)", referral.ReferralCodeID)
+				errChan <- ErrReferralCodeNotActive
+				return
+			}
+		}
+
+		// Campaign row is locked as well, codes of one campaign share its participants limit
+		if campaignID != nil {
+			var maxParticipants *int
+			if err = tx.QueryRow(ctx, lockCampaignQuery, *campaignID).Scan(&maxParticipants); err != nil {
+				r.logger.Errorf("Create[repo]: Ошибка блокировки кампании с id: %d: %s", *campaignID, err)
+				errChan <- err
+				return
+			}
+
+			if maxParticipants != nil {
+				var participantsCount int
+				if err = tx.QueryRow(ctx, participantsQuery, *campaignID).Scan(&participantsCount); err != nil {
+					r.logger.Errorf("Create[repo]: Ошибка подсчета участников кампании с id: %d: %s", *campaignID, err)
+					errChan <- err
+					return
+				}
+
+				if participantsCount >= *maxParticipants {
+					r.logger.Infof("Create[repo]: Кампания с id: %d заполнена", *campaignID)
+					errChan <- ErrReferralCodeNotActive
+					return
+				}
+			}
+		}
+
+		// User is created only once code is known to be usable, so rejected sign-up leaves nothing behind
+		var userID int
+		if err = tx.QueryRow(ctx, userQuery, user.Email, user.Password, user.DisplayName).Scan(&userID); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка создания пользователя: %s, ошибка: %s", user.Email, err)
+			errChan <- err
+			return
+		}
+
 		// Execute query and scan returned ID, created_at, and updated_at into referral object
-		err = tx.QueryRow(ctx, query, referral.Email, referral.ReferralCodeID,
-			referral.ReferrerID).Scan(&referral.ID, &referral.CreatedAt)
+		err = tx.QueryRow(ctx, query, referral.Email, referral.ReferralCodeID, referral.ReferrerID,
+			referral.ReferralClickID, userID, referral.IPHash, referral.DeviceHash).Scan(&referral.ID, &referral.UserID,
+			&referral.Status, &referral.CreatedAt)
 		if err != nil {
 			r.logger.Errorf("Create[repo]: Ошибка создания реферала: %s", err)
 			errChan <- err
 			return
 		}
 
+		err = enqueueOutbox(ctx, tx, models.OutboxAggregateReferral, referral.ID, models.OutboxEventReferralCreated,
+			referral)
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка записи события в outbox: %s", err)
+			errChan <- err
+			return
+		}
+
 		// Commit transaction
 		if err = tx.Commit(ctx); err != nil {
 			r.logger.Errorf("Create[repo]: Ошибка коммита транзакции для реферала: %s, ошибка: %s", referral.Email, err)
@@ -152,14 +417,191 @@ func (r *ReferralPostgres) Create(referral models.Referral) error {
 		}
 
 		r.logger.Infof("Create[repo]: Новый реферал успешно создан")
-		errChan <- nil
+		referralChan <- referral
 	}()
 
 	select {
+	case created := <-referralChan:
+		return created, nil
 	case err := <-errChan:
-		return err
+		return models.Referral{}, err
 	case <-ctx.Done():
 		r.logger.Errorf("Create[repo]: Время ожидания превышено для пользователя: %s", referral.Email)
-		return ctx.Err()
+		return models.Referral{}, ctx.Err()
+	}
+}
+
+// GetCampaignStatsByReferrerID counts codes and referrals of referrer grouped by campaign
+// Codes outside of any campaign are grouped into entry with nil CampaignID
+func (r *ReferralPostgres) GetCampaignStatsByReferrerID(referrerID int) ([]models.CampaignReferralStats, error) {
+	r.logger.Debugf("GetCampaignStatsByReferrerID[repo]: Получение статистики по кампаниям для реферера с id: %d",
+		referrerID)
+
+	query := `SELECT rc.campaign_id, COALESCE(c.name, ''), COUNT(DISTINCT rc.id), COUNT(r.id)
+              FROM referral_codes rc
+              LEFT JOIN campaigns c ON c.id = rc.campaign_id
+              LEFT JOIN referrals r ON r.referral_code_id = rc.id
+              WHERE rc.referrer_id = $1
+              GROUP BY rc.campaign_id, c.name
+              ORDER BY rc.campaign_id NULLS FIRST`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	// Use a channel to get stats from goroutine
+	statsChan := make(chan []models.CampaignReferralStats)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetCampaignStatsByReferrerID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, query, referrerID)
+		if err != nil {
+			r.logger.Errorf("GetCampaignStatsByReferrerID[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		var stats []models.CampaignReferralStats
+		for rows.Next() {
+			var stat models.CampaignReferralStats
+			err = rows.Scan(&stat.CampaignID, &stat.CampaignName, &stat.CodesCount, &stat.ReferralsCount)
+			if err != nil {
+				r.logger.Errorf("GetCampaignStatsByReferrerID[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			stats = append(stats, stat)
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetCampaignStatsByReferrerID[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetCampaignStatsByReferrerID[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		statsChan <- stats
+	}()
+
+	select {
+	case stats := <-statsChan:
+		r.logger.Infof("GetCampaignStatsByReferrerID[repo]: Статистика по кампаниям получена для реферера с id: %d",
+			referrerID)
+		return stats, nil
+	case err := <-errChan:
+		return nil, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetCampaignStatsByReferrerID[repo]: Время ожидания превышено для реферера с id: %d",
+			referrerID)
+		return nil, ctx.Err()
+	}
+}
+
+// GetDownline retrieves referrals of user, referrals of referred users and so on up to depth levels
+// ordered by level, nodes are returned flat with Level set and without children
+// Path of visited users stops walking if referrals ever form cycle
+func (r *ReferralPostgres) GetDownline(userID, depth int) ([]models.ReferralTreeNode, error) {
+	r.logger.Debugf("GetDownline[repo]: Получение дерева рефералов пользователя с id: %d", userID)
+
+	query := `WITH RECURSIVE tree (id, user_id, referrer_id, email, status, created_at, level, path) AS (
+                  SELECT r.id, r.user_id, r.referrer_id, r.email, r.status, r.created_at, 1, ARRAY[$1::int]
+                  FROM referrals r
+                  WHERE r.referrer_id = $1
+                  UNION ALL
+                  SELECT c.id, c.user_id, c.referrer_id, c.email, c.status, c.created_at, t.level + 1,
+                         t.path || t.user_id
+                  FROM referrals c JOIN tree t ON c.referrer_id = t.user_id
+                  WHERE t.level < $2 AND NOT t.user_id = ANY(t.path)
+              )
+              SELECT id, user_id, referrer_id, email, status, created_at, level
+              FROM tree
+              ORDER BY level, id`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	// Use a channel to get nodes from goroutine
+	nodesChan := make(chan []models.ReferralTreeNode)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetDownline[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, query, userID, depth)
+		if err != nil {
+			r.logger.Errorf("GetDownline[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		var nodes []models.ReferralTreeNode
+		for rows.Next() {
+			var node models.ReferralTreeNode
+			err = rows.Scan(&node.ReferralID, &node.UserID, &node.ReferrerID, &node.Email, &node.Status,
+				&node.CreatedAt, &node.Level)
+			if err != nil {
+				r.logger.Errorf("GetDownline[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			nodes = append(nodes, node)
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetDownline[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetDownline[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		nodesChan <- nodes
+	}()
+
+	select {
+	case nodes := <-nodesChan:
+		r.logger.Infof("GetDownline[repo]: Получено %d рефералов в дереве пользователя с id: %d", len(nodes), userID)
+		return nodes, nil
+	case err := <-errChan:
+		return nil, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetDownline[repo]: Время ожидания превышено для пользователя с id: %d", userID)
+		return nil, ctx.Err()
 	}
 }
@@ -0,0 +1,161 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+var ErrReferralClickNotFound = errors.New("переход по реферальной ссылке не найден")
+
+// ReferralClickPostgres implements the ReferralClickRepo interface for PostgreSQL database operations
+// related to clicks on referral share links
+type ReferralClickPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewReferralClickPostgres creates new ReferralClickPostgres instance with provided database connection and logger
+func NewReferralClickPostgres(db database.Database, logger *logrus.Logger) *ReferralClickPostgres {
+	return &ReferralClickPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create inserts new click into the referral_clicks table and returns it with generated id and time
+func (r *ReferralClickPostgres) Create(click models.ReferralClick) (models.ReferralClick, error) {
+	r.logger.Debugf("Create[repo]: Сохранение перехода по реферальному коду с id: %d", click.ReferralCodeID)
+
+	query := `INSERT INTO referral_clicks (token, referral_code_id, ip_hash, user_agent, referer,
+              utm_source, utm_medium, utm_campaign, utm_term, utm_content, clicked_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+              RETURNING id, clicked_at`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get click from goroutine
+	clickChan := make(chan models.ReferralClick)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		// Execute query and scan returned id and clicked_at into click object
+		err = tx.QueryRow(ctx, query, click.Token, click.ReferralCodeID, click.IPHash, click.UserAgent,
+			click.Referer, click.UTMSource, click.UTMMedium, click.UTMCampaign, click.UTMTerm, click.UTMContent).
+			Scan(&click.ID, &click.ClickedAt)
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка сохранения перехода: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		clickChan <- click
+	}()
+
+	select {
+	case created := <-clickChan:
+		r.logger.Infof("Create[repo]: Переход с id: %d по реферальному коду с id: %d сохранен",
+			created.ID, created.ReferralCodeID)
+		return created, nil
+	case err := <-errChan:
+		return models.ReferralClick{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("Create[repo]: Время ожидания превышено")
+		return models.ReferralClick{}, ctx.Err()
+	}
+}
+
+// GetByToken retrieves click together with its referral code by attribution token
+// If click not found, returns ErrReferralClickNotFound
+func (r *ReferralClickPostgres) GetByToken(token string) (models.ReferralClick, error) {
+	r.logger.Debugf("GetByToken[repo]: Получение перехода по токену")
+
+	query := `SELECT c.id, c.token, c.referral_code_id, rc.code, c.ip_hash, c.user_agent, c.referer,
+              c.utm_source, c.utm_medium, c.utm_campaign, c.utm_term, c.utm_content, c.clicked_at
+              FROM referral_clicks c JOIN referral_codes rc ON rc.id = c.referral_code_id
+              WHERE c.token = $1`
+	var click models.ReferralClick
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get click from goroutine
+	clickChan := make(chan models.ReferralClick)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetByToken[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		err = tx.QueryRow(ctx, query, token).Scan(&click.ID, &click.Token, &click.ReferralCodeID, &click.Code,
+			&click.IPHash, &click.UserAgent, &click.Referer, &click.UTMSource, &click.UTMMedium,
+			&click.UTMCampaign, &click.UTMTerm, &click.UTMContent, &click.ClickedAt)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("GetByToken[repo]: Переход по токену не найден")
+				errChan <- ErrReferralClickNotFound
+				return
+			}
+
+			r.logger.Errorf("GetByToken[repo]: Ошибка при получении перехода: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetByToken[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		clickChan <- click
+	}()
+
+	select {
+	case found := <-clickChan:
+		r.logger.Infof("GetByToken[repo]: Переход с id: %d получен", found.ID)
+		return found, nil
+	case err := <-errChan:
+		return models.ReferralClick{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetByToken[repo]: Время ожидания превышено")
+		return models.ReferralClick{}, ctx.Err()
+	}
+}
@@ -15,6 +15,19 @@ import (
 var ErrReferralCodeNotFound = errors.New("реферальный код не найден")
 var ErrReferralCodeNotActive = errors.New("реферальный код неактивен")
 
+// campaignFullColumn selects whether campaign of referral code rc has reached its participants limit
+const campaignFullColumn = `COALESCE((SELECT c.max_participants <= (SELECT COUNT(*) FROM referrals cr
+              JOIN referral_codes crc ON crc.id = cr.referral_code_id WHERE crc.campaign_id = c.id)
+              FROM campaigns c WHERE c.id = rc.campaign_id), FALSE)`
+
+// usesCountColumn counts uses of referral code rc. Codes of one referral link share their uses,
+// so rotation does not reset usage limit of the link
+const usesCountColumn = `(SELECT COUNT(*) FROM referrals ur JOIN referral_codes urc ON urc.id = ur.referral_code_id
+              WHERE urc.id = rc.id OR urc.link_id = rc.link_id)`
+
+// referralCodeEventQuery selects referral code with given id as payload of its outbox events
+const referralCodeEventQuery = `SELECT ` + referralGraphCodeColumns + ` FROM referral_codes rc WHERE rc.id = $1`
+
 type ReferralCodePostgres struct {
 	db     database.Database
 	logger *logrus.Logger
@@ -28,11 +41,14 @@ func NewReferralCodePostgres(db database.Database, logger *logrus.Logger) *Refer
 	}
 }
 
+// Create inserts new referral code together with new referral link, which later rotations keep
 func (r *ReferralCodePostgres) Create(referralCode models.ReferralCode) (models.ReferralCode, error) {
 	r.logger.Debugf("Create[repo]: Создание нового реферального кода для пользователя с id: %d", referralCode.ReferrerID)
 
-	query := `INSERT INTO referral_codes (code, expires_at, referrer_id, created_at, updated_at)
-              VALUES ($1, $2, $3, NOW(), NOW()) 
+	linkQuery := `INSERT INTO referral_links (referrer_id, created_at) VALUES ($1, NOW()) RETURNING id`
+	query := `INSERT INTO referral_codes (code, expires_at, referrer_id, max_uses, campaign_id, link_id,
+              created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW()) 
               RETURNING id, created_at, updated_at;`
 	ctx := context.Background()
 
@@ -56,8 +72,16 @@ func (r *ReferralCodePostgres) Create(referralCode models.ReferralCode) (models.
 		}
 		defer tx.Rollback(ctx) // Rollback transaction if function returns error
 
+		err = tx.QueryRow(ctx, linkQuery, referralCode.ReferrerID).Scan(&referralCode.LinkID)
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка создания реферальной ссылки: %s", err)
+			errChan <- err
+			return
+		}
+
 		// Execute query and scan returned referral code into referral code object
-		err = tx.QueryRow(ctx, query, referralCode.Code, referralCode.Expiration, referralCode.ReferrerID).
+		err = tx.QueryRow(ctx, query, referralCode.Code, referralCode.Expiration, referralCode.ReferrerID,
+			referralCode.MaxUses, referralCode.CampaignID, referralCode.LinkID).
 			Scan(&referralCode.ID, &referralCode.CreatedAt, &referralCode.UpdatedAt)
 		if err != nil {
 			r.logger.Errorf("Create[repo]: Ошибка создания реферального кода: %+v в базе: %s", referralCode, err)
@@ -65,6 +89,14 @@ func (r *ReferralCodePostgres) Create(referralCode models.ReferralCode) (models.
 			return
 		}
 
+		err = enqueueOutbox(ctx, tx, models.OutboxAggregateReferralCode, referralCode.ID,
+			models.OutboxEventReferralCodeCreated, referralCode)
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка записи события в outbox: %s", err)
+			errChan <- err
+			return
+		}
+
 		// Commit transaction
 		if err = tx.Commit(ctx); err != nil {
 			r.logger.Errorf("Create[repo]: Ошибка при коммите транзакции: %s", err)
@@ -114,6 +146,28 @@ func (r *ReferralCodePostgres) DeleteActiveReferralCodeByID(id int) error {
 		}
 		defer tx.Rollback(ctx) // Rollback transaction if function returns error
 
+		referralCode, err := scanReferralGraphCode(tx.QueryRow(ctx, referralCodeEventQuery, id))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("DeleteActiveReferralCodeByID[repo]: Реферальный код с id: %d не найден для удаления", id)
+				errChan <- ErrReferralCodeNotFound
+				return
+			}
+
+			r.logger.Errorf("DeleteActiveReferralCodeByID[repo]: Ошибка получения реферального кода с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		// Event is recorded while code is still in place, so outbox finds referrer owning it
+		err = enqueueOutbox(ctx, tx, models.OutboxAggregateReferralCode, id, models.OutboxEventReferralCodeRevoked,
+			referralCode)
+		if err != nil {
+			r.logger.Errorf("DeleteActiveReferralCodeByID[repo]: Ошибка записи события в outbox: %s", err)
+			errChan <- err
+			return
+		}
+
 		// Execute delete query and check how many rows were affected
 		result, err := tx.Exec(ctx, query, id)
 		if err != nil {
@@ -152,8 +206,11 @@ func (r *ReferralCodePostgres) GetActiveReferralCodeByUserID(referrerID int) (mo
 	r.logger.Debugf("GetActiveReferralCodeByUserID[repo]: Получение активного реферального кода"+
 		" для рефера с id: %d", referrerID)
 
-	query := ` SELECT id, code, expires_at, referrer_id, created_at, updated_at
- 			   FROM referral_codes WHERE referrer_id = $1 AND expires_at > NOW()
+	query := ` SELECT rc.id, rc.code, rc.expires_at, rc.referrer_id, rc.paused_at, rc.max_uses,
+ 			   ` + usesCountColumn + `, rc.campaign_id,
+ 			   ` + campaignFullColumn + `, rc.link_id, rc.superseded_by, rc.rotated_at, rc.created_at, rc.updated_at
+ 			   FROM referral_codes rc WHERE rc.referrer_id = $1 AND rc.expires_at > NOW() AND rc.batch_id IS NULL
+ 			   AND rc.superseded_by IS NULL
  			   LIMIT 1;`
 	var referralCode models.ReferralCode
 	ctx := context.Background()
@@ -183,6 +240,14 @@ func (r *ReferralCodePostgres) GetActiveReferralCodeByUserID(referrerID int) (mo
 			&referralCode.Code,
 			&referralCode.Expiration,
 			&referralCode.ReferrerID,
+			&referralCode.PausedAt,
+			&referralCode.MaxUses,
+			&referralCode.UsesCount,
+			&referralCode.CampaignID,
+			&referralCode.CampaignFull,
+			&referralCode.LinkID,
+			&referralCode.SupersededBy,
+			&referralCode.RotatedAt,
 			&referralCode.CreatedAt,
 			&referralCode.UpdatedAt,
 		)
@@ -227,9 +292,15 @@ func (r *ReferralCodePostgres) GetActiveReferralCodeByUserID(referrerID int) (mo
 func (r *ReferralCodePostgres) GetIDByReferralCode(code string) (int, error) {
 	r.logger.Debugf("GetIDByReferralCode[repo]: Получение id реферального кода: %s", code)
 
-	query := `SELECT id, expires_at FROM referral_codes WHERE code = $1`
+	query := `SELECT rc.id, rc.expires_at, rc.paused_at, rc.max_uses,
+              ` + usesCountColumn + `, ` + campaignFullColumn + `
+              FROM referral_codes rc WHERE rc.code = $1`
 	var codeID int
 	var expiresAt time.Time
+	var pausedAt *time.Time
+	var maxUses *int
+	var usesCount int
+	var campaignFull bool
 	ctx := context.Background()
 
 	// Create context with timeout to cancel query execution if it takes too long
@@ -252,7 +323,7 @@ func (r *ReferralCodePostgres) GetIDByReferralCode(code string) (int, error) {
 		}
 		defer tx.Rollback(ctx) // Rollback transaction if function returns error
 
-		err = tx.QueryRow(ctx, query, code).Scan(&codeID, &expiresAt)
+		err = tx.QueryRow(ctx, query, code).Scan(&codeID, &expiresAt, &pausedAt, &maxUses, &usesCount, &campaignFull)
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				r.logger.Warnf("GetIDByReferralCode[repo]: Реферальный код %s не найден", code)
@@ -262,6 +333,7 @@ func (r *ReferralCodePostgres) GetIDByReferralCode(code string) (int, error) {
 
 			r.logger.Errorf("GetIDByReferralCode[repo]: Ошибка при получении id реферального кода %s: %s", code, err)
 			errChan <- err
+			return
 		}
 
 		// Check if referral code is expired
@@ -271,6 +343,27 @@ func (r *ReferralCodePostgres) GetIDByReferralCode(code string) (int, error) {
 			return
 		}
 
+		// Check if referral code is paused by referrer
+		if pausedAt != nil {
+			r.logger.Infof("GetIDByReferralCode[repo]: Реферальный код %s неактивен (приостановлен)", code)
+			errChan <- ErrReferralCodeNotActive
+			return
+		}
+
+		// Check if referral code has reached its usage limit
+		if maxUses != nil && usesCount >= *maxUses {
+			r.logger.Infof("GetIDByReferralCode[repo]: Реферальный код %s неактивен (исчерпан лимит)", code)
+			errChan <- ErrReferralCodeNotActive
+			return
+		}
+
+		// Check if campaign of referral code has reached its participants limit
+		if campaignFull {
+			r.logger.Infof("GetIDByReferralCode[repo]: Реферальный код %s неактивен (набран лимит участников кампании)", code)
+			errChan <- ErrReferralCodeNotActive
+			return
+		}
+
 		// Commit transaction
 		if err = tx.Commit(ctx); err != nil {
 			r.logger.Errorf("GetIDByReferralCode[repo]: Ошибка при коммите транзакции: %s", err)
@@ -355,3 +448,369 @@ func (r *ReferralCodePostgres) GetReferrerIDByReferralCode(code string) (int, er
 		return 0, ctx.Err()
 	}
 }
+
+// GetByReferralCode retrieves referral code with its usage count by code regardless of its status
+// If referral code not found, returns ErrReferralCodeNotFound
+func (r *ReferralCodePostgres) GetByReferralCode(code string) (models.ReferralCode, error) {
+	r.logger.Debugf("GetByReferralCode[repo]: Получение реферального кода: %s", code)
+
+	query := `SELECT rc.id, rc.code, rc.expires_at, rc.referrer_id, rc.paused_at, rc.max_uses,
+              ` + usesCountColumn + `, rc.campaign_id,
+              ` + campaignFullColumn + `, rc.link_id, rc.superseded_by, rc.rotated_at, rc.created_at, rc.updated_at
+              FROM referral_codes rc WHERE rc.code = $1`
+	var referralCode models.ReferralCode
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get code from goroutine
+	codeChan := make(chan models.ReferralCode)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetByReferralCode[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		err = tx.QueryRow(ctx, query, code).Scan(
+			&referralCode.ID,
+			&referralCode.Code,
+			&referralCode.Expiration,
+			&referralCode.ReferrerID,
+			&referralCode.PausedAt,
+			&referralCode.MaxUses,
+			&referralCode.UsesCount,
+			&referralCode.CampaignID,
+			&referralCode.CampaignFull,
+			&referralCode.LinkID,
+			&referralCode.SupersededBy,
+			&referralCode.RotatedAt,
+			&referralCode.CreatedAt,
+			&referralCode.UpdatedAt,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("GetByReferralCode[repo]: Реферальный код %s не найден", code)
+				errChan <- ErrReferralCodeNotFound
+				return
+			}
+
+			r.logger.Errorf("GetByReferralCode[repo]: Ошибка при получении реферального кода %s: %s", code, err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetByReferralCode[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		codeChan <- referralCode
+	}()
+
+	select {
+	case code := <-codeChan:
+		r.logger.Infof("GetByReferralCode[repo]: Реферальный код с id: %d получен", code.ID)
+		return code, nil
+	case err := <-errChan:
+		return models.ReferralCode{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetByReferralCode[repo]: Время ожидания превышено")
+		return models.ReferralCode{}, ctx.Err()
+	}
+}
+
+// SetPausedByID pauses or resumes referral code with given id
+// If referral code with given id not found, returns ErrReferralCodeNotFound
+func (r *ReferralCodePostgres) SetPausedByID(id int, paused bool) error {
+	r.logger.Debugf("SetPausedByID[repo]: Изменение паузы реферального кода с id: %d на %t", id, paused)
+
+	query := `UPDATE referral_codes
+              SET paused_at = CASE WHEN $2 THEN COALESCE(paused_at, NOW()) END, updated_at = NOW()
+              WHERE id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("SetPausedByID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		// Execute update query and check how many rows were affected
+		result, err := tx.Exec(ctx, query, id, paused)
+		if err != nil {
+			r.logger.Errorf("SetPausedByID[repo]: Ошибка изменения реферального кода с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		if result.RowsAffected() == 0 {
+			r.logger.Warnf("SetPausedByID[repo]: Реферальный код с id: %d не найден", id)
+			errChan <- ErrReferralCodeNotFound
+			return
+		}
+
+		referralCode, err := scanReferralGraphCode(tx.QueryRow(ctx, referralCodeEventQuery, id))
+		if err != nil {
+			r.logger.Errorf("SetPausedByID[repo]: Ошибка получения реферального кода с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		eventType := models.OutboxEventReferralCodeResumed
+		if paused {
+			eventType = models.OutboxEventReferralCodePaused
+		}
+		err = enqueueOutbox(ctx, tx, models.OutboxAggregateReferralCode, id, eventType, referralCode)
+		if err != nil {
+			r.logger.Errorf("SetPausedByID[repo]: Ошибка записи события в outbox: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("SetPausedByID[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		r.logger.Infof("SetPausedByID[repo]: Пауза реферального кода с id %d изменена на %t", id, paused)
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		r.logger.Errorf("SetPausedByID[repo]: Время ожидания превышено для реферального кода с id: %d", id)
+		return ctx.Err()
+	}
+}
+
+// GetReferralCodeByID retrieves referral code with its usage count by id regardless of its status
+// If referral code not found, returns ErrReferralCodeNotFound
+func (r *ReferralCodePostgres) GetReferralCodeByID(id int) (models.ReferralCode, error) {
+	r.logger.Debugf("GetReferralCodeByID[repo]: Получение реферального кода с id: %d", id)
+
+	query := `SELECT rc.id, rc.code, rc.expires_at, rc.referrer_id, rc.paused_at, rc.max_uses,
+              ` + usesCountColumn + `, rc.campaign_id,
+              ` + campaignFullColumn + `, rc.link_id, rc.superseded_by, rc.rotated_at, rc.created_at, rc.updated_at
+              FROM referral_codes rc WHERE rc.id = $1`
+	var referralCode models.ReferralCode
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get code from goroutine
+	codeChan := make(chan models.ReferralCode)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetReferralCodeByID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		err = tx.QueryRow(ctx, query, id).Scan(
+			&referralCode.ID,
+			&referralCode.Code,
+			&referralCode.Expiration,
+			&referralCode.ReferrerID,
+			&referralCode.PausedAt,
+			&referralCode.MaxUses,
+			&referralCode.UsesCount,
+			&referralCode.CampaignID,
+			&referralCode.CampaignFull,
+			&referralCode.LinkID,
+			&referralCode.SupersededBy,
+			&referralCode.RotatedAt,
+			&referralCode.CreatedAt,
+			&referralCode.UpdatedAt,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("GetReferralCodeByID[repo]: Реферальный код с id: %d не найден", id)
+				errChan <- ErrReferralCodeNotFound
+				return
+			}
+
+			r.logger.Errorf("GetReferralCodeByID[repo]: Ошибка при получении реферального кода с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetReferralCodeByID[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		codeChan <- referralCode
+	}()
+
+	select {
+	case code := <-codeChan:
+		r.logger.Infof("GetReferralCodeByID[repo]: Реферальный код с id: %d получен", id)
+		return code, nil
+	case err := <-errChan:
+		return models.ReferralCode{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetReferralCodeByID[repo]: Время ожидания превышено для реферального кода с id: %d", id)
+		return models.ReferralCode{}, ctx.Err()
+	}
+}
+
+// Rotate replaces referral code with given id by replacement code in single transaction
+// Replacement joins the same referral link, old code is marked as superseded and its expiration
+// is cut to graceUntil, so it keeps working only during grace period
+// Replacement keeps pause of old code, uses are counted per link, so usage limit is not reset
+// If code not found or already rotated, returns ErrReferralCodeNotFound
+func (r *ReferralCodePostgres) Rotate(id int, replacement models.ReferralCode,
+	graceUntil time.Time) (models.ReferralCode, error) {
+	r.logger.Debugf("Rotate[repo]: Замена реферального кода с id: %d", id)
+
+	lockQuery := `SELECT link_id FROM referral_codes WHERE id = $1 AND superseded_by IS NULL FOR UPDATE`
+	linkQuery := `INSERT INTO referral_links (referrer_id, created_at) VALUES ($1, NOW()) RETURNING id`
+	attachQuery := `UPDATE referral_codes SET link_id = $2 WHERE id = $1`
+	insertQuery := `INSERT INTO referral_codes (code, expires_at, referrer_id, max_uses, campaign_id, link_id,
+                    paused_at, created_at, updated_at)
+                    VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+                    RETURNING id, created_at, updated_at`
+	supersedeQuery := `UPDATE referral_codes
+                       SET superseded_by = $2, rotated_at = NOW(), expires_at = LEAST(expires_at, $3), updated_at = NOW()
+                       WHERE id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get new code from goroutine
+	codeChan := make(chan models.ReferralCode)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Rotate[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		// Lock old code, so concurrent rotations can not fork the link
+		err = tx.QueryRow(ctx, lockQuery, id).Scan(&replacement.LinkID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("Rotate[repo]: Реферальный код с id: %d не найден или уже заменен", id)
+				errChan <- ErrReferralCodeNotFound
+				return
+			}
+
+			r.logger.Errorf("Rotate[repo]: Ошибка блокировки реферального кода с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		// Codes created before links were introduced get their link on first rotation
+		if replacement.LinkID == nil {
+			if err = tx.QueryRow(ctx, linkQuery, replacement.ReferrerID).Scan(&replacement.LinkID); err != nil {
+				r.logger.Errorf("Rotate[repo]: Ошибка создания реферальной ссылки: %s", err)
+				errChan <- err
+				return
+			}
+
+			if _, err = tx.Exec(ctx, attachQuery, id, replacement.LinkID); err != nil {
+				r.logger.Errorf("Rotate[repo]: Ошибка привязки реферального кода с id: %d к ссылке: %s", id, err)
+				errChan <- err
+				return
+			}
+		}
+
+		err = tx.QueryRow(ctx, insertQuery, replacement.Code, replacement.Expiration, replacement.ReferrerID,
+			replacement.MaxUses, replacement.CampaignID, replacement.LinkID, replacement.PausedAt).
+			Scan(&replacement.ID, &replacement.CreatedAt, &replacement.UpdatedAt)
+		if err != nil {
+			r.logger.Errorf("Rotate[repo]: Ошибка создания нового реферального кода: %s", err)
+			errChan <- err
+			return
+		}
+
+		if _, err = tx.Exec(ctx, supersedeQuery, id, replacement.ID, graceUntil); err != nil {
+			r.logger.Errorf("Rotate[repo]: Ошибка замены реферального кода с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		previous, err := scanReferralGraphCode(tx.QueryRow(ctx, referralCodeEventQuery, id))
+		if err != nil {
+			r.logger.Errorf("Rotate[repo]: Ошибка получения реферального кода с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		err = enqueueOutbox(ctx, tx, models.OutboxAggregateReferralCode, id, models.OutboxEventReferralCodeRotated,
+			models.OutboxReferralCodeRotation{PreviousID: id, GraceUntil: graceUntil, Previous: previous,
+				Current: replacement})
+		if err != nil {
+			r.logger.Errorf("Rotate[repo]: Ошибка записи события в outbox: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Rotate[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		codeChan <- replacement
+	}()
+
+	select {
+	case code := <-codeChan:
+		r.logger.Infof("Rotate[repo]: Реферальный код с id: %d заменен кодом с id: %d", id, code.ID)
+		return code, nil
+	case err := <-errChan:
+		return models.ReferralCode{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("Rotate[repo]: Время ожидания превышено для реферального кода с id: %d", id)
+		return models.ReferralCode{}, ctx.Err()
+	}
+}
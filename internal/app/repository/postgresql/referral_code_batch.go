@@ -0,0 +1,320 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+var ErrReferralCodeBatchNotFound = errors.New("пакет реферальных кодов не найден")
+var ErrReferralCodeBatchNotUnique = errors.New("не удалось сгенерировать уникальные реферальные коды")
+
+// batchTimeout is longer than usual query timeout, because batch may contain thousands of codes
+const batchTimeout = 2 * time.Minute
+
+// maxBatchAttempts limits how many times codes colliding with existing ones are regenerated
+const maxBatchAttempts = 5
+
+// ReferralCodeBatchPostgres implements the ReferralCodeBatchRepo interface for PostgreSQL database operations
+// related to batches of referral codes
+type ReferralCodeBatchPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewReferralCodeBatchPostgres creates new ReferralCodeBatchPostgres instance with provided database connection and logger
+func NewReferralCodeBatchPostgres(db database.Database, logger *logrus.Logger) *ReferralCodeBatchPostgres {
+	return &ReferralCodeBatchPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create inserts batch and batch.Size unique codes produced by generate in single transaction
+// Codes are loaded with COPY into temporary table and moved into referral_codes skipping collisions,
+// colliding codes are regenerated up to maxBatchAttempts times
+func (r *ReferralCodeBatchPostgres) Create(batch models.ReferralCodeBatch,
+	generate func() (string, error)) (models.ReferralCodeBatch, error) {
+	r.logger.Debugf("Create[repo]: Создание пакета из %d реферальных кодов для пользователя с id: %d",
+		batch.Size, batch.ReferrerID)
+
+	batchQuery := `INSERT INTO referral_code_batches (referrer_id, created_by, size, max_uses, expires_at, campaign_id,
+                   created_at)
+                   VALUES ($1, $2, $3, $4, $5, $6, NOW())
+                   RETURNING id, created_at`
+	tempTableQuery := `CREATE TEMP TABLE referral_code_batch_codes (code VARCHAR(255) NOT NULL) ON COMMIT DROP`
+	moveQuery := `INSERT INTO referral_codes (code, expires_at, referrer_id, max_uses, batch_id, campaign_id,
+                  created_at, updated_at)
+                  SELECT code, $1, $2, $3, $4, $5, NOW(), NOW() FROM referral_code_batch_codes
+                  ON CONFLICT (code) DO NOTHING`
+	outboxQuery := `INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload, created_at)
+                    SELECT $2, id, $3, jsonb_strip_nulls(jsonb_build_object('id', id, 'code', code,
+                        'expires_at', expires_at, 'referrer_id', referrer_id, 'max_uses', max_uses, 'uses_count', 0,
+                        'batch_id', batch_id, 'campaign_id', campaign_id, 'created_at', created_at,
+                        'updated_at', updated_at)), NOW()
+                    FROM referral_codes WHERE batch_id = $1 ORDER BY id`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, batchTimeout)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get batch from goroutine
+	batchChan := make(chan models.ReferralCodeBatch)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		err = tx.QueryRow(ctx, batchQuery, batch.ReferrerID, batch.CreatedBy, batch.Size, batch.MaxUses,
+			batch.Expiration, batch.CampaignID).Scan(&batch.ID, &batch.CreatedAt)
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка создания пакета реферальных кодов: %s", err)
+			errChan <- err
+			return
+		}
+
+		if _, err = tx.Exec(ctx, tempTableQuery); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка создания временной таблицы: %s", err)
+			errChan <- err
+			return
+		}
+
+		inserted := 0
+		for attempt := 0; inserted < batch.Size; attempt++ {
+			if attempt == maxBatchAttempts {
+				r.logger.Errorf("Create[repo]: Вставлено только %d из %d реферальных кодов", inserted, batch.Size)
+				errChan <- ErrReferralCodeBatchNotUnique
+				return
+			}
+
+			// Generate missing codes, skipping duplicates within this attempt
+			missing := batch.Size - inserted
+			seen := make(map[string]struct{}, missing)
+			rows := make([][]interface{}, 0, missing)
+			for len(rows) < missing {
+				code, err := generate()
+				if err != nil {
+					errChan <- err
+					return
+				}
+				if _, ok := seen[code]; ok {
+					continue
+				}
+				seen[code] = struct{}{}
+				rows = append(rows, []interface{}{code})
+			}
+
+			_, err = tx.CopyFrom(ctx, pgx.Identifier{"referral_code_batch_codes"}, []string{"code"},
+				pgx.CopyFromRows(rows))
+			if err != nil {
+				r.logger.Errorf("Create[repo]: Ошибка копирования реферальных кодов: %s", err)
+				errChan <- err
+				return
+			}
+
+			result, err := tx.Exec(ctx, moveQuery, batch.Expiration, batch.ReferrerID, batch.MaxUses, batch.ID,
+				batch.CampaignID)
+			if err != nil {
+				r.logger.Errorf("Create[repo]: Ошибка вставки реферальных кодов: %s", err)
+				errChan <- err
+				return
+			}
+			inserted += int(result.RowsAffected())
+
+			if _, err = tx.Exec(ctx, `TRUNCATE referral_code_batch_codes`); err != nil {
+				r.logger.Errorf("Create[repo]: Ошибка очистки временной таблицы: %s", err)
+				errChan <- err
+				return
+			}
+		}
+
+		// Codes of batch are recorded in outbox at once, the same way as codes created one by one
+		_, err = tx.Exec(ctx, outboxQuery, batch.ID, models.OutboxAggregateReferralCode,
+			models.OutboxEventReferralCodeCreated)
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка записи событий в outbox: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		batchChan <- batch
+	}()
+
+	select {
+	case created := <-batchChan:
+		r.logger.Infof("Create[repo]: Пакет реферальных кодов с id: %d успешно создан", created.ID)
+		return created, nil
+	case err := <-errChan:
+		return models.ReferralCodeBatch{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("Create[repo]: Время ожидания превышено")
+		return models.ReferralCodeBatch{}, ctx.Err()
+	}
+}
+
+// GetByID retrieves batch of referral codes by id
+// If batch not found, returns ErrReferralCodeBatchNotFound
+func (r *ReferralCodeBatchPostgres) GetByID(id int) (models.ReferralCodeBatch, error) {
+	r.logger.Debugf("GetByID[repo]: Получение пакета реферальных кодов с id: %d", id)
+
+	query := `SELECT id, referrer_id, created_by, size, max_uses, expires_at, campaign_id, created_at
+              FROM referral_code_batches WHERE id = $1`
+	var batch models.ReferralCodeBatch
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get batch from goroutine
+	batchChan := make(chan models.ReferralCodeBatch)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetByID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		err = tx.QueryRow(ctx, query, id).Scan(&batch.ID, &batch.ReferrerID, &batch.CreatedBy, &batch.Size,
+			&batch.MaxUses, &batch.Expiration, &batch.CampaignID, &batch.CreatedAt)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("GetByID[repo]: Пакет реферальных кодов с id: %d не найден", id)
+				errChan <- ErrReferralCodeBatchNotFound
+				return
+			}
+
+			r.logger.Errorf("GetByID[repo]: Ошибка при получении пакета реферальных кодов с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetByID[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		batchChan <- batch
+	}()
+
+	select {
+	case found := <-batchChan:
+		r.logger.Infof("GetByID[repo]: Пакет реферальных кодов с id: %d получен", id)
+		return found, nil
+	case err := <-errChan:
+		return models.ReferralCodeBatch{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetByID[repo]: Время ожидания превышено для пакета с id: %d", id)
+		return models.ReferralCodeBatch{}, ctx.Err()
+	}
+}
+
+// GetCodesByBatchID retrieves all referral codes of batch with their usage counts ordered by id
+func (r *ReferralCodeBatchPostgres) GetCodesByBatchID(batchID int) ([]models.ReferralCode, error) {
+	r.logger.Debugf("GetCodesByBatchID[repo]: Получение реферальных кодов пакета с id: %d", batchID)
+
+	query := `SELECT rc.id, rc.code, rc.expires_at, rc.referrer_id, rc.paused_at, rc.max_uses,
+              (SELECT COUNT(*) FROM referrals WHERE referral_code_id = rc.id), rc.batch_id, rc.campaign_id,
+              rc.created_at, rc.updated_at
+              FROM referral_codes rc WHERE rc.batch_id = $1 ORDER BY rc.id`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, batchTimeout)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	// Use a channel to get codes from goroutine
+	codesChan := make(chan []models.ReferralCode)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetCodesByBatchID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, query, batchID)
+		if err != nil {
+			r.logger.Errorf("GetCodesByBatchID[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		var codes []models.ReferralCode
+		for rows.Next() {
+			var code models.ReferralCode
+			err = rows.Scan(&code.ID, &code.Code, &code.Expiration, &code.ReferrerID, &code.PausedAt,
+				&code.MaxUses, &code.UsesCount, &code.BatchID, &code.CampaignID, &code.CreatedAt, &code.UpdatedAt)
+			if err != nil {
+				r.logger.Errorf("GetCodesByBatchID[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			codes = append(codes, code)
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetCodesByBatchID[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetCodesByBatchID[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		codesChan <- codes
+	}()
+
+	select {
+	case codes := <-codesChan:
+		r.logger.Infof("GetCodesByBatchID[repo]: Получено %d реферальных кодов пакета с id: %d", len(codes), batchID)
+		return codes, nil
+	case err := <-errChan:
+		return nil, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetCodesByBatchID[repo]: Время ожидания превышено для пакета с id: %d", batchID)
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,182 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+// ReferralEventPostgres implements the ReferralEventRepo interface for PostgreSQL database operations
+// related to external events about referred users
+type ReferralEventPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewReferralEventPostgres creates new ReferralEventPostgres instance with provided database connection and logger
+func NewReferralEventPostgres(db database.Database, logger *logrus.Logger) *ReferralEventPostgres {
+	return &ReferralEventPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Record stores event for referral found by event.ReferralID or, if it is zero, by email of referred user
+// and updates referral status to the one returned by decide in the same transaction.
+// decide gets current status, type of new event and all event types received for referral so far.
+// Event with already known EventID is not stored again, current status of its referral is reported as duplicate
+// If referral not found, returns ErrReferralNotFound
+func (r *ReferralEventPostgres) Record(event models.ReferralEvent, email string,
+	decide func(status, eventType string, received []string) string) (models.ReferralEventResponse, error) {
+	r.logger.Debugf("Record[repo]: Сохранение события %s типа %s", event.EventID, event.Type)
+
+	lockByIDQuery := `SELECT id, referrer_id, status FROM referrals WHERE id = $1 FOR UPDATE`
+	lockByEmailQuery := `SELECT id, referrer_id, status FROM referrals WHERE email = $1 FOR UPDATE`
+	insertQuery := `INSERT INTO referral_events (event_id, referral_id, type, payload, occurred_at, received_at)
+                    VALUES ($1, $2, $3, $4, $5, NOW())
+                    ON CONFLICT (event_id) DO NOTHING
+                    RETURNING id`
+	duplicateQuery := `SELECT e.referral_id, r.status FROM referral_events e JOIN referrals r ON r.id = e.referral_id
+                       WHERE e.event_id = $1`
+	typesQuery := `SELECT DISTINCT type FROM referral_events WHERE referral_id = $1`
+	updateQuery := `UPDATE referrals SET status = $2, status_changed_at = NOW() WHERE id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get result from goroutine
+	resultChan := make(chan models.ReferralEventResponse)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Record[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		result := models.ReferralEventResponse{EventID: event.EventID}
+
+		// Lock referral, so concurrent events of the same referral are applied one by one
+		if event.ReferralID != 0 {
+			err = tx.QueryRow(ctx, lockByIDQuery, event.ReferralID).Scan(&result.ReferralID, &result.ReferrerID,
+				&result.Status)
+		} else {
+			err = tx.QueryRow(ctx, lockByEmailQuery, email).Scan(&result.ReferralID, &result.ReferrerID, &result.Status)
+		}
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("Record[repo]: Реферал для события %s не найден", event.EventID)
+				errChan <- ErrReferralNotFound
+				return
+			}
+
+			r.logger.Errorf("Record[repo]: Ошибка при получении реферала: %s", err)
+			errChan <- err
+			return
+		}
+
+		var id int
+		err = tx.QueryRow(ctx, insertQuery, event.EventID, result.ReferralID, event.Type, event.Payload,
+			event.OccurredAt).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Event was received before, report status of its referral without changes
+			err = tx.QueryRow(ctx, duplicateQuery, event.EventID).Scan(&result.ReferralID, &result.Status)
+			if err != nil {
+				r.logger.Errorf("Record[repo]: Ошибка при получении повторного события %s: %s", event.EventID, err)
+				errChan <- err
+				return
+			}
+
+			r.logger.Infof("Record[repo]: Событие %s уже было получено", event.EventID)
+			result.Duplicate = true
+			resultChan <- result
+			return
+		}
+		if err != nil {
+			r.logger.Errorf("Record[repo]: Ошибка сохранения события %s: %s", event.EventID, err)
+			errChan <- err
+			return
+		}
+
+		rows, err := tx.Query(ctx, typesQuery, result.ReferralID)
+		if err != nil {
+			r.logger.Errorf("Record[repo]: Ошибка при получении событий реферала: %s", err)
+			errChan <- err
+			return
+		}
+
+		var received []string
+		for rows.Next() {
+			var eventType string
+			if err = rows.Scan(&eventType); err != nil {
+				rows.Close()
+				r.logger.Errorf("Record[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			received = append(received, eventType)
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("Record[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		if status := decide(result.Status, event.Type, received); status != result.Status {
+			if _, err = tx.Exec(ctx, updateQuery, result.ReferralID, status); err != nil {
+				r.logger.Errorf("Record[repo]: Ошибка изменения статуса реферала с id: %d: %s", result.ReferralID, err)
+				errChan <- err
+				return
+			}
+
+			err = enqueueOutbox(ctx, tx, models.OutboxAggregateReferral, result.ReferralID,
+				models.OutboxEventReferralStatusChanged,
+				models.OutboxReferralStatus{ReferralID: result.ReferralID, Status: status})
+			if err != nil {
+				r.logger.Errorf("Record[repo]: Ошибка записи события в outbox: %s", err)
+				errChan <- err
+				return
+			}
+
+			r.logger.Infof("Record[repo]: Статус реферала с id: %d изменен с %s на %s",
+				result.ReferralID, result.Status, status)
+			result.Status = status
+			result.StatusChanged = true
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Record[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		resultChan <- result
+	}()
+
+	select {
+	case result := <-resultChan:
+		r.logger.Infof("Record[repo]: Событие %s для реферала с id: %d обработано", result.EventID, result.ReferralID)
+		return result, nil
+	case err := <-errChan:
+		return models.ReferralEventResponse{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("Record[repo]: Время ожидания превышено для события %s", event.EventID)
+		return models.ReferralEventResponse{}, ctx.Err()
+	}
+}
@@ -0,0 +1,208 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+var ErrReferralFeedEventNotFound = errors.New("событие ленты рефералов не найдено")
+
+// referralFeedChannel is channel outbox trigger notifies about messages of referrers when they are committed
+const referralFeedChannel = "referral_feed"
+
+// ReferralFeedPostgres implements the ReferralFeedRepo interface for real-time feed of referrers
+// built on outbox messages and PostgreSQL LISTEN/NOTIFY
+type ReferralFeedPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewReferralFeedPostgres creates new ReferralFeedPostgres instance with provided database connection and logger
+func NewReferralFeedPostgres(db database.Database, logger *logrus.Logger) *ReferralFeedPostgres {
+	return &ReferralFeedPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetEventsAfter retrieves events of user with feed position greater than afterID in order of position.
+// Positions are taken when transactions commit, so event committed late is not left behind afterID.
+// Reset is true if there are more than limit events, then only latest limit of them are returned,
+// or if events following afterID were already removed from outbox
+func (r *ReferralFeedPostgres) GetEventsAfter(userID int, afterID int64,
+	limit int) ([]models.ReferralFeedEvent, bool, error) {
+	r.logger.Debugf("GetEventsAfter[repo]: События пользователя с id: %d после %d", userID, afterID)
+
+	query := `SELECT feed_position, event_type, user_id, payload, created_at FROM outbox
+              WHERE user_id = $1 AND feed_position > $2
+              ORDER BY feed_position DESC
+              LIMIT $3`
+	// Outbox retention removes messages from the start of feed only, so position preceding oldest retained one
+	// is the earliest client can resume from without losing events
+	expiredQuery := `SELECT $1 < COALESCE((SELECT MIN(feed_position) FROM outbox),
+                                   (SELECT CASE WHEN is_called THEN last_value + 1 ELSE last_value END
+                                    FROM outbox_feed_position_seq)) - 1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get events from goroutine
+	eventsChan := make(chan []models.ReferralFeedEvent)
+
+	// Use a channel to get expiration of afterID from goroutine
+	expiredChan := make(chan bool, 1)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		var expired bool
+		if err := r.db.GetPool().QueryRow(ctx, expiredQuery, afterID).Scan(&expired); err != nil {
+			r.logger.Errorf("GetEventsAfter[repo]: Ошибка проверки начала ленты: %s", err)
+			errChan <- err
+			return
+		}
+		expiredChan <- expired
+
+		rows, err := r.db.GetPool().Query(ctx, query, userID, afterID, limit+1)
+		if err != nil {
+			r.logger.Errorf("GetEventsAfter[repo]: Ошибка при получении событий: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		var events []models.ReferralFeedEvent
+		for rows.Next() {
+			event, err := scanReferralFeedEvent(rows)
+			if err != nil {
+				r.logger.Errorf("GetEventsAfter[repo]: Ошибка при чтении события: %s", err)
+				errChan <- err
+				return
+			}
+			events = append(events, event)
+		}
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetEventsAfter[repo]: Ошибка при получении событий: %s", err)
+			errChan <- err
+			return
+		}
+
+		eventsChan <- events
+	}()
+
+	select {
+	case events := <-eventsChan:
+		reset := <-expiredChan || len(events) > limit
+		if len(events) > limit {
+			events = events[:limit]
+		}
+		// Latest events were selected first, client gets them in order they happened
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+		return events, reset, nil
+	case err := <-errChan:
+		return nil, false, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetEventsAfter[repo]: Время ожидания превышено")
+		return nil, false, ctx.Err()
+	}
+}
+
+// GetEventByID retrieves event by its feed position
+// If message is not found or has no owner, returns ErrReferralFeedEventNotFound
+func (r *ReferralFeedPostgres) GetEventByID(id int64) (models.ReferralFeedEvent, error) {
+	query := `SELECT feed_position, event_type, user_id, payload, created_at FROM outbox
+              WHERE feed_position = $1 AND user_id IS NOT NULL`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get event from goroutine
+	eventChan := make(chan models.ReferralFeedEvent)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		event, err := scanReferralFeedEvent(r.db.GetPool().QueryRow(ctx, query, id))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				errChan <- ErrReferralFeedEventNotFound
+				return
+			}
+			r.logger.Errorf("GetEventByID[repo]: Ошибка при получении события с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		eventChan <- event
+	}()
+
+	select {
+	case event := <-eventChan:
+		return event, nil
+	case err := <-errChan:
+		return models.ReferralFeedEvent{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetEventByID[repo]: Время ожидания превышено")
+		return models.ReferralFeedEvent{}, ctx.Err()
+	}
+}
+
+// Listen subscribes to notifications about new outbox messages and passes their events to notify
+// until ctx is done or connection fails. Events of messages too large for notification come without data.
+// Listening holds its own connection taken out of pool, so it does not stay subscribed after return
+func (r *ReferralFeedPostgres) Listen(ctx context.Context, notify func(event models.ReferralFeedEvent)) error {
+	pooled, err := r.db.GetPool().Acquire(ctx)
+	if err != nil {
+		r.logger.Errorf("Listen[repo]: Ошибка получения соединения: %s", err)
+		return err
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+referralFeedChannel); err != nil {
+		r.logger.Errorf("Listen[repo]: Ошибка подписки на канал %s: %s", referralFeedChannel, err)
+		return err
+	}
+	r.logger.Infof("Listen[repo]: Подписка на канал %s", referralFeedChannel)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Owner is hidden from clients, so it is decoded apart from event
+		var payload struct {
+			models.ReferralFeedEvent
+			UserID int `json:"user_id"`
+		}
+		if err = json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+			r.logger.Errorf("Listen[repo]: Ошибка разбора уведомления %q: %s", notification.Payload, err)
+			continue
+		}
+		payload.ReferralFeedEvent.UserID = payload.UserID
+		notify(payload.ReferralFeedEvent)
+	}
+}
+
+// scanReferralFeedEvent scans event from row of outbox message
+func scanReferralFeedEvent(row pgx.Row) (models.ReferralFeedEvent, error) {
+	var event models.ReferralFeedEvent
+	err := row.Scan(&event.ID, &event.Type, &event.UserID, &event.Data, &event.CreatedAt)
+	return event, err
+}
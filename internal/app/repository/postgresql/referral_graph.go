@@ -0,0 +1,246 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+// referralGraphCodeColumns are columns of referral codes scanned by scanReferralGraphCode
+const referralGraphCodeColumns = `rc.id, rc.code, rc.expires_at, rc.referrer_id, rc.paused_at, rc.max_uses,
+              ` + usesCountColumn + `, rc.campaign_id, rc.link_id,
+              rc.superseded_by, rc.rotated_at, rc.created_at, rc.updated_at`
+
+// referralGraphReferralColumns are columns of referrals scanned by scanReferralGraphReferral
+const referralGraphReferralColumns = `r.id, r.email, r.referral_code_id, r.referrer_id, r.user_id, rc.campaign_id,
+              r.status, r.status_changed_at, r.created_at`
+
+// ReferralGraphPostgres implements the ReferralGraphRepo interface for PostgreSQL database operations
+// that load users, referral codes and referrals by many keys at once
+type ReferralGraphPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewReferralGraphPostgres creates new ReferralGraphPostgres instance with provided database connection and logger
+func NewReferralGraphPostgres(db database.Database, logger *logrus.Logger) *ReferralGraphPostgres {
+	return &ReferralGraphPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetUsersByIDs retrieves users with ids, missing ones are skipped
+func (r *ReferralGraphPostgres) GetUsersByIDs(ids []int) ([]models.User, error) {
+	r.logger.Debugf("GetUsersByIDs[repo]: Получение %d пользователей", len(ids))
+
+	query := `SELECT id, email, COALESCE(display_name, ''), is_admin, created_at
+              FROM users WHERE id = ANY($1) ORDER BY id`
+
+	var users []models.User
+	err := r.selectRows("GetUsersByIDs", query, func(rows pgx.Rows) error {
+		var user models.User
+		err := rows.Scan(&user.ID, &user.Email, &user.DisplayName, &user.IsAdmin, &user.CreatedAt)
+		users = append(users, user)
+		return err
+	}, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// GetReferralCodesByIDs retrieves referral codes with ids, missing ones are skipped
+func (r *ReferralGraphPostgres) GetReferralCodesByIDs(ids []int) ([]models.ReferralCode, error) {
+	r.logger.Debugf("GetReferralCodesByIDs[repo]: Получение %d реферальных кодов", len(ids))
+
+	query := `SELECT ` + referralGraphCodeColumns + `
+              FROM referral_codes rc WHERE rc.id = ANY($1) ORDER BY rc.id`
+
+	var codes []models.ReferralCode
+	err := r.selectRows("GetReferralCodesByIDs", query, func(rows pgx.Rows) error {
+		code, err := scanReferralGraphCode(rows)
+		codes = append(codes, code)
+		return err
+	}, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// GetReferralCodesByReferrerIDs retrieves page of referral codes of every referrer with ids from newest to oldest,
+// including paused, expired and rotated ones
+func (r *ReferralGraphPostgres) GetReferralCodesByReferrerIDs(ids []int, page models.GraphPage) ([]models.ReferralCode,
+	error) {
+	r.logger.Debugf("GetReferralCodesByReferrerIDs[repo]: Получение реферальных кодов %d рефереров", len(ids))
+
+	query := `SELECT ` + referralGraphCodeColumns + `
+              FROM (SELECT c.*, ROW_NUMBER() OVER (PARTITION BY c.referrer_id
+                                                   ORDER BY c.created_at DESC, c.id DESC) AS position
+                    FROM referral_codes c
+                    WHERE c.referrer_id = ANY($1)
+                      AND ($3 = 0 OR (c.created_at, c.id) < (SELECT created_at, id FROM referral_codes WHERE id = $3))
+                   ) rc
+              WHERE rc.position <= $2 ORDER BY rc.created_at DESC, rc.id DESC`
+
+	var codes []models.ReferralCode
+	err := r.selectRows("GetReferralCodesByReferrerIDs", query, func(rows pgx.Rows) error {
+		code, err := scanReferralGraphCode(rows)
+		codes = append(codes, code)
+		return err
+	}, ids, page.First, page.After)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// GetReferralsByReferralCodeIDs retrieves page of referrals registered with every referral code with ids
+// from newest to oldest
+func (r *ReferralGraphPostgres) GetReferralsByReferralCodeIDs(ids []int, page models.GraphPage) ([]models.Referral,
+	error) {
+	r.logger.Debugf("GetReferralsByReferralCodeIDs[repo]: Получение рефералов %d реферальных кодов", len(ids))
+
+	query := `SELECT ` + referralGraphReferralColumns + `
+              FROM (SELECT f.*, ROW_NUMBER() OVER (PARTITION BY f.referral_code_id
+                                                   ORDER BY f.created_at DESC, f.id DESC) AS position
+                    FROM referrals f
+                    WHERE f.referral_code_id = ANY($1)
+                      AND ($3 = 0 OR (f.created_at, f.id) < (SELECT created_at, id FROM referrals WHERE id = $3))
+                   ) r
+              LEFT JOIN referral_codes rc ON rc.id = r.referral_code_id
+              WHERE r.position <= $2 ORDER BY r.created_at DESC, r.id DESC`
+
+	var referrals []models.Referral
+	err := r.selectRows("GetReferralsByReferralCodeIDs", query, func(rows pgx.Rows) error {
+		referral, err := scanReferralGraphReferral(rows)
+		referrals = append(referrals, referral)
+		return err
+	}, ids, page.First, page.After)
+	if err != nil {
+		return nil, err
+	}
+
+	return referrals, nil
+}
+
+// GetReferralsByReferrerIDs retrieves page of referrals of every referrer with ids from newest to oldest
+func (r *ReferralGraphPostgres) GetReferralsByReferrerIDs(ids []int, page models.GraphPage) ([]models.Referral,
+	error) {
+	r.logger.Debugf("GetReferralsByReferrerIDs[repo]: Получение рефералов %d рефереров", len(ids))
+
+	query := `SELECT ` + referralGraphReferralColumns + `
+              FROM (SELECT f.*, ROW_NUMBER() OVER (PARTITION BY f.referrer_id
+                                                   ORDER BY f.created_at DESC, f.id DESC) AS position
+                    FROM referrals f
+                    WHERE f.referrer_id = ANY($1)
+                      AND ($3 = 0 OR (f.created_at, f.id) < (SELECT created_at, id FROM referrals WHERE id = $3))
+                   ) r
+              LEFT JOIN referral_codes rc ON rc.id = r.referral_code_id
+              WHERE r.position <= $2 ORDER BY r.created_at DESC, r.id DESC`
+
+	var referrals []models.Referral
+	err := r.selectRows("GetReferralsByReferrerIDs", query, func(rows pgx.Rows) error {
+		referral, err := scanReferralGraphReferral(rows)
+		referrals = append(referrals, referral)
+		return err
+	}, ids, page.First, page.After)
+	if err != nil {
+		return nil, err
+	}
+
+	return referrals, nil
+}
+
+// selectRows runs query with args in its own transaction and passes every returned row to scan
+func (r *ReferralGraphPostgres) selectRows(method, query string, scan func(rows pgx.Rows) error,
+	args ...interface{}) error {
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка начала транзакции: %s", method, err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка при выполнении запроса: %s", method, err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			if err = scan(rows); err != nil {
+				r.logger.Errorf("%s[repo]: Ошибка сканировании строки: %s", method, err)
+				errChan <- err
+				return
+			}
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка после итерации по строкам: %s", method, err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка коммита транзакции: %s", method, err)
+			errChan <- err
+			return
+		}
+
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		r.logger.Errorf("%s[repo]: Время ожидания превышено", method)
+		return ctx.Err()
+	}
+}
+
+// scanReferralGraphCode scans row of referralGraphCodeColumns into referral code
+func scanReferralGraphCode(row pgx.Row) (models.ReferralCode, error) {
+	var code models.ReferralCode
+	err := row.Scan(&code.ID, &code.Code, &code.Expiration, &code.ReferrerID, &code.PausedAt, &code.MaxUses,
+		&code.UsesCount, &code.CampaignID, &code.LinkID, &code.SupersededBy, &code.RotatedAt, &code.CreatedAt,
+		&code.UpdatedAt)
+	return code, err
+}
+
+// scanReferralGraphReferral scans row of referralGraphReferralColumns into referral
+// Referral code is nil once code is deleted, it is scanned as zero id
+func scanReferralGraphReferral(rows pgx.Rows) (models.Referral, error) {
+	var referral models.Referral
+	var codeID *int
+	err := rows.Scan(&referral.ID, &referral.Email, &codeID, &referral.ReferrerID, &referral.UserID,
+		&referral.CampaignID, &referral.Status, &referral.StatusChangedAt, &referral.CreatedAt)
+	if codeID != nil {
+		referral.ReferralCodeID = *codeID
+	}
+	return referral, err
+}
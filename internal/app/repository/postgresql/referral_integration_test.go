@@ -0,0 +1,107 @@
+//go:build integration
+
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+// signUpConcurrently creates referrals of signUps new users with codes concurrently and returns their emails
+// with errors of Create, the i-th sign-up uses codeIDs[i % len(codeIDs)]
+func signUpConcurrently(repo *ReferralPostgres, referrerID int, codeIDs []int, signUps int) ([]string, []error) {
+	emails := make([]string, signUps)
+	errs := make([]error, signUps)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range emails {
+		emails[i] = testName("referee") + "@example.com"
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			referral := models.Referral{Email: emails[i], ReferralCodeID: codeIDs[i%len(codeIDs)], ReferrerID: referrerID}
+			_, errs[i] = repo.Create(referral, models.User{Email: emails[i], Password: "hash"})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	return emails, errs
+}
+
+// checkSingleSignUp checks that exactly one of concurrent sign-ups was accepted and the rest were rejected
+// without leaving their users behind
+func checkSingleSignUp(t *testing.T, db database.Database, emails []string, errs []error) {
+	t.Helper()
+
+	created := 0
+	var createdEmail string
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			created++
+			createdEmail = emails[i]
+		case !errors.Is(err, ErrReferralCodeNotActive):
+			t.Errorf("Create(%q) error = %v, want nil or %v", emails[i], err, ErrReferralCodeNotActive)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("%d concurrent sign-ups accepted, want 1", created)
+	}
+
+	// Only user of accepted sign-up exists, rejected sign-ups may be retried with the same email
+	var users []string
+	rows, err := db.GetPool().Query(context.Background(), `SELECT email FROM users WHERE email = ANY($1)`, emails)
+	if err != nil {
+		t.Fatalf("query users error = %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var email string
+		if err = rows.Scan(&email); err != nil {
+			t.Fatalf("scan user error = %v", err)
+		}
+		users = append(users, email)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatalf("rows error = %v", err)
+	}
+	if len(users) != 1 || users[0] != createdEmail {
+		t.Errorf("users = %v, want only %q", users, createdEmail)
+	}
+}
+
+func TestReferralCreateLastUseConcurrently(t *testing.T) {
+	db := testDatabase(t)
+	repo := NewReferralPostgres(db, testLogger())
+
+	referrerID := testUser(t, db)
+	codeID := testReferralCode(t, db, referrerID, 1)
+
+	emails, errs := signUpConcurrently(repo, referrerID, []int{codeID}, 8)
+	checkSingleSignUp(t, db, emails, errs)
+}
+
+func TestReferralCreateLastCampaignParticipantConcurrently(t *testing.T) {
+	db := testDatabase(t)
+	repo := NewReferralPostgres(db, testLogger())
+
+	// Codes have no limits of their own, only campaign they share is limited
+	referrerID := testUser(t, db)
+	campaignID := testCampaign(t, db, 1)
+	codeIDs := make([]int, 4)
+	for i := range codeIDs {
+		codeIDs[i] = testReferralCode(t, db, referrerID, 0)
+		testExec(t, db, `UPDATE referral_codes SET campaign_id = $1 WHERE id = $2 RETURNING id`, campaignID, codeIDs[i])
+	}
+
+	emails, errs := signUpConcurrently(repo, referrerID, codeIDs, 8)
+	checkSingleSignUp(t, db, emails, errs)
+}
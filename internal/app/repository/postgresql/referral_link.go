@@ -0,0 +1,146 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+// ReferralLinkPostgres implements the ReferralLinkRepo interface for PostgreSQL database operations
+// related to referral links, which group referral codes across rotations
+type ReferralLinkPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewReferralLinkPostgres creates new ReferralLinkPostgres instance with provided database connection and logger
+func NewReferralLinkPostgres(db database.Database, logger *logrus.Logger) *ReferralLinkPostgres {
+	return &ReferralLinkPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetByReferrerID retrieves referral links of referrer from newest to oldest
+// Each link contains its codes in order of rotation and referrals and clicks counts of all its codes
+func (r *ReferralLinkPostgres) GetByReferrerID(referrerID int) ([]models.ReferralLink, error) {
+	r.logger.Debugf("GetByReferrerID[repo]: Получение реферальных ссылок для реферера с id: %d", referrerID)
+
+	linksQuery := `SELECT l.id, l.referrer_id, l.created_at,
+                   (SELECT COUNT(*) FROM referrals rf JOIN referral_codes rc ON rc.id = rf.referral_code_id
+                    WHERE rc.link_id = l.id),
+                   (SELECT COUNT(*) FROM referral_clicks c JOIN referral_codes rc ON rc.id = c.referral_code_id
+                    WHERE rc.link_id = l.id)
+                   FROM referral_links l WHERE l.referrer_id = $1 ORDER BY l.id DESC`
+	codesQuery := `SELECT rc.id, rc.code, rc.expires_at, rc.referrer_id, rc.paused_at, rc.max_uses,
+                   (SELECT COUNT(*) FROM referrals WHERE referral_code_id = rc.id), rc.campaign_id,
+                   rc.link_id, rc.superseded_by, rc.rotated_at, rc.created_at, rc.updated_at
+                   FROM referral_codes rc JOIN referral_links l ON l.id = rc.link_id
+                   WHERE l.referrer_id = $1 ORDER BY rc.id`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	// Use a channel to get links from goroutine
+	linksChan := make(chan []models.ReferralLink)
+
+	go func() {
+		// Begin transaction, repeatable read keeps links and codes consistent with each other
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
+		if err != nil {
+			r.logger.Errorf("GetByReferrerID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, linksQuery, referrerID)
+		if err != nil {
+			r.logger.Errorf("GetByReferrerID[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+
+		var links []models.ReferralLink
+		indexByID := make(map[int]int)
+		for rows.Next() {
+			link := models.ReferralLink{Codes: []models.ReferralCode{}}
+			err = rows.Scan(&link.ID, &link.ReferrerID, &link.CreatedAt, &link.ReferralsCount, &link.ClicksCount)
+			if err != nil {
+				rows.Close()
+				r.logger.Errorf("GetByReferrerID[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			indexByID[link.ID] = len(links)
+			links = append(links, link)
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetByReferrerID[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		rows, err = tx.Query(ctx, codesQuery, referrerID)
+		if err != nil {
+			r.logger.Errorf("GetByReferrerID[repo]: Ошибка при получении кодов ссылок: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var code models.ReferralCode
+			err = rows.Scan(&code.ID, &code.Code, &code.Expiration, &code.ReferrerID, &code.PausedAt,
+				&code.MaxUses, &code.UsesCount, &code.CampaignID, &code.LinkID, &code.SupersededBy,
+				&code.RotatedAt, &code.CreatedAt, &code.UpdatedAt)
+			if err != nil {
+				r.logger.Errorf("GetByReferrerID[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+
+			if i, ok := indexByID[*code.LinkID]; ok {
+				links[i].Codes = append(links[i].Codes, code)
+			}
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetByReferrerID[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetByReferrerID[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		linksChan <- links
+	}()
+
+	select {
+	case links := <-linksChan:
+		r.logger.Infof("GetByReferrerID[repo]: Получено %d реферальных ссылок для реферера с id: %d",
+			len(links), referrerID)
+		return links, nil
+	case err := <-errChan:
+		return nil, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetByReferrerID[repo]: Время ожидания превышено для реферера с id: %d", referrerID)
+		return nil, ctx.Err()
+	}
+}
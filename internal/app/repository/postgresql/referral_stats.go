@@ -0,0 +1,206 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+// In statistics queries $1 is referrer id or NULL for whole program, [$2, $3) is time range,
+// $4 is granularity accepted by date_trunc and $5 is time zone of periods
+
+// referralStatsTotalsQuery counts clicks and referrals signed up in range by current status
+const referralStatsTotalsQuery = `SELECT
+    (SELECT COUNT(*) FROM referral_clicks k JOIN referral_codes rc ON rc.id = k.referral_code_id
+     WHERE ($1::int IS NULL OR rc.referrer_id = $1) AND k.clicked_at >= $2 AND k.clicked_at < $3),
+    COUNT(*),
+    COUNT(*) FILTER (WHERE r.status = 'pending'),
+    COUNT(*) FILTER (WHERE r.status = 'qualified'),
+    COUNT(*) FILTER (WHERE r.status = 'rejected'),
+    COUNT(*) FILTER (WHERE r.status = 'reversed')
+    FROM referrals r
+    WHERE ($1::int IS NULL OR r.referrer_id = $1) AND r.created_at >= $2 AND r.created_at < $3`
+
+// referralStatsSeriesQuery counts clicks, sign-ups and qualifications per period, empty periods included
+const referralStatsSeriesQuery = `WITH periods AS (
+        SELECT generate_series(date_trunc($4, $2::timestamptz AT TIME ZONE $5),
+                               $3::timestamptz AT TIME ZONE $5 - INTERVAL '1 microsecond',
+                               ('1 ' || $4)::interval) AS period
+    ), clicks AS (
+        SELECT date_trunc($4, k.clicked_at AT TIME ZONE $5) AS period, COUNT(*) AS count
+        FROM referral_clicks k JOIN referral_codes rc ON rc.id = k.referral_code_id
+        WHERE ($1::int IS NULL OR rc.referrer_id = $1) AND k.clicked_at >= $2 AND k.clicked_at < $3
+        GROUP BY 1
+    ), sign_ups AS (
+        SELECT date_trunc($4, r.created_at AT TIME ZONE $5) AS period, COUNT(*) AS count
+        FROM referrals r
+        WHERE ($1::int IS NULL OR r.referrer_id = $1) AND r.created_at >= $2 AND r.created_at < $3
+        GROUP BY 1
+    ), qualified AS (
+        SELECT date_trunc($4, r.status_changed_at AT TIME ZONE $5) AS period, COUNT(*) AS count
+        FROM referrals r
+        WHERE ($1::int IS NULL OR r.referrer_id = $1) AND r.status = 'qualified'
+          AND r.status_changed_at >= $2 AND r.status_changed_at < $3
+        GROUP BY 1
+    )
+    SELECT p.period AT TIME ZONE $5, COALESCE(c.count, 0), COALESCE(s.count, 0), COALESCE(q.count, 0)
+    FROM periods p
+    LEFT JOIN clicks c ON c.period = p.period
+    LEFT JOIN sign_ups s ON s.period = p.period
+    LEFT JOIN qualified q ON q.period = p.period
+    ORDER BY p.period`
+
+// referralStatsCodesQuery breaks clicks and sign-ups in range down by referral code
+// For whole program only codes with activity in range are listed
+const referralStatsCodesQuery = `SELECT id, code, campaign_id, clicks, sign_ups, qualified FROM (
+        SELECT rc.id, rc.code, rc.campaign_id,
+               (SELECT COUNT(*) FROM referral_clicks k
+                WHERE k.referral_code_id = rc.id AND k.clicked_at >= $2 AND k.clicked_at < $3) AS clicks,
+               COUNT(r.id) AS sign_ups,
+               COUNT(r.id) FILTER (WHERE r.status = 'qualified') AS qualified
+        FROM referral_codes rc
+        LEFT JOIN referrals r ON r.referral_code_id = rc.id AND r.created_at >= $2 AND r.created_at < $3
+        WHERE $1::int IS NULL OR rc.referrer_id = $1
+        GROUP BY rc.id
+    ) s
+    WHERE $1::int IS NOT NULL OR s.clicks > 0 OR s.sign_ups > 0
+    ORDER BY id`
+
+// ReferralStatsPostgres implements the ReferralStatsRepo interface for PostgreSQL aggregates over referrals
+type ReferralStatsPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewReferralStatsPostgres creates new ReferralStatsPostgres instance with provided database connection and logger
+func NewReferralStatsPostgres(db database.Database, logger *logrus.Logger) *ReferralStatsPostgres {
+	return &ReferralStatsPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetReferralStats computes totals, time series with given granularity and per-code breakdown
+// of clicks and referrals in [from, to), periods start in given time zone
+// Nil referrerID computes statistics of whole program, conversion rates are left to caller
+func (r *ReferralStatsPostgres) GetReferralStats(referrerID *int, from, to time.Time, granularity,
+	timeZone string) (models.ReferralStatsResponse, error) {
+	r.logger.Debugf("GetReferralStats[repo]: Получение статистики рефералов с %s по %s", from, to)
+
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get stats from goroutine
+	statsChan := make(chan models.ReferralStatsResponse)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction, repeatable read keeps totals, series and codes consistent with each other
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead,
+			AccessMode: pgx.ReadOnly})
+		if err != nil {
+			r.logger.Errorf("GetReferralStats[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		stats := models.ReferralStatsResponse{
+			ReferrerID:  referrerID,
+			From:        from,
+			To:          to,
+			Granularity: granularity,
+			Series:      []models.ReferralStatsPoint{},
+			Codes:       []models.ReferralCodeStats{},
+		}
+
+		totals := &stats.Totals
+		err = tx.QueryRow(ctx, referralStatsTotalsQuery, referrerID, from, to).Scan(&totals.Clicks, &totals.SignUps,
+			&totals.Pending, &totals.Qualified, &totals.Rejected, &totals.Reversed)
+		if err != nil {
+			r.logger.Errorf("GetReferralStats[repo]: Ошибка при получении итогов: %s", err)
+			errChan <- err
+			return
+		}
+
+		rows, err := tx.Query(ctx, referralStatsSeriesQuery, referrerID, from, to, granularity, timeZone)
+		if err != nil {
+			r.logger.Errorf("GetReferralStats[repo]: Ошибка при получении временного ряда: %s", err)
+			errChan <- err
+			return
+		}
+
+		for rows.Next() {
+			var point models.ReferralStatsPoint
+			if err = rows.Scan(&point.PeriodStart, &point.Clicks, &point.SignUps, &point.Qualified); err != nil {
+				rows.Close()
+				r.logger.Errorf("GetReferralStats[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			stats.Series = append(stats.Series, point)
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetReferralStats[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		rows, err = tx.Query(ctx, referralStatsCodesQuery, referrerID, from, to)
+		if err != nil {
+			r.logger.Errorf("GetReferralStats[repo]: Ошибка при получении статистики по кодам: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var code models.ReferralCodeStats
+			err = rows.Scan(&code.ReferralCodeID, &code.Code, &code.CampaignID, &code.Clicks, &code.SignUps,
+				&code.Qualified)
+			if err != nil {
+				r.logger.Errorf("GetReferralStats[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			stats.Codes = append(stats.Codes, code)
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetReferralStats[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetReferralStats[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		statsChan <- stats
+	}()
+
+	select {
+	case stats := <-statsChan:
+		r.logger.Infof("GetReferralStats[repo]: Статистика рефералов получена")
+		return stats, nil
+	case err := <-errChan:
+		return models.ReferralStatsResponse{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetReferralStats[repo]: Время ожидания превышено")
+		return models.ReferralStatsResponse{}, ctx.Err()
+	}
+}
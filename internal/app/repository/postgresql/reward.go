@@ -0,0 +1,648 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+var ErrRewardTransactionUnbalanced = errors.New("сумма записей транзакции вознаграждения не равна нулю")
+
+// rewardBalanceQuery sums all entries of user's account
+const rewardBalanceQuery = `SELECT COALESCE(SUM(e.amount), 0) FROM reward_entries e
+                            JOIN reward_accounts a ON a.id = e.account_id WHERE a.user_id = $1`
+
+// heldRewardsQuery sums rewards of user from referrals whose status changed after $2,
+// i.e. referrals still inside chargeback window
+const heldRewardsQuery = `SELECT COALESCE(SUM(e.amount), 0) FROM reward_entries e
+                          JOIN reward_accounts a ON a.id = e.account_id
+                          JOIN reward_transactions t ON t.id = e.transaction_id
+                          JOIN referrals rf ON rf.id = t.referral_id
+                          WHERE a.user_id = $1 AND COALESCE(rf.status_changed_at, rf.created_at) > $2`
+
+// earnedSinceQuery sums what user $1 earned from referrals as one of parties $3 since $2, reversals are subtracted
+// Party of entry is not stored, user is referrer or referee of referral of transaction, otherwise upline
+const earnedSinceQuery = `SELECT COALESCE(SUM(e.amount), 0) FROM reward_entries e
+                          JOIN reward_accounts a ON a.id = e.account_id
+                          JOIN reward_transactions t ON t.id = e.transaction_id
+                          JOIN referrals rf ON rf.id = t.referral_id
+                          WHERE a.user_id = $1 AND t.created_at >= $2
+                          AND CASE a.user_id WHEN rf.referrer_id THEN 'referrer' WHEN rf.user_id THEN 'referee'
+                              ELSE 'upline' END = ANY($3)`
+
+// RewardPostgres implements the RewardRepo interface for PostgreSQL database operations related to rewards ledger
+type RewardPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewRewardPostgres creates new RewardPostgres instance with provided database connection and logger
+func NewRewardPostgres(db database.Database, logger *logrus.Logger) *RewardPostgres {
+	return &RewardPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetReferralParties retrieves referrer, referred user, up to uplineDepth referrers above referrer,
+// campaign reward rules and referral number of referral
+// If referral not found, returns ErrReferralNotFound
+func (r *RewardPostgres) GetReferralParties(referralID, uplineDepth int) (models.ReferralParties, error) {
+	r.logger.Debugf("GetReferralParties[repo]: Получение участников реферала с id: %d", referralID)
+
+	query := `SELECT rf.id, rf.referrer_id, rf.user_id, rc.campaign_id, c.reward_rules,
+              (SELECT COUNT(*) FROM referrals p WHERE p.referrer_id = rf.referrer_id AND p.id <= rf.id
+               AND p.status NOT IN ('rejected', 'reversed')),
+              COALESCE(rf.review_status, ''),
+              ARRAY(
+                  WITH RECURSIVE upline (user_id, level, path) AS (
+                      SELECT p.referrer_id, 1, ARRAY[rf.referrer_id, p.referrer_id]
+                      FROM referrals p
+                      WHERE p.user_id = rf.referrer_id AND $2 > 0
+                      UNION ALL
+                      SELECT p.referrer_id, u.level + 1, u.path || p.referrer_id
+                      FROM referrals p JOIN upline u ON p.user_id = u.user_id
+                      WHERE u.level < $2 AND NOT p.referrer_id = ANY(u.path)
+                  )
+                  SELECT user_id FROM upline ORDER BY level
+              )
+              FROM referrals rf
+              LEFT JOIN referral_codes rc ON rc.id = rf.referral_code_id
+              LEFT JOIN campaigns c ON c.id = rc.campaign_id
+              WHERE rf.id = $1`
+	var parties models.ReferralParties
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get parties from goroutine
+	partiesChan := make(chan models.ReferralParties)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetReferralParties[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		err = tx.QueryRow(ctx, query, referralID, uplineDepth).Scan(&parties.ReferralID, &parties.ReferrerID,
+			&parties.RefereeID, &parties.CampaignID, &parties.RewardRules, &parties.ReferralNumber, &parties.ReviewStatus,
+			&parties.Upline)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Warnf("GetReferralParties[repo]: Реферал с id: %d не найден", referralID)
+				errChan <- ErrReferralNotFound
+				return
+			}
+
+			r.logger.Errorf("GetReferralParties[repo]: Ошибка при получении реферала с id: %d: %s", referralID, err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetReferralParties[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		partiesChan <- parties
+	}()
+
+	select {
+	case found := <-partiesChan:
+		return found, nil
+	case err := <-errChan:
+		return models.ReferralParties{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetReferralParties[repo]: Время ожидания превышено для реферала с id: %d", referralID)
+		return models.ReferralParties{}, ctx.Err()
+	}
+}
+
+// Post records balanced transaction with its entries, accounts of users are opened on first entry
+// Entry without UserID goes to program account. Entry with caps is reduced to what is left of them under lock
+// of user's account, so concurrent transactions never exceed caps together, and program entry is reduced as much.
+// It returns false without changes if transaction with the same idempotency key was already posted
+func (r *RewardPostgres) Post(transaction models.RewardTransaction) (bool, error) {
+	r.logger.Debugf("Post[repo]: Проведение транзакции вознаграждения %s", transaction.IdempotencyKey)
+
+	transactionQuery := `INSERT INTO reward_transactions (kind, referral_id, idempotency_key, created_at)
+                         VALUES ($1, $2, $3, NOW())
+                         ON CONFLICT (idempotency_key) DO NOTHING
+                         RETURNING id`
+	openAccountQuery := `INSERT INTO reward_accounts (user_id, kind, created_at) VALUES ($1, 'user', NOW())
+                         ON CONFLICT (user_id) DO NOTHING`
+	userAccountQuery := `SELECT id FROM reward_accounts WHERE user_id = $1`
+	lockAccountQuery := `SELECT id FROM reward_accounts WHERE user_id = $1 FOR UPDATE`
+	programAccountQuery := `SELECT id FROM reward_accounts WHERE kind = 'program'`
+	entryQuery := `INSERT INTO reward_entries (transaction_id, account_id, amount, created_at)
+                   VALUES ($1, $2, $3, NOW())`
+	ctx := context.Background()
+
+	var sum int64
+	for _, entry := range transaction.Entries {
+		sum += entry.Amount
+	}
+	if sum != 0 {
+		r.logger.Errorf("Post[repo]: Транзакция %s не сбалансирована: %d", transaction.IdempotencyKey, sum)
+		return false, ErrRewardTransactionUnbalanced
+	}
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get result from goroutine
+	postedChan := make(chan bool)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Post[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		err = tx.QueryRow(ctx, transactionQuery, transaction.Kind, transaction.ReferralID,
+			transaction.IdempotencyKey).Scan(&transaction.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Infof("Post[repo]: Транзакция %s уже проведена", transaction.IdempotencyKey)
+			postedChan <- false
+			return
+		}
+		if err != nil {
+			r.logger.Errorf("Post[repo]: Ошибка создания транзакции %s: %s", transaction.IdempotencyKey, err)
+			errChan <- err
+			return
+		}
+
+		entries, err := capRewardEntries(ctx, tx, transaction.Entries, openAccountQuery, lockAccountQuery)
+		if err != nil {
+			r.logger.Errorf("Post[repo]: Ошибка проверки лимитов транзакции %s: %s", transaction.IdempotencyKey, err)
+			errChan <- err
+			return
+		}
+
+		for _, entry := range entries {
+			if entry.Amount == 0 {
+				continue
+			}
+
+			var accountID int
+			if entry.UserID == nil {
+				err = tx.QueryRow(ctx, programAccountQuery).Scan(&accountID)
+			} else {
+				if _, err = tx.Exec(ctx, openAccountQuery, *entry.UserID); err == nil {
+					err = tx.QueryRow(ctx, userAccountQuery, *entry.UserID).Scan(&accountID)
+				}
+			}
+			if err != nil {
+				r.logger.Errorf("Post[repo]: Ошибка получения счета: %s", err)
+				errChan <- err
+				return
+			}
+
+			if _, err = tx.Exec(ctx, entryQuery, transaction.ID, accountID, entry.Amount); err != nil {
+				r.logger.Errorf("Post[repo]: Ошибка создания записи транзакции %s: %s", transaction.IdempotencyKey, err)
+				errChan <- err
+				return
+			}
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Post[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		postedChan <- true
+	}()
+
+	select {
+	case posted := <-postedChan:
+		if posted {
+			r.logger.Infof("Post[repo]: Транзакция %s проведена", transaction.IdempotencyKey)
+		}
+		return posted, nil
+	case err := <-errChan:
+		return false, err
+	case <-ctx.Done():
+		r.logger.Errorf("Post[repo]: Время ожидания превышено для транзакции %s", transaction.IdempotencyKey)
+		return false, ctx.Err()
+	}
+}
+
+// capRewardEntries locks accounts of users whose entries have caps and reduces these entries to what is left
+// of caps, program entries are reduced by the same amount. Accounts are locked in order of users,
+// so concurrent transactions do not deadlock
+func capRewardEntries(ctx context.Context, tx pgx.Tx, entries []models.RewardEntry, openAccountQuery,
+	lockAccountQuery string) ([]models.RewardEntry, error) {
+	var userIDs []int
+	for _, entry := range entries {
+		if entry.UserID != nil && len(entry.Caps) > 0 {
+			userIDs = append(userIDs, *entry.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return entries, nil
+	}
+	sort.Ints(userIDs)
+
+	for _, userID := range userIDs {
+		if _, err := tx.Exec(ctx, openAccountQuery, userID); err != nil {
+			return nil, err
+		}
+		var accountID int
+		if err := tx.QueryRow(ctx, lockAccountQuery, userID).Scan(&accountID); err != nil {
+			return nil, err
+		}
+	}
+
+	capped := make([]models.RewardEntry, len(entries))
+	copy(capped, entries)
+
+	var reduced int64
+	for i := range capped {
+		entry := &capped[i]
+		if entry.UserID == nil || entry.Amount <= 0 {
+			continue
+		}
+
+		for _, entryCap := range entry.Caps {
+			var earned int64
+			err := tx.QueryRow(ctx, earnedSinceQuery, *entry.UserID, entryCap.Since, rewardCapParties(entryCap.Party)).
+				Scan(&earned)
+			if err != nil {
+				return nil, err
+			}
+
+			remaining := entryCap.Amount - earned
+			if remaining < 0 {
+				remaining = 0
+			}
+			if entry.Amount > remaining {
+				reduced += entry.Amount - remaining
+				entry.Amount = remaining
+			}
+		}
+	}
+
+	// Program account funds rewards with negative entries, they give back what capped entries lost
+	for i := range capped {
+		if reduced == 0 {
+			break
+		}
+		if capped[i].UserID != nil || capped[i].Amount >= 0 {
+			continue
+		}
+
+		back := -capped[i].Amount
+		if back > reduced {
+			back = reduced
+		}
+		capped[i].Amount += back
+		reduced -= back
+	}
+	if reduced != 0 {
+		return nil, ErrRewardTransactionUnbalanced
+	}
+
+	return capped, nil
+}
+
+// ReverseByReferralID posts reversal transaction with negated entries for every not yet reversed
+// transaction of referral and returns number of reversed transactions
+func (r *RewardPostgres) ReverseByReferralID(referralID int) (int, error) {
+	r.logger.Debugf("ReverseByReferralID[repo]: Сторнирование вознаграждений реферала с id: %d", referralID)
+
+	originalsQuery := `SELECT t.id FROM reward_transactions t
+                       WHERE t.referral_id = $1 AND t.reverses_id IS NULL
+                       AND NOT EXISTS (SELECT 1 FROM reward_transactions rv WHERE rv.reverses_id = t.id)
+                       ORDER BY t.id`
+	reversalQuery := `INSERT INTO reward_transactions (kind, referral_id, idempotency_key, reverses_id, created_at)
+                      VALUES ($1, $2, $3, $4, NOW())
+                      ON CONFLICT DO NOTHING
+                      RETURNING id`
+	entriesQuery := `INSERT INTO reward_entries (transaction_id, account_id, amount, created_at)
+                     SELECT $1, account_id, -amount, NOW() FROM reward_entries WHERE transaction_id = $2`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get number of reversed transactions from goroutine
+	countChan := make(chan int)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("ReverseByReferralID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, originalsQuery, referralID)
+		if err != nil {
+			r.logger.Errorf("ReverseByReferralID[repo]: Ошибка при получении транзакций: %s", err)
+			errChan <- err
+			return
+		}
+
+		var originals []int
+		for rows.Next() {
+			var id int
+			if err = rows.Scan(&id); err != nil {
+				rows.Close()
+				r.logger.Errorf("ReverseByReferralID[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			originals = append(originals, id)
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("ReverseByReferralID[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		reversed := 0
+		for _, originalID := range originals {
+			var reversalID int
+			err = tx.QueryRow(ctx, reversalQuery, models.RewardKindReversal, referralID,
+				fmt.Sprintf("reversal:%d", originalID), originalID).Scan(&reversalID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Reversed concurrently
+				continue
+			}
+			if err != nil {
+				r.logger.Errorf("ReverseByReferralID[repo]: Ошибка сторнирования транзакции с id: %d: %s", originalID, err)
+				errChan <- err
+				return
+			}
+
+			if _, err = tx.Exec(ctx, entriesQuery, reversalID, originalID); err != nil {
+				r.logger.Errorf("ReverseByReferralID[repo]: Ошибка создания записей сторно: %s", err)
+				errChan <- err
+				return
+			}
+			reversed++
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("ReverseByReferralID[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		countChan <- reversed
+	}()
+
+	select {
+	case count := <-countChan:
+		r.logger.Infof("ReverseByReferralID[repo]: Сторнировано %d транзакций реферала с id: %d", count, referralID)
+		return count, nil
+	case err := <-errChan:
+		return 0, err
+	case <-ctx.Done():
+		r.logger.Errorf("ReverseByReferralID[repo]: Время ожидания превышено для реферала с id: %d", referralID)
+		return 0, ctx.Err()
+	}
+}
+
+// GetRewardsByUserID retrieves balance of user's account and its entries from newest to oldest
+// User without account has zero balance and empty history
+func (r *RewardPostgres) GetRewardsByUserID(userID int) (models.RewardsResponse, error) {
+	r.logger.Debugf("GetRewardsByUserID[repo]: Получение вознаграждений пользователя с id: %d", userID)
+
+	historyQuery := `SELECT e.id, t.id, t.kind, t.referral_id, e.amount, e.created_at
+                     FROM reward_entries e
+                     JOIN reward_accounts a ON a.id = e.account_id
+                     JOIN reward_transactions t ON t.id = e.transaction_id
+                     WHERE a.user_id = $1 ORDER BY e.id DESC`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get rewards from goroutine
+	rewardsChan := make(chan models.RewardsResponse)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction, repeatable read keeps balance consistent with history
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
+		if err != nil {
+			r.logger.Errorf("GetRewardsByUserID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rewards := models.RewardsResponse{History: []models.RewardEntry{}}
+		if err = tx.QueryRow(ctx, rewardBalanceQuery, userID).Scan(&rewards.Balance); err != nil {
+			r.logger.Errorf("GetRewardsByUserID[repo]: Ошибка при получении баланса: %s", err)
+			errChan <- err
+			return
+		}
+
+		rows, err := tx.Query(ctx, historyQuery, userID)
+		if err != nil {
+			r.logger.Errorf("GetRewardsByUserID[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var entry models.RewardEntry
+			err = rows.Scan(&entry.ID, &entry.TransactionID, &entry.Kind, &entry.ReferralID, &entry.Amount,
+				&entry.CreatedAt)
+			if err != nil {
+				r.logger.Errorf("GetRewardsByUserID[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			rewards.History = append(rewards.History, entry)
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetRewardsByUserID[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetRewardsByUserID[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		rewardsChan <- rewards
+	}()
+
+	select {
+	case rewards := <-rewardsChan:
+		r.logger.Infof("GetRewardsByUserID[repo]: Вознаграждения пользователя с id: %d получены", userID)
+		return rewards, nil
+	case err := <-errChan:
+		return models.RewardsResponse{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetRewardsByUserID[repo]: Время ожидания превышено для пользователя с id: %d", userID)
+		return models.RewardsResponse{}, ctx.Err()
+	}
+}
+
+// GetEarnedSince sums what user earned from referrals as party since given moment, reversals are subtracted
+// Empty party sums what user earned as referrer and as referee, rewards of upline are never limited by caps
+// Sum is only estimate of caps for dry run and evaluation, Post checks caps again under lock of account
+func (r *RewardPostgres) GetEarnedSince(userID int, party string, since time.Time) (int64, error) {
+	r.logger.Debugf("GetEarnedSince[repo]: Получение заработка пользователя с id: %d с %s", userID, since)
+
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get earned amount from goroutine
+	earnedChan := make(chan int64)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetEarnedSince[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		var earned int64
+		if err = tx.QueryRow(ctx, earnedSinceQuery, userID, since, rewardCapParties(party)).Scan(&earned); err != nil {
+			r.logger.Errorf("GetEarnedSince[repo]: Ошибка при получении заработка: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetEarnedSince[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		earnedChan <- earned
+	}()
+
+	select {
+	case earned := <-earnedChan:
+		return earned, nil
+	case err := <-errChan:
+		return 0, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetEarnedSince[repo]: Время ожидания превышено для пользователя с id: %d", userID)
+		return 0, ctx.Err()
+	}
+}
+
+// GetHeldByUserID sums rewards of user from referrals whose status changed after since
+func (r *RewardPostgres) GetHeldByUserID(userID int, since time.Time) (int64, error) {
+	r.logger.Debugf("GetHeldByUserID[repo]: Получение удерживаемых вознаграждений пользователя с id: %d", userID)
+
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get held amount from goroutine
+	heldChan := make(chan int64)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetHeldByUserID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		var held int64
+		if err = tx.QueryRow(ctx, heldRewardsQuery, userID, since).Scan(&held); err != nil {
+			r.logger.Errorf("GetHeldByUserID[repo]: Ошибка при получении удерживаемых вознаграждений: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetHeldByUserID[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		heldChan <- held
+	}()
+
+	select {
+	case held := <-heldChan:
+		return held, nil
+	case err := <-errChan:
+		return 0, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetHeldByUserID[repo]: Время ожидания превышено для пользователя с id: %d", userID)
+		return 0, ctx.Err()
+	}
+}
+
+// rewardCapParties returns parties whose rewards count towards cap of party, cap without party limits both
+// referrer and referee
+func rewardCapParties(party string) []string {
+	if party == "" {
+		return []string{models.RewardPartyReferrer, models.RewardPartyReferee}
+	}
+	return []string{party}
+}
@@ -0,0 +1,274 @@
+//go:build integration
+
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+// testCredit posts reward of referral to user funded by program account and returns what user was credited
+func testCredit(t *testing.T, repo *RewardPostgres, referralID, userID int, amount int64,
+	caps ...models.RewardEntryCap) int64 {
+	t.Helper()
+
+	before := testBalance(t, repo.db, userID)
+	posted, err := repo.Post(models.RewardTransaction{
+		Kind:           models.RewardKindReferralCreated,
+		ReferralID:     &referralID,
+		IdempotencyKey: testName("test:"),
+		Entries:        []models.RewardEntry{{UserID: &userID, Amount: amount, Caps: caps}, {Amount: -amount}},
+	})
+	if err != nil || !posted {
+		t.Fatalf("Post() = %v, %v, want true, nil", posted, err)
+	}
+	return testBalance(t, repo.db, userID) - before
+}
+
+func TestRewardCapCountsOnlyItsParty(t *testing.T) {
+	db := testDatabase(t)
+	repo := NewRewardPostgres(db, testLogger())
+
+	// User was referred by upper and referred referee in turn
+	upper, user, referee := testUser(t, db), testUser(t, db), testUser(t, db)
+	referredUser := testReferral(t, db, upper, user)
+	referredByUser := testReferral(t, db, user, referee)
+
+	if got := testCredit(t, repo, referredUser, user, 1000); got != 1000 {
+		t.Fatalf("referee reward credited %d, want 1000", got)
+	}
+
+	referrerCap := models.RewardEntryCap{Party: models.RewardPartyReferrer, Amount: 150}
+	tests := []struct {
+		name string
+		cap  models.RewardEntryCap
+		want int64
+	}{
+		{name: "rewards as referee do not count", cap: referrerCap, want: 100},
+		{name: "cap is clamped to what is left", cap: referrerCap, want: 50},
+		{name: "cap without party counts both parties", cap: models.RewardEntryCap{Amount: 1200}, want: 50},
+		{name: "referee cap is reached", cap: models.RewardEntryCap{Party: models.RewardPartyReferee, Amount: 1000},
+			want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testCredit(t, repo, referredByUser, user, 100, tt.cap); got != tt.want {
+				t.Errorf("credited %d with cap %+v, want %d", got, tt.cap, tt.want)
+			}
+		})
+	}
+
+	earned, err := repo.GetEarnedSince(user, models.RewardPartyReferrer, time.Time{})
+	if err != nil {
+		t.Fatalf("GetEarnedSince() error = %v", err)
+	}
+	if earned != 200 {
+		t.Errorf("GetEarnedSince(referrer) = %d, want 200", earned)
+	}
+}
+
+// testTransactionPostings returns entries of transaction with idempotency key by user, program account is user 0
+func testTransactionPostings(t *testing.T, db database.Database, key string) map[int]int64 {
+	t.Helper()
+
+	rows, err := db.GetPool().Query(context.Background(), `SELECT COALESCE(a.user_id, 0), e.amount
+	                                                       FROM reward_entries e
+	                                                       JOIN reward_accounts a ON a.id = e.account_id
+	                                                       JOIN reward_transactions t ON t.id = e.transaction_id
+	                                                       WHERE t.idempotency_key = $1`, key)
+	if err != nil {
+		t.Fatalf("postings query error = %v", err)
+	}
+	defer rows.Close()
+
+	postings := make(map[int]int64)
+	for rows.Next() {
+		var userID int
+		var amount int64
+		if err = rows.Scan(&userID, &amount); err != nil {
+			t.Fatalf("scan posting error = %v", err)
+		}
+		postings[userID] += amount
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatalf("rows error = %v", err)
+	}
+	return postings
+}
+
+func TestRewardPostRejectsUnbalancedTransaction(t *testing.T) {
+	db := testDatabase(t)
+	repo := NewRewardPostgres(db, testLogger())
+	user, other := testUser(t, db), testUser(t, db)
+
+	tests := []struct {
+		name    string
+		entries []models.RewardEntry
+	}{
+		{name: "entries do not sum up to zero", entries: []models.RewardEntry{{UserID: &user, Amount: 100},
+			{Amount: -90}}},
+		{name: "capped amount can not be given back to program account", entries: []models.RewardEntry{
+			{UserID: &user, Amount: 100, Caps: []models.RewardEntryCap{{Amount: 0}}}, {UserID: &other, Amount: -100}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := testName("test:")
+			_, err := repo.Post(models.RewardTransaction{Kind: models.RewardKindReferralCreated, IdempotencyKey: key,
+				Entries: tt.entries})
+			if !errors.Is(err, ErrRewardTransactionUnbalanced) {
+				t.Fatalf("Post() error = %v, want %v", err, ErrRewardTransactionUnbalanced)
+			}
+
+			// Nothing is left of rejected transaction, so it may be posted again once fixed
+			if got := testTransactionPostings(t, db, key); len(got) != 0 {
+				t.Errorf("postings = %v, want none", got)
+			}
+			var count int
+			err = db.GetPool().QueryRow(context.Background(),
+				`SELECT COUNT(*) FROM reward_transactions WHERE idempotency_key = $1`, key).Scan(&count)
+			if err != nil || count != 0 {
+				t.Errorf("transactions with key = %d, %v, want 0, nil", count, err)
+			}
+		})
+	}
+}
+
+func TestRewardPostReplayKeepsOriginal(t *testing.T) {
+	db := testDatabase(t)
+	repo := NewRewardPostgres(db, testLogger())
+	user := testUser(t, db)
+	key := testName("test:")
+
+	original := models.RewardTransaction{Kind: models.RewardKindReferralCreated, IdempotencyKey: key,
+		Entries: []models.RewardEntry{{UserID: &user, Amount: 100}, {Amount: -100}}}
+	if posted, err := repo.Post(original); err != nil || !posted {
+		t.Fatalf("Post() = %v, %v, want true, nil", posted, err)
+	}
+
+	// Replay with the same key is not posted, even if its amounts differ
+	replay := original
+	replay.Entries = []models.RewardEntry{{UserID: &user, Amount: 300}, {Amount: -300}}
+	if posted, err := repo.Post(replay); err != nil || posted {
+		t.Fatalf("Post() replay = %v, %v, want false, nil", posted, err)
+	}
+
+	want := map[int]int64{user: 100, 0: -100}
+	if got := testTransactionPostings(t, db, key); !reflect.DeepEqual(got, want) {
+		t.Errorf("postings = %v, want %v", got, want)
+	}
+	if got := testBalance(t, db, user); got != 100 {
+		t.Errorf("balance = %d, want 100", got)
+	}
+}
+
+func TestRewardReverseMirrorsEntries(t *testing.T) {
+	db := testDatabase(t)
+	repo := NewRewardPostgres(db, testLogger())
+	referrer, user, upper := testUser(t, db), testUser(t, db), testUser(t, db)
+	referralID := testReferral(t, db, referrer, user)
+
+	keys := []string{testName("test:"), testName("test:")}
+	transactions := [][]models.RewardEntry{
+		{{UserID: &referrer, Amount: 100}, {UserID: &user, Amount: 50}, {UserID: &upper, Amount: 10}, {Amount: -160}},
+		{{UserID: &referrer, Amount: 200}, {Amount: -200}},
+	}
+	for i, entries := range transactions {
+		posted, err := repo.Post(models.RewardTransaction{Kind: models.RewardKindReferralCreated,
+			ReferralID: &referralID, IdempotencyKey: keys[i], Entries: entries})
+		if err != nil || !posted {
+			t.Fatalf("Post() #%d = %v, %v, want true, nil", i+1, posted, err)
+		}
+	}
+
+	reversed, err := repo.ReverseByReferralID(referralID)
+	if err != nil || reversed != 2 {
+		t.Fatalf("ReverseByReferralID() = %d, %v, want 2, nil", reversed, err)
+	}
+
+	for _, key := range keys {
+		var reversalKey string
+		err = db.GetPool().QueryRow(context.Background(), `SELECT rv.idempotency_key FROM reward_transactions rv
+		                                                   JOIN reward_transactions t ON t.id = rv.reverses_id
+		                                                   WHERE t.idempotency_key = $1`, key).Scan(&reversalKey)
+		if err != nil {
+			t.Fatalf("reversal of %s error = %v", key, err)
+		}
+
+		original, reversal := testTransactionPostings(t, db, key), testTransactionPostings(t, db, reversalKey)
+		mirrored := make(map[int]int64, len(original))
+		for userID, amount := range original {
+			mirrored[userID] = -amount
+		}
+		if !reflect.DeepEqual(reversal, mirrored) {
+			t.Errorf("reversal of %s = %v, want %v", key, reversal, mirrored)
+		}
+	}
+
+	for _, userID := range []int{referrer, user, upper} {
+		if got := testBalance(t, db, userID); got != 0 {
+			t.Errorf("balance of user %d after reversal = %d, want 0", userID, got)
+		}
+	}
+
+	// Reversed transactions are not reversed again
+	if reversed, err = repo.ReverseByReferralID(referralID); err != nil || reversed != 0 {
+		t.Errorf("ReverseByReferralID() again = %d, %v, want 0, nil", reversed, err)
+	}
+}
+
+func TestRewardPostLocksAccountsInOrder(t *testing.T) {
+	db := testDatabase(t)
+	repo := NewRewardPostgres(db, testLogger())
+	first, second := testUser(t, db), testUser(t, db)
+	referralID := testReferral(t, db, first, second)
+
+	// Transactions list users in opposite orders, accounts locked in order of entries would deadlock
+	const posts = 20
+	capped := []models.RewardEntryCap{{Amount: 500}}
+	errs := make(chan error, posts)
+	var wg sync.WaitGroup
+	for i := 0; i < posts; i++ {
+		a, b := first, second
+		if i%2 == 1 {
+			a, b = second, first
+		}
+		transaction := models.RewardTransaction{
+			Kind:           models.RewardKindReferralCreated,
+			ReferralID:     &referralID,
+			IdempotencyKey: testName("test:"),
+			Entries: []models.RewardEntry{{UserID: &a, Amount: 100, Caps: capped},
+				{UserID: &b, Amount: 100, Caps: capped}, {Amount: -200}},
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Post(transaction)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Post() error = %v", err)
+		}
+	}
+
+	// Accounts are locked while caps are checked, so concurrent rewards do not exceed caps together
+	for _, userID := range []int{first, second} {
+		if got := testBalance(t, db, userID); got != 500 {
+			t.Errorf("balance of user %d = %d, want cap 500", userID, got)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+var ErrRewardRuleSetNotFound = errors.New("набор правил вознаграждений не найден")
+
+// rewardRuleSetColumns lists columns of reward rule set in order expected by scanRewardRuleSet
+const rewardRuleSetColumns = `id, name, campaign_id, active, format, source, rules, created_by, created_at, updated_at`
+
+// RewardRuleSetPostgres implements the RewardRuleSetRepo interface for PostgreSQL database operations
+// related to reward rule sets
+type RewardRuleSetPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewRewardRuleSetPostgres creates new RewardRuleSetPostgres instance with provided database connection and logger
+func NewRewardRuleSetPostgres(db database.Database, logger *logrus.Logger) *RewardRuleSetPostgres {
+	return &RewardRuleSetPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create inserts new rule set and returns it with generated id and timestamps
+// Active rule set deactivates previously active rule set of the same campaign in the same transaction
+func (r *RewardRuleSetPostgres) Create(ruleSet models.RewardRuleSet) (models.RewardRuleSet, error) {
+	r.logger.Debugf("Create[repo]: Создание набора правил вознаграждений: %s", ruleSet.Name)
+
+	query := `INSERT INTO reward_rule_sets (name, campaign_id, active, format, source, rules, created_by,
+              created_at, updated_at)
+              VALUES ($1, $2, FALSE, $3, $4, $5, $6, NOW(), NOW())
+              RETURNING id, created_at, updated_at`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get rule set from goroutine
+	ruleSetChan := make(chan models.RewardRuleSet)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		err = tx.QueryRow(ctx, query, ruleSet.Name, ruleSet.CampaignID, ruleSet.Format, ruleSet.Source,
+			ruleSet.Rules, ruleSet.CreatedBy).Scan(&ruleSet.ID, &ruleSet.CreatedAt, &ruleSet.UpdatedAt)
+		if err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка создания набора правил: %s", err)
+			errChan <- err
+			return
+		}
+
+		if ruleSet.Active {
+			if err = activateRewardRuleSet(ctx, tx, ruleSet.ID, ruleSet.CampaignID); err != nil {
+				r.logger.Errorf("Create[repo]: Ошибка активации набора правил с id: %d: %s", ruleSet.ID, err)
+				errChan <- err
+				return
+			}
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Create[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		ruleSetChan <- ruleSet
+	}()
+
+	select {
+	case created := <-ruleSetChan:
+		r.logger.Infof("Create[repo]: Набор правил с id: %d успешно создан", created.ID)
+		return created, nil
+	case err := <-errChan:
+		return models.RewardRuleSet{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("Create[repo]: Время ожидания превышено")
+		return models.RewardRuleSet{}, ctx.Err()
+	}
+}
+
+// Update replaces rule set with given id, active rule set deactivates previously active one of the same campaign
+// If rule set not found, returns ErrRewardRuleSetNotFound
+func (r *RewardRuleSetPostgres) Update(ruleSet models.RewardRuleSet) error {
+	r.logger.Debugf("Update[repo]: Изменение набора правил с id: %d", ruleSet.ID)
+
+	query := `UPDATE reward_rule_sets SET name = $2, campaign_id = $3, active = FALSE, format = $4, source = $5,
+              rules = $6, updated_at = NOW()
+              WHERE id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Update[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		result, err := tx.Exec(ctx, query, ruleSet.ID, ruleSet.Name, ruleSet.CampaignID, ruleSet.Format,
+			ruleSet.Source, ruleSet.Rules)
+		if err != nil {
+			r.logger.Errorf("Update[repo]: Ошибка изменения набора правил с id: %d: %s", ruleSet.ID, err)
+			errChan <- err
+			return
+		}
+
+		if result.RowsAffected() == 0 {
+			r.logger.Warnf("Update[repo]: Набор правил с id: %d не найден", ruleSet.ID)
+			errChan <- ErrRewardRuleSetNotFound
+			return
+		}
+
+		if ruleSet.Active {
+			if err = activateRewardRuleSet(ctx, tx, ruleSet.ID, ruleSet.CampaignID); err != nil {
+				r.logger.Errorf("Update[repo]: Ошибка активации набора правил с id: %d: %s", ruleSet.ID, err)
+				errChan <- err
+				return
+			}
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Update[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		r.logger.Infof("Update[repo]: Набор правил с id: %d успешно изменен", ruleSet.ID)
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		r.logger.Errorf("Update[repo]: Время ожидания превышено для набора правил с id: %d", ruleSet.ID)
+		return ctx.Err()
+	}
+}
+
+// Delete removes rule set by id
+// If rule set not found, returns ErrRewardRuleSetNotFound
+func (r *RewardRuleSetPostgres) Delete(id int) error {
+	r.logger.Debugf("Delete[repo]: Удаление набора правил с id: %d", id)
+
+	query := `DELETE FROM reward_rule_sets WHERE id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("Delete[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		result, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			r.logger.Errorf("Delete[repo]: Ошибка удаления набора правил с id: %d: %s", id, err)
+			errChan <- err
+			return
+		}
+
+		if result.RowsAffected() == 0 {
+			r.logger.Warnf("Delete[repo]: Набор правил с id: %d не найден для удаления", id)
+			errChan <- ErrRewardRuleSetNotFound
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("Delete[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		r.logger.Infof("Delete[repo]: Набор правил с id: %d успешно удален", id)
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		r.logger.Errorf("Delete[repo]: Время ожидания превышено для набора правил с id: %d", id)
+		return ctx.Err()
+	}
+}
+
+// GetByID retrieves rule set by id
+// If rule set not found, returns ErrRewardRuleSetNotFound
+func (r *RewardRuleSetPostgres) GetByID(id int) (models.RewardRuleSet, error) {
+	r.logger.Debugf("GetByID[repo]: Получение набора правил с id: %d", id)
+
+	query := `SELECT ` + rewardRuleSetColumns + ` FROM reward_rule_sets WHERE id = $1`
+
+	return r.getOne("GetByID", query, id)
+}
+
+// GetActive retrieves active rule set of campaign or, if campaign has none or campaignID is nil,
+// active global rule set
+// If there is no active rule set, returns ErrRewardRuleSetNotFound
+func (r *RewardRuleSetPostgres) GetActive(campaignID *int) (models.RewardRuleSet, error) {
+	r.logger.Debugf("GetActive[repo]: Получение активного набора правил")
+
+	query := `SELECT ` + rewardRuleSetColumns + ` FROM reward_rule_sets
+              WHERE active AND (campaign_id = $1 OR campaign_id IS NULL)
+              ORDER BY campaign_id NULLS LAST
+              LIMIT 1`
+
+	return r.getOne("GetActive", query, campaignID)
+}
+
+// GetAll retrieves all rule sets ordered from newest to oldest
+func (r *RewardRuleSetPostgres) GetAll() ([]models.RewardRuleSet, error) {
+	r.logger.Debugf("GetAll[repo]: Получение списка наборов правил")
+
+	query := `SELECT ` + rewardRuleSetColumns + ` FROM reward_rule_sets ORDER BY id DESC`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get rule sets from goroutine
+	ruleSetsChan := make(chan []models.RewardRuleSet)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("GetAll[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		rows, err := tx.Query(ctx, query)
+		if err != nil {
+			r.logger.Errorf("GetAll[repo]: Ошибка при выполнении запроса: %s", err)
+			errChan <- err
+			return
+		}
+		defer rows.Close()
+
+		var ruleSets []models.RewardRuleSet
+		for rows.Next() {
+			ruleSet, err := scanRewardRuleSet(rows)
+			if err != nil {
+				r.logger.Errorf("GetAll[repo]: Ошибка сканировании строки: %s", err)
+				errChan <- err
+				return
+			}
+			ruleSets = append(ruleSets, ruleSet)
+		}
+
+		if err = rows.Err(); err != nil {
+			r.logger.Errorf("GetAll[repo]: Ошибка после итерации по строкам: %s", err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("GetAll[repo]: Ошибка коммита транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		ruleSetsChan <- ruleSets
+	}()
+
+	select {
+	case ruleSets := <-ruleSetsChan:
+		r.logger.Infof("GetAll[repo]: Получено %d наборов правил", len(ruleSets))
+		return ruleSets, nil
+	case err := <-errChan:
+		return nil, err
+	case <-ctx.Done():
+		r.logger.Errorf("GetAll[repo]: Время ожидания превышено")
+		return nil, ctx.Err()
+	}
+}
+
+// getOne retrieves single rule set selected by query with given argument
+// If query returns no rows, returns ErrRewardRuleSetNotFound
+func (r *RewardRuleSetPostgres) getOne(method, query string, arg interface{}) (models.RewardRuleSet, error) {
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get rule set from goroutine
+	ruleSetChan := make(chan models.RewardRuleSet)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := r.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка начала транзакции: %s", method, err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		ruleSet, err := scanRewardRuleSet(tx.QueryRow(ctx, query, arg))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.logger.Infof("%s[repo]: Набор правил не найден", method)
+				errChan <- ErrRewardRuleSetNotFound
+				return
+			}
+
+			r.logger.Errorf("%s[repo]: Ошибка при получении набора правил: %s", method, err)
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			r.logger.Errorf("%s[repo]: Ошибка при коммите транзакции: %s", method, err)
+			errChan <- err
+			return
+		}
+
+		ruleSetChan <- ruleSet
+	}()
+
+	select {
+	case ruleSet := <-ruleSetChan:
+		return ruleSet, nil
+	case err := <-errChan:
+		return models.RewardRuleSet{}, err
+	case <-ctx.Done():
+		r.logger.Errorf("%s[repo]: Время ожидания превышено", method)
+		return models.RewardRuleSet{}, ctx.Err()
+	}
+}
+
+// activateRewardRuleSet deactivates active rule set of the same campaign and activates rule set with given id
+func activateRewardRuleSet(ctx context.Context, tx pgx.Tx, id int, campaignID *int) error {
+	deactivateQuery := `UPDATE reward_rule_sets SET active = FALSE, updated_at = NOW()
+                        WHERE active AND campaign_id IS NOT DISTINCT FROM $1 AND id <> $2`
+	activateQuery := `UPDATE reward_rule_sets SET active = TRUE WHERE id = $1`
+
+	if _, err := tx.Exec(ctx, deactivateQuery, campaignID, id); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, activateQuery, id)
+	return err
+}
+
+// scanRewardRuleSet scans row selected with rewardRuleSetColumns into rule set
+func scanRewardRuleSet(row pgx.Row) (models.RewardRuleSet, error) {
+	var ruleSet models.RewardRuleSet
+
+	err := row.Scan(&ruleSet.ID, &ruleSet.Name, &ruleSet.CampaignID, &ruleSet.Active, &ruleSet.Format,
+		&ruleSet.Source, &ruleSet.Rules, &ruleSet.CreatedBy, &ruleSet.CreatedAt, &ruleSet.UpdatedAt)
+	return ruleSet, err
+}
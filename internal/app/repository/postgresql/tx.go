@@ -0,0 +1,73 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/repository/database"
+)
+
+// withTx runs fn in its own transaction, which is committed if fn succeeds
+// Errors listed in expected are outcomes reported to caller, they are logged as warnings
+func withTx(db database.Database, logger *logrus.Logger, method string, fn func(ctx context.Context, tx pgx.Tx) error,
+	expected ...error) error {
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			logger.Errorf("%s[repo]: Ошибка начала транзакции: %s", method, err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		if err = fn(ctx, tx); err != nil {
+			if isExpected(err, expected) {
+				logger.Warnf("%s[repo]: %s", method, err)
+			} else {
+				logger.Errorf("%s[repo]: Ошибка при выполнении запроса: %s", method, err)
+			}
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			logger.Errorf("%s[repo]: Ошибка коммита транзакции: %s", method, err)
+			errChan <- err
+			return
+		}
+
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		logger.Errorf("%s[repo]: Время ожидания превышено", method)
+		return ctx.Err()
+	}
+}
+
+// isExpected reports whether err is one of expected errors
+func isExpected(err error, expected []error) bool {
+	for _, target := range expected {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -32,7 +32,8 @@ func NewUserPostgres(db database.Database, logger *logrus.Logger) *UserPostgres
 func (up *UserPostgres) GetByEmail(email string) (models.User, error) {
 	up.logger.Debugf("GetByEmail[repo]: Получение пользователя по email: %s", email)
 
-	query := `SELECT id, email, password, created_at FROM users WHERE email = $1`
+	query := `SELECT id, email, password, COALESCE(display_name, ''), is_admin, leaderboard_opt_in,
+              notification_mode, COALESCE(locale, ''), created_at FROM users WHERE email = $1`
 	var dbUser models.User
 	ctx := context.Background()
 
@@ -58,7 +59,8 @@ func (up *UserPostgres) GetByEmail(email string) (models.User, error) {
 
 		// Execute query and scan returned user into dbUser object
 		err = tx.QueryRow(ctx, query, email).
-			Scan(&dbUser.ID, &dbUser.Email, &dbUser.Password, &dbUser.CreatedAt)
+			Scan(&dbUser.ID, &dbUser.Email, &dbUser.Password, &dbUser.DisplayName, &dbUser.IsAdmin,
+				&dbUser.LeaderboardOptIn, &dbUser.NotificationMode, &dbUser.Locale, &dbUser.CreatedAt)
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				up.logger.Errorf("GetByEmail[repo]: Пользователь по email: %s не найден", email)
@@ -91,12 +93,78 @@ func (up *UserPostgres) GetByEmail(email string) (models.User, error) {
 	}
 }
 
+// GetByID retrieves user from the users table by id
+// Returns ErrUserNotFound if user does not exist
+func (up *UserPostgres) GetByID(id int) (models.User, error) {
+	up.logger.Debugf("GetByID[repo]: Получение пользователя по id: %d", id)
+
+	query := `SELECT id, email, password, COALESCE(display_name, ''), is_admin, leaderboard_opt_in,
+              notification_mode, COALESCE(locale, ''), created_at FROM users WHERE id = $1`
+	var dbUser models.User
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get user from goroutine
+	userChan := make(chan models.User)
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := up.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			up.logger.Errorf("GetByID[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		// Execute query and scan returned user into dbUser object
+		err = tx.QueryRow(ctx, query, id).
+			Scan(&dbUser.ID, &dbUser.Email, &dbUser.Password, &dbUser.DisplayName, &dbUser.IsAdmin,
+				&dbUser.LeaderboardOptIn, &dbUser.NotificationMode, &dbUser.Locale, &dbUser.CreatedAt)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				up.logger.Errorf("GetByID[repo]: Пользователь с id: %d не найден", id)
+				errChan <- ErrUserNotFound
+				return
+			}
+			errChan <- err
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			up.logger.Errorf("GetByID[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		userChan <- dbUser
+	}()
+
+	select {
+	case user := <-userChan:
+		up.logger.Infof("GetByID[repo]: Пользователь успешно получен по id: %d", id)
+		return user, nil
+	case err := <-errChan:
+		return models.User{}, err
+	case <-ctx.Done():
+		up.logger.Errorf("GetByID[repo]: Время ожидания превышено для пользователя с id: %d", id)
+		return models.User{}, ctx.Err()
+	}
+}
+
 // Create inserts new user into the users table and returns error if operation fails
 func (up *UserPostgres) Create(user models.User) error {
 	up.logger.Debugf("Create[repo]: Создание нового пользователя: %s", user.Email)
 
-	query := `INSERT INTO users (email, password, created_at) 
-	          VALUES ($1, $2, NOW()) RETURNING id, created_at`
+	query := `INSERT INTO users (email, password, display_name, created_at) 
+	          VALUES ($1, $2, NULLIF($3, ''), NOW()) RETURNING id, created_at`
 	ctx := context.Background()
 
 	// Create context with timeout to cancel query execution if it takes too long
@@ -117,7 +185,7 @@ func (up *UserPostgres) Create(user models.User) error {
 		defer tx.Rollback(ctx) // Rollback transaction if function returns error
 
 		// Execute query and scan returned ID and created_at into user object
-		err = tx.QueryRow(ctx, query, user.Email, user.Password).
+		err = tx.QueryRow(ctx, query, user.Email, user.Password, user.DisplayName).
 			Scan(&user.ID, &user.CreatedAt)
 		if err != nil {
 			up.logger.Errorf("Create[repo]: Ошибка создания пользователя: %s, ошибка: %s", user.Email, err)
@@ -144,3 +212,114 @@ func (up *UserPostgres) Create(user models.User) error {
 		return ctx.Err()
 	}
 }
+
+// UpdateLeaderboardSettings sets whether user is shown on leaderboards by display name and the name itself
+// Returns ErrUserNotFound if user does not exist
+func (up *UserPostgres) UpdateLeaderboardSettings(id int, optIn bool, displayName string) error {
+	up.logger.Debugf("UpdateLeaderboardSettings[repo]: Изменение настроек рейтинга пользователя с id: %d", id)
+
+	query := `UPDATE users SET leaderboard_opt_in = $2, display_name = NULLIF($3, ''), updated_at = NOW()
+              WHERE id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := up.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			up.logger.Errorf("UpdateLeaderboardSettings[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		tag, err := tx.Exec(ctx, query, id, optIn, displayName)
+		if err != nil {
+			up.logger.Errorf("UpdateLeaderboardSettings[repo]: Ошибка изменения настроек рейтинга: %s", err)
+			errChan <- err
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			errChan <- ErrUserNotFound
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			up.logger.Errorf("UpdateLeaderboardSettings[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		up.logger.Errorf("UpdateLeaderboardSettings[repo]: Время ожидания превышено для пользователя с id: %d", id)
+		return ctx.Err()
+	}
+}
+
+// UpdateNotificationSettings sets how user is notified and language of notifications, empty locale means default one
+// Returns ErrUserNotFound if user does not exist
+func (up *UserPostgres) UpdateNotificationSettings(id int, mode, locale string) error {
+	up.logger.Debugf("UpdateNotificationSettings[repo]: Изменение настроек уведомлений пользователя с id: %d", id)
+
+	query := `UPDATE users SET notification_mode = $2, locale = NULLIF($3, ''), updated_at = NOW() WHERE id = $1`
+	ctx := context.Background()
+
+	// Create context with timeout to cancel query execution if it takes too long
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel() // Cancel context after function ends
+
+	// Use a channel to get error from goroutine
+	errChan := make(chan error)
+
+	go func() {
+		// Begin transaction
+		tx, err := up.db.GetPool().BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			up.logger.Errorf("UpdateNotificationSettings[repo]: Ошибка начала транзакции: %s", err)
+			errChan <- err
+			return
+		}
+		defer tx.Rollback(ctx) // Rollback transaction if function returns error
+
+		tag, err := tx.Exec(ctx, query, id, mode, locale)
+		if err != nil {
+			up.logger.Errorf("UpdateNotificationSettings[repo]: Ошибка изменения настроек уведомлений: %s", err)
+			errChan <- err
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			errChan <- ErrUserNotFound
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(ctx); err != nil {
+			up.logger.Errorf("UpdateNotificationSettings[repo]: Ошибка при коммите транзакции: %s", err)
+			errChan <- err
+			return
+		}
+
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		up.logger.Errorf("UpdateNotificationSettings[repo]: Время ожидания превышено для пользователя с id: %d", id)
+		return ctx.Err()
+	}
+}
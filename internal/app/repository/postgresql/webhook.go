@@ -0,0 +1,401 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"rest-refs/internal/app/models"
+	"rest-refs/internal/app/repository/database"
+)
+
+var ErrWebhookSubscriptionNotFound = errors.New("подписка на вебхуки не найдена")
+var ErrWebhookDeliveryNotFound = errors.New("доставка вебхука не найдена")
+var ErrWebhookDeliveryPending = errors.New("доставка вебхука еще не завершена")
+
+// webhookOutcomes are errors of webhook operations reported to caller rather than failures of database
+var webhookOutcomes = []error{ErrWebhookSubscriptionNotFound, ErrWebhookDeliveryNotFound, ErrWebhookDeliveryPending}
+
+// webhookSubscriptionColumns lists columns of subscription in order expected by scanWebhookSubscription
+const webhookSubscriptionColumns = `id, user_id, url, secret, events, all_users, created_at, updated_at`
+
+// webhookDeliveryColumns lists columns of delivery joined with its event as e
+// in order expected by scanWebhookDelivery
+const webhookDeliveryColumns = `d.id, d.subscription_id, e.id, e.type, e.data, e.created_at, d.status, d.attempts,
+    CASE WHEN d.status = 'pending' THEN d.next_attempt_at END, d.last_attempt_at, d.last_response_status, d.last_error,
+    d.delivered_at, d.created_at`
+
+// WebhookPostgres implements the WebhookRepo interface for PostgreSQL storage of webhook subscriptions,
+// events and their deliveries
+type WebhookPostgres struct {
+	db     database.Database
+	logger *logrus.Logger
+}
+
+// NewWebhookPostgres creates new WebhookPostgres instance with provided database connection and logger
+func NewWebhookPostgres(db database.Database, logger *logrus.Logger) *WebhookPostgres {
+	return &WebhookPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateSubscription inserts new subscription and returns it with generated id
+func (r *WebhookPostgres) CreateSubscription(subscription models.WebhookSubscription) (models.WebhookSubscription,
+	error) {
+	r.logger.Debugf("CreateSubscription[repo]: Создание подписки на вебхуки пользователя с id: %d", subscription.UserID)
+
+	query := `INSERT INTO webhook_subscriptions (user_id, url, secret, events, all_users, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+              RETURNING ` + webhookSubscriptionColumns
+
+	var created models.WebhookSubscription
+	err := withTx(r.db, r.logger, "CreateSubscription", func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		created, err = scanWebhookSubscription(tx.QueryRow(ctx, query, subscription.UserID, subscription.URL,
+			subscription.Secret, subscription.Events, subscription.AllUsers))
+		return err
+	}, webhookOutcomes...)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	r.logger.Infof("CreateSubscription[repo]: Подписка на вебхуки с id: %d создана", created.ID)
+	return created, nil
+}
+
+// GetSubscriptionByID retrieves subscription, if it is not found, returns ErrWebhookSubscriptionNotFound
+func (r *WebhookPostgres) GetSubscriptionByID(id int) (models.WebhookSubscription, error) {
+	r.logger.Debugf("GetSubscriptionByID[repo]: Получение подписки на вебхуки с id: %d", id)
+
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	var subscription models.WebhookSubscription
+	err := withTx(r.db, r.logger, "GetSubscriptionByID", func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		subscription, err = scanWebhookSubscription(tx.QueryRow(ctx, query, id))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWebhookSubscriptionNotFound
+		}
+		return err
+	}, webhookOutcomes...)
+	return subscription, err
+}
+
+// GetSubscriptionsByUserID retrieves subscriptions of user in order of creation
+func (r *WebhookPostgres) GetSubscriptionsByUserID(userID int) ([]models.WebhookSubscription, error) {
+	r.logger.Debugf("GetSubscriptionsByUserID[repo]: Получение подписок на вебхуки пользователя с id: %d", userID)
+
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE user_id = $1 ORDER BY id`
+
+	var subscriptions []models.WebhookSubscription
+	err := withTx(r.db, r.logger, "GetSubscriptionsByUserID", func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, userID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			subscription, err := scanWebhookSubscription(rows)
+			if err != nil {
+				return err
+			}
+			subscriptions = append(subscriptions, subscription)
+		}
+		return rows.Err()
+	}, webhookOutcomes...)
+	return subscriptions, err
+}
+
+// DeleteSubscription removes subscription with all its deliveries
+// If subscription not found, returns ErrWebhookSubscriptionNotFound
+func (r *WebhookPostgres) DeleteSubscription(id int) error {
+	r.logger.Debugf("DeleteSubscription[repo]: Удаление подписки на вебхуки с id: %d", id)
+
+	query := `DELETE FROM webhook_subscriptions WHERE id = $1`
+
+	return withTx(r.db, r.logger, "DeleteSubscription", func(ctx context.Context, tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrWebhookSubscriptionNotFound
+		}
+		return nil
+	}, webhookOutcomes...)
+}
+
+// CreateEventsFromOutbox turns up to limit outbox messages not yet seen by webhooks into webhook events and
+// queues every event for matching subscriptions, messages are marked in the same transaction, so each of them
+// becomes event exactly once. Messages without owner or rejected by convert are marked without event.
+// It returns number of handled messages
+func (r *WebhookPostgres) CreateEventsFromOutbox(limit int,
+	convert func(message models.OutboxMessage) (models.WebhookEvent, error)) (int, error) {
+	selectQuery := `SELECT id, aggregate_type, aggregate_id, event_type, payload, user_id, created_at
+                    FROM outbox
+                    WHERE webhook_enqueued_at IS NULL
+                    ORDER BY id
+                    LIMIT $1
+                    FOR UPDATE SKIP LOCKED`
+	insertQuery := `WITH event AS (
+                        INSERT INTO webhook_events (type, user_id, data, created_at) VALUES ($1, $2, $3, $4)
+                        RETURNING id
+                    )
+                    INSERT INTO webhook_deliveries (subscription_id, event_id, status, next_attempt_at, created_at)
+                    SELECT s.id, event.id, 'pending', NOW(), NOW()
+                    FROM event, webhook_subscriptions s
+                    WHERE (s.user_id = $2 OR s.all_users)
+                      AND (cardinality(s.events) = 0 OR $1 = ANY(s.events))`
+	markQuery := `UPDATE outbox SET webhook_enqueued_at = NOW() WHERE id = ANY($1)`
+
+	var handled int
+	err := withTx(r.db, r.logger, "CreateEventsFromOutbox", func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, selectQuery, limit)
+		if err != nil {
+			return err
+		}
+
+		var messages []models.OutboxMessage
+		for rows.Next() {
+			var message models.OutboxMessage
+			err = rows.Scan(&message.ID, &message.AggregateType, &message.AggregateID, &message.EventType,
+				&message.Payload, &message.UserID, &message.CreatedAt)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			messages = append(messages, message)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		ids := make([]int64, 0, len(messages))
+		for _, message := range messages {
+			ids = append(ids, message.ID)
+			if message.UserID == nil {
+				continue
+			}
+
+			event, err := convert(message)
+			if err != nil {
+				r.logger.Errorf("CreateEventsFromOutbox[repo]: Сообщение с id: %d пропущено: %s", message.ID, err)
+				continue
+			}
+
+			if _, err = tx.Exec(ctx, insertQuery, event.Type, event.UserID, event.Data, message.CreatedAt); err != nil {
+				return err
+			}
+		}
+
+		if len(ids) > 0 {
+			if _, err = tx.Exec(ctx, markQuery, ids); err != nil {
+				return err
+			}
+		}
+
+		handled = len(ids)
+		return nil
+	}, webhookOutcomes...)
+	if err != nil {
+		return 0, err
+	}
+
+	if handled > 0 {
+		r.logger.Infof("CreateEventsFromOutbox[repo]: Из outbox создано событий вебхуков: %d", handled)
+	}
+	return handled, nil
+}
+
+// ClaimDeliveries takes up to limit pending deliveries due for sending and counts new attempt for each of them
+// Claimed deliveries are not due again until leaseUntil, so concurrent dispatchers never send the same delivery
+// twice, and delivery of dispatcher that crashed is retried once lease expires
+func (r *WebhookPostgres) ClaimDeliveries(limit int, leaseUntil time.Time) ([]models.WebhookDispatch, error) {
+	query := `WITH due AS (
+                  SELECT id FROM webhook_deliveries
+                  WHERE status = 'pending' AND next_attempt_at <= NOW()
+                  ORDER BY next_attempt_at
+                  LIMIT $1
+                  FOR UPDATE SKIP LOCKED
+              )
+              UPDATE webhook_deliveries d SET attempts = d.attempts + 1, next_attempt_at = $2
+              FROM due, webhook_subscriptions s, webhook_events e
+              WHERE d.id = due.id AND s.id = d.subscription_id AND e.id = d.event_id
+              RETURNING d.id, d.attempts, s.url, s.secret, e.id, e.type, e.data, e.created_at`
+
+	var dispatches []models.WebhookDispatch
+	err := withTx(r.db, r.logger, "ClaimDeliveries", func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, limit, leaseUntil)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var dispatch models.WebhookDispatch
+			err = rows.Scan(&dispatch.DeliveryID, &dispatch.Attempt, &dispatch.URL, &dispatch.Secret,
+				&dispatch.Event.ID, &dispatch.Event.Type, &dispatch.Event.Data, &dispatch.Event.CreatedAt)
+			if err != nil {
+				return err
+			}
+			dispatches = append(dispatches, dispatch)
+		}
+		return rows.Err()
+	}, webhookOutcomes...)
+	return dispatches, err
+}
+
+// RecordAttempt stores result of delivery attempt and moves delivery to status
+// Pending delivery is retried at nextAttemptAt
+func (r *WebhookPostgres) RecordAttempt(deliveryID int64, attempt models.WebhookDeliveryAttempt, status string,
+	nextAttemptAt time.Time) error {
+	r.logger.Debugf("RecordAttempt[repo]: Сохранение попытки %d доставки с id: %d", attempt.Attempt, deliveryID)
+
+	insertQuery := `INSERT INTO webhook_delivery_attempts (delivery_id, attempt, response_status, error, duration_ms,
+                    attempted_at)
+                    VALUES ($1, $2, $3, $4, $5, $6)`
+	updateQuery := `UPDATE webhook_deliveries SET status = $2, next_attempt_at = $3, last_attempt_at = $4,
+                    last_response_status = $5, last_error = $6,
+                    delivered_at = CASE WHEN $2 = 'delivered' THEN $4 ELSE delivered_at END
+                    WHERE id = $1`
+
+	return withTx(r.db, r.logger, "RecordAttempt", func(ctx context.Context, tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, insertQuery, deliveryID, attempt.Attempt, attempt.ResponseStatus, attempt.Error,
+			attempt.DurationMs, attempt.AttemptedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, updateQuery, deliveryID, status, nextAttemptAt, attempt.AttemptedAt,
+			attempt.ResponseStatus, attempt.Error)
+		return err
+	}, webhookOutcomes...)
+}
+
+// GetDeliveries retrieves deliveries of subscription from newest to oldest,
+// non-empty statuses limit result to deliveries in these statuses
+func (r *WebhookPostgres) GetDeliveries(subscriptionID int, statuses []string, limit int) ([]models.WebhookDelivery,
+	error) {
+	r.logger.Debugf("GetDeliveries[repo]: Получение доставок подписки с id: %d", subscriptionID)
+
+	query := `SELECT ` + webhookDeliveryColumns + `
+              FROM webhook_deliveries d JOIN webhook_events e ON e.id = d.event_id
+              WHERE d.subscription_id = $1
+                AND (COALESCE(cardinality($2::text[]), 0) = 0 OR d.status = ANY($2))
+              ORDER BY d.id DESC
+              LIMIT $3`
+
+	var deliveries []models.WebhookDelivery
+	err := withTx(r.db, r.logger, "GetDeliveries", func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, subscriptionID, statuses, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			delivery, err := scanWebhookDelivery(rows)
+			if err != nil {
+				return err
+			}
+			deliveries = append(deliveries, delivery)
+		}
+		return rows.Err()
+	}, webhookOutcomes...)
+	return deliveries, err
+}
+
+// GetDeliveryByID retrieves delivery of subscription with history of its attempts
+// If delivery not found, returns ErrWebhookDeliveryNotFound
+func (r *WebhookPostgres) GetDeliveryByID(subscriptionID int, deliveryID int64) (models.WebhookDelivery, error) {
+	r.logger.Debugf("GetDeliveryByID[repo]: Получение доставки с id: %d", deliveryID)
+
+	query := `SELECT ` + webhookDeliveryColumns + `
+              FROM webhook_deliveries d JOIN webhook_events e ON e.id = d.event_id
+              WHERE d.id = $1 AND d.subscription_id = $2`
+	historyQuery := `SELECT attempt, response_status, error, duration_ms, attempted_at
+                     FROM webhook_delivery_attempts WHERE delivery_id = $1 ORDER BY id`
+
+	var delivery models.WebhookDelivery
+	err := withTx(r.db, r.logger, "GetDeliveryByID", func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		delivery, err = scanWebhookDelivery(tx.QueryRow(ctx, query, deliveryID, subscriptionID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWebhookDeliveryNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, historyQuery, deliveryID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var attempt models.WebhookDeliveryAttempt
+			err = rows.Scan(&attempt.Attempt, &attempt.ResponseStatus, &attempt.Error, &attempt.DurationMs,
+				&attempt.AttemptedAt)
+			if err != nil {
+				return err
+			}
+			delivery.History = append(delivery.History, attempt)
+		}
+		return rows.Err()
+	}, webhookOutcomes...)
+	return delivery, err
+}
+
+// Redeliver puts delivered or dead delivery of subscription back into queue with full number of attempts
+// If delivery not found, returns ErrWebhookDeliveryNotFound, if it is still pending, returns ErrWebhookDeliveryPending
+func (r *WebhookPostgres) Redeliver(subscriptionID int, deliveryID int64) error {
+	r.logger.Debugf("Redeliver[repo]: Повторная доставка с id: %d", deliveryID)
+
+	lockQuery := `SELECT status FROM webhook_deliveries WHERE id = $1 AND subscription_id = $2 FOR UPDATE`
+	updateQuery := `UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+                    WHERE id = $1`
+
+	return withTx(r.db, r.logger, "Redeliver", func(ctx context.Context, tx pgx.Tx) error {
+		var status string
+		err := tx.QueryRow(ctx, lockQuery, deliveryID, subscriptionID).Scan(&status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWebhookDeliveryNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if status == models.WebhookDeliveryPending {
+			return ErrWebhookDeliveryPending
+		}
+
+		_, err = tx.Exec(ctx, updateQuery, deliveryID)
+		return err
+	}, webhookOutcomes...)
+}
+
+// scanWebhookSubscription scans row selected with webhookSubscriptionColumns into subscription
+func scanWebhookSubscription(row pgx.Row) (models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+
+	err := row.Scan(&subscription.ID, &subscription.UserID, &subscription.URL, &subscription.Secret,
+		&subscription.Events, &subscription.AllUsers, &subscription.CreatedAt, &subscription.UpdatedAt)
+	return subscription, err
+}
+
+// scanWebhookDelivery scans row selected with webhookDeliveryColumns into delivery
+func scanWebhookDelivery(row pgx.Row) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+
+	err := row.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.Event.ID, &delivery.Event.Type,
+		&delivery.Event.Data, &delivery.Event.CreatedAt, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt,
+		&delivery.LastAttemptAt, &delivery.LastResponseStatus, &delivery.LastError, &delivery.DeliveredAt,
+		&delivery.CreatedAt)
+	return delivery, err
+}
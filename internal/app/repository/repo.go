@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/sirupsen/logrus"
 	"rest-refs/internal/app/models"
 	"rest-refs/internal/app/repository/database"
@@ -11,6 +14,9 @@ import (
 type UserRepo interface {
 	Create(user models.User) error
 	GetByEmail(email string) (models.User, error)
+	GetByID(id int) (models.User, error)
+	UpdateLeaderboardSettings(id int, optIn bool, displayName string) error
+	UpdateNotificationSettings(id int, mode, locale string) error
 }
 
 // ReferralCodeRepo defines interface for referral code-related database operations
@@ -20,27 +26,200 @@ type ReferralCodeRepo interface {
 	GetActiveReferralCodeByUserID(referrerID int) (models.ReferralCode, error)
 	GetIDByReferralCode(code string) (int, error)
 	GetReferrerIDByReferralCode(code string) (int, error)
+	GetByReferralCode(code string) (models.ReferralCode, error)
+	GetReferralCodeByID(id int) (models.ReferralCode, error)
+	SetPausedByID(id int, paused bool) error
+	Rotate(id int, replacement models.ReferralCode, graceUntil time.Time) (models.ReferralCode, error)
+}
+
+// ReferralLinkRepo defines interface for database operations related to referral links
+type ReferralLinkRepo interface {
+	GetByReferrerID(referrerID int) ([]models.ReferralLink, error)
 }
 
 // ReferralRepo defines interface for referral-related database operations
 type ReferralRepo interface {
-	GetReferralsByReferrerID(id int) ([]models.Referral, error)
-	Create(referral models.Referral) error
+	GetReferralsByReferrerID(id int, filter models.ReferralFilter) ([]models.Referral, int, error)
+	StreamReferrals(referrerID *int, filter models.ReferralFilter, fn func(row models.ReferralExportRow) error) error
+	Create(referral models.Referral, user models.User) (models.Referral, error)
+	GetCampaignStatsByReferrerID(referrerID int) ([]models.CampaignReferralStats, error)
+	GetDownline(userID, depth int) ([]models.ReferralTreeNode, error)
+}
+
+// ReferralStatsRepo defines interface for aggregate statistics of clicks and referrals
+type ReferralStatsRepo interface {
+	GetReferralStats(referrerID *int, from, to time.Time, granularity,
+		timeZone string) (models.ReferralStatsResponse, error)
+}
+
+// LeaderboardRepo defines interface for ranking referrers
+type LeaderboardRepo interface {
+	GetLeaderboard(metric string, since time.Time, campaignID *int) ([]models.LeaderboardEntry, error)
+}
+
+// FraudRepo defines interface for database operations related to fraud checks and manual review of referrals
+type FraudRepo interface {
+	RecordDevice(userID int, ipHash, deviceHash string) error
+	MatchDevice(userID int, ipHash, deviceHash string) (bool, bool, error)
+	CountReferralsByCodeSince(codeID int, since time.Time) (int, error)
+	SaveAssessment(referralID int, assessment models.RiskAssessment) error
+	GetReviews(statuses []string) ([]models.ReferralReview, error)
+	Decide(referralID, adminID int, decision string, reason *string) (models.ReferralReview, error)
+}
+
+// WebhookRepo defines interface for database operations related to webhook subscriptions, events and deliveries
+type WebhookRepo interface {
+	CreateSubscription(subscription models.WebhookSubscription) (models.WebhookSubscription, error)
+	GetSubscriptionByID(id int) (models.WebhookSubscription, error)
+	GetSubscriptionsByUserID(userID int) ([]models.WebhookSubscription, error)
+	DeleteSubscription(id int) error
+	CreateEventsFromOutbox(limit int, convert func(message models.OutboxMessage) (models.WebhookEvent, error)) (int,
+		error)
+	ClaimDeliveries(limit int, leaseUntil time.Time) ([]models.WebhookDispatch, error)
+	RecordAttempt(deliveryID int64, attempt models.WebhookDeliveryAttempt, status string, nextAttemptAt time.Time) error
+	GetDeliveries(subscriptionID int, statuses []string, limit int) ([]models.WebhookDelivery, error)
+	GetDeliveryByID(subscriptionID int, deliveryID int64) (models.WebhookDelivery, error)
+	Redeliver(subscriptionID int, deliveryID int64) error
+}
+
+// OutboxRepo defines interface for database operations related to relaying outbox messages
+type OutboxRepo interface {
+	PublishPending(limit int, timeout time.Duration,
+		publish func(ctx context.Context, message models.OutboxMessage) error,
+		retryAt func(attempts int) time.Time) (int, error)
+	DeletePublished(before time.Time) (int64, error)
+}
+
+// NotificationRepo defines interface for database operations related to queue of notification emails
+type NotificationRepo interface {
+	Enqueue(userID int, notificationType string, data []byte, digestAt time.Time) (bool, error)
+	Claim(limit int, leaseUntil time.Time) ([]models.Notification, error)
+	Complete(ids []int64, status string, nextAttemptAt time.Time, lastError *string) error
+}
+
+// ReferralEventRepo defines interface for database operations related to external events about referred users
+type ReferralEventRepo interface {
+	Record(event models.ReferralEvent, email string,
+		decide func(status, eventType string, received []string) string) (models.ReferralEventResponse, error)
+}
+
+// RewardRepo defines interface for rewards ledger database operations
+type RewardRepo interface {
+	GetReferralParties(referralID, uplineDepth int) (models.ReferralParties, error)
+	Post(transaction models.RewardTransaction) (bool, error)
+	ReverseByReferralID(referralID int) (int, error)
+	GetRewardsByUserID(userID int) (models.RewardsResponse, error)
+	GetEarnedSince(userID int, party string, since time.Time) (int64, error)
+	GetHeldByUserID(userID int, since time.Time) (int64, error)
+}
+
+// PayoutRepo defines interface for payout database operations
+type PayoutRepo interface {
+	Create(payout models.Payout, heldSince time.Time) (models.Payout, error)
+	ChangeStatus(id int, from string, change models.PayoutStatusChange, reference *string,
+		ledgerKind string) (models.Payout, error)
+	GetByID(id int) (models.Payout, error)
+	GetByUserID(userID int) ([]models.Payout, error)
+	GetAll(statuses []string) ([]models.Payout, error)
+}
+
+// RewardRuleSetRepo defines interface for reward rule set database operations
+type RewardRuleSetRepo interface {
+	Create(ruleSet models.RewardRuleSet) (models.RewardRuleSet, error)
+	Update(ruleSet models.RewardRuleSet) error
+	Delete(id int) error
+	GetByID(id int) (models.RewardRuleSet, error)
+	GetActive(campaignID *int) (models.RewardRuleSet, error)
+	GetAll() ([]models.RewardRuleSet, error)
+}
+
+// ReferralClickRepo defines interface for referral link click-related database operations
+type ReferralClickRepo interface {
+	Create(click models.ReferralClick) (models.ReferralClick, error)
+	GetByToken(token string) (models.ReferralClick, error)
+}
+
+// ReferralCodeBatchRepo defines interface for database operations related to batches of referral codes
+type ReferralCodeBatchRepo interface {
+	Create(batch models.ReferralCodeBatch, generate func() (string, error)) (models.ReferralCodeBatch, error)
+	GetByID(id int) (models.ReferralCodeBatch, error)
+	GetCodesByBatchID(batchID int) ([]models.ReferralCode, error)
+}
+
+// CampaignRepo defines interface for campaign-related database operations
+type CampaignRepo interface {
+	Create(campaign models.Campaign) (models.Campaign, error)
+	Update(campaign models.Campaign) error
+	Delete(id int) error
+	GetByID(id int) (models.Campaign, error)
+	GetAll() ([]models.Campaign, error)
+}
+
+// ReferralFeedRepo defines interface for database operations related to real-time feed of referrers
+type ReferralFeedRepo interface {
+	GetEventsAfter(userID int, afterID int64, limit int) ([]models.ReferralFeedEvent, bool, error)
+	GetEventByID(id int64) (models.ReferralFeedEvent, error)
+	Listen(ctx context.Context, notify func(event models.ReferralFeedEvent)) error
+}
+
+// ReferralGraphRepo defines interface for database operations that load users, referral codes and referrals
+// by many keys at once
+type ReferralGraphRepo interface {
+	GetUsersByIDs(ids []int) ([]models.User, error)
+	GetReferralCodesByIDs(ids []int) ([]models.ReferralCode, error)
+	GetReferralCodesByReferrerIDs(ids []int, page models.GraphPage) ([]models.ReferralCode, error)
+	GetReferralsByReferralCodeIDs(ids []int, page models.GraphPage) ([]models.Referral, error)
+	GetReferralsByReferrerIDs(ids []int, page models.GraphPage) ([]models.Referral, error)
 }
 
-// Repository combines UserRepo, ReferralCodeRepo, and ReferralRepo interfaces into single struct
+// Repository combines UserRepo, ReferralCodeRepo, ReferralRepo, ReferralClickRepo, ReferralCodeBatchRepo,
+// CampaignRepo, ReferralLinkRepo, ReferralEventRepo, RewardRepo, RewardRuleSetRepo, PayoutRepo,
+// ReferralStatsRepo, LeaderboardRepo, FraudRepo, WebhookRepo, OutboxRepo, NotificationRepo, ReferralFeedRepo and
+// ReferralGraphRepo interfaces into single struct
 type Repository struct {
 	UserRepo
 	ReferralRepo
 	ReferralCodeRepo
+	ReferralClickRepo
+	ReferralCodeBatchRepo
+	CampaignRepo
+	ReferralLinkRepo
+	ReferralEventRepo
+	RewardRepo
+	RewardRuleSetRepo
+	PayoutRepo
+	ReferralStatsRepo
+	LeaderboardRepo
+	FraudRepo
+	WebhookRepo
+	OutboxRepo
+	NotificationRepo
+	ReferralFeedRepo
+	ReferralGraphRepo
 }
 
 // New initializes and returns new Repository instance with PostgreSQL implementations for UserRepo and ReferralRepo
 func New(db database.Database, logger *logrus.Logger) *Repository {
 
 	return &Repository{
-		UserRepo:         postgresql.NewUserPostgres(db, logger),
-		ReferralCodeRepo: postgresql.NewReferralCodePostgres(db, logger),
-		ReferralRepo:     postgresql.NewReferralPostgres(db, logger),
+		UserRepo:              postgresql.NewUserPostgres(db, logger),
+		ReferralCodeRepo:      postgresql.NewReferralCodePostgres(db, logger),
+		ReferralRepo:          postgresql.NewReferralPostgres(db, logger),
+		ReferralClickRepo:     postgresql.NewReferralClickPostgres(db, logger),
+		ReferralCodeBatchRepo: postgresql.NewReferralCodeBatchPostgres(db, logger),
+		CampaignRepo:          postgresql.NewCampaignPostgres(db, logger),
+		ReferralLinkRepo:      postgresql.NewReferralLinkPostgres(db, logger),
+		ReferralEventRepo:     postgresql.NewReferralEventPostgres(db, logger),
+		RewardRepo:            postgresql.NewRewardPostgres(db, logger),
+		RewardRuleSetRepo:     postgresql.NewRewardRuleSetPostgres(db, logger),
+		PayoutRepo:            postgresql.NewPayoutPostgres(db, logger),
+		ReferralStatsRepo:     postgresql.NewReferralStatsPostgres(db, logger),
+		LeaderboardRepo:       postgresql.NewLeaderboardPostgres(db, logger),
+		FraudRepo:             postgresql.NewFraudPostgres(db, logger),
+		WebhookRepo:           postgresql.NewWebhookPostgres(db, logger),
+		OutboxRepo:            postgresql.NewOutboxPostgres(db, logger),
+		NotificationRepo:      postgresql.NewNotificationPostgres(db, logger),
+		ReferralFeedRepo:      postgresql.NewReferralFeedPostgres(db, logger),
+		ReferralGraphRepo:     postgresql.NewReferralGraphPostgres(db, logger),
 	}
 }
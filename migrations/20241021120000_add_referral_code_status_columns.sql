@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE referral_codes
+    ADD COLUMN paused_at TIMESTAMPTZ,
+    ADD COLUMN max_uses INT CHECK (max_uses > 0);
+
+ALTER TABLE users
+    ADD COLUMN display_name VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS display_name;
+
+ALTER TABLE referral_codes
+    DROP COLUMN IF EXISTS max_uses,
+    DROP COLUMN IF EXISTS paused_at;
+-- +goose StatementEnd
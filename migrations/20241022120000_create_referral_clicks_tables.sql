@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE referral_clicks (
+                                id SERIAL PRIMARY KEY,
+                                token VARCHAR(64) NOT NULL UNIQUE,
+                                referral_code_id INT NOT NULL REFERENCES referral_codes(id) ON DELETE CASCADE,
+                                ip_hash VARCHAR(64) NOT NULL,
+                                user_agent TEXT NOT NULL DEFAULT '',
+                                referer TEXT NOT NULL DEFAULT '',
+                                utm_source VARCHAR(255) NOT NULL DEFAULT '',
+                                utm_medium VARCHAR(255) NOT NULL DEFAULT '',
+                                utm_campaign VARCHAR(255) NOT NULL DEFAULT '',
+                                utm_term VARCHAR(255) NOT NULL DEFAULT '',
+                                utm_content VARCHAR(255) NOT NULL DEFAULT '',
+                                clicked_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX referral_clicks_referral_code_id_clicked_at_idx ON referral_clicks (referral_code_id, clicked_at);
+
+ALTER TABLE referrals
+    ADD COLUMN referral_click_id INT REFERENCES referral_clicks(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE referrals
+    DROP COLUMN IF EXISTS referral_click_id;
+
+DROP TABLE IF EXISTS referral_clicks;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE referral_code_batches (
+                                id SERIAL PRIMARY KEY,
+                                referrer_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                created_by INT REFERENCES users(id) ON DELETE SET NULL,
+                                size INT NOT NULL CHECK (size > 0),
+                                max_uses INT CHECK (max_uses > 0),
+                                expires_at TIMESTAMPTZ NOT NULL,
+                                created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+ALTER TABLE referral_codes
+    ADD COLUMN batch_id INT REFERENCES referral_code_batches(id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX referral_codes_code_key ON referral_codes (code);
+CREATE INDEX referral_codes_batch_id_idx ON referral_codes (batch_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS referral_codes_batch_id_idx;
+DROP INDEX IF EXISTS referral_codes_code_key;
+
+ALTER TABLE referral_codes
+    DROP COLUMN IF EXISTS batch_id;
+
+DROP TABLE IF EXISTS referral_code_batches;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS is_admin;
+-- +goose StatementEnd
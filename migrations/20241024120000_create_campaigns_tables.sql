@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE campaigns (
+                                id SERIAL PRIMARY KEY,
+                                name VARCHAR(255) NOT NULL,
+                                starts_at TIMESTAMPTZ NOT NULL,
+                                ends_at TIMESTAMPTZ,
+                                default_code_lifetime_seconds BIGINT CHECK (default_code_lifetime_seconds > 0),
+                                reward_rules JSONB NOT NULL DEFAULT '{}',
+                                max_participants INT CHECK (max_participants > 0),
+                                created_by INT REFERENCES users(id) ON DELETE SET NULL,
+                                created_at TIMESTAMPTZ DEFAULT NOW(),
+                                updated_at TIMESTAMPTZ DEFAULT NOW(),
+                                CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+ALTER TABLE referral_codes
+    ADD COLUMN campaign_id INT REFERENCES campaigns(id) ON DELETE SET NULL;
+
+ALTER TABLE referral_code_batches
+    ADD COLUMN campaign_id INT REFERENCES campaigns(id) ON DELETE SET NULL;
+
+CREATE INDEX referral_codes_campaign_id_idx ON referral_codes (campaign_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS referral_codes_campaign_id_idx;
+
+ALTER TABLE referral_code_batches
+    DROP COLUMN IF EXISTS campaign_id;
+
+ALTER TABLE referral_codes
+    DROP COLUMN IF EXISTS campaign_id;
+
+DROP TABLE IF EXISTS campaigns;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE referral_links (
+                                id SERIAL PRIMARY KEY,
+                                referrer_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+ALTER TABLE referral_codes
+    ADD COLUMN link_id INT REFERENCES referral_links(id) ON DELETE SET NULL,
+    ADD COLUMN superseded_by INT REFERENCES referral_codes(id) ON DELETE SET NULL,
+    ADD COLUMN rotated_at TIMESTAMPTZ;
+
+-- Every existing personal code becomes its own link, link ids reuse code ids
+INSERT INTO referral_links (id, referrer_id, created_at)
+SELECT id, referrer_id, created_at FROM referral_codes WHERE batch_id IS NULL;
+
+UPDATE referral_codes SET link_id = id WHERE batch_id IS NULL;
+
+SELECT setval(pg_get_serial_sequence('referral_links', 'id'), COALESCE((SELECT MAX(id) FROM referral_links), 0) + 1, false);
+
+CREATE INDEX referral_codes_link_id_idx ON referral_codes (link_id);
+CREATE INDEX referral_links_referrer_id_idx ON referral_links (referrer_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS referral_links_referrer_id_idx;
+DROP INDEX IF EXISTS referral_codes_link_id_idx;
+
+ALTER TABLE referral_codes
+    DROP COLUMN IF EXISTS rotated_at,
+    DROP COLUMN IF EXISTS superseded_by,
+    DROP COLUMN IF EXISTS link_id;
+
+DROP TABLE IF EXISTS referral_links;
+-- +goose StatementEnd
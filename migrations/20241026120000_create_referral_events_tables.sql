@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE referrals
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'qualified', 'rejected', 'reversed')),
+    ADD COLUMN status_changed_at TIMESTAMPTZ;
+
+CREATE TABLE referral_events (
+                                id SERIAL PRIMARY KEY,
+                                event_id VARCHAR(255) NOT NULL UNIQUE,
+                                referral_id INT NOT NULL REFERENCES referrals(id) ON DELETE CASCADE,
+                                type VARCHAR(64) NOT NULL,
+                                payload JSONB NOT NULL DEFAULT '{}',
+                                occurred_at TIMESTAMPTZ NOT NULL,
+                                received_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX referral_events_referral_id_idx ON referral_events (referral_id);
+CREATE INDEX referrals_referrer_id_status_idx ON referrals (referrer_id, status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS referrals_referrer_id_status_idx;
+DROP TABLE IF EXISTS referral_events;
+
+ALTER TABLE referrals
+    DROP COLUMN IF EXISTS status_changed_at,
+    DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE reward_accounts (
+                                id SERIAL PRIMARY KEY,
+                                user_id INT UNIQUE REFERENCES users(id),
+                                kind VARCHAR(16) NOT NULL CHECK (kind IN ('user', 'program')),
+                                created_at TIMESTAMPTZ DEFAULT NOW(),
+                                CHECK ((kind = 'user') = (user_id IS NOT NULL))
+);
+
+-- Program account funds all rewards, its balance is negative sum of rewards paid
+INSERT INTO reward_accounts (kind) VALUES ('program');
+
+CREATE TABLE reward_transactions (
+                                id SERIAL PRIMARY KEY,
+                                kind VARCHAR(32) NOT NULL,
+                                referral_id INT REFERENCES referrals(id) ON DELETE SET NULL,
+                                idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+                                reverses_id INT UNIQUE REFERENCES reward_transactions(id),
+                                created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE reward_entries (
+                                id SERIAL PRIMARY KEY,
+                                transaction_id INT NOT NULL REFERENCES reward_transactions(id),
+                                account_id INT NOT NULL REFERENCES reward_accounts(id),
+                                amount BIGINT NOT NULL CHECK (amount <> 0),
+                                created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX reward_entries_account_id_idx ON reward_entries (account_id);
+CREATE INDEX reward_entries_transaction_id_idx ON reward_entries (transaction_id);
+CREATE INDEX reward_transactions_referral_id_idx ON reward_transactions (referral_id);
+
+-- Ledger is append-only, mistakes are corrected by reversal transactions
+CREATE FUNCTION reward_ledger_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'записи журнала вознаграждений нельзя изменять или удалять';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reward_entries_immutable BEFORE UPDATE OR DELETE ON reward_entries
+    FOR EACH ROW EXECUTE FUNCTION reward_ledger_immutable();
+
+CREATE TRIGGER reward_transactions_immutable BEFORE UPDATE OF kind, idempotency_key, reverses_id OR DELETE
+    ON reward_transactions
+    FOR EACH ROW EXECUTE FUNCTION reward_ledger_immutable();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reward_entries;
+DROP TABLE IF EXISTS reward_transactions;
+DROP FUNCTION IF EXISTS reward_ledger_immutable();
+DROP TABLE IF EXISTS reward_accounts;
+-- +goose StatementEnd
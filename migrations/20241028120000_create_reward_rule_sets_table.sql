@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE reward_rule_sets (
+                                id SERIAL PRIMARY KEY,
+                                name VARCHAR(255) NOT NULL,
+                                campaign_id INT REFERENCES campaigns(id) ON DELETE CASCADE,
+                                active BOOLEAN NOT NULL DEFAULT FALSE,
+                                format VARCHAR(8) NOT NULL CHECK (format IN ('json', 'yaml')),
+                                source TEXT NOT NULL,
+                                rules JSONB NOT NULL,
+                                created_by INT REFERENCES users(id) ON DELETE SET NULL,
+                                created_at TIMESTAMPTZ DEFAULT NOW(),
+                                updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- At most one active rule set is global and at most one is active per campaign
+CREATE UNIQUE INDEX reward_rule_sets_active_idx ON reward_rule_sets (COALESCE(campaign_id, 0)) WHERE active;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS reward_rule_sets_active_idx;
+
+DROP TABLE IF EXISTS reward_rule_sets;
+-- +goose StatementEnd
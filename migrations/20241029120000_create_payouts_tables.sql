@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Payout account holds rewards reserved by payout requests until they are paid or released
+ALTER TABLE reward_accounts DROP CONSTRAINT reward_accounts_kind_check;
+ALTER TABLE reward_accounts ADD CONSTRAINT reward_accounts_kind_check CHECK (kind IN ('user', 'program', 'payout'));
+
+INSERT INTO reward_accounts (kind) VALUES ('payout');
+
+CREATE TABLE payouts (
+                                id SERIAL PRIMARY KEY,
+                                user_id INT NOT NULL REFERENCES users(id),
+                                amount BIGINT NOT NULL CHECK (amount > 0),
+                                destination VARCHAR(255) NOT NULL,
+                                status VARCHAR(16) NOT NULL DEFAULT 'requested'
+                                    CHECK (status IN ('requested', 'approved', 'rejected', 'paid', 'failed')),
+                                provider VARCHAR(32) NOT NULL,
+                                provider_reference VARCHAR(255),
+                                reason TEXT,
+                                created_at TIMESTAMPTZ DEFAULT NOW(),
+                                updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE payout_status_changes (
+                                id SERIAL PRIMARY KEY,
+                                payout_id INT NOT NULL REFERENCES payouts(id) ON DELETE CASCADE,
+                                from_status VARCHAR(16),
+                                to_status VARCHAR(16) NOT NULL,
+                                changed_by INT REFERENCES users(id) ON DELETE SET NULL,
+                                reason TEXT,
+                                created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+ALTER TABLE reward_transactions
+    ADD COLUMN payout_id INT REFERENCES payouts(id);
+
+CREATE INDEX payouts_user_id_idx ON payouts (user_id);
+CREATE INDEX payouts_status_idx ON payouts (status);
+CREATE INDEX payout_status_changes_payout_id_idx ON payout_status_changes (payout_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Ledger entries of payouts are immutable, so payout account and its kind are kept
+ALTER TABLE reward_transactions
+    DROP COLUMN IF EXISTS payout_id;
+
+DROP TABLE IF EXISTS payout_status_changes;
+DROP TABLE IF EXISTS payouts;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE referrals
+    ADD COLUMN user_id INT UNIQUE REFERENCES users(id) ON DELETE SET NULL;
+
+-- Link referrals to users registered with their emails, user can be referred only once
+UPDATE referrals r SET user_id = u.id
+FROM users u
+WHERE u.email = r.email AND r.id = (SELECT MIN(d.id) FROM referrals d WHERE d.email = r.email);
+
+CREATE INDEX referrals_referrer_id_idx ON referrals (referrer_id);
+
+-- Every user has at most one referrer, so walking up from referrer must never reach referred user
+CREATE FUNCTION referrals_prevent_cycle() RETURNS trigger AS $$
+BEGIN
+    IF NEW.user_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF NEW.user_id = NEW.referrer_id OR EXISTS (
+        WITH RECURSIVE upline (user_id, path) AS (
+            SELECT NEW.referrer_id, ARRAY[NEW.referrer_id]
+            UNION ALL
+            SELECT r.referrer_id, u.path || r.referrer_id
+            FROM referrals r JOIN upline u ON r.user_id = u.user_id
+            WHERE NOT r.referrer_id = ANY(u.path)
+        )
+        SELECT 1 FROM upline WHERE user_id = NEW.user_id
+    ) THEN
+        RAISE EXCEPTION 'реферал создает цикл в дереве рефералов' USING ERRCODE = 'check_violation';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER referrals_prevent_cycle BEFORE INSERT OR UPDATE OF user_id, referrer_id ON referrals
+    FOR EACH ROW EXECUTE FUNCTION referrals_prevent_cycle();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS referrals_prevent_cycle ON referrals;
+DROP FUNCTION IF EXISTS referrals_prevent_cycle();
+DROP INDEX IF EXISTS referrals_referrer_id_idx;
+
+ALTER TABLE referrals
+    DROP COLUMN IF EXISTS user_id;
+-- +goose StatementEnd
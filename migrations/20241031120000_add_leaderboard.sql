@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN leaderboard_opt_in BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX referrals_created_at_idx ON referrals (created_at);
+CREATE INDEX referrals_status_changed_at_idx ON referrals (status_changed_at) WHERE status = 'qualified';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS referrals_status_changed_at_idx;
+DROP INDEX IF EXISTS referrals_created_at_idx;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS leaderboard_opt_in;
+-- +goose StatementEnd
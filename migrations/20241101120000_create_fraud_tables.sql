@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE referrals
+    ADD COLUMN ip_hash VARCHAR(64),
+    ADD COLUMN device_hash VARCHAR(64),
+    ADD COLUMN risk_score INT NOT NULL DEFAULT 0 CHECK (risk_score BETWEEN 0 AND 100),
+    ADD COLUMN risk_signals JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN review_status VARCHAR(16) CHECK (review_status IN ('pending', 'approved', 'rejected')),
+    ADD COLUMN reviewed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN reviewed_at TIMESTAMPTZ,
+    ADD COLUMN review_reason TEXT;
+
+CREATE INDEX referrals_review_status_idx ON referrals (review_status, created_at) WHERE review_status IS NOT NULL;
+CREATE INDEX referrals_referral_code_id_created_at_idx ON referrals (referral_code_id, created_at);
+
+-- Known IP addresses and devices of users, hashed the same way as IP addresses of clicks
+CREATE TABLE user_devices (
+                              user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                              ip_hash VARCHAR(64) NOT NULL DEFAULT '',
+                              device_hash VARCHAR(64) NOT NULL DEFAULT '',
+                              first_seen_at TIMESTAMPTZ DEFAULT NOW(),
+                              last_seen_at TIMESTAMPTZ DEFAULT NOW(),
+                              PRIMARY KEY (user_id, ip_hash, device_hash)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_devices;
+
+DROP INDEX IF EXISTS referrals_referral_code_id_created_at_idx;
+DROP INDEX IF EXISTS referrals_review_status_idx;
+
+ALTER TABLE referrals
+    DROP COLUMN IF EXISTS review_reason,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS reviewed_by,
+    DROP COLUMN IF EXISTS review_status,
+    DROP COLUMN IF EXISTS risk_signals,
+    DROP COLUMN IF EXISTS risk_score,
+    DROP COLUMN IF EXISTS device_hash,
+    DROP COLUMN IF EXISTS ip_hash;
+-- +goose StatementEnd
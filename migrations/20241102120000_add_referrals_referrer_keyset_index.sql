@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX referrals_referrer_id_created_at_id_idx ON referrals (referrer_id, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS referrals_referrer_id_created_at_id_idx;
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE webhook_subscriptions (
+                                       id SERIAL PRIMARY KEY,
+                                       user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                       url TEXT NOT NULL,
+                                       secret VARCHAR(128) NOT NULL,
+                                       events TEXT[] NOT NULL DEFAULT '{}',
+                                       all_users BOOLEAN NOT NULL DEFAULT FALSE,
+                                       created_at TIMESTAMPTZ DEFAULT NOW(),
+                                       updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX webhook_subscriptions_user_id_idx ON webhook_subscriptions (user_id);
+
+-- Events are stored once and fanned out into deliveries to every matching subscription
+CREATE TABLE webhook_events (
+                                id BIGSERIAL PRIMARY KEY,
+                                type VARCHAR(64) NOT NULL,
+                                user_id INT REFERENCES users(id) ON DELETE SET NULL,
+                                data JSONB NOT NULL DEFAULT '{}',
+                                created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE webhook_deliveries (
+                                    id BIGSERIAL PRIMARY KEY,
+                                    subscription_id INT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+                                    event_id BIGINT NOT NULL REFERENCES webhook_events(id) ON DELETE CASCADE,
+                                    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+                                        CHECK (status IN ('pending', 'delivered', 'dead')),
+                                    attempts INT NOT NULL DEFAULT 0,
+                                    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    last_attempt_at TIMESTAMPTZ,
+                                    last_response_status INT,
+                                    last_error TEXT,
+                                    delivered_at TIMESTAMPTZ,
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_subscription_id_idx ON webhook_deliveries (subscription_id, id);
+
+CREATE TABLE webhook_delivery_attempts (
+                                           id BIGSERIAL PRIMARY KEY,
+                                           delivery_id BIGINT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+                                           attempt INT NOT NULL,
+                                           response_status INT,
+                                           error TEXT,
+                                           duration_ms INT NOT NULL,
+                                           attempted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX webhook_delivery_attempts_delivery_id_idx ON webhook_delivery_attempts (delivery_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_events;
+DROP TABLE IF EXISTS webhook_subscriptions;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Domain events are written in the same transaction as the change they describe and published by relay later
+CREATE TABLE outbox (
+                        id BIGSERIAL PRIMARY KEY,
+                        aggregate_type VARCHAR(32) NOT NULL,
+                        aggregate_id INT NOT NULL,
+                        event_type VARCHAR(64) NOT NULL,
+                        payload JSONB NOT NULL DEFAULT '{}',
+                        attempts INT NOT NULL DEFAULT 0,
+                        next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                        last_error TEXT,
+                        published_at TIMESTAMPTZ,
+                        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Relay looks up oldest unpublished message of every aggregate
+CREATE INDEX outbox_unpublished_idx ON outbox (aggregate_type, aggregate_id, id) WHERE published_at IS NULL;
+CREATE INDEX outbox_published_at_idx ON outbox (published_at) WHERE published_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
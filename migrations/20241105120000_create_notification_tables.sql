@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN notification_mode VARCHAR(16) NOT NULL DEFAULT 'immediate'
+        CHECK (notification_mode IN ('immediate', 'digest', 'off')),
+    ADD COLUMN locale VARCHAR(8);
+
+-- Queue of emails to users, digest notifications wait until digest time and are sent together
+CREATE TABLE notifications (
+                               id BIGSERIAL PRIMARY KEY,
+                               user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               type VARCHAR(64) NOT NULL,
+                               data JSONB NOT NULL DEFAULT '{}',
+                               digest BOOLEAN NOT NULL DEFAULT FALSE,
+                               status VARCHAR(16) NOT NULL DEFAULT 'pending'
+                                   CHECK (status IN ('pending', 'sent', 'failed')),
+                               attempts INT NOT NULL DEFAULT 0,
+                               next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                               last_error TEXT,
+                               sent_at TIMESTAMPTZ,
+                               created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX notifications_pending_idx ON notifications (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX notifications_user_id_idx ON notifications (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS locale,
+    DROP COLUMN IF EXISTS notification_mode;
+-- +goose StatementEnd
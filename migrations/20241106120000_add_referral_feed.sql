@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Outbox messages double as real-time feed of referrer, user_id is referrer owning aggregate of message
+ALTER TABLE outbox
+    ADD COLUMN user_id INT;
+
+UPDATE outbox o SET user_id = CASE o.aggregate_type
+    WHEN 'referral' THEN (SELECT r.referrer_id FROM referrals r WHERE r.id = o.aggregate_id)
+    WHEN 'referral_code' THEN (SELECT c.referrer_id FROM referral_codes c WHERE c.id = o.aggregate_id)
+END;
+
+-- Feed resumes from last event seen by client
+CREATE INDEX outbox_user_id_idx ON outbox (user_id, id) WHERE user_id IS NOT NULL;
+
+-- Owner of message is looked up while aggregate is still in place, and every app instance listening
+-- on referral_feed channel is notified once transaction is committed
+CREATE FUNCTION outbox_referral_feed() RETURNS trigger AS $$
+DECLARE
+    message TEXT;
+BEGIN
+    IF NEW.user_id IS NULL THEN
+        IF NEW.aggregate_type = 'referral' THEN
+            NEW.user_id := (SELECT referrer_id FROM referrals WHERE id = NEW.aggregate_id);
+        ELSIF NEW.aggregate_type = 'referral_code' THEN
+            NEW.user_id := (SELECT referrer_id FROM referral_codes WHERE id = NEW.aggregate_id);
+        END IF;
+    END IF;
+
+    IF NEW.user_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    message := json_build_object('id', NEW.id, 'user_id', NEW.user_id, 'type', NEW.event_type,
+                                 'data', NEW.payload, 'created_at', NEW.created_at)::text;
+    -- Notification payload is limited to 8000 bytes, listeners read data of larger messages from outbox
+    IF octet_length(message) > 7900 THEN
+        message := json_build_object('id', NEW.id, 'user_id', NEW.user_id, 'type', NEW.event_type,
+                                     'created_at', NEW.created_at)::text;
+    END IF;
+    PERFORM pg_notify('referral_feed', message);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_referral_feed BEFORE INSERT ON outbox
+    FOR EACH ROW EXECUTE FUNCTION outbox_referral_feed();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS outbox_referral_feed ON outbox;
+DROP FUNCTION IF EXISTS outbox_referral_feed();
+DROP INDEX IF EXISTS outbox_user_id_idx;
+
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS user_id;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Referral codes are stored without dashes, which are added only when code is shown, so changing
+-- REFERRAL_CODE_GROUP_SIZE does not orphan stored codes. Codes issued before check characters are
+-- 8 characters long and may contain dashes of their own, so they are left as they are
+UPDATE referral_codes
+SET code = REPLACE(code, '-', '')
+WHERE code ~ '^[0-9A-HJKMNP-TV-Z]+(-[0-9A-HJKMNP-TV-Z]+)+$'
+  AND LENGTH(code) <> 8;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Grouping of canonical codes is configurable, so dashes are not restored
+SELECT 1;
+-- +goose StatementEnd
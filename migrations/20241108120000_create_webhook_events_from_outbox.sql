@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Webhook events are created from outbox messages, so event is delivered if and only if change is committed.
+-- Messages recorded before were already turned into webhook events by service
+ALTER TABLE outbox
+    ADD COLUMN webhook_enqueued_at TIMESTAMPTZ;
+
+UPDATE outbox SET webhook_enqueued_at = NOW();
+
+CREATE INDEX outbox_webhook_pending_idx ON outbox (id) WHERE webhook_enqueued_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS outbox_webhook_pending_idx;
+
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS webhook_enqueued_at;
+-- +goose StatementEnd
@@ -0,0 +1,107 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Feed follows messages in order their transactions commit. Position is taken at commit under lock held
+-- until commit ends, so message of transaction committed late never gets position below one already seen
+-- by client. Existing messages keep their ids as positions, so Last-Event-ID of connected clients stays valid
+ALTER TABLE outbox
+    ADD COLUMN feed_position BIGINT;
+
+UPDATE outbox SET feed_position = id;
+
+CREATE SEQUENCE outbox_feed_position_seq;
+SELECT setval('outbox_feed_position_seq', COALESCE((SELECT MAX(id) FROM outbox), 0) + 1, false);
+
+DROP INDEX IF EXISTS outbox_user_id_idx;
+CREATE INDEX outbox_user_feed_position_idx ON outbox (user_id, feed_position)
+    WHERE user_id IS NOT NULL AND feed_position IS NOT NULL;
+CREATE UNIQUE INDEX outbox_feed_position_idx ON outbox (feed_position);
+
+-- Owner of message is still looked up while aggregate is in place, notification is sent at commit
+CREATE OR REPLACE FUNCTION outbox_referral_feed() RETURNS trigger AS $$
+BEGIN
+    IF NEW.user_id IS NULL THEN
+        IF NEW.aggregate_type = 'referral' THEN
+            NEW.user_id := (SELECT referrer_id FROM referrals WHERE id = NEW.aggregate_id);
+        ELSIF NEW.aggregate_type = 'referral_code' THEN
+            NEW.user_id := (SELECT referrer_id FROM referral_codes WHERE id = NEW.aggregate_id);
+        END IF;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Runs when transaction commits, every app instance listening on referral_feed channel is notified
+-- in order of positions
+CREATE FUNCTION outbox_feed_position() RETURNS trigger AS $$
+DECLARE
+    next_position BIGINT;
+    message TEXT;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('outbox_feed_position'));
+    next_position := nextval('outbox_feed_position_seq');
+    UPDATE outbox SET feed_position = next_position WHERE id = NEW.id;
+
+    IF NEW.user_id IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    message := json_build_object('id', next_position, 'user_id', NEW.user_id, 'type', NEW.event_type,
+                                 'data', NEW.payload, 'created_at', NEW.created_at)::text;
+    -- Notification payload is limited to 8000 bytes, listeners read data of larger messages from outbox
+    IF octet_length(message) > 7900 THEN
+        message := json_build_object('id', next_position, 'user_id', NEW.user_id, 'type', NEW.event_type,
+                                     'created_at', NEW.created_at)::text;
+    END IF;
+    PERFORM pg_notify('referral_feed', message);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER outbox_feed_position AFTER INSERT ON outbox
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION outbox_feed_position();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS outbox_feed_position ON outbox;
+DROP FUNCTION IF EXISTS outbox_feed_position();
+
+CREATE OR REPLACE FUNCTION outbox_referral_feed() RETURNS trigger AS $$
+DECLARE
+    message TEXT;
+BEGIN
+    IF NEW.user_id IS NULL THEN
+        IF NEW.aggregate_type = 'referral' THEN
+            NEW.user_id := (SELECT referrer_id FROM referrals WHERE id = NEW.aggregate_id);
+        ELSIF NEW.aggregate_type = 'referral_code' THEN
+            NEW.user_id := (SELECT referrer_id FROM referral_codes WHERE id = NEW.aggregate_id);
+        END IF;
+    END IF;
+
+    IF NEW.user_id IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    message := json_build_object('id', NEW.id, 'user_id', NEW.user_id, 'type', NEW.event_type,
+                                 'data', NEW.payload, 'created_at', NEW.created_at)::text;
+    IF octet_length(message) > 7900 THEN
+        message := json_build_object('id', NEW.id, 'user_id', NEW.user_id, 'type', NEW.event_type,
+                                     'created_at', NEW.created_at)::text;
+    END IF;
+    PERFORM pg_notify('referral_feed', message);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS outbox_feed_position_idx;
+DROP INDEX IF EXISTS outbox_user_feed_position_idx;
+CREATE INDEX outbox_user_id_idx ON outbox (user_id, id) WHERE user_id IS NOT NULL;
+DROP SEQUENCE IF EXISTS outbox_feed_position_seq;
+
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS feed_position;
+-- +goose StatementEnd